@@ -2,15 +2,51 @@ package main
 
 import (
 	"astral/env"
+	"astral/internal/domain/contracts"
 	"astral/internal/presentation"
+	accountrepo "astral/internal/repository/account"
+	adminrepo "astral/internal/repository/admin"
+	auditrepo "astral/internal/repository/audit"
 	authrepo "astral/internal/repository/auth"
+	brokerrepo "astral/internal/repository/broker"
+	commentsrepo "astral/internal/repository/comments"
 	miniostorage "astral/internal/repository/db/minio"
 	pg "astral/internal/repository/db/postgres"
 	"astral/internal/repository/db/redis"
+	docdatarepo "astral/internal/repository/docdata"
 	filesrepo "astral/internal/repository/files"
+	imagesrepo "astral/internal/repository/images"
+	locksrepo "astral/internal/repository/locks"
+	notificationsrepo "astral/internal/repository/notifications"
+	outboxrepo "astral/internal/repository/outbox"
+	reconcilerepo "astral/internal/repository/reconcile"
+	replicationrepo "astral/internal/repository/replication"
+	s3repo "astral/internal/repository/s3"
+	transferrepo "astral/internal/repository/transfer"
+	uploadsrepo "astral/internal/repository/uploads"
+	tagsrepo "astral/internal/repository/tags"
+	webhooksrepo "astral/internal/repository/webhooks"
+	accountservice "astral/internal/services/account"
+	activityservice "astral/internal/services/activity"
+	adminservice "astral/internal/services/admin"
+	archivesservice "astral/internal/services/archives"
+	auditservice "astral/internal/services/audit"
 	authservice "astral/internal/services/authorization"
+	commentsservice "astral/internal/services/comments"
 	fileservice "astral/internal/services/files"
+	imagesservice "astral/internal/services/images"
+	jsondocsservice "astral/internal/services/jsondocs"
+	linksservice "astral/internal/services/links"
+	locksservice "astral/internal/services/locks"
+	notificationsservice "astral/internal/services/notifications"
+	outboxservice "astral/internal/services/outbox"
+	reconcileservice "astral/internal/services/reconcile"
+	replicationservice "astral/internal/services/replication"
+	s3service "astral/internal/services/s3"
+	scrubservice "astral/internal/services/scrub"
+	tagsservice "astral/internal/services/tags"
 	validationservice "astral/internal/services/validation"
+	webhooksservice "astral/internal/services/webhooks"
 	"astral/logger"
 	"context"
 	"fmt"
@@ -34,6 +70,27 @@ func main() {
 
 	validatonService := validationservice.NewValidationService(logger)
 
+	outboxPersister := outboxrepo.NewOutboxPersister(pgStorage, logger)
+	broker, err := newBroker(env.Broker)
+	if err != nil {
+		logger.Error("failed to connect to broker", "error", err, "driver", env.Broker.Driver)
+		return
+	}
+	defer broker.Close()
+
+	relayService := outboxservice.NewRelayService(
+		outboxPersister,
+		broker,
+		logger,
+		env.Broker.RelayInterval,
+		env.Broker.BatchSize,
+		env.Broker.MaxBackoff,
+		env.Broker.Retention,
+	)
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	defer stopRelay()
+	go relayService.Run(relayCtx)
+
 	authPersister := authrepo.NewUserPersister(pgStorage, logger)
 	authService := authservice.NewAuthService(authPersister, logger, validatonService, env.JWTSecret, env.AdminToken)
 
@@ -43,15 +100,151 @@ func main() {
 		return
 	}
 
-	filesPersister := filesrepo.NewFilePersister(*minioStorage, logger)
+	var replicaStorage *miniostorage.MinioStorage
+	var replicationQueue replicationrepo.QueueRepo
+	var replicationService contracts.ReplicationInterface
+	replicationCtx, stopReplication := context.WithCancel(context.Background())
+	defer stopReplication()
+
+	if env.Replication.Enabled {
+		replicaStorage, err = miniostorage.NewMinioStorage(&env.Replication.Replica)
+		if err != nil {
+			logger.Error("failed to connect to replica minio", "error", err, "endpoint", env.Replication.Replica.Endpoint)
+			return
+		}
+
+		queuePersister := replicationrepo.NewQueuePersister(pgStorage, logger)
+		objectsPersister := replicationrepo.NewObjectsPersister(*minioStorage, *replicaStorage, logger)
+		replicator := replicationservice.NewReplicationService(
+			queuePersister,
+			objectsPersister,
+			logger,
+			env.Replication.Interval,
+			env.Replication.BatchSize,
+			env.Replication.MaxBackoff,
+		)
+		go replicator.Run(replicationCtx)
+
+		replicationQueue = queuePersister
+		replicationService = replicator
+	}
+
+	var presignStorage *miniostorage.MinioStorage
+	if env.Presign.Enabled {
+		presignStorage, err = miniostorage.NewPresignStorage(&env.MinIO, &env.Presign)
+		if err != nil {
+			logger.Error("failed to create presign minio client", "error", err, "endpoint", env.Presign.Endpoint)
+			return
+		}
+	}
+
+	filesPersister := filesrepo.NewFilePersister(*minioStorage, replicaStorage, replicationQueue, env.Compression, presignStorage, env.Presign, logger)
 	cachPersister, err := redis.NewConnectRedis(env.Redis, logger)
 	if err != nil {
 		logger.Error("failed to connect to database redis", "error", err, "bucket", env.MinIO.BucketName)
 		return
 	}
-	fileService := fileservice.NewFileService(filesPersister, *cachPersister, logger)
+	dataPersister := docdatarepo.NewDataPersister(pgStorage, logger)
+	locksPersister := locksrepo.NewLocksPersister(cachPersister.DB, logger)
+	transferPersister := transferrepo.NewTransferPersister(pgStorage, logger)
+	uploadsPersister := uploadsrepo.NewUploadsPersister(pgStorage, logger)
+	imagesStorage, err := newImagesStorage(env.MinIO, env.Images)
+	if err != nil {
+		logger.Error("failed to prepare images bucket", "error", err, "bucket", env.Images.Bucket)
+		return
+	}
+	imagesPersister := imagesrepo.NewImagesPersister(*imagesStorage, cachPersister.DB, env.Images, logger)
+	fileService := fileservice.NewFileService(filesPersister, *cachPersister, dataPersister, outboxPersister, locksPersister, transferPersister, uploadsPersister, imagesPersister, env.Metadata, logger)
+
+	multipartPersister := s3repo.NewMultipartPersister(pgStorage, *minioStorage, logger)
+	s3Service := s3service.NewS3Service(fileService, multipartPersister, logger)
+
+	jsonDocsService := jsondocsservice.NewJSONDocsService(fileService, dataPersister, logger)
+
+	tagsPersister := tagsrepo.NewTagsPersister(pgStorage, logger)
+	tagsService := tagsservice.NewTagsService(tagsPersister, fileService, logger)
+
+	archivesService := archivesservice.NewArchivesService(fileService, logger)
+
+	locksService := locksservice.NewLocksService(locksPersister, fileService, logger, env.AdminToken, env.Locks.DefaultTTL, env.Locks.MaxTTL)
+
+	auditPersister := auditrepo.NewAuditPersister(pgStorage, logger)
+	auditService := auditservice.NewAuditService(auditPersister, logger, env.AdminToken)
 
-	app := presentation.New(logger, env, authService, validatonService, fileService)
+	webhooksPersister := webhooksrepo.NewWebhooksPersister(pgStorage, logger)
+	webhooksService := webhooksservice.NewWebhooksService(
+		webhooksPersister,
+		logger,
+		env.JWTSecret,
+		env.Webhooks.Interval,
+		env.Webhooks.BatchSize,
+		env.Webhooks.MaxAttempts,
+		env.Webhooks.MaxBackoff,
+		env.Webhooks.Timeout,
+	)
+	webhooksCtx, stopWebhooks := context.WithCancel(context.Background())
+	defer stopWebhooks()
+	go webhooksService.Run(webhooksCtx)
+
+	notificationsPersister := notificationsrepo.NewNotificationsPersister(cachPersister.DB, logger, env.Notifications.History, env.Notifications.Retention)
+	notificationsService := notificationsservice.NewNotificationsService(notificationsPersister, logger, env.Notifications.Buffer, env.Notifications.History)
+	notificationsCtx, stopNotifications := context.WithCancel(context.Background())
+	defer stopNotifications()
+	go notificationsService.Run(notificationsCtx)
+
+	activityService := activityservice.NewActivityService(auditService, webhooksService, notificationsService)
+
+	commentsPersister := commentsrepo.NewCommentsPersister(pgStorage, logger)
+	commentsService := commentsservice.NewCommentsService(commentsPersister, fileService, notificationsService, logger)
+
+	var scrubService contracts.ScrubInterface
+	scrubCtx, stopScrub := context.WithCancel(context.Background())
+	defer stopScrub()
+
+	if env.Scrub.Enabled {
+		scrubber := scrubservice.NewScrubService(filesPersister, fileService, webhooksService, notificationsService, logger, env.Scrub.Interval)
+		go scrubber.Run(scrubCtx)
+
+		scrubService = scrubber
+	}
+
+	reconcileCtx, stopReconcile := context.WithCancel(context.Background())
+	defer stopReconcile()
+
+	if env.Reconcile.Enabled {
+		reconcilePersister := reconcilerepo.NewReconcilePersister(pgStorage, *minioStorage, replicationQueue, cachPersister.DB, logger)
+		reconciler := reconcileservice.NewReconcileService(reconcilePersister, logger, env.Reconcile.Interval, env.Reconcile.Repair, env.Reconcile.MinAge)
+		go reconciler.Run(reconcileCtx)
+	}
+
+	accountPersister := accountrepo.NewAccountPersister(pgStorage, *minioStorage, replicationQueue, cachPersister.DB, logger)
+	accountService := accountservice.NewAccountService(
+		accountPersister,
+		authPersister,
+		authService,
+		fileService,
+		imagesPersister,
+		logger,
+		env.Account.DeletionGrace,
+		env.Account.ExportTTL,
+		env.Account.Interval,
+	)
+	accountCtx, stopAccount := context.WithCancel(context.Background())
+	defer stopAccount()
+	go accountService.Run(accountCtx)
+
+	adminPersister := adminrepo.NewAdminPersister(pgStorage, *minioStorage, logger)
+	adminService := adminservice.NewAdminService(adminPersister, fileService, validatonService, logger, env.AdminToken)
+
+	linksService, err := linksservice.NewLinksService(fileService, env.Links, logger)
+	if err != nil {
+		logger.Error("failed to configure signed links", "error", err)
+		return
+	}
+
+	imagesService := imagesservice.NewImagesService(fileService, imagesPersister, env.Images, logger)
+
+	app := presentation.New(logger, env, authService, validatonService, fileService, replicationService, scrubService, s3Service, jsonDocsService, tagsService, commentsService, locksService, archivesService, auditService, webhooksService, notificationsService, activityService, accountService, adminService, linksService, imagesService)
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
@@ -90,6 +283,50 @@ func main() {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
+
+		stopReplication()
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		stopWebhooks()
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		stopRelay()
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		stopNotifications()
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		stopScrub()
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		stopReconcile()
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		stopAccount()
 	}()
 
 	if len(errors) > 0 {
@@ -97,4 +334,39 @@ func main() {
 	} else {
 		logger.Info("Application has been shutdown gracefully")
 	}
-}
\ No newline at end of file
+}
+
+// newBroker выбирает брокер для публикации событий из outbox
+func newBroker(cfg env.Broker) (brokerrepo.Broker, error) {
+	switch cfg.Driver {
+	case "memory":
+		return brokerrepo.NewMemoryBroker(), nil
+	case "nats":
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		return brokerrepo.NewNatsBroker(ctx, cfg.NatsURL, cfg.NatsStream, cfg.SubjectPrefix)
+	case "kafka":
+		return brokerrepo.NewKafkaBroker(cfg.KafkaBrokers, cfg.KafkaTopic), nil
+	default:
+		return nil, fmt.Errorf("unknown broker driver %q", cfg.Driver)
+	}
+}
+
+// newImagesStorage подключает бакет вариантов изображений в том же MinIO и ставит на него срок хранения
+func newImagesStorage(cfg env.MinIO, images env.Images) (*miniostorage.MinioStorage, error) {
+	cfg.BucketName = images.Bucket
+	storage, err := miniostorage.NewMinioStorage(&cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := storage.SetExpiration(ctx, images.CacheTTL); err != nil {
+		return nil, err
+	}
+
+	return storage, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"astral/env"
+	miniostorage "astral/internal/repository/db/minio"
+	pg "astral/internal/repository/db/postgres"
+	"astral/internal/repository/db/redis"
+	reconcilerepo "astral/internal/repository/reconcile"
+	replicationrepo "astral/internal/repository/replication"
+	reconcileservice "astral/internal/services/reconcile"
+	"astral/logger"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+func main() {
+	var repair bool
+	var dryRun bool
+	var timeout time.Duration
+	var minAge time.Duration
+
+	flag.BoolVar(&repair, "repair", false, "delete orphaned objects, dangling rows and stale cache entries")
+	flag.BoolVar(&dryRun, "dry-run", false, "with -repair, only report what would be repaired")
+	flag.DurationVar(&timeout, "timeout", time.Hour, "maximum duration of the check")
+	flag.DurationVar(&minAge, "min-age", 0, "ignore objects younger than this, RECONCILE_MIN_AGE by default")
+	flag.Parse()
+
+	env := env.MustLoad()
+	logger := logger.NewLogger(env.Env)
+
+	if minAge == 0 {
+		minAge = env.Reconcile.MinAge
+	}
+
+	pgStorage, err := pg.NewDBConnection(&env.PgSql)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	minioStorage, err := miniostorage.NewMinioStorage(&env.MinIO)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cache, err := redis.NewConnectRedis(env.Redis, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Удаленные объекты должны удалиться и из реплики
+	var queue replicationrepo.QueueRepo
+	if env.Replication.Enabled {
+		queue = replicationrepo.NewQueuePersister(pgStorage, logger)
+	}
+
+	persister := reconcilerepo.NewReconcilePersister(pgStorage, *minioStorage, queue, cache.DB, logger)
+	service := reconcileservice.NewReconcileService(persister, logger, env.Reconcile.Interval, repair, minAge)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	report, err := service.Reconcile(ctx, repair, dryRun)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+
+	if unrepaired := report.Total() - report.Repaired; unrepaired > 0 {
+		fmt.Fprintf(os.Stderr, "%d inconsistencies are not repaired\n", unrepaired)
+		os.Exit(1)
+	}
+}
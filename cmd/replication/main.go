@@ -0,0 +1,70 @@
+package main
+
+import (
+	"astral/env"
+	miniostorage "astral/internal/repository/db/minio"
+	replicationrepo "astral/internal/repository/replication"
+	replicationservice "astral/internal/services/replication"
+	"astral/logger"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+func main() {
+	var repair bool
+	var prune bool
+	var timeout time.Duration
+
+	flag.BoolVar(&repair, "repair", false, "copy missing or mismatched objects to the replica and restore replica-only objects to the primary")
+	flag.BoolVar(&prune, "prune", false, "remove objects that exist only on the replica instead of restoring them")
+	flag.DurationVar(&timeout, "timeout", time.Hour, "maximum duration of the check")
+	flag.Parse()
+
+	env := env.MustLoad()
+	logger := logger.NewLogger(env.Env)
+
+	primary, err := miniostorage.NewMinioStorage(&env.MinIO)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	replica, err := miniostorage.NewMinioStorage(&env.Replication.Replica)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	objects := replicationrepo.NewObjectsPersister(*primary, *replica, logger)
+	service := replicationservice.NewReplicationService(
+		nil,
+		objects,
+		logger,
+		env.Replication.Interval,
+		env.Replication.BatchSize,
+		env.Replication.MaxBackoff,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	report, err := service.Verify(ctx, repair, prune)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+
+	diverged := len(report.MissingOnReplica) + len(report.MissingOnPrimary) + len(report.Mismatched)
+	if diverged > 0 && !repair && !prune {
+		fmt.Fprintf(os.Stderr, "%d objects differ between primary and replica\n", diverged)
+		os.Exit(1)
+	}
+}
@@ -3,6 +3,7 @@ package env
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
@@ -11,9 +12,24 @@ import (
 type Env struct {
 	Env   		string 	  `env:"ENV" env-required:"true"`
 	Http  		Http   	  `env-required:"true"`
+	Grpc        Grpc
 	PgSql 		PgSql  	  `env-required:"true"`
 	MinIO 		MinIO  	  `env-required:"true"`
 	Redis 		Redis     `env-required:"true"`
+	Replication Replication
+	Webhooks    Webhooks
+	Broker      Broker
+	Notifications Notifications
+	Locks       Locks
+	Compression Compression
+	Scrub       Scrub
+	Reconcile   Reconcile
+	Account     Account
+	Presign     Presign
+	Links       Links
+	Images      Images
+	Metadata    Metadata
+	Metrics     Metrics
 	AdminToken 	string 	  `env:"ADMIN_TOKEN" env-required:"true"`
 	JWTSecret 	string    `env:"JWT_SECRET" env-required:"true"`
 }
@@ -21,6 +37,13 @@ type Env struct {
 type Http struct {
 	Host string `env:"HOST" env-required:"true"`
 	Port string `env:"PORT" env-required:"true"`
+	// TrustedProxies - адреса и подсети прокси, которым доверяется X-Forwarded-For и X-Real-IP.
+	// По умолчанию не доверяется никому и адресом клиента считается адрес соединения
+	TrustedProxies []string `env:"TRUSTED_PROXIES" env-separator:","`
+}
+
+type Grpc struct {
+	Port string `env:"GRPC_PORT" env-default:"9090"`
 }
 
 type PgSql struct {
@@ -48,6 +71,123 @@ type Redis struct {
 	Pass string `env:"REDIS_PASS" env-required:"true"`
 }
 
+type Replication struct {
+	Enabled    bool          `env:"REPLICATION_ENABLED" env-default:"false"`
+	Interval   time.Duration `env:"REPLICATION_INTERVAL" env-default:"5s"`
+	BatchSize  int           `env:"REPLICATION_BATCH_SIZE" env-default:"50"`
+	MaxBackoff time.Duration `env:"REPLICATION_MAX_BACKOFF" env-default:"30m"`
+	Replica    MinIO         `env-prefix:"REPLICA_"`
+}
+
+type Webhooks struct {
+	Interval    time.Duration `env:"WEBHOOKS_INTERVAL" env-default:"5s"`
+	BatchSize   int           `env:"WEBHOOKS_BATCH_SIZE" env-default:"20"`
+	MaxAttempts int           `env:"WEBHOOKS_MAX_ATTEMPTS" env-default:"10"`
+	MaxBackoff  time.Duration `env:"WEBHOOKS_MAX_BACKOFF" env-default:"1h"`
+	Timeout     time.Duration `env:"WEBHOOKS_TIMEOUT" env-default:"10s"`
+}
+
+type Broker struct {
+	Driver        string        `env:"BROKER_DRIVER" env-default:"memory"`
+	RelayInterval time.Duration `env:"BROKER_RELAY_INTERVAL" env-default:"1s"`
+	BatchSize     int           `env:"BROKER_RELAY_BATCH_SIZE" env-default:"100"`
+	MaxBackoff    time.Duration `env:"BROKER_RELAY_MAX_BACKOFF" env-default:"5m"`
+	Retention     time.Duration `env:"BROKER_OUTBOX_RETENTION" env-default:"168h"`
+	SubjectPrefix string        `env:"BROKER_SUBJECT_PREFIX" env-default:"astral"`
+	NatsURL       string        `env:"NATS_URL" env-default:"nats://localhost:4222"`
+	NatsStream    string        `env:"NATS_STREAM" env-default:"ASTRAL"`
+	KafkaBrokers  []string      `env:"KAFKA_BROKERS" env-separator:"," env-default:"localhost:9092"`
+	KafkaTopic    string        `env:"KAFKA_TOPIC" env-default:"astral.events"`
+}
+
+type Notifications struct {
+	Heartbeat time.Duration `env:"NOTIFICATIONS_HEARTBEAT" env-default:"15s"`
+	History   int64         `env:"NOTIFICATIONS_HISTORY" env-default:"1000"`
+	Retention time.Duration `env:"NOTIFICATIONS_RETENTION" env-default:"24h"`
+	Buffer    int           `env:"NOTIFICATIONS_BUFFER" env-default:"64"`
+}
+
+type Locks struct {
+	DefaultTTL time.Duration `env:"LOCKS_DEFAULT_TTL" env-default:"30m"`
+	MaxTTL     time.Duration `env:"LOCKS_MAX_TTL" env-default:"24h"`
+}
+
+type Compression struct {
+	Algorithm string `env:"STORAGE_COMPRESSION" env-default:"none"`
+	Threshold int    `env:"STORAGE_COMPRESSION_THRESHOLD" env-default:"1024"`
+}
+
+type Scrub struct {
+	Enabled  bool          `env:"SCRUB_ENABLED" env-default:"true"`
+	Interval time.Duration `env:"SCRUB_INTERVAL" env-default:"24h"`
+}
+
+type Reconcile struct {
+	Enabled  bool          `env:"RECONCILE_ENABLED" env-default:"false"`
+	Interval time.Duration `env:"RECONCILE_INTERVAL" env-default:"24h"`
+	Repair   bool          `env:"RECONCILE_REPAIR" env-default:"false"`
+	MinAge   time.Duration `env:"RECONCILE_MIN_AGE" env-default:"1h"`
+}
+
+type Account struct {
+	DeletionGrace time.Duration `env:"ACCOUNT_DELETION_GRACE" env-default:"720h"`
+	ExportTTL     time.Duration `env:"ACCOUNT_EXPORT_TTL" env-default:"168h"`
+	Interval      time.Duration `env:"ACCOUNT_INTERVAL" env-default:"1m"`
+}
+
+// Presign - выдача подписанных ссылок на прямую загрузку в MinIO и скачивание из него. Endpoint -
+// адрес MinIO, доступный клиентам; пустой означает MINIO_ENDPOINT
+type Presign struct {
+	Enabled  bool          `env:"PRESIGN_ENABLED" env-default:"false"`
+	Endpoint string        `env:"PRESIGN_ENDPOINT"`
+	UseSSL   bool          `env:"PRESIGN_USE_SSL" env-default:"false"`
+	TTL      time.Duration `env:"PRESIGN_TTL" env-default:"15m"`
+	MaxSize  int64         `env:"PRESIGN_MAX_SIZE" env-default:"5368709120"`
+}
+
+// Links - подписанные ссылки на скачивание документов без токена. Пустой Key выключает их.
+// После смены ключа прежний указывается в PreviousKey и принимается до PreviousKeyUntil (RFC 3339).
+// BaseURL - внешний адрес сервиса для ссылок, пустой - адрес из запроса
+type Links struct {
+	Key              string        `env:"LINKS_KEY"`
+	PreviousKey      string        `env:"LINKS_PREVIOUS_KEY"`
+	PreviousKeyUntil string        `env:"LINKS_PREVIOUS_KEY_UNTIL"`
+	BaseURL          string        `env:"LINKS_BASE_URL"`
+	DefaultTTL       time.Duration `env:"LINKS_DEFAULT_TTL" env-default:"24h"`
+	MaxTTL           time.Duration `env:"LINKS_MAX_TTL" env-default:"168h"`
+}
+
+// Images - преобразование изображений при скачивании. Результаты хранятся в отдельном бакете Bucket
+// и удаляются правилом жизненного цикла через CacheTTL (с точностью до дня); варианты не больше
+// RedisMaxSize байт дополнительно кэшируются в Redis на RedisTTL. MaxSourcePixels ограничивает
+// размер исходного изображения, MaxConcurrency - число одновременных преобразований
+type Images struct {
+	Bucket          string        `env:"IMAGES_BUCKET" env-default:"images"`
+	MaxWidth        int           `env:"IMAGES_MAX_WIDTH" env-default:"4096"`
+	MaxHeight       int           `env:"IMAGES_MAX_HEIGHT" env-default:"4096"`
+	MaxSourcePixels int           `env:"IMAGES_MAX_SOURCE_PIXELS" env-default:"50000000"`
+	MaxConcurrency  int           `env:"IMAGES_MAX_CONCURRENCY" env-default:"4"`
+	QueueTimeout    time.Duration `env:"IMAGES_QUEUE_TIMEOUT" env-default:"5s"`
+	Quality         int           `env:"IMAGES_QUALITY" env-default:"85"`
+	CacheTTL        time.Duration `env:"IMAGES_CACHE_TTL" env-default:"720h"`
+	RedisMaxSize    int           `env:"IMAGES_REDIS_MAX_SIZE" env-default:"262144"`
+	RedisTTL        time.Duration `env:"IMAGES_REDIS_TTL" env-default:"1h"`
+}
+
+// Metadata - извлечение метаданных из содержимого при загрузке. StripGPS удаляет координаты из EXIF
+// всех загружаемых JPEG, без него - только по запросу клиента. MaxScan - сколько байт документа
+// разбор читает из содержимого; PDF больше предела читается с начала и с конца
+type Metadata struct {
+	Enabled  bool  `env:"METADATA_EXTRACT" env-default:"true"`
+	StripGPS bool  `env:"METADATA_STRIP_GPS" env-default:"false"`
+	MaxScan  int64 `env:"METADATA_MAX_SCAN" env-default:"33554432"`
+}
+
+// Metrics - без токена /metrics не обслуживается: метрики раскрывают состояние хранилища
+type Metrics struct {
+	Token string `env:"METRICS_TOKEN"`
+}
+
 func (c *Http) GetPort() string {
 	if envPort := os.Getenv("PORT"); envPort != "" {
 		return envPort
@@ -0,0 +1,59 @@
+package account
+
+import "time"
+
+type ExportStatus string
+
+const (
+	ExportPending ExportStatus = "pending"
+	ExportRunning ExportStatus = "running"
+	ExportReady   ExportStatus = "ready"
+	ExportFailed  ExportStatus = "failed"
+	ExportExpired ExportStatus = "expired"
+)
+
+// Export - задание на выгрузку всех данных пользователя. Архив собирается в фоне и доступен
+// для скачивания до ExpiresAt
+type Export struct {
+	ID          string       `json:"id"`
+	Login       string       `json:"user_login"`
+	Status      ExportStatus `json:"status"`
+	Size        int64        `json:"size,omitempty"`
+	Error       string       `json:"error,omitempty"`
+	CreatedAt   *time.Time   `json:"created_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time   `json:"expires_at,omitempty"`
+}
+
+// Deletion - запрошенное удаление учетной записи. До DeleteAfter удаление можно отменить,
+// после него данные пользователя удаляются безвозвратно
+type Deletion struct {
+	Login       string     `json:"login"`
+	RequestedAt *time.Time `json:"requested_at"`
+	DeleteAfter *time.Time `json:"delete_after"`
+}
+
+type SessionKind string
+
+const (
+	SessionToken       SessionKind = "token"
+	SessionAppPassword SessionKind = "app_password"
+	SessionAccessKey   SessionKind = "access_key"
+)
+
+// Session - способ входа пользователя в выгрузке. Секреты в выгрузку не попадают, ID токенов
+// и ключей маскируются
+type Session struct {
+	Kind      SessionKind `json:"kind"`
+	ID        string      `json:"id"`
+	Name      string      `json:"name,omitempty"`
+	CreatedAt *time.Time  `json:"created_at"`
+}
+
+// Share - документ пользователя, к которому выдан доступ другим
+type Share struct {
+	DocumentID string   `json:"document_id"`
+	Name       string   `json:"name"`
+	Public     bool     `json:"public"`
+	Grant      []string `json:"grant"`
+}
@@ -0,0 +1,40 @@
+package admin
+
+import "time"
+
+type UserStatus string
+
+const (
+	StatusActive          UserStatus = "active"
+	StatusDisabled        UserStatus = "disabled"
+	StatusPendingDeletion UserStatus = "pending_deletion"
+)
+
+// User - учетная запись в выдаче администратора. Sessions - число действующих токенов
+type User struct {
+	Login          string     `json:"login"`
+	Status         UserStatus `json:"status"`
+	Sessions       int        `json:"sessions"`
+	DisabledReason string     `json:"disabled_reason,omitempty"`
+	CreatedAt      *time.Time `json:"created_at"`
+	UpdatedAt      *time.Time `json:"updated_at"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"`
+	DeleteAfter    *time.Time `json:"delete_after,omitempty"`
+	Usage          *Usage     `json:"usage,omitempty"`
+}
+
+// Usage - занятое пользователем место. StoredBytes - размер объектов в хранилище после сжатия,
+// Bytes - исходный размер документов
+type Usage struct {
+	Documents   int   `json:"documents"`
+	Bytes       int64 `json:"bytes"`
+	StoredBytes int64 `json:"stored_bytes"`
+}
+
+// UserFilter - поиск учетных записей: Query - подстрока логина
+type UserFilter struct {
+	Query  string
+	Status UserStatus
+	Limit  int
+	Offset int
+}
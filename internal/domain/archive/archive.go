@@ -0,0 +1,50 @@
+package archive
+
+import (
+	"strings"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindZip   Kind = "zip"
+	KindTar   Kind = "tar"
+	KindTarGz Kind = "tar.gz"
+)
+
+// Entry - запись архива. Name - путь внутри архива без ведущего слеша
+type Entry struct {
+	Name           string    `json:"name"`
+	Size           int64     `json:"size"`
+	CompressedSize int64     `json:"compressed_size,omitempty"`
+	Modified       time.Time `json:"modified"`
+	Dir            bool      `json:"dir,omitempty"`
+}
+
+// KindOf определяет формат архива по имени документа, false - документ не архив
+func KindOf(name string) (Kind, bool) {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return KindZip, true
+	case strings.HasSuffix(name, ".tar"):
+		return KindTar, true
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return KindTarGz, true
+	}
+
+	return "", false
+}
+
+// TrimExt возвращает имя архива без расширения формата
+func TrimExt(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".tar.gz", ".tgz", ".tar", ".zip"} {
+		if strings.HasSuffix(lower, ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+
+	return name
+}
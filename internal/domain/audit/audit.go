@@ -0,0 +1,71 @@
+package audit
+
+import "time"
+
+type Action string
+
+const (
+	ActionUpload         Action = "upload"
+	ActionDownload       Action = "download"
+	ActionMetadataUpdate Action = "metadata_update"
+	ActionDelete         Action = "delete"
+	ActionShare          Action = "share"
+	ActionComment        Action = "comment"
+	ActionLock           Action = "lock"
+	ActionUnlock         Action = "unlock"
+	ActionLogin          Action = "login"
+	ActionLogout         Action = "logout"
+	ActionAuthFailed     Action = "auth_failed"
+	ActionExport         Action = "export"
+	ActionAccountDelete  Action = "account_delete"
+	ActionAccountRestore Action = "account_restore"
+	ActionCopy           Action = "copy"
+	ActionTransfer       Action = "transfer"
+	// Действия администратора, автор таких событий - ActorAdmin
+	ActionAdminView      Action = "admin_view"
+	ActionUserDisable    Action = "user_disable"
+	ActionUserEnable     Action = "user_enable"
+	ActionSessionsRevoke Action = "sessions_revoke"
+	ActionPasswordReset  Action = "password_reset"
+)
+
+// ActorAdmin - автор действий, выполненных с токеном администратора. Логин пользователя не
+// короче 8 символов, поэтому с ним не совпадает
+const ActorAdmin = "admin"
+
+type TargetType string
+
+const (
+	TargetDocument TargetType = "document"
+	TargetSession  TargetType = "session"
+	TargetUser     TargetType = "user"
+	TargetExport   TargetType = "export"
+)
+
+// Event - неизменяемая запись журнала аудита. Owner - владелец объекта действия,
+// по нему владелец документа видит, кто и что делал с его документами
+type Event struct {
+	ID         int64             `json:"id"`
+	Action     Action            `json:"action"`
+	Actor      string            `json:"actor,omitempty"`
+	Owner      string            `json:"owner,omitempty"`
+	TargetType TargetType        `json:"target_type,omitempty"`
+	TargetID   string            `json:"target_id,omitempty"`
+	IP         string            `json:"ip,omitempty"`
+	UserAgent  string            `json:"user_agent,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+	CreatedAt  *time.Time        `json:"created_at"`
+}
+
+// Subject ограничивает выборку событиями, где пользователь - автор действия или владелец объекта
+type Filter struct {
+	Subject  string
+	Actor    string
+	Owner    string
+	Action   Action
+	TargetID string
+	From     *time.Time
+	To       *time.Time
+	BeforeID int64
+	Limit    int
+}
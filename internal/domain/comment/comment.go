@@ -0,0 +1,30 @@
+package comment
+
+import "time"
+
+// Comment - комментарий к документу. Ответы ссылаются на родителя через ParentID и
+// в выдаче вложены в Replies. Удаленный комментарий с ответами остается в ветке без текста
+type Comment struct {
+	ID         int64      `json:"id"`
+	DocumentID string     `json:"document_id"`
+	Owner      string     `json:"owner"`
+	ParentID   *int64     `json:"parent_id,omitempty"`
+	Author     string     `json:"author"`
+	Body       string     `json:"body"`
+	Mentions   []string   `json:"mentions,omitempty"`
+	Anchor     *Anchor    `json:"anchor,omitempty"`
+	Resolved   bool       `json:"resolved"`
+	ResolvedBy string     `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	Deleted    bool       `json:"deleted,omitempty"`
+	CreatedAt  *time.Time `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+	Replies    []Comment  `json:"replies,omitempty"`
+}
+
+// Anchor привязывает комментарий к странице документа или к диапазону байт [Start, End)
+type Anchor struct {
+	Page  *int   `json:"page,omitempty"`
+	Start *int64 `json:"start,omitempty"`
+	End   *int64 `json:"end,omitempty"`
+}
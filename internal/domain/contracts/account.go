@@ -0,0 +1,16 @@
+package contracts
+
+import (
+	"astral/internal/domain/account"
+	"astral/internal/domain/dto"
+	"astral/internal/domain/user"
+	"io"
+)
+
+type AccountInterface interface {
+	RequestDeletion(login string) (*account.Deletion, error)
+	RestoreAccount(userData dto.UserData) (*user.Token, error)
+	CreateExport(login string) (*account.Export, error)
+	GetExport(login, ID string) (*account.Export, error)
+	OpenExport(login, ID string) (*account.Export, io.ReadCloser, error)
+}
@@ -0,0 +1,12 @@
+package contracts
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/file"
+)
+
+type ActivityInterface interface {
+	Record(event audit.Event)
+	RecordDocument(event audit.Event, doc file.File)
+	RecordShare(event audit.Event, doc file.File)
+}
@@ -0,0 +1,20 @@
+package contracts
+
+import (
+	"astral/internal/domain/admin"
+	"astral/internal/domain/file"
+)
+
+type AdminInterface interface {
+	ListUsers(adminToken string, filter admin.UserFilter) ([]admin.User, error)
+	GetUser(adminToken, login string) (*admin.User, error)
+	DisableUser(adminToken, login, reason string) (*admin.User, error)
+	EnableUser(adminToken, login string) (*admin.User, error)
+	RevokeSessions(adminToken, login string) (int64, error)
+	ResetPassword(adminToken, login, password string) (string, error)
+	ListDocuments(adminToken, login string) ([]file.File, error)
+	GetDocument(adminToken, login, ID string) (*file.File, error)
+	OpenDocument(adminToken, login, ID string) (*file.File, file.Object, error)
+	DeleteDocument(adminToken, login, ID string) (*file.File, error)
+	TransferDocuments(adminToken, login, to string) (*file.Transfer, error)
+}
@@ -0,0 +1,14 @@
+package contracts
+
+import (
+	"astral/internal/domain/archive"
+	"astral/internal/domain/file"
+	"io"
+)
+
+type ArchivesInterface interface {
+	Document(login, owner, ID string) (*file.File, error)
+	ListEntries(login, owner, ID string) (*file.File, []archive.Entry, error)
+	StreamEntry(login, owner, ID, name string, fn func(doc file.File, entry archive.Entry, r io.Reader) error) error
+	Extract(login, ID, folder string) (*file.File, []file.File, error)
+}
@@ -0,0 +1,11 @@
+package contracts
+
+import (
+	"astral/internal/domain/audit"
+)
+
+type AuditInterface interface {
+	Record(event audit.Event)
+	Query(login, adminToken string, filter audit.Filter) ([]audit.Event, error)
+	Export(login, adminToken string, filter audit.Filter, fn func(audit.Event) error) error
+}
@@ -10,4 +10,12 @@ type AuthInterface interface {
 	Login(user dto.UserData) (*user.Token, error)
 	Authorization(token string) (*user.Token, error)
 	CloseSession(token dto.TokenData) (*user.Token, error)
+	CreateAppPassword(login, name string) (*user.AppPassword, error)
+	GetAppPasswords(login string) ([]user.AppPassword, error)
+	DeleteAppPassword(login, id string) (*user.AppPassword, error)
+	AuthorizationByAppPassword(login, password string) (*user.Token, error)
+	CreateAccessKey(login string) (*user.AccessKey, error)
+	GetAccessKeys(login string) ([]user.AccessKey, error)
+	DeleteAccessKey(login, id string) (*user.AccessKey, error)
+	GetAccessKey(id string) (*user.AccessKey, error)
 }
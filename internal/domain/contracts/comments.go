@@ -0,0 +1,14 @@
+package contracts
+
+import (
+	"astral/internal/domain/comment"
+	"astral/internal/domain/file"
+)
+
+type CommentsInterface interface {
+	GetComments(login, owner, documentID string) ([]comment.Comment, error)
+	CreateComment(login, owner, documentID string, parentID *int64, body string, anchor *comment.Anchor) (*file.File, *comment.Comment, error)
+	UpdateComment(login, owner, documentID string, id int64, body string) (*file.File, *comment.Comment, error)
+	DeleteComment(login, owner, documentID string, id int64) (*file.File, *comment.Comment, error)
+	ResolveComment(login, owner, documentID string, id int64, resolved bool) (*file.File, *comment.Comment, error)
+}
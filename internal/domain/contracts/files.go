@@ -2,13 +2,29 @@ package contracts
 
 import (
 	"astral/internal/domain/file"
+	"encoding/json"
+	"io"
 )
 
 type FilesInterface interface {
 	UploadFiles(fileData file.File) (*file.File, error)
 	GetFilesByUser(userID string, filter FilterData) ([]file.File, error)
 	GetFileByID(ID, userID string) (*file.File, error)
+	GetFileInfo(ID, userID string) (*file.File, error)
 	DeleteFile(ID, userID string) (*file.File, error)
+	UpdateData(ID, userID string, data json.RawMessage, revision int64) (*file.File, error)
+	UpdateFileInfo(ID, userID string, info file.File) (*file.File, error)
+	ReplaceFile(ID, userID string, content io.Reader, size int, digest *file.Digest) (*file.File, error)
+	SetStatus(ID, owner string, status file.Status) (*file.File, error)
+	CheckLock(ID, userID string) error
+	OpenObject(ID, owner string) (file.Object, error)
+	OpenRaw(ID, owner string) (io.ReadCloser, int64, string, error)
+	CopyFile(ID, userID, toUserID, name string) (*file.File, error)
+	TransferFile(ID, userID, toUserID string) (*file.File, error)
+	TransferAll(fromUserID, toUserID string) (*file.Transfer, error)
+	PresignUpload(fileData file.File) (*file.Presigned, error)
+	FinalizeUpload(ID, userID string) (*file.File, error)
+	PresignDownload(ID, userID string) (*file.File, *file.Presigned, error)
 }
 
 type FilterData struct {
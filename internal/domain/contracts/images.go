@@ -0,0 +1,10 @@
+package contracts
+
+import (
+	"astral/internal/domain/file"
+	"astral/internal/domain/imaging"
+)
+
+type ImagesInterface interface {
+	Transform(login, owner, ID string, opts imaging.Options) (*file.File, *imaging.Variant, error)
+}
@@ -0,0 +1,14 @@
+package contracts
+
+import (
+	"astral/internal/domain/file"
+)
+
+type JSONDocsInterface interface {
+	Create(login string, doc file.File) (*file.File, error)
+	List(login, owner string, filter FilterData) ([]file.File, error)
+	Get(login, ID string) (*file.File, error)
+	Replace(login, ID string, doc file.File, revision int64) (*file.File, error)
+	Patch(login, ID string, patch []byte, revision int64) (*file.File, error)
+	Delete(login, ID string, revision int64) (*file.File, error)
+}
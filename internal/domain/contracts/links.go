@@ -0,0 +1,12 @@
+package contracts
+
+import (
+	"astral/internal/domain/file"
+	"astral/internal/domain/link"
+)
+
+type LinksInterface interface {
+	Issue(login, ID string, opts link.Options) (*file.File, *link.Link, error)
+	Resolve(l link.Link, clientIP string) (*file.File, error)
+	Open(l link.Link, clientIP string) (*file.File, file.Object, error)
+}
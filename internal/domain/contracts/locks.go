@@ -0,0 +1,14 @@
+package contracts
+
+import (
+	"astral/internal/domain/file"
+	"astral/internal/domain/lock"
+	"time"
+)
+
+type LocksInterface interface {
+	GetLock(login, owner, documentID string) (*lock.Lock, error)
+	Lock(login, owner, documentID, reason string, ttl time.Duration, mode lock.Mode) (*file.File, *lock.Lock, error)
+	Unlock(login, owner, documentID string) (*file.File, *lock.Lock, error)
+	BreakLock(adminToken, documentID string) (*lock.Lock, error)
+}
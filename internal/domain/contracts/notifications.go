@@ -0,0 +1,12 @@
+package contracts
+
+import (
+	"astral/internal/domain/file"
+	"astral/internal/domain/notification"
+	"astral/internal/domain/webhook"
+)
+
+type NotificationsInterface interface {
+	Publish(eventType webhook.EventType, actor string, doc file.File, details map[string]string)
+	Subscribe(login, lastEventID string) (<-chan notification.Notification, func(), error)
+}
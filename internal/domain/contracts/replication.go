@@ -0,0 +1,11 @@
+package contracts
+
+import (
+	"astral/internal/domain/replication"
+	"context"
+)
+
+type ReplicationInterface interface {
+	Status() (*replication.Status, error)
+	Verify(ctx context.Context, repair, prune bool) (*replication.Report, error)
+}
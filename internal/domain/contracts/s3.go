@@ -0,0 +1,22 @@
+package contracts
+
+import (
+	"astral/internal/domain/file"
+	"astral/internal/domain/s3"
+	"io"
+)
+
+type S3Interface interface {
+	ListBuckets(login string) ([]string, error)
+	HeadBucket(login, bucket string) error
+	ListObjects(login, bucket string, query s3.ListQuery) (*s3.ListResult, error)
+	HeadObject(login, bucket, key string) (*file.File, error)
+	GetObject(login, bucket, key string) (*file.File, error)
+	PutObject(login, bucket, key string, fileData file.File) (*file.File, error)
+	DeleteObject(login, bucket, key string) (*file.File, error)
+	CreateMultipartUpload(login, bucket, key string, fileData file.File) (*s3.MultipartUpload, error)
+	UploadPart(login, bucket, key, uploadID string, number int, reader io.Reader, size int64) (*s3.Part, error)
+	ListParts(login, bucket, key, uploadID string) ([]s3.Part, error)
+	CompleteMultipartUpload(login, bucket, key, uploadID string, parts []s3.Part) (*file.File, error)
+	AbortMultipartUpload(login, bucket, key, uploadID string) error
+}
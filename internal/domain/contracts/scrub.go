@@ -0,0 +1,11 @@
+package contracts
+
+import (
+	"astral/internal/domain/scrub"
+	"context"
+)
+
+type ScrubInterface interface {
+	Report() *scrub.Report
+	Scrub(ctx context.Context) (*scrub.Report, error)
+}
@@ -0,0 +1,19 @@
+package contracts
+
+import (
+	"astral/internal/domain/file"
+	"astral/internal/domain/tag"
+)
+
+type TagsInterface interface {
+	CreateTag(login, name, color string, parentID *int64) (*tag.Tag, error)
+	GetTags(login string) ([]tag.Tag, error)
+	GetTag(login string, id int64) (*tag.Tag, error)
+	UpdateTag(login string, id int64, update tag.Update) (*tag.Tag, error)
+	DeleteTag(login string, id int64) (*tag.Tag, error)
+	MergeTags(login string, sourceID, targetID int64) (*tag.Tag, error)
+	AttachTag(login, documentID string, tagID int64) (*file.File, *tag.Tag, error)
+	DetachTag(login, documentID string, tagID int64) (*file.File, *tag.Tag, error)
+	GetDocumentTags(login, documentID string) ([]tag.Tag, error)
+	FindDocuments(login, query string) ([]file.File, error)
+}
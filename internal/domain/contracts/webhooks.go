@@ -0,0 +1,17 @@
+package contracts
+
+import (
+	"astral/internal/domain/file"
+	"astral/internal/domain/webhook"
+)
+
+type WebhooksInterface interface {
+	CreateWebhook(login, url string, events []webhook.EventType, secret string) (*webhook.Webhook, error)
+	GetWebhooks(login string) ([]webhook.Webhook, error)
+	GetWebhook(login, id string) (*webhook.Webhook, error)
+	DeleteWebhook(login, id string) (*webhook.Webhook, error)
+	GetDeliveries(login, webhookID string, beforeID int64, limit int) ([]webhook.Delivery, error)
+	GetDelivery(login, webhookID string, deliveryID int64) (*webhook.Delivery, error)
+	Redeliver(login, webhookID string, deliveryID int64) (*webhook.Delivery, error)
+	Publish(eventType webhook.EventType, actor string, doc file.File, details map[string]string)
+}
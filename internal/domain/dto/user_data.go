@@ -1,5 +1,7 @@
 package dto
 
+import "astral/internal/domain/comment"
+
 type UserData struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
@@ -9,3 +11,39 @@ type TokenData struct {
 	Token string `json:"token"`
 	Login string `json:"login"`
 }
+
+type AppPasswordData struct {
+	Login        string `json:"login"`
+	Name         string `json:"name"`
+	PasswordHash string `json:"password_hash"`
+}
+
+type AccessKeyData struct {
+	ID              string `json:"access_key_id"`
+	Login           string `json:"login"`
+	EncryptedSecret string `json:"encrypted_secret"`
+}
+
+type WebhookData struct {
+	Login           string   `json:"login"`
+	URL             string   `json:"url"`
+	Events          []string `json:"events"`
+	EncryptedSecret string   `json:"encrypted_secret"`
+}
+
+type TagData struct {
+	Login    string `json:"login"`
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+	ParentID *int64 `json:"parent_id"`
+}
+
+type CommentData struct {
+	DocumentID string          `json:"document_id"`
+	Owner      string          `json:"owner"`
+	ParentID   *int64          `json:"parent_id"`
+	Author     string          `json:"author"`
+	Body       string          `json:"body"`
+	Mentions   []string        `json:"mentions"`
+	Anchor     *comment.Anchor `json:"anchor"`
+}
@@ -0,0 +1,84 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Type string
+
+const (
+	TypeUserRegistered    Type = "user.registered"
+	TypeSessionCreated    Type = "session.created"
+	TypeSessionClosed     Type = "session.closed"
+	TypeDocumentUploaded  Type = "document.uploaded"
+	TypeDocumentUpdated   Type = "document.updated"
+	TypeDocumentDeleted   Type = "document.deleted"
+	TypeDocumentCorrupted Type = "document.corrupted"
+
+	// Удаление учетной записи: запрошено, отменено до конца отсрочки и выполнено
+	TypeUserDeletionScheduled Type = "user.deletion_scheduled"
+	TypeUserDeletionCancelled Type = "user.deletion_cancelled"
+	TypeUserDeleted           Type = "user.deleted"
+
+	// Действия администратора с учетной записью
+	TypeUserDisabled      Type = "user.disabled"
+	TypeUserEnabled       Type = "user.enabled"
+	TypeUserPasswordReset Type = "user.password_reset"
+
+	// Документ передан другому владельцу с тем же ID
+	TypeDocumentTransferred Type = "document.transferred"
+)
+
+// Event - доменное событие для публикации в брокер. ID назначается при создании и не меняется
+// при повторных отправках, по нему потребители отбрасывают дубликаты. Key - логин пользователя,
+// события одного пользователя попадают в одну партицию
+type Event struct {
+	ID        string          `json:"id"`
+	Type      Type            `json:"type"`
+	Key       string          `json:"key"`
+	Payload   json.RawMessage `json:"payload" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Record - событие в таблице outbox вместе с состоянием публикации
+type Record struct {
+	Seq      int64
+	Event    Event
+	Attempts int
+}
+
+type UserPayload struct {
+	Login string `json:"login"`
+}
+
+type DocumentPayload struct {
+	ID       string            `json:"id"`
+	Owner    string            `json:"owner"`
+	Name     string            `json:"name"`
+	File     bool              `json:"file"`
+	Public   bool              `json:"public"`
+	Mime     string            `json:"mime,omitempty"`
+	Grant    []string          `json:"grant,omitempty"`
+	Size     int               `json:"size"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// PreviousOwner заполняется только при передаче документа
+	PreviousOwner string `json:"previous_owner,omitempty"`
+}
+
+func New(eventType Type, key string, payload any) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		Key:       key,
+		Payload:   data,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
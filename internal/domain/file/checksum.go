@@ -0,0 +1,24 @@
+package file
+
+// Status - состояние документа, значения совпадают с типом file_status в PostgreSQL
+type Status string
+
+const (
+	StatusActive     Status = "active"
+	StatusDeleted    Status = "deleted"
+	StatusProcessing Status = "processing"
+	StatusError      Status = "error"
+	// StatusPending - содержимое загружается клиентом напрямую в хранилище и еще не проверено.
+	// Хранится только в метаданных объекта, в file_status его нет
+	StatusPending Status = "pending"
+)
+
+// Digest - контрольные суммы содержимого, присланные клиентом при загрузке. Пустое поле не проверяется
+type Digest struct {
+	MD5    []byte
+	SHA256 []byte
+}
+
+func (d *Digest) Empty() bool {
+	return d == nil || (len(d.MD5) == 0 && len(d.SHA256) == 0)
+}
@@ -1,6 +1,7 @@
 package file
 
 import (
+	"encoding/json"
 	"io"
 	"time"
 )
@@ -14,6 +15,17 @@ type File struct {
 	Grant     []string 		    `json:"grant"`
 	Size      int			    `json:"size,omitempty"`
 	Metadata  map[string]string `json:"metadata,omitempty"`
+	Data      json.RawMessage   `json:"data,omitempty" swaggertype:"object"`
+	Revision  int64             `json:"revision,omitempty"`
+	// Encoding - способ сжатия содержимого в хранилище, Size при этом - размер до сжатия
+	Encoding  string            `json:"-"`
+	// Checksum - SHA-256 содержимого в hex, считается при загрузке и проверяется при проверке хранилища
+	Checksum  string            `json:"checksum,omitempty"`
+	Status    Status            `json:"status,omitempty"`
+	// Digest - контрольные суммы, которые прислал клиент, содержимое с ними сверяется до записи
+	Digest    *Digest           `json:"-"`
+	// StripGPS - удалить координаты из EXIF изображения перед записью
+	StripGPS  bool              `json:"-"`
 	CreatedAt *time.Time        `json:"created"`
 	Reader 	  io.Reader			`json:"-"`
 	User      string			`json:"-"`
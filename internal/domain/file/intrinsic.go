@@ -0,0 +1,54 @@
+package file
+
+import "strings"
+
+// Метаданные, извлеченные из содержимого при загрузке, хранятся среди метаданных документа под
+// префиксом auto. Клиент задать их не может, при изменении сведений о документе они сохраняются,
+// при замене содержимого извлекаются заново. По ним работает фильтр metadata.<ключ>
+const (
+	INTRINSIC_METADATA_PREFIX = "auto."
+
+	// Размер изображения в пикселях
+	INTRINSIC_WIDTH  = "auto.width"
+	INTRINSIC_HEIGHT = "auto.height"
+	// EXIF: камера, время съемки (RFC 3339, без смещения, если камера его не записала) и координаты
+	// в градусах
+	INTRINSIC_CAMERA_MAKE   = "auto.camera_make"
+	INTRINSIC_CAMERA_MODEL  = "auto.camera_model"
+	INTRINSIC_TAKEN_AT      = "auto.taken_at"
+	INTRINSIC_GPS_LATITUDE  = "auto.gps_latitude"
+	INTRINSIC_GPS_LONGITUDE = "auto.gps_longitude"
+	// INTRINSIC_GPS_STRIPPED - координаты удалены из сохраненного изображения
+	INTRINSIC_GPS_STRIPPED = "auto.gps_stripped"
+	// Сведения PDF и свойства документов Office
+	INTRINSIC_PAGES       = "auto.pages"
+	INTRINSIC_TITLE       = "auto.title"
+	INTRINSIC_SUBJECT     = "auto.subject"
+	INTRINSIC_AUTHOR      = "auto.author"
+	INTRINSIC_APPLICATION = "auto.application"
+	INTRINSIC_CREATED     = "auto.created"
+	INTRINSIC_MODIFIED    = "auto.modified"
+	// INTRINSIC_DURATION - длительность аудио или видео в секундах
+	INTRINSIC_DURATION = "auto.duration"
+)
+
+// IsIntrinsicKey сообщает, относится ли ключ метаданных к извлеченным из содержимого
+func IsIntrinsicKey(key string) bool {
+	return strings.HasPrefix(strings.ToLower(key), INTRINSIC_METADATA_PREFIX)
+}
+
+// WithoutIntrinsic возвращает копию метаданных без извлеченных из содержимого ключей
+func WithoutIntrinsic(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+
+	result := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if !IsIntrinsicKey(k) {
+			result[k] = v
+		}
+	}
+
+	return result
+}
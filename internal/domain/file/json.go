@@ -0,0 +1,12 @@
+package file
+
+// JSON документы не имеют содержимого в хранилище: объект в MinIO пустой,
+// а сами данные и их ревизия лежат в PostgreSQL
+const (
+	JSON_METADATA_KEY = "json"
+	JSON_MIME         = "application/json"
+)
+
+func (f File) IsJSON() bool {
+	return f.Metadata[JSON_METADATA_KEY] == "true"
+}
@@ -0,0 +1,10 @@
+package file
+
+import "io"
+
+// Object - содержимое документа с произвольным доступом. Чтение по смещению выполняется
+// диапазонным запросом к хранилищу, поэтому объект не загружается целиком
+type Object interface {
+	io.ReadSeekCloser
+	io.ReaderAt
+}
@@ -0,0 +1,41 @@
+package file
+
+import (
+	"path"
+	"strings"
+)
+
+// Документы хранятся плоско, иерархия для WebDAV и S3 задается папкой в метаданных.
+// Пустые папки хранятся как документы без файла с признаком коллекции
+const (
+	FOLDER_METADATA_KEY     = "folder"
+	COLLECTION_METADATA_KEY = "collection"
+)
+
+func (f File) Folder() string {
+	return strings.Trim(f.Metadata[FOLDER_METADATA_KEY], "/")
+}
+
+func (f File) IsCollection() bool {
+	return f.Metadata[COLLECTION_METADATA_KEY] == "true"
+}
+
+// Path возвращает путь документа без ведущего слеша, например reports/2024/q1.pdf
+func (f File) Path() string {
+	return path.Join(f.Folder(), f.Name)
+}
+
+// SplitPath разбивает путь на папку и имя документа
+func SplitPath(name string) (string, string) {
+	current := CleanPath(name)
+	if current == "" {
+		return "", ""
+	}
+
+	dir, base := path.Split(current)
+	return strings.Trim(dir, "/"), base
+}
+
+func CleanPath(name string) string {
+	return strings.Trim(path.Clean("/"+name), "/")
+}
@@ -0,0 +1,13 @@
+package file
+
+import "time"
+
+// Presigned - подписанная ссылка на прямую загрузку или скачивание содержимого документа в обход
+// сервиса. Headers нужно передать в запросе как есть: они входят в подпись
+type Presigned struct {
+	ID        string            `json:"id"`
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
@@ -0,0 +1,15 @@
+package file
+
+// Transfer - итог передачи всех документов одного пользователя другому. Документы передаются по
+// одному, поэтому ошибка одного не отменяет передачу остальных
+type Transfer struct {
+	From        string          `json:"from"`
+	To          string          `json:"to"`
+	Transferred []string        `json:"transferred"`
+	Failed      []TransferError `json:"failed,omitempty"`
+}
+
+type TransferError struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
@@ -0,0 +1,82 @@
+package imaging
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Способ вписать изображение в рамку Width x Height, когда заданы обе стороны
+const (
+	// FitContain - изображение целиком внутри рамки, пропорции сохраняются
+	FitContain = "contain"
+	// FitCover - изображение заполняет рамку, пропорции сохраняются, лишнее обрезается по центру
+	FitCover = "cover"
+	// FitFill - изображение растягивается точно по рамке
+	FitFill = "fill"
+)
+
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatGIF  = "gif"
+)
+
+// Crop - область исходного изображения в пикселях, вырезается до изменения размера
+type Crop struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// Options - параметры преобразования. Нулевые Width и Height сохраняют размер, если задана только
+// одна сторона, вторая считается по пропорциям. Пустой Format - формат исходного изображения
+type Options struct {
+	Width   int
+	Height  int
+	Fit     string
+	Format  string
+	Quality int
+	Crop    *Crop
+}
+
+// Key - каноническая запись параметров, одинаковая для равных преобразований. Используется в ключе кэша
+func (o Options) Key() string {
+	parts := []string{
+		fmt.Sprintf("w%d", o.Width),
+		fmt.Sprintf("h%d", o.Height),
+		o.Fit,
+		o.Format,
+	}
+	if o.Format == FormatJPEG {
+		parts = append(parts, fmt.Sprintf("q%d", o.Quality))
+	}
+	if o.Crop != nil {
+		parts = append(parts, fmt.Sprintf("c%d.%d.%d.%d", o.Crop.X, o.Crop.Y, o.Crop.Width, o.Crop.Height))
+	}
+
+	return strings.Join(parts, "-")
+}
+
+// Variant - преобразованное изображение
+type Variant struct {
+	Data   []byte
+	Mime   string
+	Width  int
+	Height int
+	// Cached - вариант взят из кэша, а не построен заново
+	Cached bool
+}
+
+// MimeOf возвращает MIME-тип формата
+func MimeOf(format string) string {
+	return "image/" + format
+}
+
+// Extension возвращает расширение файла для формата
+func Extension(format string) string {
+	if format == FormatJPEG {
+		return ".jpg"
+	}
+	return "." + format
+}
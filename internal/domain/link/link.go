@@ -0,0 +1,30 @@
+package link
+
+import "time"
+
+const (
+	DispositionInline     = "inline"
+	DispositionAttachment = "attachment"
+)
+
+// Options - параметры выдаваемой ссылки. Нулевой TTL - срок по умолчанию, пустой IP - ссылка
+// работает с любого адреса, пустой Disposition - документ скачивается как вложение
+type Options struct {
+	TTL         time.Duration
+	IP          string
+	Disposition string
+	Filename    string
+}
+
+// Link - подписанная ссылка на скачивание документа без токена. Подпись покрывает владельца,
+// ID документа, срок, адрес и Content-Disposition, KeyID указывает, каким ключом она сделана
+type Link struct {
+	DocumentID  string    `json:"id"`
+	Owner       string    `json:"owner"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	IP          string    `json:"ip,omitempty"`
+	Disposition string    `json:"disposition,omitempty"`
+	KeyID       string    `json:"-"`
+	Signature   string    `json:"-"`
+	URL         string    `json:"url"`
+}
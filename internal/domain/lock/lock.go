@@ -0,0 +1,33 @@
+package lock
+
+import "time"
+
+type Mode string
+
+const (
+	// ModeAdvisory только сообщает другим, что документ в работе
+	ModeAdvisory Mode = "advisory"
+	// ModeEnforced запрещает изменять и удалять документ всем, кроме держателя блокировки
+	ModeEnforced Mode = "enforced"
+)
+
+// Lock - блокировка документа на время редактирования. Holder - пользователь, взявший
+// блокировку, Owner - владелец документа
+type Lock struct {
+	DocumentID string    `json:"document_id"`
+	Owner      string    `json:"owner"`
+	Holder     string    `json:"holder"`
+	Reason     string    `json:"reason,omitempty"`
+	Mode       Mode      `json:"mode"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func (m Mode) Valid() bool {
+	return m == ModeAdvisory || m == ModeEnforced
+}
+
+// Blocks сообщает, запрещает ли блокировка изменения пользователю login
+func (l Lock) Blocks(login string) bool {
+	return l.Mode == ModeEnforced && l.Holder != login
+}
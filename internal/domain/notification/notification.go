@@ -0,0 +1,53 @@
+package notification
+
+import (
+	"astral/internal/domain/file"
+	"astral/internal/domain/webhook"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notification - событие, которое получает подключенный клиент. Типы совпадают с событиями webhook,
+// ID назначает хранилище истории и по нему клиент продолжает поток после переподключения
+type Notification struct {
+	ID        string            `json:"id"`
+	Type      webhook.EventType `json:"type"`
+	Recipient string            `json:"-"`
+	Actor     string            `json:"actor,omitempty"`
+	Owner     string            `json:"owner"`
+	Document  file.File         `json:"document"`
+	Details   map[string]string `json:"details,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// ParseID разбирает ID вида "<миллисекунды>-<номер>", в котором Redis нумерует записи потока
+func ParseID(id string) (uint64, uint64, bool) {
+	msPart, seqPart, ok := strings.Cut(id, "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return ms, seq, true
+}
+
+// After сообщает, что уведомление с ID a создано позже уведомления с ID b
+func After(a, b string) bool {
+	aMs, aSeq, _ := ParseID(a)
+	bMs, bSeq, _ := ParseID(b)
+	if aMs != bMs {
+		return aMs > bMs
+	}
+
+	return aSeq > bSeq
+}
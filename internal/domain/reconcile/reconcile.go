@@ -0,0 +1,71 @@
+package reconcile
+
+import "time"
+
+type Kind string
+
+const (
+	KindOrphanedObject    Kind = "orphaned_object"
+	KindDanglingReference Kind = "dangling_reference"
+	KindStaleCache        Kind = "stale_cache"
+)
+
+// Finding - одно расхождение между PostgreSQL, Redis и MinIO. Source - где оно найдено (minio,
+// таблица или redis), Action - что сделает исправление, Repaired - исправление выполнено
+type Finding struct {
+	Kind     Kind   `json:"kind"`
+	Source   string `json:"source"`
+	Key      string `json:"key"`
+	Reason   string `json:"reason"`
+	Action   string `json:"action"`
+	Repaired bool   `json:"repaired"`
+	Error    string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Repair             bool       `json:"repair"`
+	DryRun             bool       `json:"dry_run"`
+	Objects            int        `json:"objects"`
+	OrphanedObjects    []Finding  `json:"orphaned_objects"`
+	DanglingReferences []Finding  `json:"dangling_references"`
+	StaleCache         []Finding  `json:"stale_cache"`
+	Repaired           int        `json:"repaired"`
+	StartedAt          *time.Time `json:"started_at"`
+	FinishedAt         *time.Time `json:"finished_at"`
+}
+
+func (r *Report) Total() int {
+	return len(r.OrphanedObjects) + len(r.DanglingReferences) + len(r.StaleCache)
+}
+
+// Object - объект MinIO для сверки. Document - у объекта есть метаданные документа Astral,
+// Name и Checksum берутся из них. Pending - документ прямой загрузки, которую не завершили
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+	Document     bool
+	Name         string
+	Checksum     string
+	Pending      bool
+}
+
+// Reference - строки таблицы, в которых столбец Column ссылается на документ или пользователя Key
+type Reference struct {
+	Table  string
+	Column string
+	Key    string
+	Count  int
+}
+
+// CacheEntry - ключ Redis с документом, списком документов, блокировкой или историей уведомлений
+type CacheEntry struct {
+	Key string
+	// DocumentID и Login - на что ссылается ключ, пустое значение - ключ на это не ссылается
+	DocumentID string
+	Login      string
+	// Copy - ключ хранит копию документа, по Checksum и Name находится устаревшая
+	Copy     bool
+	Checksum string
+	Name     string
+}
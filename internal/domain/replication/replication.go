@@ -0,0 +1,41 @@
+package replication
+
+import "time"
+
+type Operation string
+
+const (
+	OperationPut    Operation = "put"
+	OperationDelete Operation = "delete"
+)
+
+type Task struct {
+	ID            int64      `json:"id"`
+	Operation     Operation  `json:"operation"`
+	ObjectKey     string     `json:"object_key"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	CreatedAt     *time.Time `json:"created_at"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+}
+
+type Status struct {
+	Pending  int           `json:"pending"`
+	Retrying int           `json:"retrying"`
+	Lag      time.Duration `json:"lag"`
+}
+
+type Object struct {
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+	ETag string `json:"etag"`
+}
+
+type Report struct {
+	Checked          int      `json:"checked"`
+	MissingOnReplica []string `json:"missing_on_replica"`
+	MissingOnPrimary []string `json:"missing_on_primary"`
+	Mismatched       []string `json:"mismatched"`
+	Repaired         int      `json:"repaired"`
+	Pruned           int      `json:"pruned"`
+}
@@ -0,0 +1,41 @@
+package s3
+
+import (
+	"astral/internal/domain/file"
+	"time"
+)
+
+type MultipartUpload struct {
+	ID          string            `json:"upload_id"`
+	Login       string            `json:"user_login"`
+	Bucket      string            `json:"bucket"`
+	Key         string            `json:"key"`
+	ContentType string            `json:"content_type"`
+	Metadata    map[string]string `json:"metadata"`
+	CreatedAt   *time.Time        `json:"created_at"`
+}
+
+type Part struct {
+	Number int    `json:"part_number"`
+	Size   int64  `json:"size"`
+	ETag   string `json:"etag"`
+}
+
+type ListQuery struct {
+	Prefix     string
+	Delimiter  string
+	StartAfter string
+	MaxKeys    int
+}
+
+type Object struct {
+	Key      string
+	Document file.File
+}
+
+type ListResult struct {
+	Objects        []Object
+	CommonPrefixes []string
+	IsTruncated    bool
+	NextMarker     string
+}
@@ -0,0 +1,14 @@
+package scrub
+
+import "time"
+
+// Report - итог одного прохода проверки хранилища. Skipped - объекты без контрольной суммы,
+// Failed - объекты, которые не удалось прочитать, Corrupted - ключи owner/id поврежденных документов
+type Report struct {
+	Checked    int        `json:"checked"`
+	Skipped    int        `json:"skipped"`
+	Failed     int        `json:"failed"`
+	Corrupted  []string   `json:"corrupted"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
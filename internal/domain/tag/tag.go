@@ -0,0 +1,23 @@
+package tag
+
+import "time"
+
+// Tag - именованная метка пользователя. Теги вкладываются друг в друга через ParentID,
+// документ с тегом считается отмеченным и всеми его предками
+type Tag struct {
+	ID        int64      `json:"id"`
+	Login     string     `json:"user_login"`
+	Name      string     `json:"name"`
+	Color     string     `json:"color,omitempty"`
+	ParentID  *int64     `json:"parent_id,omitempty"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+// Update - изменение тега, nil поля не меняются. SetParent нужен, чтобы отличить
+// перенос в корень (ParentID = nil) от сохранения текущего родителя
+type Update struct {
+	Name      *string
+	Color     *string
+	ParentID  *int64
+	SetParent bool
+}
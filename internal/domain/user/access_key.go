@@ -0,0 +1,10 @@
+package user
+
+import "time"
+
+type AccessKey struct {
+	ID        string     `json:"access_key_id"`
+	Login     string     `json:"user_login"`
+	Secret    string     `json:"secret_access_key,omitempty"`
+	CreatedAt *time.Time `json:"created_at"`
+}
@@ -0,0 +1,11 @@
+package user
+
+import "time"
+
+type AppPassword struct {
+	ID        string     `json:"id"`
+	Login     string     `json:"user_login"`
+	Name      string     `json:"name"`
+	Password  string     `json:"password,omitempty"`
+	CreatedAt *time.Time `json:"created_at"`
+}
@@ -7,6 +7,10 @@ type User struct {
 	Password  string     `json:"password"`
 	UpdatedAt *time.Time `json:"updated_at"`
 	CreatedAt *time.Time `json:"created_at"`
+	// DeleteAfter - время, после которого учетная запись будет удалена, nil - удаление не запрошено
+	DeleteAfter *time.Time `json:"delete_after,omitempty"`
+	// DisabledAt - время блокировки учетной записи администратором, nil - учетная запись активна
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
 }
 
 func NewUser(login, password string) *User {
@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/file"
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventDocumentUploaded  EventType = "document.uploaded"
+	EventDocumentUpdated   EventType = "document.updated"
+	EventDocumentDeleted   EventType = "document.deleted"
+	EventDocumentShared    EventType = "document.shared"
+	EventDocumentCommented EventType = "document.commented"
+	// EventDocumentTransferred - у документа сменился владелец, ID документа сохраняется
+	EventDocumentTransferred EventType = "document.transferred"
+	// EventDocumentCorrupted - проверка хранилища нашла, что содержимое не совпадает с контрольной суммой
+	EventDocumentCorrupted EventType = "document.corrupted"
+
+	// EventCommentMentioned приходит только упомянутым пользователям как уведомление, webhook на него не подписать
+	EventCommentMentioned EventType = "comment.mentioned"
+)
+
+var EventTypes = []EventType{
+	EventDocumentUploaded,
+	EventDocumentUpdated,
+	EventDocumentDeleted,
+	EventDocumentShared,
+	EventDocumentCommented,
+	EventDocumentCorrupted,
+	EventDocumentTransferred,
+}
+
+type DeliveryStatus string
+
+const (
+	StatusPending   DeliveryStatus = "pending"
+	StatusDelivered DeliveryStatus = "delivered"
+	StatusFailed    DeliveryStatus = "failed"
+)
+
+// Webhook - подписка пользователя на события его документов. Secret возвращается только при создании
+type Webhook struct {
+	ID        string      `json:"id"`
+	Login     string      `json:"user_login"`
+	URL       string      `json:"url"`
+	Events    []EventType `json:"events"`
+	Active    bool        `json:"active"`
+	Secret    string      `json:"secret,omitempty"`
+	CreatedAt *time.Time  `json:"created_at"`
+}
+
+// Delivery - доставка одного события на один webhook, Attempts в выдаче заполняется только для одной доставки
+type Delivery struct {
+	ID            int64           `json:"id"`
+	WebhookID     string          `json:"webhook_id"`
+	EventID       string          `json:"event_id"`
+	Event         EventType       `json:"event"`
+	Status        DeliveryStatus  `json:"status"`
+	Attempts      int             `json:"attempts"`
+	ResponseCode  *int            `json:"response_code,omitempty"`
+	LastError     string          `json:"last_error,omitempty"`
+	Payload       json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
+	CreatedAt     *time.Time      `json:"created_at"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+	Log           []Attempt       `json:"log,omitempty"`
+}
+
+// Attempt - запись журнала доставки об одной отправке запроса получателю
+type Attempt struct {
+	ID           int64      `json:"id"`
+	DeliveryID   int64      `json:"delivery_id"`
+	ResponseCode *int       `json:"response_code,omitempty"`
+	ResponseBody string     `json:"response_body,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	DurationMs   int64      `json:"duration_ms"`
+	CreatedAt    *time.Time `json:"created_at"`
+}
+
+// Task - доставка, выбранная воркером, вместе с адресом и зашифрованным секретом webhook
+type Task struct {
+	Delivery        Delivery
+	URL             string
+	EncryptedSecret string
+}
+
+// Payload - тело запроса, которое получает webhook
+type Payload struct {
+	ID        string            `json:"id"`
+	Type      EventType         `json:"type"`
+	CreatedAt time.Time         `json:"created_at"`
+	Actor     string            `json:"actor,omitempty"`
+	Owner     string            `json:"owner"`
+	Document  file.File         `json:"document"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+func IsEventType(value string) bool {
+	for _, eventType := range EventTypes {
+		if string(eventType) == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EventTypeOf сопоставляет действие аудита событию webhook. Загрузка с previous_id заменяет
+// существующий документ, поэтому для подписчиков это обновление
+func EventTypeOf(action audit.Action, details map[string]string) (EventType, bool) {
+	switch action {
+	case audit.ActionUpload:
+		if details["previous_id"] != "" {
+			return EventDocumentUpdated, true
+		}
+		return EventDocumentUploaded, true
+	case audit.ActionMetadataUpdate:
+		return EventDocumentUpdated, true
+	case audit.ActionDelete:
+		return EventDocumentDeleted, true
+	case audit.ActionShare:
+		return EventDocumentShared, true
+	case audit.ActionComment:
+		return EventDocumentCommented, true
+	case audit.ActionCopy:
+		return EventDocumentUploaded, true
+	case audit.ActionTransfer:
+		return EventDocumentTransferred, true
+	}
+
+	return "", false
+}
@@ -3,6 +3,7 @@ package presentation
 import (
 	"astral/env"
 	"astral/internal/domain/contracts"
+	grpcserver "astral/internal/presentation/grpc"
 	server "astral/internal/presentation/http"
 	"context"
 	"log/slog"
@@ -17,6 +18,7 @@ type ctxKey string
 type Api struct {
 	logger   		  *slog.Logger
 	server 			  *server.Server
+	grpcServer        *grpcserver.Server
 	port   			  string
 }
 
@@ -26,27 +28,55 @@ func New(
 	authService 	  contracts.AuthInterface,
 	validationService contracts.ValidationInterface,
 	filesService      contracts.FilesInterface,
+	replicationService contracts.ReplicationInterface,
+	scrubService      contracts.ScrubInterface,
+	s3Service         contracts.S3Interface,
+	jsonDocsService   contracts.JSONDocsInterface,
+	tagsService       contracts.TagsInterface,
+	commentsService   contracts.CommentsInterface,
+	locksService      contracts.LocksInterface,
+	archivesService   contracts.ArchivesInterface,
+	auditService      contracts.AuditInterface,
+	webhooksService   contracts.WebhooksInterface,
+	notificationsService contracts.NotificationsInterface,
+	activityService   contracts.ActivityInterface,
+	accountService    contracts.AccountInterface,
+	adminService      contracts.AdminInterface,
+	linksService      contracts.LinksInterface,
+	imagesService     contracts.ImagesInterface,
 ) *Api {
 	port := env.Http.GetPort()
-	router := server.NewHandler(logger, filesService, authService, validationService, *env)
+	router := server.NewHandler(logger, filesService, authService, validationService, replicationService, scrubService, s3Service, jsonDocsService, tagsService, commentsService, locksService, archivesService, auditService, webhooksService, notificationsService, activityService, accountService, adminService, linksService, imagesService, *env)
 	routes := router.InitRouts(env)
 	srv := server.NewServer(port, routes)
+	grpcSrv := grpcserver.NewServer(logger, env.Grpc.Port, authService, filesService, auditService, activityService)
 
 	return &Api{
-		logger: logger,
-		server: srv,
-		port:   port,
+		logger:     logger,
+		server:     srv,
+		grpcServer: grpcSrv,
+		port:       port,
 	}
 }
 
 func (a *Api) Run() error {
 	a.logger.Info("Starting the application", slog.String("port", a.port))
 
+	go func() {
+		if err := a.grpcServer.Run(); err != nil {
+			a.logger.Error("grpc server error", slog.String("error", err.Error()))
+		}
+	}()
+
 	return a.server.Run()
 }
 
 func (a *Api) GracefulShutdown(ctx context.Context) error {
 	a.logger.Info("Initiating graceful shutdown")
 
+	if err := a.grpcServer.Shutdown(ctx); err != nil {
+		a.logger.Error("grpc server shutdown error", slog.String("error", err.Error()))
+	}
+
 	return a.server.Shutdown(ctx)
 }
@@ -0,0 +1,83 @@
+package accountcontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/dto"
+	controllererrors "astral/internal/presentation/controller/errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Delete account
+// @Description Schedule deletion of the current account. All sessions, app passwords and access keys are revoked immediately, documents and the account itself are deleted after the grace period. Until then the account can be restored.
+// @Tags account
+// @Produce json
+// @Success 200 {object} deletionResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "Deletion is already scheduled"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/account [delete]
+func (c *Controller) DeleteAccount(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.accountService.RequestDeletion(token.Login)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordAccountEvent(ctx, audit.ActionAccountDelete, token.Login, map[string]string{
+		"delete_after": res.DeleteAfter.Format(time.RFC3339),
+	})
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Restore account
+// @Description Cancel a scheduled account deletion during the grace period and open a new session
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param request body restoreRequest true "user data"
+// @Success 200 {object} restoreResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "Deletion is not scheduled"
+// @Failure 410 {object} response.ErrorResponse "Grace period has expired"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/account/restore [post]
+func (c *Controller) RestoreAccount(ctx *gin.Context) {
+	var request restoreRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid json"))
+		return
+	}
+
+	if request.Login == "" {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("login field is required"))
+		return
+	}
+
+	if request.Pass == "" {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("pswd field is required"))
+		return
+	}
+
+	res, err := c.accountService.RestoreAccount(dto.UserData{
+		Login:    request.Login,
+		Password: request.Pass,
+	})
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordAccountEvent(ctx, audit.ActionAccountRestore, res.Login, nil)
+
+	c.responseBuilder.Ok(ctx, map[string]string{"token": res.Token}, nil)
+}
@@ -0,0 +1,30 @@
+package accountcontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/presentation/controller/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordAccountEvent фиксирует действие с учетной записью, владелец - сам пользователь. Журнал
+// аудита не связан с users и переживает удаление учетной записи
+func (c *Controller) recordAccountEvent(ctx *gin.Context, action audit.Action, login string, details map[string]string) {
+	event := utils.NewAuditEvent(ctx, action, login)
+	event.Owner = login
+	event.TargetType = audit.TargetUser
+	event.TargetID = login
+	event.Details = details
+
+	c.auditService.Record(event)
+}
+
+func (c *Controller) recordExportEvent(ctx *gin.Context, login, exportID string, details map[string]string) {
+	event := utils.NewAuditEvent(ctx, audit.ActionExport, login)
+	event.Owner = login
+	event.TargetType = audit.TargetExport
+	event.TargetID = exportID
+	event.Details = details
+
+	c.auditService.Record(event)
+}
@@ -0,0 +1,33 @@
+package accountcontroller
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/presentation/controller/utils"
+	"astral/internal/presentation/response"
+	"log/slog"
+)
+
+type Controller struct {
+	logger          *slog.Logger
+	responseBuilder *response.ResponseBuilder
+	accountService  contracts.AccountInterface
+	auditService    contracts.AuditInterface
+	utils           utils.Utils
+}
+
+func NewController(
+	logger *slog.Logger,
+	responseBuilder *response.ResponseBuilder,
+	account contracts.AccountInterface,
+	audit contracts.AuditInterface,
+	utils utils.Utils,
+) *Controller {
+	logger = logger.With("controller", "account")
+	return &Controller{
+		logger:          logger,
+		responseBuilder: responseBuilder,
+		accountService:  account,
+		auditService:    audit,
+		utils:           utils,
+	}
+}
@@ -0,0 +1,100 @@
+package accountcontroller
+
+import (
+	accountrepo "astral/internal/repository/account"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Request data export
+// @Description Start building an archive with all documents, their metadata, shares and sessions of the current user. The archive is built in the background, poll the export until it is ready.
+// @Tags account
+// @Produce json
+// @Success 200 {object} exportResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/account/export [post]
+func (c *Controller) CreateExport(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.accountService.CreateExport(token.Login)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordExportEvent(ctx, token.Login, res.ID, map[string]string{"stage": "requested"})
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Get data export
+// @Description Get the status of a data export
+// @Tags account
+// @Produce json
+// @Param id path string true "Export ID"
+// @Success 200 {object} exportResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/account/export/{id} [get]
+func (c *Controller) GetExport(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.accountService.GetExport(token.Login, ctx.Param("id"))
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Download data export
+// @Description Download a ready export archive. The archive is available until expires_at.
+// @Tags account
+// @Produce application/zip
+// @Param id path string true "Export ID"
+// @Success 200 {file} binary
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "Export is not ready"
+// @Failure 410 {object} response.ErrorResponse "Export has expired"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/account/export/{id}/download [get]
+func (c *Controller) DownloadExport(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	export, content, err := c.accountService.OpenExport(token.Login, ctx.Param("id"))
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	defer content.Close()
+	c.recordExportEvent(ctx, token.Login, export.ID, map[string]string{"stage": "download"})
+
+	ctx.Header("Content-Type", accountrepo.EXPORT_CONTENT_TYPE)
+	ctx.Header("Content-Length", strconv.FormatInt(export.Size, 10))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"takeout-%s.zip\"", export.ID))
+	ctx.Status(http.StatusOK)
+
+	// Заголовки уже отправлены, ошибку передачи остается только записать в лог
+	if _, err := io.Copy(ctx.Writer, content); err != nil {
+		c.logger.Error("failed to send export", "exportID", export.ID, "error", err)
+	}
+}
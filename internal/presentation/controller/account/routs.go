@@ -0,0 +1,26 @@
+package accountcontroller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type Router struct {
+	controller *Controller
+}
+
+func NewRouter(controller *Controller) *Router {
+	return &Router{
+		controller: controller,
+	}
+}
+
+// @Summary Register account routes
+// @Description Group of endpoints for personal data export and account deletion
+func (r *Router) RegisterRoutes(account *gin.RouterGroup, accountSecure *gin.RouterGroup) {
+	account.POST("/account/restore", r.controller.RestoreAccount)
+	accountSecure.DELETE("/account", r.controller.DeleteAccount)
+
+	accountSecure.POST("/account/export", r.controller.CreateExport)
+	accountSecure.GET("/account/export/:id", r.controller.GetExport)
+	accountSecure.GET("/account/export/:id/download", r.controller.DownloadExport)
+}
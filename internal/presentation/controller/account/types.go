@@ -0,0 +1,22 @@
+package accountcontroller
+
+import "astral/internal/domain/account"
+
+type restoreRequest struct {
+	Login string `json:"login"`
+	Pass  string `json:"pswd"`
+}
+
+type restoreResponse struct {
+	Response struct {
+		Token string `json:"token"`
+	} `json:"response"`
+}
+
+type deletionResponse struct {
+	Response account.Deletion `json:"response"`
+}
+
+type exportResponse struct {
+	Response account.Export `json:"response"`
+}
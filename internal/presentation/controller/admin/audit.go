@@ -0,0 +1,50 @@
+package admincontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/file"
+	"astral/internal/presentation/controller/utils"
+	adminservice "astral/internal/services/admin"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordUserEvent фиксирует действие администратора с учетной записью login. Пустой login -
+// действие со списком учетных записей
+func (c *Controller) recordUserEvent(ctx *gin.Context, action audit.Action, login string, details map[string]string) {
+	event := utils.NewAuditEvent(ctx, action, audit.ActorAdmin)
+	if login != "" {
+		event.Owner = login
+		event.TargetType = audit.TargetUser
+		event.TargetID = login
+	}
+	event.Details = details
+
+	c.activityService.Record(event)
+}
+
+// recordDocumentEvent фиксирует действие администратора с документом. Об удалении владелец
+// узнает через вебхуки и уведомления так же, как о действиях других пользователей
+func (c *Controller) recordDocumentEvent(ctx *gin.Context, action audit.Action, doc file.File, details map[string]string) {
+	event := utils.NewAuditEvent(ctx, action, audit.ActorAdmin)
+	event.Details = details
+
+	c.activityService.RecordDocument(event, doc)
+}
+
+// fail отвечает ошибкой, попытка с неверным токеном администратора попадает в журнал
+func (c *Controller) fail(ctx *gin.Context, err error) {
+	if errors.Is(err, adminservice.ErrAccessDenied) {
+		event := utils.NewAuditEvent(ctx, audit.ActionAuthFailed, "")
+		event.Details = map[string]string{
+			"method": "admin_token",
+			"path":   ctx.Request.URL.Path,
+			"reason": err.Error(),
+		}
+
+		c.activityService.Record(event)
+	}
+
+	c.responseBuilder.Error(ctx, err)
+}
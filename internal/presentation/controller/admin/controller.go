@@ -0,0 +1,33 @@
+package admincontroller
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/presentation/response"
+	"log/slog"
+)
+
+const (
+	ADMIN_TOKEN_HEADER = "X-Admin-Token"
+)
+
+type Controller struct {
+	logger          *slog.Logger
+	responseBuilder *response.ResponseBuilder
+	adminService    contracts.AdminInterface
+	activityService contracts.ActivityInterface
+}
+
+func NewController(
+	logger *slog.Logger,
+	responseBuilder *response.ResponseBuilder,
+	admin contracts.AdminInterface,
+	activity contracts.ActivityInterface,
+) *Controller {
+	logger = logger.With("controller", "admin")
+	return &Controller{
+		logger:          logger,
+		responseBuilder: responseBuilder,
+		adminService:    admin,
+		activityService: activity,
+	}
+}
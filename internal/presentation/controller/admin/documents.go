@@ -0,0 +1,165 @@
+package admincontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/file"
+	controllererrors "astral/internal/presentation/controller/errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary List user documents
+// @Description List all documents and collections of a user regardless of grants
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param login path string true "User login"
+// @Success 200 {object} documentsResponse
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{login}/docs [get]
+func (c *Controller) ListDocuments(ctx *gin.Context) {
+	login := ctx.Param("login")
+
+	docs, err := c.adminService.ListDocuments(ctx.GetHeader(ADMIN_TOKEN_HEADER), login)
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+	c.recordUserEvent(ctx, audit.ActionAdminView, login, map[string]string{"target": "documents"})
+
+	if docs == nil {
+		docs = []file.File{}
+	}
+
+	c.responseBuilder.Ok(ctx, docs, nil)
+}
+
+// @Summary Get user document
+// @Description Get metadata of any document of a user
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param login path string true "User login"
+// @Param id path string true "Document ID"
+// @Success 200 {object} documentResponse
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{login}/docs/{id} [get]
+func (c *Controller) GetDocument(ctx *gin.Context) {
+	doc, err := c.adminService.GetDocument(ctx.GetHeader(ADMIN_TOKEN_HEADER), ctx.Param("login"), ctx.Param("id"))
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+	c.recordDocumentEvent(ctx, audit.ActionAdminView, *doc, nil)
+
+	c.responseBuilder.Ok(ctx, doc, nil)
+}
+
+// @Summary Download user document
+// @Description Download the content of any document of a user. Range requests are supported.
+// @Tags admin
+// @Produce octet-stream
+// @Param X-Admin-Token header string true "Admin token"
+// @Param login path string true "User login"
+// @Param id path string true "Document ID"
+// @Success 200 {file} binary
+// @Success 206 {file} binary "Partial Content"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{login}/docs/{id}/content [get]
+func (c *Controller) DownloadDocument(ctx *gin.Context) {
+	doc, content, err := c.adminService.OpenDocument(ctx.GetHeader(ADMIN_TOKEN_HEADER), ctx.Param("login"), ctx.Param("id"))
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+	defer content.Close()
+	c.recordDocumentEvent(ctx, audit.ActionDownload, *doc, nil)
+
+	var modTime time.Time
+	if doc.CreatedAt != nil {
+		modTime = *doc.CreatedAt
+	}
+
+	if doc.Mime != "" {
+		ctx.Header("Content-Type", doc.Mime)
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", doc.Name))
+
+	// ServeContent обрабатывает Range и условные заголовки по Last-Modified
+	http.ServeContent(ctx.Writer, ctx.Request, doc.Name, modTime, content)
+}
+
+// @Summary Delete user document
+// @Description Delete any document of a user. The owner is notified like about any other deletion.
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param login path string true "User login"
+// @Param id path string true "Document ID"
+// @Success 200 {object} documentResponse
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{login}/docs/{id} [delete]
+func (c *Controller) DeleteDocument(ctx *gin.Context) {
+	login := ctx.Param("login")
+
+	doc, err := c.adminService.DeleteDocument(ctx.GetHeader(ADMIN_TOKEN_HEADER), login, ctx.Param("id"))
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+	doc.User = login
+	c.recordDocumentEvent(ctx, audit.ActionDelete, *doc, nil)
+
+	c.responseBuilder.Ok(ctx, doc, nil)
+}
+
+// @Summary Transfer all user documents
+// @Description Make another user the owner of every document of a user, for example when an employee leaves. Documents keep their IDs, grants, JSON data and comments. Documents are transferred one by one, failures are listed in the response.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param login path string true "Current owner login"
+// @Param request body transferRequest true "New owner login"
+// @Success 200 {object} transferResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "Both users are the same"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{login}/transfer [post]
+func (c *Controller) TransferDocuments(ctx *gin.Context) {
+	var request transferRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("to field is required"))
+		return
+	}
+
+	login := ctx.Param("login")
+
+	res, err := c.adminService.TransferDocuments(ctx.GetHeader(ADMIN_TOKEN_HEADER), login, request.To)
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+	c.recordUserEvent(ctx, audit.ActionTransfer, login, map[string]string{
+		"from":        login,
+		"to":          request.To,
+		"transferred": strconv.Itoa(len(res.Transferred)),
+		"failed":      strconv.Itoa(len(res.Failed)),
+	})
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
@@ -0,0 +1,32 @@
+package admincontroller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type Router struct {
+	controller *Controller
+}
+
+func NewRouter(controller *Controller) *Router {
+	return &Router{
+		controller: controller,
+	}
+}
+
+// @Summary Register admin routes
+// @Description Group of endpoints for managing users and their documents, authorized by the admin token
+func (r *Router) RegisterRoutes(admin *gin.RouterGroup) {
+	admin.GET("/admin/users", r.controller.ListUsers)
+	admin.GET("/admin/users/:login", r.controller.GetUser)
+	admin.POST("/admin/users/:login/disable", r.controller.DisableUser)
+	admin.POST("/admin/users/:login/enable", r.controller.EnableUser)
+	admin.DELETE("/admin/users/:login/sessions", r.controller.RevokeSessions)
+	admin.POST("/admin/users/:login/password", r.controller.ResetPassword)
+
+	admin.GET("/admin/users/:login/docs", r.controller.ListDocuments)
+	admin.GET("/admin/users/:login/docs/:id", r.controller.GetDocument)
+	admin.GET("/admin/users/:login/docs/:id/content", r.controller.DownloadDocument)
+	admin.DELETE("/admin/users/:login/docs/:id", r.controller.DeleteDocument)
+	admin.POST("/admin/users/:login/transfer", r.controller.TransferDocuments)
+}
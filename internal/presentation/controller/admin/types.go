@@ -0,0 +1,50 @@
+package admincontroller
+
+import (
+	"astral/internal/domain/admin"
+	"astral/internal/domain/file"
+)
+
+type disableRequest struct {
+	Reason string `json:"reason"`
+}
+
+type passwordRequest struct {
+	Pass string `json:"pswd"`
+}
+
+type transferRequest struct {
+	To string `json:"to" binding:"required"`
+}
+
+type usersResponse struct {
+	Response []admin.User `json:"response"`
+}
+
+type userResponse struct {
+	Response admin.User `json:"response"`
+}
+
+type sessionsResponse struct {
+	Response struct {
+		Revoked int64 `json:"revoked"`
+	} `json:"response"`
+}
+
+type passwordResponse struct {
+	Response struct {
+		Pass string `json:"pswd"`
+	} `json:"response"`
+}
+
+type documentsResponse struct {
+	Response []file.File `json:"response"`
+}
+
+type documentResponse struct {
+	Response file.File `json:"response"`
+}
+
+type transferResponse struct {
+	Response file.Transfer `json:"response"`
+}
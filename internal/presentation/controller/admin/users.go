@@ -0,0 +1,195 @@
+package admincontroller
+
+import (
+	"astral/internal/domain/admin"
+	"astral/internal/domain/audit"
+	controllererrors "astral/internal/presentation/controller/errors"
+	"io"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary List users
+// @Description Search users by a login substring and status. Every user comes with the number of active sessions, documents and used storage.
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param q query string false "Login substring"
+// @Param status query string false "Status" Enums(active, disabled, pending_deletion)
+// @Param limit query int false "Number of users to return" minimum(1) maximum(200) default(50)
+// @Param offset query int false "Number of users to skip" default(0)
+// @Success 200 {object} usersResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users [get]
+func (c *Controller) ListUsers(ctx *gin.Context) {
+	filter := admin.UserFilter{
+		Query:  ctx.Query("q"),
+		Status: admin.UserStatus(ctx.Query("status")),
+	}
+
+	var err error
+	if limit := ctx.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 1 {
+			c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("limit must be a positive integer"))
+			return
+		}
+	}
+
+	if offset := ctx.Query("offset"); offset != "" {
+		if filter.Offset, err = strconv.Atoi(offset); err != nil || filter.Offset < 0 {
+			c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("offset must be a non-negative integer"))
+			return
+		}
+	}
+
+	users, err := c.adminService.ListUsers(ctx.GetHeader(ADMIN_TOKEN_HEADER), filter)
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+	c.recordUserEvent(ctx, audit.ActionAdminView, "", map[string]string{
+		"query":  filter.Query,
+		"status": string(filter.Status),
+	})
+
+	c.responseBuilder.Ok(ctx, users, nil)
+}
+
+// @Summary Get user
+// @Description Get a user with the number of active sessions, documents and used storage
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param login path string true "User login"
+// @Success 200 {object} userResponse
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{login} [get]
+func (c *Controller) GetUser(ctx *gin.Context) {
+	res, err := c.adminService.GetUser(ctx.GetHeader(ADMIN_TOKEN_HEADER), ctx.Param("login"))
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+	c.recordUserEvent(ctx, audit.ActionAdminView, res.Login, nil)
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Disable user
+// @Description Disable an account. All sessions are revoked, login, app passwords and access keys stop working until the account is enabled again.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param login path string true "User login"
+// @Param request body disableRequest false "Reason"
+// @Success 200 {object} userResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{login}/disable [post]
+func (c *Controller) DisableUser(ctx *gin.Context) {
+	var request disableRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil && err != io.EOF {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid json"))
+		return
+	}
+
+	res, err := c.adminService.DisableUser(ctx.GetHeader(ADMIN_TOKEN_HEADER), ctx.Param("login"), request.Reason)
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+	c.recordUserEvent(ctx, audit.ActionUserDisable, res.Login, map[string]string{"reason": request.Reason})
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Enable user
+// @Description Enable a disabled account. Revoked sessions are not restored.
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param login path string true "User login"
+// @Success 200 {object} userResponse
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{login}/enable [post]
+func (c *Controller) EnableUser(ctx *gin.Context) {
+	res, err := c.adminService.EnableUser(ctx.GetHeader(ADMIN_TOKEN_HEADER), ctx.Param("login"))
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+	c.recordUserEvent(ctx, audit.ActionUserEnable, res.Login, nil)
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Revoke user sessions
+// @Description Log the user out everywhere: all active tokens are revoked
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param login path string true "User login"
+// @Success 200 {object} sessionsResponse
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{login}/sessions [delete]
+func (c *Controller) RevokeSessions(ctx *gin.Context) {
+	login := ctx.Param("login")
+
+	revoked, err := c.adminService.RevokeSessions(ctx.GetHeader(ADMIN_TOKEN_HEADER), login)
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+	c.recordUserEvent(ctx, audit.ActionSessionsRevoke, login, map[string]string{
+		"revoked": strconv.FormatInt(revoked, 10),
+	})
+
+	c.responseBuilder.Ok(ctx, map[string]int64{"revoked": revoked}, nil)
+}
+
+// @Summary Reset user password
+// @Description Set a new password and revoke all sessions of the user. Without a password in the body a random one is generated; it is returned only in this response.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param login path string true "User login"
+// @Param request body passwordRequest false "New password"
+// @Success 200 {object} passwordResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{login}/password [post]
+func (c *Controller) ResetPassword(ctx *gin.Context) {
+	var request passwordRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil && err != io.EOF {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid json"))
+		return
+	}
+
+	login := ctx.Param("login")
+
+	password, err := c.adminService.ResetPassword(ctx.GetHeader(ADMIN_TOKEN_HEADER), login, request.Pass)
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+	c.recordUserEvent(ctx, audit.ActionPasswordReset, login, map[string]string{
+		"generated": strconv.FormatBool(request.Pass == ""),
+	})
+
+	c.responseBuilder.Ok(ctx, map[string]string{"pswd": password}, nil)
+}
@@ -0,0 +1,151 @@
+package archivescontroller
+
+import (
+	"astral/internal/domain/archive"
+	"astral/internal/domain/audit"
+	"astral/internal/domain/file"
+	controllererrors "astral/internal/presentation/controller/errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary List archive entries
+// @Description Entries of a ZIP, TAR or TAR.GZ document with name, size and modification time. For ZIP only the central directory is read from storage.
+// @Tags archives
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param owner query string false "Document owner (optional - own document if not specified)"
+// @Success 200 {object} entriesResponse
+// @Failure 400 {object} response.ErrorResponse "Not an archive"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 422 {object} response.ErrorResponse "Corrupted archive"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/archive [get]
+func (c *Controller) ListEntries(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	_, res, err := c.archivesService.ListEntries(token.Login, ctx.Query("owner"), ctx.Param("docs_id"))
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Download archive entry
+// @Description Stream a single file from an archive without downloading the whole archive
+// @Tags archives
+// @Produce octet-stream
+// @Param docs_id path string true "Document ID"
+// @Param path query string true "Entry path inside the archive"
+// @Param owner query string false "Document owner (optional - own document if not specified)"
+// @Success 200 {file} binary
+// @Success 304 "Not Modified"
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 422 {object} response.ErrorResponse "Corrupted archive"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/archive/entry [get]
+func (c *Controller) GetEntry(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	name := ctx.Query("path")
+	if file.CleanPath(name) == "" {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("path is required"))
+		return
+	}
+
+	err := c.archivesService.StreamEntry(token.Login, ctx.Query("owner"), ctx.Param("docs_id"), name, func(doc file.File, entry archive.Entry, r io.Reader) error {
+		c.recordDocumentEvent(ctx, audit.ActionDownload, token.Login, doc, map[string]string{"entry": entry.Name})
+
+		mimeType := mime.TypeByExtension(path.Ext(entry.Name))
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+
+		ctx.Header("Content-Type", mimeType)
+		ctx.Header("Content-Length", strconv.FormatInt(entry.Size, 10))
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", path.Base(entry.Name)))
+		ctx.Status(http.StatusOK)
+
+		// Заголовки уже отправлены, ошибку передачи остается только записать в лог
+		if _, err := io.Copy(ctx.Writer, r); err != nil {
+			c.logger.Error("failed to send archive entry", "fileID", doc.ID, "entry", entry.Name, "error", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+}
+
+// @Summary Extract archive
+// @Description Extract an own archive into documents. Entry paths become folders under the given folder, by default a folder named after the archive next to it. Extraction is all or nothing: on failure the documents created so far are removed.
+// @Tags archives
+// @Accept json
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param request body extractRequest false "Target folder"
+// @Success 200 {object} documentsResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 413 {object} response.ErrorResponse "Archive is too large"
+// @Failure 422 {object} response.ErrorResponse "Corrupted archive"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/archive/extract [post]
+func (c *Controller) Extract(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	var req extractRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid json"))
+			return
+		}
+	}
+
+	_, res, err := c.archivesService.Extract(token.Login, ctx.Param("docs_id"), req.Folder)
+	// При ошибке сервис удаляет распакованное, здесь остаются только документы, которые удалить не удалось
+	for _, created := range res {
+		c.recordDocumentEvent(ctx, audit.ActionUpload, token.Login, created, map[string]string{
+			"mime":       created.Mime,
+			"path":       created.Path(),
+			"archive_id": ctx.Param("docs_id"),
+		})
+	}
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
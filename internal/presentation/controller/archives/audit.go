@@ -0,0 +1,16 @@
+package archivescontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/file"
+	"astral/internal/presentation/controller/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (c *Controller) recordDocumentEvent(ctx *gin.Context, action audit.Action, actor string, doc file.File, details map[string]string) {
+	event := utils.NewAuditEvent(ctx, action, actor)
+	event.Details = details
+
+	c.activityService.RecordDocument(event, doc)
+}
@@ -0,0 +1,53 @@
+package archivescontroller
+
+import (
+	"astral/internal/presentation/middleware"
+	filesrepo "astral/internal/repository/files"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ArchiveValidators возвращает ETag и дату изменения своего архива для условной распаковки
+func (c *Controller) ArchiveValidators(ctx *gin.Context) (*middleware.Validators, error) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return nil, nil
+	}
+
+	doc, err := c.archivesService.Document(token.Login, "", ctx.Param("docs_id"))
+	if errors.Is(err, filesrepo.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return middleware.DocumentValidators(*doc), nil
+}
+
+// EntryValidators возвращает валидаторы записи архива. Запись меняется только вместе с архивом,
+// но это другое представление, поэтому ее тег выводится из тега архива и пути записи
+func (c *Controller) EntryValidators(ctx *gin.Context) (*middleware.Validators, error) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return nil, nil
+	}
+
+	doc, err := c.archivesService.Document(token.Login, ctx.Query("owner"), ctx.Param("docs_id"))
+	if errors.Is(err, filesrepo.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	validators := middleware.DocumentValidators(*doc)
+	hash := sha256.Sum256([]byte(strings.Trim(validators.ETag, `"`) + "/" + ctx.Query("path")))
+	validators.ETag = middleware.StrongETag(hex.EncodeToString(hash[:]))
+
+	return validators, nil
+}
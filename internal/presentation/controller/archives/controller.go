@@ -0,0 +1,33 @@
+package archivescontroller
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/presentation/controller/utils"
+	"astral/internal/presentation/response"
+	"log/slog"
+)
+
+type Controller struct {
+	logger          *slog.Logger
+	responseBuilder *response.ResponseBuilder
+	archivesService contracts.ArchivesInterface
+	activityService contracts.ActivityInterface
+	utils           utils.Utils
+}
+
+func NewController(
+	logger *slog.Logger,
+	responseBuilder *response.ResponseBuilder,
+	archives contracts.ArchivesInterface,
+	activity contracts.ActivityInterface,
+	utils utils.Utils,
+) *Controller {
+	logger = logger.With("controller", "archives")
+	return &Controller{
+		logger:          logger,
+		responseBuilder: responseBuilder,
+		archivesService: archives,
+		activityService: activity,
+		utils:           utils,
+	}
+}
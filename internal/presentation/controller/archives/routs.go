@@ -0,0 +1,27 @@
+package archivescontroller
+
+import (
+	"astral/internal/presentation/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Router struct {
+	controller  *Controller
+	conditional func(lookup middleware.ValidatorsFunc) gin.HandlerFunc
+}
+
+func NewRouter(controller *Controller, conditional func(lookup middleware.ValidatorsFunc) gin.HandlerFunc) *Router {
+	return &Router{
+		controller:  controller,
+		conditional: conditional,
+	}
+}
+
+// @Summary Register archives routes
+// @Description Group of endpoints for browsing and extracting ZIP and TAR archives
+func (r *Router) RegisterRoutes(archives *gin.RouterGroup) {
+	archives.GET("/docs/:docs_id/archive", r.controller.ListEntries)
+	archives.GET("/docs/:docs_id/archive/entry", r.conditional(r.controller.EntryValidators), r.controller.GetEntry)
+	archives.POST("/docs/:docs_id/archive/extract", r.conditional(r.controller.ArchiveValidators), r.controller.Extract)
+}
@@ -0,0 +1,18 @@
+package archivescontroller
+
+import (
+	"astral/internal/domain/archive"
+	"astral/internal/domain/file"
+)
+
+type extractRequest struct {
+	Folder string `json:"folder" example:"reports/2024"`
+}
+
+type entriesResponse struct {
+	Response []archive.Entry `json:"response"`
+}
+
+type documentsResponse struct {
+	Response []file.File `json:"response"`
+}
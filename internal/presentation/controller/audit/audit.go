@@ -0,0 +1,159 @@
+package auditcontroller
+
+import (
+	"astral/internal/domain/audit"
+	controllererrors "astral/internal/presentation/controller/errors"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get audit events
+// @Description Get audit events, newest first. Users see their own actions and actions on their documents, the admin token in X-Admin-Token opens the whole log.
+// @Tags audit
+// @Produce json
+// @Param X-Admin-Token header string false "Admin token"
+// @Param actor query string false "Who performed the action"
+// @Param owner query string false "Owner of the target"
+// @Param action query string false "Action" Enums(upload, download, metadata_update, delete, share, login, logout, auth_failed, export, account_delete, account_restore, admin_view, user_disable, user_enable, sessions_revoke, password_reset, copy, transfer)
+// @Param target query string false "Target ID (document ID)"
+// @Param from query string false "Start of the period, RFC 3339"
+// @Param to query string false "End of the period, RFC 3339"
+// @Param before query int false "Return events with ID less than this value (pagination)"
+// @Param limit query int false "Number of events to return" minimum(1) maximum(1000) default(100)
+// @Success 200 {object} eventsResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/audit [get]
+func (c *Controller) GetEvents(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	filter, err := parseFilter(ctx)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	events, err := c.auditService.Query(token.Login, ctx.GetHeader(ADMIN_TOKEN_HEADER), *filter)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	if events == nil {
+		events = []audit.Event{}
+	}
+
+	c.responseBuilder.Ok(ctx, events, nil)
+}
+
+// @Summary Export audit events
+// @Description Export all matching audit events as NDJSON, one event per line. Accepts the same filters as GET /api/audit except limit.
+// @Tags audit
+// @Produce x-ndjson
+// @Param X-Admin-Token header string false "Admin token"
+// @Param actor query string false "Who performed the action"
+// @Param owner query string false "Owner of the target"
+// @Param action query string false "Action"
+// @Param target query string false "Target ID (document ID)"
+// @Param from query string false "Start of the period, RFC 3339"
+// @Param to query string false "End of the period, RFC 3339"
+// @Success 200 {string} string "NDJSON stream of audit events"
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/audit/export [get]
+func (c *Controller) ExportEvents(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	filter, err := parseFilter(ctx)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	started := false
+	encoder := json.NewEncoder(ctx.Writer)
+	err = c.auditService.Export(token.Login, ctx.GetHeader(ADMIN_TOKEN_HEADER), *filter, func(event audit.Event) error {
+		// Заголовки отправляем с первым событием, чтобы ошибка до начала выгрузки ушла обычным JSON ответом
+		if !started {
+			started = true
+			ctx.Header("Content-Type", "application/x-ndjson")
+			ctx.Header("Content-Disposition", `attachment; filename="audit.ndjson"`)
+			ctx.Status(http.StatusOK)
+		}
+
+		return encoder.Encode(event)
+	})
+	if err != nil {
+		if !started {
+			c.responseBuilder.Error(ctx, err)
+			return
+		}
+
+		c.logger.Error("failed to export audit events", "login", token.Login, "error", err)
+		return
+	}
+
+	if !started {
+		ctx.Header("Content-Type", "application/x-ndjson")
+		ctx.Status(http.StatusOK)
+	}
+}
+
+func parseFilter(ctx *gin.Context) (*audit.Filter, error) {
+	filter := audit.Filter{
+		Actor:    ctx.Query("actor"),
+		Owner:    ctx.Query("owner"),
+		Action:   audit.Action(ctx.Query("action")),
+		TargetID: ctx.Query("target"),
+	}
+
+	if value := ctx.Query("from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, controllererrors.NewErrInvalidInputData("invalid from value")
+		}
+		filter.From = &from
+	}
+
+	if value := ctx.Query("to"); value != "" {
+		to, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, controllererrors.NewErrInvalidInputData("invalid to value")
+		}
+		filter.To = &to
+	}
+
+	if value := ctx.Query("before"); value != "" {
+		before, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || before <= 0 {
+			return nil, controllererrors.NewErrInvalidInputData("invalid before value")
+		}
+		filter.BeforeID = before
+	}
+
+	if value := ctx.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return nil, controllererrors.NewErrInvalidInputData("invalid limit value")
+		}
+		filter.Limit = limit
+	}
+
+	return &filter, nil
+}
@@ -0,0 +1,34 @@
+package auditcontroller
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/presentation/controller/utils"
+	"astral/internal/presentation/response"
+	"log/slog"
+)
+
+const (
+	ADMIN_TOKEN_HEADER = "X-Admin-Token"
+)
+
+type Controller struct {
+	logger          *slog.Logger
+	responseBuilder *response.ResponseBuilder
+	auditService    contracts.AuditInterface
+	utils           utils.Utils
+}
+
+func NewController(
+	logger *slog.Logger,
+	responseBuilder *response.ResponseBuilder,
+	audit contracts.AuditInterface,
+	utils utils.Utils,
+) *Controller {
+	logger = logger.With("controller", "audit")
+	return &Controller{
+		logger:          logger,
+		responseBuilder: responseBuilder,
+		auditService:    audit,
+		utils:           utils,
+	}
+}
@@ -0,0 +1,22 @@
+package auditcontroller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type Router struct {
+	controller *Controller
+}
+
+func NewRouter(controller *Controller) *Router {
+	return &Router{
+		controller: controller,
+	}
+}
+
+// @Summary Register audit routes
+// @Description Group of endpoints for reading the audit log
+func (r *Router) RegisterRoutes(audit *gin.RouterGroup) {
+	audit.GET("/audit", r.controller.GetEvents)
+	audit.GET("/audit/export", r.controller.ExportEvents)
+}
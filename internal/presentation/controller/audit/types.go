@@ -0,0 +1,7 @@
+package auditcontroller
+
+import "astral/internal/domain/audit"
+
+type eventsResponse struct {
+	Response []audit.Event `json:"response"`
+}
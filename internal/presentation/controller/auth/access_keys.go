@@ -0,0 +1,93 @@
+package authcontroller
+
+import (
+	controllererrors "astral/internal/presentation/controller/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create S3 access key
+// @Description Create access key pair for the S3-compatible API. The secret is returned only once.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} accessKeyResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/access-keys [post]
+func (c *Controller) CreateAccessKey(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.authService.CreateAccessKey(token.Login)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary List S3 access keys
+// @Tags auth
+// @Produce json
+// @Success 200 {object} accessKeysResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/access-keys [get]
+func (c *Controller) GetAccessKeys(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.authService.GetAccessKeys(token.Login)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Revoke S3 access key
+// @Tags auth
+// @Produce json
+// @Param id path string true "Access key ID"
+// @Success 200 {object} deleteAccessKeyResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/access-keys/{id} [delete]
+func (c *Controller) DeleteAccessKey(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	id := ctx.Param("id")
+	if id == "" {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("id param is required"))
+		return
+	}
+
+	res, err := c.authService.DeleteAccessKey(token.Login, id)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	isDeleted := map[string]bool{
+		res.ID: true,
+	}
+
+	c.responseBuilder.Ok(ctx, isDeleted, nil)
+}
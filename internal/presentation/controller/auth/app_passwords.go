@@ -0,0 +1,109 @@
+package authcontroller
+
+import (
+	controllererrors "astral/internal/presentation/controller/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Create app password
+// @Description Create password for third-party clients (WebDAV, office suites). The password is returned only once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body appPasswordRequest true "app password data"
+// @Success 200 {object} appPasswordResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/app-passwords [post]
+func (c *Controller) CreateAppPassword(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	var request appPasswordRequest
+	err := ctx.ShouldBindJSON(&request)
+	if err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid json"))
+		return
+	}
+
+	if request.Name == "" {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("name field is required"))
+		return
+	}
+
+	res, err := c.authService.CreateAppPassword(token.Login, request.Name)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary List app passwords
+// @Tags auth
+// @Produce json
+// @Success 200 {object} appPasswordsResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/app-passwords [get]
+func (c *Controller) GetAppPasswords(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.authService.GetAppPasswords(token.Login)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Revoke app password
+// @Tags auth
+// @Produce json
+// @Param id path string true "App password ID"
+// @Success 200 {object} deleteAppPasswordResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/app-passwords/{id} [delete]
+func (c *Controller) DeleteAppPassword(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	id := ctx.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid id"))
+		return
+	}
+
+	res, err := c.authService.DeleteAppPassword(token.Login, id)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	isDeleted := map[string]bool{
+		res.ID: true,
+	}
+
+	c.responseBuilder.Ok(ctx, isDeleted, nil)
+}
@@ -0,0 +1,20 @@
+package authcontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/presentation/controller/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordAuthEvent фиксирует событие входа или выхода, владельцем считается учетная запись,
+// чтобы пользователь видел в журнале и неудачные попытки входа под своим логином
+func (c *Controller) recordAuthEvent(ctx *gin.Context, action audit.Action, login string, details map[string]string) {
+	event := utils.NewAuditEvent(ctx, action, login)
+	event.Owner = login
+	event.TargetType = audit.TargetUser
+	event.TargetID = login
+	event.Details = details
+
+	c.auditService.Record(event)
+}
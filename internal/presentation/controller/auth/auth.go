@@ -1,6 +1,7 @@
 package authcontroller
 
 import (
+	"astral/internal/domain/audit"
 	"astral/internal/domain/dto"
 	controllererrors "astral/internal/presentation/controller/errors"
 
@@ -96,9 +97,11 @@ func (c *Controller) Login(ctx *gin.Context) {
 
 	res, err := c.authService.Login(user)
 	if err != nil {
+		c.recordAuthEvent(ctx, audit.ActionAuthFailed, loginRequestData.Login, map[string]string{"method": "password", "reason": err.Error()})
 		c.responseBuilder.Error(ctx, err)
 		return
 	}
+	c.recordAuthEvent(ctx, audit.ActionLogin, res.Login, map[string]string{"method": "password"})
 
 	token := map[string]string{
 		"token": res.Token,
@@ -141,6 +144,7 @@ func (c *Controller) DeleteSession(ctx *gin.Context) {
 		c.responseBuilder.Error(ctx, err)
 		return
 	}
+	c.recordAuthEvent(ctx, audit.ActionLogout, token.Login, nil)
 
 	isClosedSession := map[string]bool{
 		res.Token: true,
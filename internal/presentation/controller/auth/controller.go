@@ -11,6 +11,7 @@ type Controller struct {
 	logger           *slog.Logger
 	responseBuilder  *response.ResponseBuilder
 	authService      contracts.AuthInterface
+	auditService     contracts.AuditInterface
 	adminToken 		 string
 	utils            utils.Utils
 }
@@ -19,6 +20,7 @@ func NewController(
 	logger 			*slog.Logger,
 	responseBuilder *response.ResponseBuilder,
 	auth 			contracts.AuthInterface,
+	audit           contracts.AuditInterface,
 	token			string,
 	utils           utils.Utils,
 ) *Controller {
@@ -27,6 +29,7 @@ func NewController(
 		logger:           logger,
 		responseBuilder:  responseBuilder,
 		authService: 	  auth,
+		auditService:     audit,
 		adminToken:		  token,
 		utils:            utils,
 	}
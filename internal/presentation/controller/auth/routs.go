@@ -20,4 +20,12 @@ func (r *Router) RegisterRoutes(auth *gin.RouterGroup, authSecure *gin.RouterGro
 	auth.POST("/register", r.controller.Register)
 	auth.POST("/auth", r.controller.Login)
 	authSecure.DELETE("/auth/:token_id", r.controller.DeleteSession)
+
+	authSecure.POST("/app-passwords", r.controller.CreateAppPassword)
+	authSecure.GET("/app-passwords", r.controller.GetAppPasswords)
+	authSecure.DELETE("/app-passwords/:id", r.controller.DeleteAppPassword)
+
+	authSecure.POST("/access-keys", r.controller.CreateAccessKey)
+	authSecure.GET("/access-keys", r.controller.GetAccessKeys)
+	authSecure.DELETE("/access-keys/:id", r.controller.DeleteAccessKey)
 }
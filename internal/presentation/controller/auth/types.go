@@ -27,4 +27,47 @@ type deleteResponse struct {
 	Response struct {
 		Token bool `json:"token_id"`
 	} `json:"response"`
-}
\ No newline at end of file
+}
+type appPasswordRequest struct {
+	Name string `json:"name"`
+}
+
+type appPasswordResponse struct {
+	Response struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	} `json:"response"`
+}
+
+type appPasswordsResponse struct {
+	Response []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"response"`
+}
+
+type deleteAppPasswordResponse struct {
+	Response struct {
+		ID bool `json:"id"`
+	} `json:"response"`
+}
+
+type accessKeyResponse struct {
+	Response struct {
+		ID     string `json:"access_key_id"`
+		Secret string `json:"secret_access_key"`
+	} `json:"response"`
+}
+
+type accessKeysResponse struct {
+	Response []struct {
+		ID string `json:"access_key_id"`
+	} `json:"response"`
+}
+
+type deleteAccessKeyResponse struct {
+	Response struct {
+		ID bool `json:"access_key_id"`
+	} `json:"response"`
+}
@@ -0,0 +1,22 @@
+package commentscontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/comment"
+	"astral/internal/domain/file"
+	"astral/internal/presentation/controller/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordComment фиксирует действие с комментарием как событие документа
+func (c *Controller) recordComment(ctx *gin.Context, actor string, doc file.File, current comment.Comment, commentAction string) {
+	event := utils.NewAuditEvent(ctx, audit.ActionComment, actor)
+	event.Details = map[string]string{
+		"comment_id":     strconv.FormatInt(current.ID, 10),
+		"comment_action": commentAction,
+	}
+
+	c.activityService.RecordDocument(event, doc)
+}
@@ -0,0 +1,232 @@
+package commentscontroller
+
+import (
+	controllererrors "astral/internal/presentation/controller/errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary List document comments
+// @Description Comment threads of a document available to the user. Replies are nested into their parent comment, deleted comments are kept only while they have replies.
+// @Tags comments
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param owner query string false "Document owner (optional - own document if not specified)"
+// @Success 200 {object} commentsResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/comments [get]
+func (c *Controller) GetComments(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.commentsService.GetComments(token.Login, ctx.Query("owner"), ctx.Param("docs_id"))
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Create comment
+// @Description Comment a document or reply to a comment with parent_id. A top-level comment may be anchored to a page or to a byte range [start, end). Mentioned users (@login) with access to the document are notified.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param owner query string false "Document owner (optional - own document if not specified)"
+// @Param request body createCommentRequest true "Comment data"
+// @Success 200 {object} commentResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/comments [post]
+func (c *Controller) CreateComment(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	var req createCommentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid json"))
+		return
+	}
+
+	doc, res, err := c.commentsService.CreateComment(token.Login, ctx.Query("owner"), ctx.Param("docs_id"), req.ParentID, req.Body, req.Anchor)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordComment(ctx, token.Login, *doc, *res, "create")
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Edit comment
+// @Description Change the text of an own comment. Only newly mentioned users are notified.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param comment_id path int true "Comment ID"
+// @Param owner query string false "Document owner (optional - own document if not specified)"
+// @Param request body updateCommentRequest true "Comment text"
+// @Success 200 {object} commentResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/comments/{comment_id} [patch]
+func (c *Controller) UpdateComment(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	id, ok := c.commentID(ctx)
+	if !ok {
+		return
+	}
+
+	var req updateCommentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid json"))
+		return
+	}
+
+	doc, res, err := c.commentsService.UpdateComment(token.Login, ctx.Query("owner"), ctx.Param("docs_id"), id, req.Body)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordComment(ctx, token.Login, *doc, *res, "edit")
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Delete comment
+// @Description Delete an own comment. Replies to it stay in the thread.
+// @Tags comments
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param comment_id path int true "Comment ID"
+// @Param owner query string false "Document owner (optional - own document if not specified)"
+// @Success 200 {object} deleteCommentResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/comments/{comment_id} [delete]
+func (c *Controller) DeleteComment(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	id, ok := c.commentID(ctx)
+	if !ok {
+		return
+	}
+
+	doc, res, err := c.commentsService.DeleteComment(token.Login, ctx.Query("owner"), ctx.Param("docs_id"), id)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordComment(ctx, token.Login, *doc, *res, "delete")
+
+	isDeleted := map[string]bool{
+		strconv.FormatInt(res.ID, 10): true,
+	}
+
+	c.responseBuilder.Ok(ctx, isDeleted, nil)
+}
+
+// @Summary Resolve comment thread
+// @Description Mark a top-level comment thread as resolved. Allowed to the thread author and the document owner.
+// @Tags comments
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param comment_id path int true "Comment ID"
+// @Param owner query string false "Document owner (optional - own document if not specified)"
+// @Success 200 {object} commentResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/comments/{comment_id}/resolve [post]
+func (c *Controller) ResolveComment(ctx *gin.Context) {
+	c.setResolved(ctx, true)
+}
+
+// @Summary Reopen comment thread
+// @Description Remove the resolved mark from a top-level comment thread
+// @Tags comments
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param comment_id path int true "Comment ID"
+// @Param owner query string false "Document owner (optional - own document if not specified)"
+// @Success 200 {object} commentResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/comments/{comment_id}/resolve [delete]
+func (c *Controller) ReopenComment(ctx *gin.Context) {
+	c.setResolved(ctx, false)
+}
+
+func (c *Controller) setResolved(ctx *gin.Context, resolved bool) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	id, ok := c.commentID(ctx)
+	if !ok {
+		return
+	}
+
+	doc, res, err := c.commentsService.ResolveComment(token.Login, ctx.Query("owner"), ctx.Param("docs_id"), id, resolved)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	action := "reopen"
+	if resolved {
+		action = "resolve"
+	}
+	c.recordComment(ctx, token.Login, *doc, *res, action)
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+func (c *Controller) commentID(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("comment_id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid comment_id value"))
+		return 0, false
+	}
+
+	return id, true
+}
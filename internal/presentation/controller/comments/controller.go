@@ -0,0 +1,33 @@
+package commentscontroller
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/presentation/controller/utils"
+	"astral/internal/presentation/response"
+	"log/slog"
+)
+
+type Controller struct {
+	logger          *slog.Logger
+	responseBuilder *response.ResponseBuilder
+	commentsService contracts.CommentsInterface
+	activityService contracts.ActivityInterface
+	utils           utils.Utils
+}
+
+func NewController(
+	logger *slog.Logger,
+	responseBuilder *response.ResponseBuilder,
+	comments contracts.CommentsInterface,
+	activity contracts.ActivityInterface,
+	utils utils.Utils,
+) *Controller {
+	logger = logger.With("controller", "comments")
+	return &Controller{
+		logger:          logger,
+		responseBuilder: responseBuilder,
+		commentsService: comments,
+		activityService: activity,
+		utils:           utils,
+	}
+}
@@ -0,0 +1,26 @@
+package commentscontroller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type Router struct {
+	controller *Controller
+}
+
+func NewRouter(controller *Controller) *Router {
+	return &Router{
+		controller: controller,
+	}
+}
+
+// @Summary Register comments routes
+// @Description Group of endpoints for document comments
+func (r *Router) RegisterRoutes(comments *gin.RouterGroup) {
+	comments.GET("/docs/:docs_id/comments", r.controller.GetComments)
+	comments.POST("/docs/:docs_id/comments", r.controller.CreateComment)
+	comments.PATCH("/docs/:docs_id/comments/:comment_id", r.controller.UpdateComment)
+	comments.DELETE("/docs/:docs_id/comments/:comment_id", r.controller.DeleteComment)
+	comments.POST("/docs/:docs_id/comments/:comment_id/resolve", r.controller.ResolveComment)
+	comments.DELETE("/docs/:docs_id/comments/:comment_id/resolve", r.controller.ReopenComment)
+}
@@ -0,0 +1,27 @@
+package commentscontroller
+
+import "astral/internal/domain/comment"
+
+type createCommentRequest struct {
+	ParentID *int64          `json:"parent_id"`
+	Body     string          `json:"body" example:"@alice please check the second page"`
+	Anchor   *comment.Anchor `json:"anchor"`
+}
+
+type updateCommentRequest struct {
+	Body string `json:"body"`
+}
+
+type commentResponse struct {
+	Response comment.Comment `json:"response"`
+}
+
+type commentsResponse struct {
+	Response []comment.Comment `json:"response"`
+}
+
+type deleteCommentResponse struct {
+	Response struct {
+		ID bool `json:"comment_id"`
+	} `json:"response"`
+}
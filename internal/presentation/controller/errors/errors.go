@@ -1,5 +1,7 @@
 package controllererrors
 
+import "errors"
+
 type ErrInvalidInputData struct {
 	err string
 }
@@ -12,4 +14,12 @@ func NewErrInvalidInputData(err string) ErrInvalidInputData {
 
 func (e ErrInvalidInputData) Error() string {
 	return "invalid input data: " + e.err
-}
\ No newline at end of file
+}
+
+var (
+	// ErrPreconditionRequired - изменение без If-Match, когда сервер требует условный запрос (RFC 6585)
+	ErrPreconditionRequired = errors.New("if-match header is required")
+	// ErrPreconditionFailed - условие If-Match / If-None-Match / If-Unmodified-Since не выполнено (RFC 9110)
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
@@ -0,0 +1,89 @@
+package filescontroller
+
+import (
+	"astral/internal/domain/file"
+	controllererrors "astral/internal/presentation/controller/errors"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// parseDigest читает контрольные суммы загружаемого файла из заголовков Content-MD5, Digest
+// (md5 и sha-256, RFC 3230) и X-Checksum-SHA256 (hex или base64). Для multipart запроса они
+// относятся к содержимому файла, а не к телу запроса. Без заголовков возвращает nil
+func parseDigest(header http.Header) (*file.Digest, error) {
+	digest := &file.Digest{}
+
+	if value := header.Get("Content-MD5"); value != "" {
+		if err := setChecksum(&digest.MD5, value, md5.Size, false); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, part := range strings.Split(header.Get("Digest"), ",") {
+		algorithm, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+
+		var err error
+		switch strings.ToLower(algorithm) {
+		case "md5":
+			err = setChecksum(&digest.MD5, value, md5.Size, false)
+		case "sha-256":
+			err = setChecksum(&digest.SHA256, value, sha256.Size, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if value := header.Get("X-Checksum-SHA256"); value != "" {
+		if err := setChecksum(&digest.SHA256, value, sha256.Size, true); err != nil {
+			return nil, err
+		}
+	}
+
+	if digest.Empty() {
+		return nil, nil
+	}
+
+	return digest, nil
+}
+
+// setChecksum декодирует значение заголовка и сверяет его с уже прочитанным из другого заголовка
+func setChecksum(dst *[]byte, value string, size int, allowHex bool) error {
+	value = strings.TrimSpace(value)
+
+	var sum []byte
+	if allowHex && len(value) == hex.EncodedLen(size) {
+		sum, _ = hex.DecodeString(value)
+	}
+	if sum == nil {
+		sum, _ = base64.StdEncoding.DecodeString(value)
+	}
+	if len(sum) != size {
+		return controllererrors.NewErrInvalidInputData("invalid checksum header")
+	}
+
+	if *dst != nil && !bytes.Equal(*dst, sum) {
+		return controllererrors.NewErrInvalidInputData("conflicting checksum headers")
+	}
+	*dst = sum
+
+	return nil
+}
+
+// digestHeader возвращает значение заголовка Digest для SHA-256 документа в hex
+func digestHeader(checksum string) string {
+	sum, err := hex.DecodeString(checksum)
+	if err != nil || len(sum) != sha256.Size {
+		return ""
+	}
+
+	return "sha-256=" + base64.StdEncoding.EncodeToString(sum)
+}
@@ -0,0 +1,34 @@
+package filescontroller
+
+import (
+	"astral/internal/presentation/middleware"
+	filesrepo "astral/internal/repository/files"
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DocumentValidators возвращает ETag и дату изменения документа для условных запросов
+func (c *Controller) DocumentValidators(ctx *gin.Context) (*middleware.Validators, error) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return nil, nil
+	}
+
+	fileData, err := c.filesService.GetFileInfo(ctx.Param("docs_id"), token.Login)
+	if errors.Is(err, filesrepo.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	validators := middleware.DocumentValidators(*fileData)
+	// Сжатое и распакованное содержимое - разные представления, сильный тег у них должен различаться
+	if fileData.File && fileData.Encoding != "" && acceptsEncoding(ctx.GetHeader("Accept-Encoding"), fileData.Encoding) {
+		validators.ETag = middleware.StrongETag(strings.Trim(validators.ETag, `"`) + "-" + fileData.Encoding)
+	}
+
+	return validators, nil
+}
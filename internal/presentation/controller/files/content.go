@@ -0,0 +1,80 @@
+package filescontroller
+
+import (
+	"astral/internal/domain/audit"
+	controllererrors "astral/internal/presentation/controller/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Replace document content
+// @Description Upload a new version of an own document under the same ID. Name, access and data are kept, the uploader's lock on the document is released.
+// @Tags docs
+// @Accept multipart/form-data
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param file formData file true "New document content"
+// @Param Content-MD5 header string false "Base64 MD5 of the file content"
+// @Param Digest header string false "RFC 3230 digest of the file content (md5, sha-256)"
+// @Param X-Checksum-SHA256 header string false "Hex or base64 SHA-256 of the file content"
+// @Param If-Match header string false "Expected ETags, strong comparison"
+// @Param If-Unmodified-Since header string false "HTTP-date, ignored with If-Match"
+// @Param If-None-Match header string false "ETags the document must not have, * - the document must not exist"
+// @Success 200 {object} uploadDataResponse "Document replaced successfully"
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 422 {object} response.ErrorResponse "Content does not match the checksum"
+// @Failure 423 {object} response.ErrorResponse "Locked by another user"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/content [put]
+func (c *Controller) ReplaceFile(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	digest, err := parseDigest(ctx.Request.Header)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid multipart form"))
+		return
+	}
+
+	files := form.File["file"]
+	if len(files) != 1 {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("exactly one file is required"))
+		return
+	}
+
+	r, err := files[0].Open()
+	if err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("failed to open file"))
+		return
+	}
+	defer r.Close()
+
+	res, err := c.filesService.ReplaceFile(ctx.Param("docs_id"), token.Login, r, int(files[0].Size), digest)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	// Версия заменена на месте, поэтому предыдущий ID совпадает с текущим
+	c.recordDocumentEvent(ctx, audit.ActionUpload, token.Login, *res, map[string]string{
+		"mime":        res.Mime,
+		"previous_id": res.ID,
+	})
+
+	c.responseBuilder.Ok(ctx, nil, uploadDataResponse{
+		Json: res,
+		File: res.Name,
+	})
+}
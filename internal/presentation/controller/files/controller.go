@@ -11,6 +11,7 @@ type Controller struct {
 	logger           *slog.Logger
 	responseBuilder  *response.ResponseBuilder
 	filesService     contracts.FilesInterface
+	activityService  contracts.ActivityInterface
 	adminToken 		 string
 	utils            utils.Utils
 }
@@ -19,6 +20,7 @@ func NewController(
 	logger 			*slog.Logger,
 	responseBuilder *response.ResponseBuilder,
 	files 			contracts.FilesInterface,
+	activity        contracts.ActivityInterface,
 	token			string,
 ) *Controller {
 	logger = logger.With("controller", "files")
@@ -27,5 +29,6 @@ func NewController(
 		responseBuilder:  responseBuilder,
 		adminToken:		  token,
 		filesService:     files,
+		activityService:  activity,
 	}
 }
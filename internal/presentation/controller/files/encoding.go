@@ -0,0 +1,61 @@
+package filescontroller
+
+import (
+	"astral/internal/domain/file"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sendEncoded отдает сжатый документ без распаковки, с заголовком Content-Encoding. Возвращает
+// false, если хранимый объект отдать не удалось и документ нужно отправить распакованным
+func (c *Controller) sendEncoded(ctx *gin.Context, fileData file.File, login string) bool {
+	owner := fileData.User
+	if owner == "" {
+		owner = login
+	}
+
+	reader, size, encoding, err := c.filesService.OpenRaw(fileData.ID, owner)
+	if err != nil {
+		c.logger.Warn("failed to open stored file", "fileID", fileData.ID, "error", err)
+		return false
+	}
+	defer reader.Close()
+
+	// Объект могли перезаписать после кэширования
+	if encoding != fileData.Encoding || size < 0 {
+		return false
+	}
+
+	ctx.Header("Content-Encoding", encoding)
+	ctx.Header("Content-Length", strconv.FormatInt(size, 10))
+	ctx.Status(http.StatusOK)
+	if _, err := io.Copy(ctx.Writer, reader); err != nil {
+		c.logger.Error("failed to send file", "error", err)
+	}
+
+	return true
+}
+
+// acceptsEncoding сообщает, допускает ли заголовок Accept-Encoding кодирование encoding
+func acceptsEncoding(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.TrimSpace(name)
+		if !strings.EqualFold(name, encoding) && name != "*" {
+			continue
+		}
+
+		q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !ok {
+			return true
+		}
+		weight, err := strconv.ParseFloat(q, 64)
+		return err == nil && weight > 0
+	}
+
+	return false
+}
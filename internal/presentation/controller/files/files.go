@@ -1,9 +1,11 @@
 package filescontroller
 
 import (
+	"astral/internal/domain/audit"
 	"astral/internal/domain/contracts"
 	"astral/internal/domain/file"
 	controllererrors "astral/internal/presentation/controller/errors"
+	"astral/internal/presentation/middleware"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -17,19 +19,27 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	MAX_JSON_SIZE = 1 << 20
+)
+
 // @Summary Upload document
 // @Description Upload new document with metadata and file
 // @Tags docs
 // @Accept multipart/form-data
 // @Produce json
-// @Param meta formData string true "Document metadata in JSON format" example({"name": "photo.jpg", "file": true, "public": false, "mime": "image/jpg", "grant": ["login1", "login2"]})
+// @Param meta formData string true "Document metadata in JSON format" example({"name": "photo.jpg", "file": true, "public": false, "mime": "image/jpg", "grant": ["login1", "login2"], "strip_gps": true})
 // @Param json formData string false "Document data in JSON format (optional)"
 // @Param file formData file true "Document file"
+// @Param Content-MD5 header string false "Base64 MD5 of the file content"
+// @Param Digest header string false "RFC 3230 digest of the file content (md5, sha-256)"
+// @Param X-Checksum-SHA256 header string false "Hex or base64 SHA-256 of the file content"
 // @Success 200 {object} uploadDataResponse "Document uploaded successfully"
 // @Failure 400 {object} response.ErrorResponse "Bad Request"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 403 {object} response.ErrorResponse "Forbidden"
 // @Failure 409 {object} response.ErrorResponse "Conflict"
+// @Failure 422 {object} response.ErrorResponse "Content does not match the checksum"
 // @Failure 500 {object} response.ErrorResponse "Internal Server Error"
 // @Security BearerAuth
 // @Router /api/docs [post]
@@ -56,13 +66,25 @@ func (c *Controller) UploadFile(ctx *gin.Context) {
         return
     }
 
-	var documentData map[string]any
-    if jsonData := ctx.PostForm("json"); jsonData != "" {
-        if err := json.Unmarshal([]byte(jsonData), &documentData); err != nil {
-            c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid json"))
-            return
-        }
-    }
+	// JSON сохраняется как есть и доступен для запросов в списке документов
+	var documentData json.RawMessage
+	if jsonData := ctx.PostForm("json"); jsonData != "" {
+		if len(jsonData) > MAX_JSON_SIZE {
+			c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("json is too large"))
+			return
+		}
+		if !json.Valid([]byte(jsonData)) {
+			c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid json"))
+			return
+		}
+		documentData = json.RawMessage(jsonData)
+	}
+
+	digest, err := parseDigest(ctx.Request.Header)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
 
 	form, err := ctx.MultipartForm()
 	if err != nil {
@@ -95,9 +117,11 @@ func (c *Controller) UploadFile(ctx *gin.Context) {
 		Mime:     meta.Mime,
 		Grant:    meta.Grant,
 		Size:     int(files[0].Size),
-		Metadata: convertToStringMap(documentData),
+		Data:     documentData,
 		Reader:   r,
 		User:     token.Login,
+		Digest:   digest,
+		StripGPS: meta.StripGPS,
 	}
 
 	res, err := c.filesService.UploadFiles(fileData)
@@ -105,6 +129,8 @@ func (c *Controller) UploadFile(ctx *gin.Context) {
 		c.responseBuilder.Error(ctx, err)
 		return
 	}
+	c.recordDocumentEvent(ctx, audit.ActionUpload, token.Login, *res, map[string]string{"mime": res.Mime})
+	c.recordShare(ctx, token.Login, *res)
 
 	uploadFileResponseData := uploadDataResponse{
 			Json: res,
@@ -119,10 +145,12 @@ func (c *Controller) UploadFile(ctx *gin.Context) {
 // @Tags docs
 // @Produce json
 // @Param login query string false "User login filter (optional - returns own documents if not specified)"
-// @Param key query string false "Column name for filtering (optional)"
-// @Param value query string false "Filter value (optional)"
+// @Param key query string false "Column name for filtering (optional): name, mime, public, file, size, created, metadata, metadata.<key> (one metadata key, e.g. metadata.auto.width with >N, <N or N-M for numbers), grant, data (JSONPath predicate) or data_contains (JSON containment)"
+// @Param value query string false "Filter value (optional), for data e.g. invoice.total > 1000, for data_contains a JSON object"
 // @Param limit query int false "Number of documents to return (optional)" minimum(1) maximum(1000) default(50)
+// @Param If-None-Match header string false "Known ETags, weak comparison"
 // @Success 200 {object} getFilesResponse
+// @Success 304 "Not Modified"
 // @Failure 400 {object} response.ErrorResponse "Bad Request"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 403 {object} response.ErrorResponse "Forbidden"
@@ -134,7 +162,9 @@ func (c *Controller) UploadFile(ctx *gin.Context) {
 // @Summary Check documents availability
 // @Description Check if documents exist with given filters (HEAD request). Returns same headers as GET but without body.
 // @Tags docs
+// @Param If-None-Match header string false "Known ETags, weak comparison"
 // @Success 200 "Documents exist"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} response.ErrorResponse "Bad Request"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 403 {object} response.ErrorResponse "Forbidden"
@@ -175,12 +205,12 @@ func (c *Controller) GetFiles(ctx *gin.Context) {
 	}
 
 	actualEtag := generateCollectionETag(files, *filter)
-	if ctx.Request.Header.Get("If-None-Match") == actualEtag {
-		ctx.Status(http.StatusNotModified)
+    ctx.Header("ETag", actualEtag)
+	if middleware.EvaluatePreconditions(ctx.Request, &middleware.Validators{ETag: actualEtag}) == http.StatusNotModified {
+		ctx.AbortWithStatus(http.StatusNotModified)
 		return
 	}
 
-    ctx.Header("ETag", "\""+actualEtag+"\"")
     ctx.Header("Cache-Control", "public, max-age=43200")
     ctx.Header("X-File-Count", strconv.Itoa(len(files)))
 
@@ -201,11 +231,17 @@ func (c *Controller) GetFiles(ctx *gin.Context) {
 // @Tags docs
 // @Produce json,octet-stream
 // @Param id path string true "Document ID"
+// @Param If-None-Match header string false "Known ETags, weak comparison"
+// @Param If-Modified-Since header string false "HTTP-date, ignored with If-None-Match"
+// @Param If-Match header string false "Expected ETags, strong comparison"
+// @Param If-Unmodified-Since header string false "HTTP-date, ignored with If-Match"
 // @Success 200 {object} getFileResponse "JSON document retrieved successfully"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} response.ErrorResponse "Bad Request"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 403 {object} response.ErrorResponse "Forbidden"
 // @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
 // @Failure 500 {object} response.ErrorResponse "Internal Server Error"
 // @Security BearerAuth
 // @Router /api/docs/{id} [get]
@@ -215,11 +251,17 @@ func (c *Controller) GetFiles(ctx *gin.Context) {
 // @Tags docs
 // @Param id path string true "Document ID"
 // @Param token query string true "Authentication token"
+// @Param If-None-Match header string false "Known ETags, weak comparison"
+// @Param If-Modified-Since header string false "HTTP-date, ignored with If-None-Match"
+// @Param If-Match header string false "Expected ETags, strong comparison"
+// @Param If-Unmodified-Since header string false "HTTP-date, ignored with If-Match"
 // @Success 200 "Documents exist"
+// @Success 304 "Not Modified"
 // @Failure 400 {object} response.ErrorResponse "Bad Request"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 403 {object} response.ErrorResponse "Forbidden"
 // @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
 // @Failure 500 {object} response.ErrorResponse "Internal Server Error"
 // @Security BearerAuth
 // @Router /api/docs/{id} [head]
@@ -235,23 +277,23 @@ func (c *Controller) GetFile(ctx *gin.Context) {
 		c.responseBuilder.Error(ctx, err)
 		return
 	}
-
-	actualEtag := generateETag(*fileData)
-	if ctx.Request.Header.Get("If-None-Match") == actualEtag {
-		ctx.Status(http.StatusNotModified)
-		return
+	if closer, ok := fileData.Reader.(io.Closer); ok {
+		defer closer.Close()
 	}
 
+	// ETag и Last-Modified выставляет middleware условных запросов, он же отвечает 304 и 412
     ctx.Header("Content-Length", strconv.FormatInt(int64(fileData.Size), 10))
     ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", fileData.Name))
     ctx.Header("Cache-Control", "public, max-age=3600")
-    ctx.Header("Last-Modified", fileData.CreatedAt.String())
-    ctx.Header("ETag", "\""+actualEtag+"\"")
+	if digest := digestHeader(fileData.Checksum); digest != "" {
+		ctx.Header("Digest", digest)
+	}
 
 	switch ctx.Request.Method {
 	case "GET":
 		reader := fileData.Reader
 		fileData.Reader = nil
+		c.recordDocumentEvent(ctx, audit.ActionDownload, token.Login, *fileData, nil)
 
 		if !fileData.File {
 			c.responseBuilder.Ok(ctx, nil, fileData)
@@ -259,6 +301,13 @@ func (c *Controller) GetFile(ctx *gin.Context) {
 		}
 
 		ctx.Header("Content-Type", fileData.Mime) 
+		if fileData.Encoding != "" {
+			ctx.Header("Vary", "Accept-Encoding")
+			if acceptsEncoding(ctx.GetHeader("Accept-Encoding"), fileData.Encoding) && c.sendEncoded(ctx, *fileData, token.Login) {
+				return
+			}
+		}
+
 		_, err = io.Copy(ctx.Writer, reader)
 		if err != nil {
 			c.logger.Error("failed to send file", "error", err)
@@ -278,11 +327,15 @@ func (c *Controller) GetFile(ctx *gin.Context) {
 // @Tags docs
 // @Produce json
 // @Param id path string true "Document ID"
+// @Param If-Match header string false "Expected ETags, strong comparison"
+// @Param If-Unmodified-Since header string false "HTTP-date, ignored with If-Match"
+// @Param If-None-Match header string false "ETags the document must not have, * - the document must not exist"
 // @Success 200 {object} deleteFileResponse
 // @Failure 400 {object} response.ErrorResponse "Bad Request"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 403 {object} response.ErrorResponse "Forbidden"
 // @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
 // @Failure 500 {object} response.ErrorResponse "Internal Server Error"
 // @Security BearerAuth
 // @Router /api/docs/{id} [delete]
@@ -303,6 +356,7 @@ func (c *Controller) DeleteFile(ctx *gin.Context) {
 		c.responseBuilder.Error(ctx, err)
 		return
 	}
+	c.recordDocumentEvent(ctx, audit.ActionDelete, token.Login, *fileData, nil)
 
 	isDeletedFile := map[string]bool{
 		fileData.ID: true,
@@ -312,34 +366,6 @@ func (c *Controller) DeleteFile(ctx *gin.Context) {
 }
 
 
-func convertToStringMap(input map[string]any) map[string]string {
-    result := make(map[string]string)
-    for key, value := range input {
-        switch v := value.(type) {
-        case string:
-            result[key] = v
-        case fmt.Stringer:
-            result[key] = v.String()
-        default:
-            result[key] = fmt.Sprintf("%v", v)
-        }
-    }
-    return result
-}
-
-func generateETag(file file.File) string {
-    data := fmt.Sprintf("%s-%s-%d-%t-%v",
-        file.ID,
-        file.Name,
-        file.Size,
-        file.Public,
-        file.CreatedAt.Unix(),
-    )
-    
-    hash := sha256.Sum256([]byte(data))
-    return hex.EncodeToString(hash[:])
-}
-
 func generateCollectionETag(files []file.File, filter contracts.FilterData) string {
     components := []string{
         fmt.Sprintf("count:%d", len(files)),
@@ -350,12 +376,13 @@ func generateCollectionETag(files []file.File, filter contracts.FilterData) stri
 
     fileHashes := make([]string, len(files))
     for i, file := range files {
-        fileHashes[i] = generateETag(file)
+        fileHashes[i] = middleware.DocumentETag(file)
     }
     
     sort.Strings(fileHashes)
     components = append(components, "files:"+strings.Join(fileHashes, ","))
     content := strings.Join(components, "|")
     hash := sha256.Sum256([]byte(content))
-    return `"` + hex.EncodeToString(hash[:12]) + `"`
+    // Слабый тег: список равен по смыслу, но порядок документов в ответе может отличаться
+    return middleware.WeakETag(hex.EncodeToString(hash[:12]))
 }
\ No newline at end of file
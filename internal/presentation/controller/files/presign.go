@@ -0,0 +1,127 @@
+package filescontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/file"
+	controllererrors "astral/internal/presentation/controller/errors"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Presign document upload
+// @Description Create a document in pending state and get a short-lived URL to upload its content directly to the storage. Send a PUT request to the URL with the returned headers, then call finalize. The document stays pending until finalized.
+// @Tags docs
+// @Accept json
+// @Produce json
+// @Param request body presignRequest true "Document name, content size in bytes, access and optional hex or base64 SHA-256 of the content"
+// @Success 200 {object} presignResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 501 {object} response.ErrorResponse "Presigned URLs are disabled"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/presign [post]
+func (c *Controller) PresignUpload(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	var request presignRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("name and size fields are required"))
+		return
+	}
+
+	var checksum []byte
+	if request.Checksum != "" {
+		if err := setChecksum(&checksum, request.Checksum, sha256.Size, true); err != nil {
+			c.responseBuilder.Error(ctx, err)
+			return
+		}
+	}
+
+	res, err := c.filesService.PresignUpload(file.File{
+		Name:     request.Name,
+		File:     true,
+		Public:   request.Public,
+		Grant:    request.Grant,
+		Size:     request.Size,
+		Checksum: hex.EncodeToString(checksum),
+		User:     token.Login,
+	})
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Finalize presigned upload
+// @Description Verify the content uploaded by a presigned URL against the declared size and checksum and make the document available. On mismatch the document stays pending and the content can be uploaded again while the URL is valid.
+// @Tags docs
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Success 200 {object} documentResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "The document is not pending or was changed during verification"
+// @Failure 422 {object} response.ErrorResponse "Content does not match the declared size or checksum"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/finalize [post]
+func (c *Controller) FinalizeUpload(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.filesService.FinalizeUpload(ctx.Param("docs_id"), token.Login)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordDocumentEvent(ctx, audit.ActionUpload, token.Login, *res, map[string]string{
+		"mime":      res.Mime,
+		"presigned": "true",
+	})
+	c.recordShare(ctx, token.Login, *res)
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Presign document download
+// @Description Get a short-lived URL to download the content of an own document directly from the storage. A compressed document is sent with Content-Encoding.
+// @Tags docs
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Success 200 {object} presignResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "The document content is not available"
+// @Failure 501 {object} response.ErrorResponse "Presigned URLs are disabled"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/presign [get]
+func (c *Controller) PresignDownload(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	doc, res, err := c.filesService.PresignDownload(ctx.Param("docs_id"), token.Login)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordDocumentEvent(ctx, audit.ActionDownload, token.Login, *doc, map[string]string{"presigned": "true"})
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
@@ -1,16 +1,20 @@
 package filescontroller
 
 import (
+	"astral/internal/presentation/middleware"
+
 	"github.com/gin-gonic/gin"
 )
 
 type Router struct {
-	controller *Controller
+	controller  *Controller
+	conditional func(lookup middleware.ValidatorsFunc) gin.HandlerFunc
 }
 
-func NewRouter(controller *Controller) *Router {
+func NewRouter(controller *Controller, conditional func(lookup middleware.ValidatorsFunc) gin.HandlerFunc) *Router {
 	return &Router{
-		controller: controller,
+		controller:  controller,
+		conditional: conditional,
 	}
 }
 
@@ -18,11 +22,18 @@ func NewRouter(controller *Controller) *Router {
 // @Description Group of endpoints for working with files
 func (r *Router) RegisterRoutes(files *gin.RouterGroup) {
 	files.POST("/docs", r.controller.UploadFile)
+	files.POST("/docs/presign", r.controller.PresignUpload)
 
 	files.GET("/docs", r.controller.GetFiles)
 	files.HEAD("/docs", r.controller.GetFiles)
 
-	files.GET("/docs/:docs_id", r.controller.GetFile)
-	files.HEAD("/docs/:docs_id", r.controller.GetFile)
-	files.DELETE("/docs/:docs_id", r.controller.DeleteFile)
+	conditional := r.conditional(r.controller.DocumentValidators)
+	files.GET("/docs/:docs_id", conditional, r.controller.GetFile)
+	files.HEAD("/docs/:docs_id", conditional, r.controller.GetFile)
+	files.DELETE("/docs/:docs_id", conditional, r.controller.DeleteFile)
+	files.PUT("/docs/:docs_id/content", conditional, r.controller.ReplaceFile)
+	files.POST("/docs/:docs_id/copy", conditional, r.controller.CopyFile)
+	files.POST("/docs/:docs_id/transfer", conditional, r.controller.TransferFile)
+	files.POST("/docs/:docs_id/finalize", conditional, r.controller.FinalizeUpload)
+	files.GET("/docs/:docs_id/presign", conditional, r.controller.PresignDownload)
 }
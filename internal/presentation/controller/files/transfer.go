@@ -0,0 +1,96 @@
+package filescontroller
+
+import (
+	"astral/internal/domain/audit"
+	controllererrors "astral/internal/presentation/controller/errors"
+	"io"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Copy document
+// @Description Copy an own document under a new ID to yourself or to another user. The content is copied inside the storage without downloading, JSON data is copied too. The copy is private: grants, comments and tags are not copied.
+// @Tags docs
+// @Accept json
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param request body copyRequest false "Recipient login (default: yourself) and name of the copy (default: the same name)"
+// @Param If-Match header string false "Expected ETags, strong comparison"
+// @Param If-Unmodified-Since header string false "HTTP-date, ignored with If-Match"
+// @Param If-None-Match header string false "ETags the document must not have, * - the document must not exist"
+// @Success 200 {object} documentResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/copy [post]
+func (c *Controller) CopyFile(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	var request copyRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil && err != io.EOF {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid json"))
+		return
+	}
+
+	fileID := ctx.Param("docs_id")
+	res, err := c.filesService.CopyFile(fileID, token.Login, request.To, request.Name)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordDocumentEvent(ctx, audit.ActionCopy, token.Login, *res, map[string]string{"copied_from": fileID})
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Transfer document ownership
+// @Description Make another user the owner of an own document. The ID, grants, JSON data and comments are kept; your personal tags are removed from the document.
+// @Tags docs
+// @Accept json
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param request body transferRequest true "New owner login"
+// @Param If-Match header string false "Expected ETags, strong comparison"
+// @Param If-Unmodified-Since header string false "HTTP-date, ignored with If-Match"
+// @Param If-None-Match header string false "ETags the document must not have, * - the document must not exist"
+// @Success 200 {object} documentResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "The document already belongs to this user"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/transfer [post]
+func (c *Controller) TransferFile(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	var request transferRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("to field is required"))
+		return
+	}
+
+	res, err := c.filesService.TransferFile(ctx.Param("docs_id"), token.Login, request.To)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordDocumentEvent(ctx, audit.ActionTransfer, token.Login, *res, map[string]string{
+		"from": token.Login,
+		"to":   request.To,
+	})
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
@@ -9,6 +9,8 @@ type docMeta struct {
 	Token  string   `json:"token" binding:"required"`
 	Mime   string   `json:"mime"`
 	Grant  []string `json:"grant"`
+	// StripGPS - удалить координаты из EXIF изображения, даже если это не включено в настройках
+	StripGPS bool `json:"strip_gps"`
 }
 
 type uploadDataResponse struct {
@@ -24,4 +26,28 @@ type deleteFileResponse struct {
 	Response struct {
 		ID bool `json:"file_id"`
 	} `json:"response"`
-}
\ No newline at end of file
+}
+type copyRequest struct {
+	To   string `json:"to"`
+	Name string `json:"name"`
+}
+
+type transferRequest struct {
+	To string `json:"to" binding:"required"`
+}
+
+type documentResponse struct {
+	Response file.File `json:"response"`
+}
+
+type presignRequest struct {
+	Name     string   `json:"name" binding:"required"`
+	Size     int      `json:"size" binding:"required"`
+	Public   bool     `json:"public"`
+	Grant    []string `json:"grant"`
+	Checksum string   `json:"checksum"`
+}
+
+type presignResponse struct {
+	Response file.Presigned `json:"response"`
+}
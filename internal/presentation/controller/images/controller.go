@@ -0,0 +1,33 @@
+package imagescontroller
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/presentation/controller/utils"
+	"astral/internal/presentation/response"
+	"log/slog"
+)
+
+type Controller struct {
+	logger          *slog.Logger
+	responseBuilder *response.ResponseBuilder
+	imagesService   contracts.ImagesInterface
+	activityService contracts.ActivityInterface
+	utils           utils.Utils
+}
+
+func NewController(
+	logger *slog.Logger,
+	responseBuilder *response.ResponseBuilder,
+	images contracts.ImagesInterface,
+	activity contracts.ActivityInterface,
+	utils utils.Utils,
+) *Controller {
+	logger = logger.With("controller", "images")
+	return &Controller{
+		logger:          logger,
+		responseBuilder: responseBuilder,
+		imagesService:   images,
+		activityService: activity,
+		utils:           utils,
+	}
+}
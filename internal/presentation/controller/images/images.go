@@ -0,0 +1,140 @@
+package imagescontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/imaging"
+	controllererrors "astral/internal/presentation/controller/errors"
+	"astral/internal/presentation/middleware"
+	imagesservice "astral/internal/services/images"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RETRY_AFTER - через сколько секунд повторить запрос, когда все преобразования заняты
+const RETRY_AFTER = "5"
+
+// @Summary Get transformed image
+// @Description Resize, crop and convert an image document. Crop is applied first, then the result is resized: with only w or h the other side keeps the aspect ratio, with both fit decides how the image fills the box - contain (inside the box), cover (fills the box, center is kept) or fill (stretched). Variants are cached by content version and parameters. JPEG, PNG, GIF, WebP, BMP and TIFF sources are supported, GIF animation is reduced to the first frame.
+// @Tags images
+// @Produce jpeg,png,gif
+// @Param docs_id path string true "Document ID"
+// @Param owner query string false "Document owner (optional - own document if not specified)"
+// @Param w query int false "Output width in pixels"
+// @Param h query int false "Output height in pixels"
+// @Param fit query string false "contain (default), cover or fill"
+// @Param format query string false "jpeg, png or gif (default - source format, PNG for formats that cannot be written)"
+// @Param q query int false "JPEG quality 1-100"
+// @Param crop query string false "Source area x,y,width,height"
+// @Param If-None-Match header string false "Known ETag of the variant"
+// @Success 200 {file} binary
+// @Success 304 "Not Modified"
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 413 {object} response.ErrorResponse "Source image is too large"
+// @Failure 422 {object} response.ErrorResponse "Corrupted image"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Failure 503 {object} response.ErrorResponse "Too many transformations in progress"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/image [get]
+func (c *Controller) GetImage(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	opts, err := parseOptions(ctx)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	doc, variant, err := c.imagesService.Transform(token.Login, ctx.Query("owner"), ctx.Param("docs_id"), opts)
+	if err != nil {
+		if errors.Is(err, imagesservice.ErrBusy) {
+			ctx.Header("Retry-After", RETRY_AFTER)
+		}
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	hash := sha256.Sum256(variant.Data)
+	etag := middleware.StrongETag(hex.EncodeToString(hash[:16]))
+	ctx.Header("ETag", etag)
+	ctx.Header("Cache-Control", "private, max-age=3600")
+	switch middleware.EvaluatePreconditions(ctx.Request, &middleware.Validators{ETag: etag}) {
+	case http.StatusNotModified:
+		ctx.AbortWithStatus(http.StatusNotModified)
+		return
+	case http.StatusPreconditionFailed:
+		c.responseBuilder.Error(ctx, controllererrors.ErrPreconditionFailed)
+		return
+	}
+
+	cache := "MISS"
+	if variant.Cached {
+		cache = "HIT"
+	}
+	ctx.Header("X-Cache", cache)
+
+	name := fmt.Sprintf("%s-%dx%d%s", strings.TrimSuffix(doc.Name, path.Ext(doc.Name)), variant.Width, variant.Height, imaging.Extension(path.Base(variant.Mime)))
+	if disposition := mime.FormatMediaType("inline", map[string]string{"filename": name}); disposition != "" {
+		ctx.Header("Content-Disposition", disposition)
+	}
+
+	c.recordDocumentEvent(ctx, audit.ActionDownload, token.Login, *doc, map[string]string{"image": variant.Mime, "size": fmt.Sprintf("%dx%d", variant.Width, variant.Height)})
+	ctx.Data(http.StatusOK, variant.Mime, variant.Data)
+}
+
+// parseOptions разбирает параметры w, h, fit, format, q и crop
+func parseOptions(ctx *gin.Context) (imaging.Options, error) {
+	opts := imaging.Options{
+		Fit:    ctx.Query("fit"),
+		Format: ctx.Query("format"),
+	}
+
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{{"w", &opts.Width}, {"h", &opts.Height}, {"q", &opts.Quality}} {
+		raw := ctx.Query(param.name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return opts, controllererrors.NewErrInvalidInputData(fmt.Sprintf("invalid %s value", param.name))
+		}
+		*param.value = value
+	}
+
+	if raw := ctx.Query("crop"); raw != "" {
+		parts := strings.Split(raw, ",")
+		if len(parts) != 4 {
+			return opts, imagesservice.ErrInvalidCrop
+		}
+
+		values := make([]int, len(parts))
+		for i, part := range parts {
+			value, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return opts, imagesservice.ErrInvalidCrop
+			}
+			values[i] = value
+		}
+		opts.Crop = &imaging.Crop{X: values[0], Y: values[1], Width: values[2], Height: values[3]}
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,21 @@
+package imagescontroller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type Router struct {
+	controller *Controller
+}
+
+func NewRouter(controller *Controller) *Router {
+	return &Router{
+		controller: controller,
+	}
+}
+
+// @Summary Register image routes
+// @Description Group of endpoints for resized, cropped and converted images
+func (r *Router) RegisterRoutes(images *gin.RouterGroup) {
+	images.GET("/docs/:docs_id/image", r.controller.GetImage)
+}
@@ -0,0 +1,29 @@
+package jsondocscontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/file"
+	"astral/internal/presentation/controller/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (c *Controller) recordDocumentEvent(ctx *gin.Context, action audit.Action, actor string, doc file.File, details map[string]string) {
+	event := utils.NewAuditEvent(ctx, action, actor)
+
+	event.Details = map[string]string{}
+	if doc.Revision > 0 {
+		event.Details["revision"] = strconv.FormatInt(doc.Revision, 10)
+	}
+	for k, v := range details {
+		event.Details[k] = v
+	}
+
+	c.activityService.RecordDocument(event, doc)
+}
+
+// recordShare фиксирует выдачу доступа, если документ создан с грантами или публичным
+func (c *Controller) recordShare(ctx *gin.Context, actor string, doc file.File) {
+	c.activityService.RecordShare(utils.NewAuditEvent(ctx, audit.ActionShare, actor), doc)
+}
@@ -0,0 +1,28 @@
+package jsondocscontroller
+
+import (
+	"astral/internal/presentation/middleware"
+	jsondocsservice "astral/internal/services/jsondocs"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DocumentValidators возвращает ETag с ревизией документа для условных запросов.
+// Даты изменения у JSON-документа нет, поэтому If-Modified-Since и If-Unmodified-Since не применяются
+func (c *Controller) DocumentValidators(ctx *gin.Context) (*middleware.Validators, error) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return nil, nil
+	}
+
+	doc, err := c.jsonDocsService.Get(token.Login, ctx.Param("doc_id"))
+	if errors.Is(err, jsondocsservice.ErrDocumentNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &middleware.Validators{ETag: revisionETag(doc.Revision)}, nil
+}
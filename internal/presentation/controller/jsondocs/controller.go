@@ -0,0 +1,33 @@
+package jsondocscontroller
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/presentation/controller/utils"
+	"astral/internal/presentation/response"
+	"log/slog"
+)
+
+type Controller struct {
+	logger          *slog.Logger
+	responseBuilder *response.ResponseBuilder
+	jsonDocsService contracts.JSONDocsInterface
+	activityService contracts.ActivityInterface
+	utils           utils.Utils
+}
+
+func NewController(
+	logger *slog.Logger,
+	responseBuilder *response.ResponseBuilder,
+	jsonDocs contracts.JSONDocsInterface,
+	activity contracts.ActivityInterface,
+	utils utils.Utils,
+) *Controller {
+	logger = logger.With("controller", "json-docs")
+	return &Controller{
+		logger:          logger,
+		responseBuilder: responseBuilder,
+		jsonDocsService: jsonDocs,
+		activityService: activity,
+		utils:           utils,
+	}
+}
@@ -0,0 +1,339 @@
+package jsondocscontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	controllererrors "astral/internal/presentation/controller/errors"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	MAX_JSON_SIZE = 1 << 20
+
+	JSON_PATCH_MIME = "application/json-patch+json"
+)
+
+// @Summary Create JSON document
+// @Description Create a document that holds only JSON data, without file content. Grants and public flag work the same way as for file documents. The response ETag is the document revision.
+// @Tags json-docs
+// @Accept json
+// @Produce json
+// @Param request body documentRequest true "Document name, access and data"
+// @Success 200 {object} documentResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/json-docs [post]
+func (c *Controller) CreateDocument(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	req, err := readDocumentRequest(ctx)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	if req.Name == "" {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("name field is required"))
+		return
+	}
+
+	res, err := c.jsonDocsService.Create(token.Login, file.File{
+		Name:   req.Name,
+		Public: req.Public,
+		Grant:  req.Grant,
+		Data:   req.Data,
+	})
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordDocumentEvent(ctx, audit.ActionUpload, token.Login, *res, map[string]string{"mime": res.Mime})
+	c.recordShare(ctx, token.Login, *res)
+
+	ctx.Header("ETag", revisionETag(res.Revision))
+	c.responseBuilder.Ok(ctx, toDocument(*res), nil)
+}
+
+// @Summary List JSON documents
+// @Description List JSON documents of a user. Returns own documents if owner not specified, for other owners only public and granted documents.
+// @Tags json-docs
+// @Produce json
+// @Param owner query string false "Documents owner (optional - returns own documents if not specified)"
+// @Param key query string false "Filter key (optional): name, public, created, grant, data (JSONPath predicate) or data_contains (JSON containment)"
+// @Param value query string false "Filter value (optional)"
+// @Param limit query int false "Number of documents to return (optional)"
+// @Success 200 {object} documentsResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/json-docs [get]
+func (c *Controller) GetDocuments(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	limit := 0
+	if value := ctx.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid limit value"))
+			return
+		}
+		limit = parsed
+	}
+
+	filter := contracts.NewFilterData(ctx.Query("value"), ctx.Query("key"), limit)
+	docs, err := c.jsonDocsService.List(token.Login, ctx.Query("owner"), *filter)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	res := make([]document, 0, len(docs))
+	for _, doc := range docs {
+		res = append(res, toDocument(doc))
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Get JSON document
+// @Description Get JSON document by ID. The ETag header carries the document revision, If-None-Match with the current revision returns 304, If-Match with another revision returns 412.
+// @Tags json-docs
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param If-None-Match header string false "Known revision, e.g. \"3\""
+// @Param If-Match header string false "Expected revision, e.g. \"3\""
+// @Success 200 {object} documentResponse
+// @Success 304 "Not Modified"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/json-docs/{id} [get]
+func (c *Controller) GetDocument(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.jsonDocsService.Get(token.Login, ctx.Param("doc_id"))
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", revisionETag(res.Revision))
+	c.recordDocumentEvent(ctx, audit.ActionDownload, token.Login, *res, nil)
+	c.responseBuilder.Ok(ctx, toDocument(*res), nil)
+}
+
+// @Summary Replace JSON document
+// @Description Replace data, name and access of a JSON document. Requires If-Match with the current revision: a stale revision returns 412, a missing header returns 428. If-Match: * skips the check.
+// @Tags json-docs
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param If-Match header string true "Expected revision, e.g. \"3\""
+// @Param request body documentRequest true "New document name, access and data, empty name keeps the current one"
+// @Success 200 {object} documentResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
+// @Failure 428 {object} response.ErrorResponse "Precondition Required"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/json-docs/{id} [put]
+func (c *Controller) ReplaceDocument(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	revision, err := ifMatchRevision(ctx, true)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	req, err := readDocumentRequest(ctx)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	res, err := c.jsonDocsService.Replace(token.Login, ctx.Param("doc_id"), file.File{
+		Name:   req.Name,
+		Public: req.Public,
+		Grant:  req.Grant,
+		Data:   req.Data,
+	}, revision)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordDocumentEvent(ctx, audit.ActionMetadataUpdate, token.Login, *res, nil)
+
+	ctx.Header("ETag", revisionETag(res.Revision))
+	c.responseBuilder.Ok(ctx, toDocument(*res), nil)
+}
+
+// @Summary Patch JSON document
+// @Description Apply JSON Patch (RFC 6902) to the document data, paths start at the data root. Requires If-Match with the current revision: a stale revision returns 412, a missing header returns 428. A patch that cannot be applied, including a failed test operation, returns 422.
+// @Tags json-docs
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param If-Match header string true "Expected revision, e.g. \"3\""
+// @Param request body []object true "JSON Patch operations, e.g. [{\"op\": \"replace\", \"path\": \"/status\", \"value\": \"paid\"}]"
+// @Success 200 {object} documentResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
+// @Failure 415 {object} response.ErrorResponse "Unsupported Media Type"
+// @Failure 422 {object} response.ErrorResponse "Unprocessable Entity"
+// @Failure 428 {object} response.ErrorResponse "Precondition Required"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/json-docs/{id} [patch]
+func (c *Controller) PatchDocument(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	if ctx.ContentType() != JSON_PATCH_MIME {
+		ctx.Header("Accept-Patch", JSON_PATCH_MIME)
+		c.responseBuilder.Error(ctx, controllererrors.ErrUnsupportedMediaType)
+		return
+	}
+
+	revision, err := ifMatchRevision(ctx, true)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	patch, err := io.ReadAll(io.LimitReader(ctx.Request.Body, MAX_JSON_SIZE+1))
+	if err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("failed to read body"))
+		return
+	}
+	if len(patch) > MAX_JSON_SIZE {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("patch is too large"))
+		return
+	}
+
+	res, err := c.jsonDocsService.Patch(token.Login, ctx.Param("doc_id"), patch, revision)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordDocumentEvent(ctx, audit.ActionMetadataUpdate, token.Login, *res, map[string]string{"patch": "true"})
+
+	ctx.Header("ETag", revisionETag(res.Revision))
+	c.responseBuilder.Ok(ctx, toDocument(*res), nil)
+}
+
+// @Summary Delete JSON document
+// @Description Delete JSON document by ID. With If-Match the document is deleted only if its revision matches.
+// @Tags json-docs
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param If-Match header string false "Expected revision, e.g. \"3\""
+// @Success 200 {object} deleteDocumentResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/json-docs/{id} [delete]
+func (c *Controller) DeleteDocument(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	revision, err := ifMatchRevision(ctx, false)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	res, err := c.jsonDocsService.Delete(token.Login, ctx.Param("doc_id"), revision)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordDocumentEvent(ctx, audit.ActionDelete, token.Login, *res, nil)
+
+	c.responseBuilder.Ok(ctx, map[string]bool{res.ID: true}, nil)
+}
+
+func readDocumentRequest(ctx *gin.Context) (*documentRequest, error) {
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, MAX_JSON_SIZE+1))
+	if err != nil {
+		return nil, controllererrors.NewErrInvalidInputData("failed to read body")
+	}
+	if len(body) > MAX_JSON_SIZE {
+		return nil, controllererrors.NewErrInvalidInputData("document is too large")
+	}
+
+	var req documentRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, controllererrors.NewErrInvalidInputData("invalid json")
+	}
+
+	if len(req.Data) == 0 || string(req.Data) == "null" {
+		return nil, controllererrors.NewErrInvalidInputData("data field is required")
+	}
+
+	return &req, nil
+}
+
+// ifMatchRevision разбирает If-Match с ревизией документа. "*" и отсутствующий необязательный
+// заголовок дают 0, то есть изменение без проверки ревизии
+func ifMatchRevision(ctx *gin.Context, required bool) (int64, error) {
+	value := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if value == "" {
+		if required {
+			return 0, controllererrors.ErrPreconditionRequired
+		}
+		return 0, nil
+	}
+
+	if value == "*" {
+		return 0, nil
+	}
+
+	revision, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+	if err != nil || revision <= 0 {
+		return 0, controllererrors.NewErrInvalidInputData("invalid If-Match header")
+	}
+
+	return revision, nil
+}
+
+func revisionETag(revision int64) string {
+	return `"` + strconv.FormatInt(revision, 10) + `"`
+}
@@ -0,0 +1,32 @@
+package jsondocscontroller
+
+import (
+	"astral/internal/presentation/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Router struct {
+	controller  *Controller
+	conditional func(lookup middleware.ValidatorsFunc) gin.HandlerFunc
+}
+
+func NewRouter(controller *Controller, conditional func(lookup middleware.ValidatorsFunc) gin.HandlerFunc) *Router {
+	return &Router{
+		controller:  controller,
+		conditional: conditional,
+	}
+}
+
+// @Summary Register JSON documents routes
+// @Description Group of endpoints for JSON documents without file content
+func (r *Router) RegisterRoutes(docs *gin.RouterGroup) {
+	docs.POST("/json-docs", r.controller.CreateDocument)
+	docs.GET("/json-docs", r.controller.GetDocuments)
+
+	conditional := r.conditional(r.controller.DocumentValidators)
+	docs.GET("/json-docs/:doc_id", conditional, r.controller.GetDocument)
+	docs.PUT("/json-docs/:doc_id", conditional, r.controller.ReplaceDocument)
+	docs.PATCH("/json-docs/:doc_id", conditional, r.controller.PatchDocument)
+	docs.DELETE("/json-docs/:doc_id", conditional, r.controller.DeleteDocument)
+}
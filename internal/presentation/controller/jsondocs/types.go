@@ -0,0 +1,52 @@
+package jsondocscontroller
+
+import (
+	"astral/internal/domain/file"
+	"encoding/json"
+	"time"
+)
+
+type documentRequest struct {
+	Name   string          `json:"name"`
+	Public bool            `json:"public"`
+	Grant  []string        `json:"grant"`
+	Data   json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+type document struct {
+	ID        string          `json:"id"`
+	Owner     string          `json:"owner"`
+	Name      string          `json:"name"`
+	Public    bool            `json:"public"`
+	Grant     []string        `json:"grant"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+	Revision  int64           `json:"revision"`
+	CreatedAt *time.Time      `json:"created"`
+}
+
+type documentResponse struct {
+	Response document `json:"response"`
+}
+
+type documentsResponse struct {
+	Response []document `json:"response"`
+}
+
+type deleteDocumentResponse struct {
+	Response struct {
+		ID bool `json:"doc_id"`
+	} `json:"response"`
+}
+
+func toDocument(doc file.File) document {
+	return document{
+		ID:        doc.ID,
+		Owner:     doc.User,
+		Name:      doc.Name,
+		Public:    doc.Public,
+		Grant:     doc.Grant,
+		Data:      doc.Data,
+		Revision:  doc.Revision,
+		CreatedAt: doc.CreatedAt,
+	}
+}
@@ -0,0 +1,27 @@
+package linkscontroller
+
+import (
+	"astral/internal/presentation/middleware"
+	linksservice "astral/internal/services/links"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LinkValidators проверяет ссылку и возвращает ETag и дату изменения документа, который она отдает
+func (c *Controller) LinkValidators(ctx *gin.Context) (*middleware.Validators, error) {
+	l, err := parseLink(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := c.linksService.Resolve(l, ctx.ClientIP())
+	if errors.Is(err, linksservice.ErrDocumentNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return middleware.DocumentValidators(*doc), nil
+}
@@ -0,0 +1,40 @@
+package linkscontroller
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/presentation/controller/utils"
+	"astral/internal/presentation/response"
+	"log/slog"
+)
+
+// LINKS_PATH - путь публичного скачивания по подписанной ссылке
+const LINKS_PATH = "/api/links"
+
+type Controller struct {
+	logger          *slog.Logger
+	responseBuilder *response.ResponseBuilder
+	linksService    contracts.LinksInterface
+	activityService contracts.ActivityInterface
+	baseURL         string
+	utils           utils.Utils
+}
+
+// baseURL - внешний адрес сервиса, с которого начинаются ссылки. Пустой - адрес из запроса
+func NewController(
+	logger *slog.Logger,
+	responseBuilder *response.ResponseBuilder,
+	links contracts.LinksInterface,
+	activity contracts.ActivityInterface,
+	baseURL string,
+	utils utils.Utils,
+) *Controller {
+	logger = logger.With("controller", "links")
+	return &Controller{
+		logger:          logger,
+		responseBuilder: responseBuilder,
+		linksService:    links,
+		activityService: activity,
+		baseURL:         baseURL,
+		utils:           utils,
+	}
+}
@@ -0,0 +1,180 @@
+package linkscontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/link"
+	controllererrors "astral/internal/presentation/controller/errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Issue signed download link
+// @Description Issue a link to download an own document without a token, e.g. for emails and third-party UIs. The link is signed with the server key and covers the document, the expiry, the optional client IP and the optional Content-Disposition. Links are not stored and cannot be revoked one by one: rotating the server key revokes all of them.
+// @Tags links
+// @Accept json
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param request body linkRequest false "Lifetime in seconds (default LINKS_DEFAULT_TTL, at most LINKS_MAX_TTL), IP the link is bound to, disposition (inline or attachment) and file name"
+// @Success 200 {object} linkResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 501 {object} response.ErrorResponse "Signed links are disabled"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/link [post]
+func (c *Controller) IssueLink(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	var request linkRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil && err != io.EOF {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid json"))
+		return
+	}
+
+	if request.ExpiresIn < 0 {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("expires_in must be a positive number of seconds"))
+		return
+	}
+
+	doc, res, err := c.linksService.Issue(token.Login, ctx.Param("docs_id"), link.Options{
+		TTL:         time.Duration(request.ExpiresIn) * time.Second,
+		IP:          request.IP,
+		Disposition: request.Disposition,
+		Filename:    request.Filename,
+	})
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	res.URL = c.linkURL(ctx, *res)
+
+	details := map[string]string{
+		"link":       "signed",
+		"expires_at": res.ExpiresAt.Format(time.RFC3339),
+	}
+	if res.IP != "" {
+		details["ip"] = res.IP
+	}
+	c.recordDocumentEvent(ctx, audit.ActionShare, token.Login, *doc, details)
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Download by signed link
+// @Description Download a document by a signed link without a token. Range requests are supported.
+// @Tags links
+// @Produce octet-stream
+// @Param docs_id path string true "Document ID"
+// @Param owner query string true "Document owner"
+// @Param expires query int true "Expiry, unix time"
+// @Param ip query string false "IP the link is bound to"
+// @Param disposition query string false "Content-Disposition of the response"
+// @Param kid query string true "Signing key ID"
+// @Param signature query string true "Signature"
+// @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Success 304 "Not Modified"
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 403 {object} response.ErrorResponse "Invalid signature or another IP"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 410 {object} response.ErrorResponse "The link has expired"
+// @Failure 412 {object} response.ErrorResponse "Precondition Failed"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/links/{docs_id} [get]
+func (c *Controller) OpenLink(ctx *gin.Context) {
+	l, err := parseLink(ctx)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	doc, content, err := c.linksService.Open(l, ctx.ClientIP())
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	defer content.Close()
+
+	if ctx.Request.Method == http.MethodGet {
+		c.recordDocumentEvent(ctx, audit.ActionDownload, "", *doc, map[string]string{
+			"link":   "signed",
+			"key_id": l.KeyID,
+		})
+	}
+
+	var modTime time.Time
+	if doc.CreatedAt != nil {
+		modTime = *doc.CreatedAt
+	}
+
+	disposition := l.Disposition
+	if disposition == "" {
+		disposition = fmt.Sprintf("attachment; filename=\"%s\"", doc.Name)
+	}
+
+	if doc.Mime != "" {
+		ctx.Header("Content-Type", doc.Mime)
+	}
+	ctx.Header("Content-Disposition", disposition)
+	ctx.Header("Cache-Control", "private")
+	// Подпись в адресе не должна уходить в Referer со страниц, открытых из документа
+	ctx.Header("Referrer-Policy", "no-referrer")
+	ctx.Header("X-Content-Type-Options", "nosniff")
+
+	// Предусловия уже проверил middleware, ServeContent обрабатывает Range и If-Range по выставленным им ETag и Last-Modified
+	http.ServeContent(ctx.Writer, ctx.Request, doc.Name, modTime, content)
+}
+
+// linkURL собирает адрес ссылки. Без внешнего адреса в настройках берется адрес из запроса
+func (c *Controller) linkURL(ctx *gin.Context, l link.Link) string {
+	base := c.baseURL
+	if base == "" {
+		scheme := "http"
+		if ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+		base = scheme + "://" + ctx.Request.Host
+	}
+
+	query := url.Values{}
+	query.Set("owner", l.Owner)
+	query.Set("expires", strconv.FormatInt(l.ExpiresAt.Unix(), 10))
+	if l.IP != "" {
+		query.Set("ip", l.IP)
+	}
+	if l.Disposition != "" {
+		query.Set("disposition", l.Disposition)
+	}
+	query.Set("kid", l.KeyID)
+	query.Set("signature", l.Signature)
+
+	return strings.TrimSuffix(base, "/") + LINKS_PATH + "/" + url.PathEscape(l.DocumentID) + "?" + query.Encode()
+}
+
+func parseLink(ctx *gin.Context) (link.Link, error) {
+	expires, err := strconv.ParseInt(ctx.Query("expires"), 10, 64)
+	if err != nil || ctx.Query("owner") == "" || ctx.Query("kid") == "" || ctx.Query("signature") == "" {
+		return link.Link{}, controllererrors.NewErrInvalidInputData("invalid link")
+	}
+
+	return link.Link{
+		DocumentID:  ctx.Param("docs_id"),
+		Owner:       ctx.Query("owner"),
+		ExpiresAt:   time.Unix(expires, 0),
+		IP:          ctx.Query("ip"),
+		Disposition: ctx.Query("disposition"),
+		KeyID:       ctx.Query("kid"),
+		Signature:   ctx.Query("signature"),
+	}, nil
+}
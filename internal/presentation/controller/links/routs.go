@@ -0,0 +1,29 @@
+package linkscontroller
+
+import (
+	"astral/internal/presentation/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Router struct {
+	controller  *Controller
+	conditional func(lookup middleware.ValidatorsFunc) gin.HandlerFunc
+}
+
+func NewRouter(controller *Controller, conditional func(lookup middleware.ValidatorsFunc) gin.HandlerFunc) *Router {
+	return &Router{
+		controller:  controller,
+		conditional: conditional,
+	}
+}
+
+// @Summary Register signed links routes
+// @Description Group of endpoints for issuing signed download links and downloading documents by them without a token
+func (r *Router) RegisterRoutes(links *gin.RouterGroup, linksSecure *gin.RouterGroup) {
+	linksSecure.POST("/docs/:docs_id/link", r.controller.IssueLink)
+
+	conditional := r.conditional(r.controller.LinkValidators)
+	links.GET("/links/:docs_id", conditional, r.controller.OpenLink)
+	links.HEAD("/links/:docs_id", conditional, r.controller.OpenLink)
+}
@@ -0,0 +1,14 @@
+package linkscontroller
+
+import "astral/internal/domain/link"
+
+type linkRequest struct {
+	ExpiresIn   int    `json:"expires_in"`
+	IP          string `json:"ip"`
+	Disposition string `json:"disposition" enums:"inline,attachment"`
+	Filename    string `json:"filename"`
+}
+
+type linkResponse struct {
+	Response link.Link `json:"response"`
+}
@@ -0,0 +1,33 @@
+package lockscontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/lock"
+	"astral/internal/presentation/controller/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordLock фиксирует взятие и снятие блокировки документа, держатель указывается явно,
+// потому что администратор снимает чужую блокировку
+func (c *Controller) recordLock(ctx *gin.Context, action audit.Action, actor, name string, current lock.Lock, details map[string]string) {
+	event := utils.NewAuditEvent(ctx, action, actor)
+	event.Owner = current.Owner
+	event.TargetType = audit.TargetDocument
+	event.TargetID = current.DocumentID
+	event.Details = map[string]string{
+		"holder": current.Holder,
+		"mode":   string(current.Mode),
+	}
+	if name != "" {
+		event.Details["name"] = name
+	}
+	if current.Reason != "" {
+		event.Details["reason"] = current.Reason
+	}
+	for k, v := range details {
+		event.Details[k] = v
+	}
+
+	c.auditService.Record(event)
+}
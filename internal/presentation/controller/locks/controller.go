@@ -0,0 +1,37 @@
+package lockscontroller
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/presentation/controller/utils"
+	"astral/internal/presentation/response"
+	"log/slog"
+)
+
+const (
+	ADMIN_TOKEN_HEADER = "X-Admin-Token"
+)
+
+type Controller struct {
+	logger          *slog.Logger
+	responseBuilder *response.ResponseBuilder
+	locksService    contracts.LocksInterface
+	auditService    contracts.AuditInterface
+	utils           utils.Utils
+}
+
+func NewController(
+	logger *slog.Logger,
+	responseBuilder *response.ResponseBuilder,
+	locks contracts.LocksInterface,
+	audit contracts.AuditInterface,
+	utils utils.Utils,
+) *Controller {
+	logger = logger.With("controller", "locks")
+	return &Controller{
+		logger:          logger,
+		responseBuilder: responseBuilder,
+		locksService:    locks,
+		auditService:    audit,
+		utils:           utils,
+	}
+}
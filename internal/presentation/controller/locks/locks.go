@@ -0,0 +1,121 @@
+package lockscontroller
+
+import (
+	"astral/internal/domain/audit"
+	controllererrors "astral/internal/presentation/controller/errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get document lock
+// @Tags locks
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param owner query string false "Document owner (optional - own document if not specified)"
+// @Success 200 {object} lockResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Document not found or not locked"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/lock [get]
+func (c *Controller) GetLock(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.locksService.GetLock(token.Login, ctx.Query("owner"), ctx.Param("docs_id"))
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Lock document
+// @Description Check out a document for editing. An enforced lock blocks changes and deletion for everyone except the holder, an advisory lock only tells others the document is being edited. Locking again by the holder renews the lock. TTL is in seconds, 0 means the default.
+// @Tags locks
+// @Accept json
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param owner query string false "Document owner (optional - own document if not specified)"
+// @Param request body lockRequest true "Lock data"
+// @Success 200 {object} lockResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 423 {object} response.ErrorResponse "Locked by another user"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/lock [put]
+func (c *Controller) Lock(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	var req lockRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid json"))
+			return
+		}
+	}
+
+	doc, res, err := c.locksService.Lock(token.Login, ctx.Query("owner"), ctx.Param("docs_id"), req.Reason, time.Duration(req.TTL)*time.Second, req.Mode)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordLock(ctx, audit.ActionLock, token.Login, doc.Name, *res, map[string]string{
+		"expires_at": res.ExpiresAt.Format(time.RFC3339),
+	})
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Unlock document
+// @Description Release own lock. The document owner also breaks a lock held by a grantee. With the admin token in X-Admin-Token a lock held by anyone is broken.
+// @Tags locks
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param owner query string false "Document owner (optional - own document if not specified)"
+// @Param X-Admin-Token header string false "Admin token"
+// @Success 200 {object} lockResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Document not found or not locked"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/lock [delete]
+func (c *Controller) Unlock(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	if adminToken := ctx.GetHeader(ADMIN_TOKEN_HEADER); adminToken != "" {
+		res, err := c.locksService.BreakLock(adminToken, ctx.Param("docs_id"))
+		if err != nil {
+			c.responseBuilder.Error(ctx, err)
+			return
+		}
+		c.recordLock(ctx, audit.ActionUnlock, token.Login, "", *res, map[string]string{"broken": "true"})
+
+		c.responseBuilder.Ok(ctx, res, nil)
+		return
+	}
+
+	doc, res, err := c.locksService.Unlock(token.Login, ctx.Query("owner"), ctx.Param("docs_id"))
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordLock(ctx, audit.ActionUnlock, token.Login, doc.Name, *res, nil)
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
@@ -0,0 +1,23 @@
+package lockscontroller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type Router struct {
+	controller *Controller
+}
+
+func NewRouter(controller *Controller) *Router {
+	return &Router{
+		controller: controller,
+	}
+}
+
+// @Summary Register locks routes
+// @Description Group of endpoints for document locks
+func (r *Router) RegisterRoutes(locks *gin.RouterGroup) {
+	locks.GET("/docs/:docs_id/lock", r.controller.GetLock)
+	locks.PUT("/docs/:docs_id/lock", r.controller.Lock)
+	locks.DELETE("/docs/:docs_id/lock", r.controller.Unlock)
+}
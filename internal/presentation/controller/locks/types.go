@@ -0,0 +1,13 @@
+package lockscontroller
+
+import "astral/internal/domain/lock"
+
+type lockRequest struct {
+	Reason string    `json:"reason" example:"updating Q3 figures"`
+	TTL    int       `json:"ttl" example:"1800"`
+	Mode   lock.Mode `json:"mode" example:"enforced"`
+}
+
+type lockResponse struct {
+	Response lock.Lock `json:"response"`
+}
@@ -0,0 +1,30 @@
+package metricscontroller
+
+import (
+	"astral/internal/domain/contracts"
+	"log/slog"
+)
+
+type Controller struct {
+	logger             *slog.Logger
+	replicationService contracts.ReplicationInterface
+	scrubService       contracts.ScrubInterface
+	token              string
+}
+
+// replication и scrub могут быть nil, если репликация или проверка хранилища выключены.
+// token - токен, который сборщик метрик передает в заголовке Authorization: Bearer
+func NewController(
+	logger *slog.Logger,
+	replication contracts.ReplicationInterface,
+	scrub contracts.ScrubInterface,
+	token string,
+) *Controller {
+	logger = logger.With("controller", "metrics")
+	return &Controller{
+		logger:             logger,
+		replicationService: replication,
+		scrubService:       scrub,
+		token:              token,
+	}
+}
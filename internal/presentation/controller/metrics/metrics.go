@@ -0,0 +1,59 @@
+package metricscontroller
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Service metrics
+// @Description Metrics in Prometheus text exposition format. Requires the METRICS_TOKEN bearer token, without METRICS_TOKEN the endpoint is not served.
+// @Tags metrics
+// @Produce plain
+// @Param Authorization header string true "Bearer METRICS_TOKEN"
+// @Success 200 {string} string "metrics"
+// @Failure 401 {string} string "Unauthorized"
+// @Router /metrics [get]
+func (c *Controller) Metrics(ctx *gin.Context) {
+	token := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(c.token)) != 1 {
+		c.logger.Info("metrics authorization failed", "client_ip", ctx.ClientIP())
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var b strings.Builder
+
+	if c.replicationService != nil {
+		status, err := c.replicationService.Status()
+		if err != nil {
+			c.logger.Error("failed to get replication status", "error", err)
+			ctx.Status(http.StatusInternalServerError)
+			return
+		}
+
+		writeGauge(&b, "astral_replication_lag_seconds", "Age of the oldest object waiting for replication", status.Lag.Seconds())
+		writeGauge(&b, "astral_replication_queue_pending", "Number of replication tasks in the queue", float64(status.Pending))
+		writeGauge(&b, "astral_replication_queue_retrying", "Number of replication tasks that failed at least once", float64(status.Retrying))
+	}
+
+	if c.scrubService != nil {
+		if report := c.scrubService.Report(); report != nil {
+			writeGauge(&b, "astral_scrub_last_run_timestamp_seconds", "Time the last storage scrub finished", float64(report.FinishedAt.Unix()))
+			writeGauge(&b, "astral_scrub_checked", "Number of objects verified by the last storage scrub", float64(report.Checked))
+			writeGauge(&b, "astral_scrub_failed", "Number of objects the last storage scrub could not read", float64(report.Failed))
+			writeGauge(&b, "astral_scrub_corrupted", "Number of corrupted documents found by the last storage scrub", float64(len(report.Corrupted)))
+		}
+	}
+
+	ctx.Data(http.StatusOK, "text/plain; version=0.0.4", []byte(b.String()))
+}
+
+func writeGauge(b *strings.Builder, name, help string, value float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(b, "# TYPE %s gauge\n", name)
+	fmt.Fprintf(b, "%s %g\n", name, value)
+}
@@ -0,0 +1,26 @@
+package metricscontroller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type Router struct {
+	controller *Controller
+}
+
+func NewRouter(controller *Controller) *Router {
+	return &Router{
+		controller: controller,
+	}
+}
+
+// @Summary Register metrics routes
+// @Description Endpoints in Prometheus text exposition format
+func (r *Router) RegisterRoutes(router *gin.Engine) {
+	// Без токена метрики не отдаются никому
+	if r.controller.token == "" {
+		return
+	}
+
+	router.GET("/metrics", r.controller.Metrics)
+}
@@ -0,0 +1,34 @@
+package notificationscontroller
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/presentation/controller/utils"
+	"astral/internal/presentation/response"
+	"log/slog"
+	"time"
+)
+
+type Controller struct {
+	logger               *slog.Logger
+	responseBuilder      *response.ResponseBuilder
+	notificationsService contracts.NotificationsInterface
+	heartbeat            time.Duration
+	utils                utils.Utils
+}
+
+func NewController(
+	logger *slog.Logger,
+	responseBuilder *response.ResponseBuilder,
+	notifications contracts.NotificationsInterface,
+	heartbeat time.Duration,
+	utils utils.Utils,
+) *Controller {
+	logger = logger.With("controller", "notifications")
+	return &Controller{
+		logger:               logger,
+		responseBuilder:      responseBuilder,
+		notificationsService: notifications,
+		heartbeat:            heartbeat,
+		utils:                utils,
+	}
+}
@@ -0,0 +1,166 @@
+package notificationscontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	WRITE_TIMEOUT = time.Second * 10
+)
+
+// Доступ к потоку дает только JWT из заголовка, cookie не используются, поэтому Origin не проверяем
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// @Summary Notifications stream
+// @Description Server-Sent Events stream of changes: documents uploaded, updated or deleted in the user's space and documents shared with the user. Event id can be passed back in Last-Event-ID header (or last_event_id query) to resume after reconnect. Heartbeat comments are sent periodically.
+// @Tags notifications
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "Last received event id"
+// @Param last_event_id query string false "Last received event id, if the header can't be set"
+// @Success 200 {object} notification.Notification
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/notifications/stream [get]
+func (c *Controller) Stream(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	lastEventID := ctx.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = ctx.Query("last_event_id")
+	}
+
+	events, cancel, err := c.notificationsService.Subscribe(token.Login, lastEventID)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	defer cancel()
+
+	// Поток живет дольше WriteTimeout сервера, поэтому срок записи продлеваем перед каждым событием
+	rc := http.NewResponseController(ctx.Writer)
+	rc.SetWriteDeadline(time.Now().Add(c.heartbeat + WRITE_TIMEOUT))
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	// Клиент переподключается через 3 секунды, если соединение оборвалось
+	fmt.Fprint(ctx.Writer, "retry: 3000\n\n")
+	ctx.Writer.Flush()
+
+	ticker := time.NewTicker(c.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-ticker.C:
+			rc.SetWriteDeadline(time.Now().Add(c.heartbeat + WRITE_TIMEOUT))
+			if _, err := fmt.Fprint(ctx.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		case n, ok := <-events:
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(n)
+			if err != nil {
+				c.logger.Error("failed to marshal notification", "id", n.ID, "error", err)
+				continue
+			}
+
+			rc.SetWriteDeadline(time.Now().Add(c.heartbeat + WRITE_TIMEOUT))
+			if _, err := fmt.Fprintf(ctx.Writer, "id: %s\nevent: %s\ndata: %s\n\n", n.ID, n.Type, data); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		}
+	}
+}
+
+// @Summary Notifications over WebSocket
+// @Description WebSocket variant of the notifications stream: every text message is a notification in JSON. Pass last_event_id to resume after reconnect. Server sends ping frames as heartbeats and closes connections that don't answer.
+// @Tags notifications
+// @Param last_event_id query string false "Last received event id"
+// @Success 101 {object} notification.Notification
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/notifications/ws [get]
+func (c *Controller) WebSocket(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	events, cancel, err := c.notificationsService.Subscribe(token.Login, ctx.Query("last_event_id"))
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	defer cancel()
+
+	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		c.logger.Info("failed to upgrade connection", "login", token.Login, "error", err)
+		return
+	}
+	defer conn.Close()
+
+	// Клиент ничего не присылает, читаем только для обработки pong и закрытия соединения
+	closed := make(chan struct{})
+	conn.SetReadDeadline(time.Now().Add(2 * c.heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * c.heartbeat))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(c.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(WRITE_TIMEOUT)); err != nil {
+				return
+			}
+		case n, ok := <-events:
+			if !ok {
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "subscription closed"), time.Now().Add(WRITE_TIMEOUT))
+				return
+			}
+
+			conn.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT))
+			if err := conn.WriteJSON(n); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package notificationscontroller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type Router struct {
+	controller *Controller
+}
+
+func NewRouter(controller *Controller) *Router {
+	return &Router{
+		controller: controller,
+	}
+}
+
+// @Summary Register notification routes
+// @Description Group of endpoints for real-time document change notifications
+func (r *Router) RegisterRoutes(notifications *gin.RouterGroup) {
+	notifications.GET("/notifications/stream", r.controller.Stream)
+	notifications.GET("/notifications/ws", r.controller.WebSocket)
+}
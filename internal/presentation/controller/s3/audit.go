@@ -0,0 +1,38 @@
+package s3controller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/file"
+	"astral/internal/presentation/controller/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (c *Controller) recordObjectEvent(ctx *gin.Context, action audit.Action, login, bucket, key string, doc *file.File, details map[string]string) {
+	event := utils.NewAuditEvent(ctx, action, login)
+	event.Owner = bucket
+
+	event.Details = map[string]string{
+		"protocol": "s3",
+		"key":      key,
+	}
+	for k, v := range details {
+		event.Details[k] = v
+	}
+
+	c.activityService.RecordDocument(event, *doc)
+}
+
+func (c *Controller) recordAuthFailed(ctx *gin.Context, login, accessKey string, err *apiError) {
+	event := utils.NewAuditEvent(ctx, audit.ActionAuthFailed, login)
+	event.Owner = login
+	event.TargetType = audit.TargetUser
+	event.TargetID = login
+	event.Details = map[string]string{
+		"method":     "s3_sigv4",
+		"access_key": accessKey,
+		"reason":     err.code,
+	}
+
+	c.activityService.Record(event)
+}
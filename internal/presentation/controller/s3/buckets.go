@@ -0,0 +1,230 @@
+package s3controller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/s3"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	MAX_LIST_KEYS       = 1000
+	MAX_DELETE_OBJECTS  = 1000
+	MAX_XML_REQUEST_LEN = 1 << 20
+)
+
+func (c *Controller) listBuckets(ctx *gin.Context, login string) {
+	buckets, err := c.s3Service.ListBuckets(login)
+	if err != nil {
+		c.writeError(ctx, err)
+		return
+	}
+
+	resp := listBucketsResponse{
+		Xmlns: S3_XMLNS,
+		Owner: owner{ID: login, DisplayName: login},
+	}
+	for _, bucket := range buckets {
+		resp.Buckets = append(resp.Buckets, bucketEntry{
+			Name:         bucket,
+			CreationDate: time.Unix(0, 0).UTC().Format(S3_TIME_FORMAT),
+		})
+	}
+
+	writeXML(ctx, http.StatusOK, resp)
+}
+
+func (c *Controller) headBucket(ctx *gin.Context, login, bucket string) {
+	if err := c.s3Service.HeadBucket(login, bucket); err != nil {
+		c.writeError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (c *Controller) getBucketLocation(ctx *gin.Context, login, bucket string) {
+	if err := c.s3Service.HeadBucket(login, bucket); err != nil {
+		c.writeError(ctx, err)
+		return
+	}
+
+	writeXML(ctx, http.StatusOK, locationResponse{Xmlns: S3_XMLNS})
+}
+
+// listObjects обслуживает ListObjects и ListObjectsV2, версии отличаются только маркерами продолжения
+func (c *Controller) listObjects(ctx *gin.Context, login, bucket string) {
+	query := ctx.Request.URL.Query()
+	v2 := query.Get("list-type") == "2"
+
+	encodingType := query.Get("encoding-type")
+	if encodingType != "" && encodingType != URL_ENCODING_TYPE {
+		c.writeError(ctx, errInvalidArgument)
+		return
+	}
+
+	maxKeys := MAX_LIST_KEYS
+	if value := query.Get("max-keys"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			c.writeError(ctx, errInvalidArgument)
+			return
+		}
+		maxKeys = min(parsed, MAX_LIST_KEYS)
+	}
+
+	listQuery := s3.ListQuery{
+		Prefix:    query.Get("prefix"),
+		Delimiter: query.Get("delimiter"),
+		MaxKeys:   maxKeys,
+	}
+
+	if v2 {
+		listQuery.StartAfter = query.Get("start-after")
+		if token := query.Get("continuation-token"); token != "" {
+			marker, err := base64.RawURLEncoding.DecodeString(token)
+			if err != nil {
+				c.writeError(ctx, errInvalidArgument)
+				return
+			}
+			listQuery.StartAfter = string(marker)
+		}
+	} else {
+		listQuery.StartAfter = query.Get("marker")
+	}
+
+	resp := listObjectsResponse{
+		Xmlns:        S3_XMLNS,
+		Name:         bucket,
+		Prefix:       encodeKey(listQuery.Prefix, encodingType),
+		MaxKeys:      maxKeys,
+		Delimiter:    encodeKey(listQuery.Delimiter, encodingType),
+		EncodingType: encodingType,
+	}
+
+	var result *s3.ListResult
+	if maxKeys > 0 {
+		var err error
+		result, err = c.s3Service.ListObjects(login, bucket, listQuery)
+		if err != nil {
+			c.writeError(ctx, err)
+			return
+		}
+	} else if err := c.s3Service.HeadBucket(login, bucket); err != nil {
+		c.writeError(ctx, err)
+		return
+	} else {
+		result = &s3.ListResult{}
+	}
+
+	fetchOwner := !v2 || query.Get("fetch-owner") == "true"
+	for _, object := range result.Objects {
+		entry := objectEntry{
+			Key:          encodeKey(object.Key, encodingType),
+			ETag:         objectETag(object.Document.ID),
+			Size:         object.Document.Size,
+			StorageClass: S3_STORAGE_CLASS,
+		}
+		if object.Document.CreatedAt != nil {
+			entry.LastModified = object.Document.CreatedAt.UTC().Format(S3_TIME_FORMAT)
+		}
+		if fetchOwner {
+			entry.Owner = &owner{ID: bucket, DisplayName: bucket}
+		}
+
+		resp.Contents = append(resp.Contents, entry)
+	}
+
+	for _, prefix := range result.CommonPrefixes {
+		resp.CommonPrefixes = append(resp.CommonPrefixes, commonPrefix{
+			Prefix: encodeKey(prefix, encodingType),
+		})
+	}
+
+	resp.IsTruncated = result.IsTruncated
+	if v2 {
+		keyCount := len(resp.Contents) + len(resp.CommonPrefixes)
+		resp.KeyCount = &keyCount
+		resp.StartAfter = encodeKey(query.Get("start-after"), encodingType)
+		resp.ContinuationToken = query.Get("continuation-token")
+		if result.IsTruncated {
+			resp.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(result.NextMarker))
+		}
+	} else {
+		marker := encodeKey(query.Get("marker"), encodingType)
+		resp.Marker = &marker
+		resp.NextMarker = encodeKey(result.NextMarker, encodingType)
+	}
+
+	writeXML(ctx, http.StatusOK, resp)
+}
+
+func (c *Controller) deleteObjects(ctx *gin.Context, login, bucket string) {
+	var req deleteObjectsRequest
+	if err := readXML(ctx, &req); err != nil {
+		c.writeError(ctx, err)
+		return
+	}
+
+	if len(req.Objects) > MAX_DELETE_OBJECTS {
+		c.writeError(ctx, errMalformedXML)
+		return
+	}
+
+	resp := deleteObjectsResponse{Xmlns: S3_XMLNS}
+	for _, object := range req.Objects {
+		doc, err := c.s3Service.DeleteObject(login, bucket, object.Key)
+		if err != nil {
+			apiErr := toAPIError(err)
+			resp.Errors = append(resp.Errors, deleteErrorEntry{
+				Key:     object.Key,
+				Code:    apiErr.code,
+				Message: apiErr.message,
+			})
+			continue
+		}
+		if doc != nil {
+			c.recordObjectEvent(ctx, audit.ActionDelete, login, bucket, object.Key, doc, nil)
+		}
+
+		if !req.Quiet {
+			resp.Deleted = append(resp.Deleted, deletedEntry{Key: object.Key})
+		}
+	}
+
+	writeXML(ctx, http.StatusOK, resp)
+}
+
+func readXML(ctx *gin.Context, dst any) error {
+	data, err := io.ReadAll(io.LimitReader(ctx.Request.Body, MAX_XML_REQUEST_LEN))
+	if err != nil {
+		return err
+	}
+
+	if err := xml.Unmarshal(data, dst); err != nil {
+		return errMalformedXML
+	}
+
+	return nil
+}
+
+// encodeKey кодирует ключ для encoding-type=url, слеши оставляем читаемыми, как это делает S3
+func encodeKey(key, encodingType string) string {
+	if encodingType != URL_ENCODING_TYPE {
+		return key
+	}
+
+	return strings.ReplaceAll(url.QueryEscape(key), "%2F", "/")
+}
+
+func objectETag(id string) string {
+	return `"` + id + `"`
+}
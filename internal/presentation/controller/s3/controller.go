@@ -0,0 +1,32 @@
+package s3controller
+
+import (
+	"astral/internal/domain/contracts"
+	"log/slog"
+)
+
+const (
+	S3_PREFIX = "/s3"
+)
+
+type Controller struct {
+	logger          *slog.Logger
+	s3Service       contracts.S3Interface
+	authService     contracts.AuthInterface
+	activityService contracts.ActivityInterface
+}
+
+func NewController(
+	logger *slog.Logger,
+	s3Service contracts.S3Interface,
+	authService contracts.AuthInterface,
+	activityService contracts.ActivityInterface,
+) *Controller {
+	logger = logger.With("controller", "s3")
+	return &Controller{
+		logger:          logger,
+		s3Service:       s3Service,
+		authService:     authService,
+		activityService: activityService,
+	}
+}
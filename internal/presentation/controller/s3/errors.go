@@ -0,0 +1,113 @@
+package s3controller
+
+import (
+	filesrepo "astral/internal/repository/files"
+	fileservice "astral/internal/services/files"
+	s3service "astral/internal/services/s3"
+	"encoding/xml"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiError описывает ошибку в терминах S3, клиенты SDK ориентируются на код, а не на текст
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.code + ": " + e.message
+}
+
+var (
+	errAccessDenied          = &apiError{http.StatusForbidden, "AccessDenied", "Access Denied"}
+	errInvalidAccessKeyID    = &apiError{http.StatusForbidden, "InvalidAccessKeyId", "The access key ID you provided does not exist in our records"}
+	errSignatureDoesNotMatch = &apiError{http.StatusForbidden, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided"}
+	errRequestTimeTooSkewed  = &apiError{http.StatusForbidden, "RequestTimeTooSkewed", "The difference between the request time and the server's time is too large"}
+	errExpiredRequest        = &apiError{http.StatusForbidden, "AccessDenied", "Request has expired"}
+	errAuthorizationHeader   = &apiError{http.StatusBadRequest, "AuthorizationHeaderMalformed", "The authorization header is malformed"}
+	errAuthorizationQuery    = &apiError{http.StatusBadRequest, "AuthorizationQueryParametersError", "The presigned URL parameters are malformed"}
+	errMissingSecurityHeader = &apiError{http.StatusBadRequest, "MissingSecurityHeader", "Your request is missing a required header"}
+	errContentSHA256Mismatch = &apiError{http.StatusBadRequest, "XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed"}
+	errIncompleteBody        = &apiError{http.StatusBadRequest, "IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header"}
+	errMissingContentLength  = &apiError{http.StatusLengthRequired, "MissingContentLength", "You must provide the Content-Length HTTP header"}
+	errMalformedXML          = &apiError{http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed"}
+	errInvalidArgument       = &apiError{http.StatusBadRequest, "InvalidArgument", "Invalid argument"}
+	errInvalidKey            = &apiError{http.StatusBadRequest, "InvalidArgument", "Object key is not a valid Astral path"}
+	errNoSuchBucket          = &apiError{http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist"}
+	errNoSuchKey             = &apiError{http.StatusNotFound, "NoSuchKey", "The specified key does not exist"}
+	errNoSuchUpload          = &apiError{http.StatusNotFound, "NoSuchUpload", "The specified multipart upload does not exist"}
+	errInvalidPart           = &apiError{http.StatusBadRequest, "InvalidPart", "One or more of the specified parts could not be found"}
+	errInvalidPartOrder      = &apiError{http.StatusBadRequest, "InvalidPartOrder", "The list of parts was not in ascending order"}
+	errInvalidDigest         = &apiError{http.StatusBadRequest, "InvalidDigest", "The Content-MD5 you specified is not valid"}
+	errBadDigest             = &apiError{http.StatusBadRequest, "BadDigest", "The Content-MD5 you specified did not match what we received"}
+	errObjectLocked          = &apiError{http.StatusConflict, "OperationAborted", "The object is locked by another user"}
+	errNotImplemented        = &apiError{http.StatusNotImplemented, "NotImplemented", "A header or query you provided implies functionality that is not implemented"}
+	errInternal              = &apiError{http.StatusInternalServerError, "InternalError", "We encountered an internal error, please try again"}
+)
+
+func toAPIError(err error) *apiError {
+	var apiErr *apiError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr
+	case errors.Is(err, s3service.ErrAccessDenied), errors.Is(err, fileservice.ErrAccessDenied):
+		return errAccessDenied
+	case errors.Is(err, fileservice.ErrDocumentLocked):
+		return errObjectLocked
+	case errors.Is(err, fileservice.ErrChecksumMismatch):
+		return errBadDigest
+	case errors.Is(err, s3service.ErrNoSuchBucket):
+		return errNoSuchBucket
+	case errors.Is(err, s3service.ErrNoSuchKey), errors.Is(err, filesrepo.ErrFileNotFound):
+		return errNoSuchKey
+	case errors.Is(err, s3service.ErrNoSuchUpload):
+		return errNoSuchUpload
+	case errors.Is(err, s3service.ErrInvalidKey):
+		return errInvalidKey
+	case errors.Is(err, s3service.ErrInvalidPart):
+		return errInvalidPart
+	case errors.Is(err, s3service.ErrInvalidPartOrder):
+		return errInvalidPartOrder
+	default:
+		return errInternal
+	}
+}
+
+type errorResponse struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
+
+func (c *Controller) writeError(ctx *gin.Context, err error) {
+	// Ошибка проверки тела запроса важнее ошибки сервиса, которую она вызвала
+	if payload, ok := ctx.Get(PAYLOAD_CONTEXT_KEY); ok {
+		if payloadErr := payload.(*payloadReader).Err(); payloadErr != nil {
+			err = payloadErr
+		}
+	}
+
+	apiErr := toAPIError(err)
+	if apiErr == errInternal {
+		c.logger.Error("s3 request failed", "method", ctx.Request.Method, "path", ctx.Request.URL.Path, "error", err)
+	} else {
+		c.logger.Debug("s3 request rejected", "method", ctx.Request.Method, "path", ctx.Request.URL.Path, "code", apiErr.code, "error", err)
+	}
+
+	if ctx.Request.Method == http.MethodHead {
+		ctx.AbortWithStatus(apiErr.status)
+		return
+	}
+
+	ctx.Abort()
+	writeXML(ctx, apiErr.status, errorResponse{
+		Code:     apiErr.code,
+		Message:  apiErr.message,
+		Resource: ctx.Request.URL.Path,
+	})
+}
@@ -0,0 +1,115 @@
+package s3controller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/file"
+	"astral/internal/domain/s3"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (c *Controller) createMultipartUpload(ctx *gin.Context, login, bucket, key string) {
+	upload, err := c.s3Service.CreateMultipartUpload(login, bucket, key, file.File{
+		Mime:     objectMime(ctx, key),
+		Metadata: objectMetadata(ctx),
+	})
+	if err != nil {
+		c.writeError(ctx, err)
+		return
+	}
+
+	writeXML(ctx, http.StatusOK, initiateMultipartUploadResponse{
+		Xmlns:    S3_XMLNS,
+		Bucket:   bucket,
+		Key:      key,
+		UploadID: upload.ID,
+	})
+}
+
+func (c *Controller) uploadPart(ctx *gin.Context, login, bucket, key, uploadID string) {
+	number, err := strconv.Atoi(ctx.Query("partNumber"))
+	if err != nil {
+		c.writeError(ctx, errInvalidArgument)
+		return
+	}
+
+	if ctx.Request.ContentLength < 0 {
+		c.writeError(ctx, errMissingContentLength)
+		return
+	}
+
+	part, err := c.s3Service.UploadPart(login, bucket, key, uploadID, number, ctx.Request.Body, ctx.Request.ContentLength)
+	if err != nil {
+		c.writeError(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", `"`+part.ETag+`"`)
+	ctx.Status(http.StatusOK)
+}
+
+func (c *Controller) listParts(ctx *gin.Context, login, bucket, key, uploadID string) {
+	parts, err := c.s3Service.ListParts(login, bucket, key, uploadID)
+	if err != nil {
+		c.writeError(ctx, err)
+		return
+	}
+
+	resp := listPartsResponse{
+		Xmlns:    S3_XMLNS,
+		Bucket:   bucket,
+		Key:      key,
+		UploadID: uploadID,
+	}
+	for _, part := range parts {
+		resp.Parts = append(resp.Parts, partEntry{
+			PartNumber: part.Number,
+			ETag:       `"` + part.ETag + `"`,
+			Size:       part.Size,
+		})
+	}
+
+	writeXML(ctx, http.StatusOK, resp)
+}
+
+func (c *Controller) completeMultipartUpload(ctx *gin.Context, login, bucket, key, uploadID string) {
+	var req completeMultipartUploadRequest
+	if err := readXML(ctx, &req); err != nil {
+		c.writeError(ctx, err)
+		return
+	}
+
+	parts := make([]s3.Part, 0, len(req.Parts))
+	for _, part := range req.Parts {
+		parts = append(parts, s3.Part{
+			Number: part.PartNumber,
+			ETag:   part.ETag,
+		})
+	}
+
+	res, err := c.s3Service.CompleteMultipartUpload(login, bucket, key, uploadID, parts)
+	if err != nil {
+		c.writeError(ctx, err)
+		return
+	}
+	c.recordObjectEvent(ctx, audit.ActionUpload, login, bucket, key, res, map[string]string{"upload_id": uploadID})
+
+	writeXML(ctx, http.StatusOK, completeMultipartUploadResponse{
+		Xmlns:    S3_XMLNS,
+		Location: S3_PREFIX + "/" + bucket + "/" + key,
+		Bucket:   bucket,
+		Key:      key,
+		ETag:     objectETag(res.ID),
+	})
+}
+
+func (c *Controller) abortMultipartUpload(ctx *gin.Context, login, bucket, key, uploadID string) {
+	if err := c.s3Service.AbortMultipartUpload(login, bucket, key, uploadID); err != nil {
+		c.writeError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,170 @@
+package s3controller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/file"
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	META_HEADER_PREFIX = "x-amz-meta-"
+	DEFAULT_MIME       = "application/octet-stream"
+)
+
+func (c *Controller) getObject(ctx *gin.Context, login, bucket, key string) {
+	fileData, err := c.s3Service.GetObject(login, bucket, key)
+	if err != nil {
+		c.writeError(ctx, err)
+		return
+	}
+	if closer, ok := fileData.Reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	seeker, ok := fileData.Reader.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(fileData.Reader)
+		if err != nil {
+			c.writeError(ctx, err)
+			return
+		}
+		seeker = bytes.NewReader(data)
+	}
+
+	setObjectHeaders(ctx, fileData)
+	c.recordObjectEvent(ctx, audit.ActionDownload, login, bucket, key, fileData, nil)
+
+	// ServeContent обрабатывает Range и условные заголовки по ETag и Last-Modified
+	http.ServeContent(ctx.Writer, ctx.Request, "", modTime(fileData), seeker)
+}
+
+func (c *Controller) headObject(ctx *gin.Context, login, bucket, key string) {
+	doc, err := c.s3Service.HeadObject(login, bucket, key)
+	if err != nil {
+		c.writeError(ctx, err)
+		return
+	}
+
+	setObjectHeaders(ctx, doc)
+	ctx.Header("Content-Length", strconv.Itoa(doc.Size))
+	ctx.Status(http.StatusOK)
+}
+
+func (c *Controller) putObject(ctx *gin.Context, login, bucket, key string) {
+	if ctx.GetHeader("X-Amz-Copy-Source") != "" {
+		c.writeError(ctx, errNotImplemented)
+		return
+	}
+
+	if ctx.Request.ContentLength < 0 {
+		c.writeError(ctx, errMissingContentLength)
+		return
+	}
+
+	var digest *file.Digest
+	if value := ctx.GetHeader("Content-MD5"); value != "" {
+		sum, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(sum) != md5.Size {
+			c.writeError(ctx, errInvalidDigest)
+			return
+		}
+		digest = &file.Digest{MD5: sum}
+	}
+
+	res, err := c.s3Service.PutObject(login, bucket, key, file.File{
+		Mime:     objectMime(ctx, key),
+		Size:     int(ctx.Request.ContentLength),
+		Metadata: objectMetadata(ctx),
+		Reader:   ctx.Request.Body,
+		Digest:   digest,
+	})
+	if err != nil {
+		c.writeError(ctx, err)
+		return
+	}
+
+	c.recordObjectEvent(ctx, audit.ActionUpload, login, bucket, key, res, nil)
+
+	ctx.Header("ETag", objectETag(res.ID))
+	ctx.Status(http.StatusOK)
+}
+
+func (c *Controller) deleteObject(ctx *gin.Context, login, bucket, key string) {
+	doc, err := c.s3Service.DeleteObject(login, bucket, key)
+	if err != nil {
+		c.writeError(ctx, err)
+		return
+	}
+	if doc != nil {
+		c.recordObjectEvent(ctx, audit.ActionDelete, login, bucket, key, doc, nil)
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func setObjectHeaders(ctx *gin.Context, doc *file.File) {
+	mimeType := doc.Mime
+	if mimeType == "" {
+		mimeType = DEFAULT_MIME
+	}
+
+	ctx.Header("Content-Type", mimeType)
+	ctx.Header("ETag", objectETag(doc.ID))
+	ctx.Header("Accept-Ranges", "bytes")
+	if doc.CreatedAt != nil {
+		ctx.Header("Last-Modified", doc.CreatedAt.UTC().Format(http.TimeFormat))
+	}
+
+	for k, v := range doc.Metadata {
+		if k == file.FOLDER_METADATA_KEY || k == file.COLLECTION_METADATA_KEY {
+			continue
+		}
+		ctx.Header(META_HEADER_PREFIX+k, v)
+	}
+}
+
+// objectMetadata собирает пользовательские метаданные из заголовков x-amz-meta-*
+func objectMetadata(ctx *gin.Context) map[string]string {
+	metadata := map[string]string{}
+	for name, values := range ctx.Request.Header {
+		name = strings.ToLower(name)
+		if !strings.HasPrefix(name, META_HEADER_PREFIX) || len(values) == 0 {
+			continue
+		}
+
+		metadata[strings.TrimPrefix(name, META_HEADER_PREFIX)] = values[0]
+	}
+
+	return metadata
+}
+
+func objectMime(ctx *gin.Context, key string) string {
+	if mimeType := ctx.GetHeader("Content-Type"); mimeType != "" && mimeType != "binary/octet-stream" {
+		return mimeType
+	}
+
+	if mimeType := mime.TypeByExtension(path.Ext(key)); mimeType != "" {
+		return mimeType
+	}
+
+	return DEFAULT_MIME
+}
+
+func modTime(doc *file.File) time.Time {
+	if doc.CreatedAt == nil {
+		return time.Time{}
+	}
+
+	return *doc.CreatedAt
+}
@@ -0,0 +1,282 @@
+package s3controller
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	MAX_CHUNK_SIZE = 16 << 20
+
+	CHUNK_SIGNATURE_PREFIX   = "chunk-signature="
+	TRAILER_SIGNATURE_HEADER = "x-amz-trailer-signature"
+)
+
+// payloadReader проверяет тело запроса на лету: для обычной подписи сверяет sha256 всего тела,
+// для aws-chunked снимает обрамление чанков и проверяет подпись каждого из них.
+// Ошибка возвращается вместе с последними байтами, поэтому потребитель не получает тело целиком,
+// если оно не совпало с подписью
+type payloadReader struct {
+	body io.ReadCloser
+	size int64
+	read int64
+	err  error
+
+	hash     hash.Hash
+	expected string
+	verified bool
+
+	chunked    bool
+	signed     bool
+	trailer    bool
+	reader     *bufio.Reader
+	sig        *signature
+	signingKey []byte
+	prevSig    string
+	buf        []byte
+	done       bool
+}
+
+func newPayloadReader(r *http.Request, payloadHash string, sig *signature, signingKey []byte) (*payloadReader, error) {
+	switch payloadHash {
+	case UNSIGNED_PAYLOAD:
+		return nil, nil
+
+	case STREAMING_PAYLOAD, STREAMING_PAYLOAD_TRAIL, STREAMING_UNSIGNED_TRAIL:
+		size, err := strconv.ParseInt(r.Header.Get("X-Amz-Decoded-Content-Length"), 10, 64)
+		if err != nil || size < 0 {
+			return nil, errMissingContentLength
+		}
+
+		return &payloadReader{
+			body:       r.Body,
+			size:       size,
+			chunked:    true,
+			signed:     payloadHash != STREAMING_UNSIGNED_TRAIL,
+			trailer:    payloadHash != STREAMING_PAYLOAD,
+			reader:     bufio.NewReader(r.Body),
+			sig:        sig,
+			signingKey: signingKey,
+			prevSig:    sig.signature,
+		}, nil
+
+	default:
+		if decoded, err := hex.DecodeString(payloadHash); err != nil || len(decoded) != sha256.Size {
+			return nil, errContentSHA256Mismatch
+		}
+
+		return &payloadReader{
+			body:     r.Body,
+			size:     r.ContentLength,
+			hash:     sha256.New(),
+			expected: strings.ToLower(payloadHash),
+		}, nil
+	}
+}
+
+func (p *payloadReader) Err() error {
+	return p.err
+}
+
+func (p *payloadReader) Close() error {
+	return p.body.Close()
+}
+
+func (p *payloadReader) Read(b []byte) (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+
+	if p.chunked {
+		return p.readChunked(b)
+	}
+
+	return p.readPlain(b)
+}
+
+func (p *payloadReader) readPlain(b []byte) (int, error) {
+	n, err := p.body.Read(b)
+	p.hash.Write(b[:n])
+	p.read += int64(n)
+
+	if p.verified || (err != io.EOF && (p.size < 0 || p.read < p.size)) {
+		return n, err
+	}
+	p.verified = true
+
+	if p.size >= 0 && p.read != p.size {
+		return 0, p.fail(errIncompleteBody)
+	}
+
+	if hex.EncodeToString(p.hash.Sum(nil)) != p.expected {
+		return 0, p.fail(errContentSHA256Mismatch)
+	}
+
+	return n, err
+}
+
+func (p *payloadReader) readChunked(b []byte) (int, error) {
+	for len(p.buf) == 0 {
+		if p.done {
+			return 0, io.EOF
+		}
+
+		if err := p.nextChunk(); err != nil {
+			return 0, p.fail(err)
+		}
+	}
+
+	n := copy(b, p.buf)
+	p.buf = p.buf[n:]
+
+	return n, nil
+}
+
+// nextChunk читает очередной чанк, а после последнего чанка с данными сразу дочитывает завершающий,
+// чтобы подпись трейлера была проверена до того, как потребитель получит конец тела
+func (p *payloadReader) nextChunk() error {
+	data, err := p.readChunk()
+	if err != nil {
+		return err
+	}
+
+	if len(data) > 0 && p.read == p.size {
+		final, err := p.readChunk()
+		if err != nil {
+			return err
+		}
+		if len(final) > 0 {
+			return errIncompleteBody
+		}
+	}
+
+	if p.done && p.read != p.size {
+		return errIncompleteBody
+	}
+
+	p.buf = data
+	return nil
+}
+
+func (p *payloadReader) readChunk() ([]byte, error) {
+	line, err := p.readLine()
+	if err != nil {
+		return nil, errIncompleteBody
+	}
+
+	sizeValue, extension, _ := strings.Cut(line, ";")
+	size, err := strconv.ParseInt(strings.TrimSpace(sizeValue), 16, 64)
+	if err != nil || size < 0 || size > MAX_CHUNK_SIZE || p.read+size > p.size {
+		return nil, errIncompleteBody
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(p.reader, data); err != nil {
+		return nil, errIncompleteBody
+	}
+
+	if p.signed {
+		chunkSig := strings.TrimPrefix(strings.TrimSpace(extension), CHUNK_SIGNATURE_PREFIX)
+		if !p.verifyChunk("AWS4-HMAC-SHA256-PAYLOAD", EMPTY_SHA256+"\n"+hashHex(data), chunkSig) {
+			return nil, errSignatureDoesNotMatch
+		}
+	}
+	p.read += size
+
+	if size > 0 {
+		if line, err := p.readLine(); err != nil || line != "" {
+			return nil, errIncompleteBody
+		}
+		return data, nil
+	}
+
+	p.done = true
+	if p.trailer {
+		return nil, p.readTrailer()
+	}
+
+	// Завершающая пустая строка после последнего чанка, часть клиентов ее не отправляет
+	p.readLine()
+	return nil, nil
+}
+
+func (p *payloadReader) readTrailer() error {
+	var trailer strings.Builder
+	trailerSig := ""
+
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return errIncompleteBody
+		}
+		if line == "" {
+			break
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return errIncompleteBody
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+
+		if name == TRAILER_SIGNATURE_HEADER {
+			trailerSig = value
+			continue
+		}
+		trailer.WriteString(name + ":" + value + "\n")
+	}
+
+	if p.signed && !p.verifyChunk("AWS4-HMAC-SHA256-TRAILER", hashHex([]byte(trailer.String())), trailerSig) {
+		return errSignatureDoesNotMatch
+	}
+
+	return nil
+}
+
+// verifyChunk проверяет подпись чанка или трейлера, каждая подпись зацеплена за предыдущую
+func (p *payloadReader) verifyChunk(algorithm, payload, chunkSig string) bool {
+	stringToSign := strings.Join([]string{
+		algorithm,
+		p.sig.amzDate.Format(AMZ_DATE_FORMAT),
+		p.sig.scope(),
+		p.prevSig,
+		payload,
+	}, "\n")
+
+	expected := hex.EncodeToString(hmacSHA256(p.signingKey, []byte(stringToSign)))
+	if !hmac.Equal([]byte(expected), []byte(chunkSig)) {
+		return false
+	}
+	p.prevSig = chunkSig
+
+	return true
+}
+
+func (p *payloadReader) readLine() (string, error) {
+	line, err := p.reader.ReadSlice('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(line) > 0 {
+			return strings.TrimRight(string(line), "\r\n"), nil
+		}
+		return "", err
+	}
+
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+func (p *payloadReader) fail(err error) error {
+	p.err = err
+	return err
+}
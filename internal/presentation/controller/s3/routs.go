@@ -0,0 +1,28 @@
+package s3controller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type Router struct {
+	controller *Controller
+}
+
+func NewRouter(controller *Controller) *Router {
+	return &Router{
+		controller: controller,
+	}
+}
+
+var s3Methods = []string{
+	"GET", "HEAD", "PUT", "POST", "DELETE",
+}
+
+// @Summary Register S3 routes
+// @Description S3-compatible access to the user's documents, path-style addressing: /s3/{bucket}/{key}
+func (r *Router) RegisterRoutes(s3 *gin.RouterGroup) {
+	s3.Use(r.controller.Authenticate())
+	for _, method := range s3Methods {
+		s3.Handle(method, "/*path", r.controller.ServeS3)
+	}
+}
@@ -0,0 +1,102 @@
+package s3controller
+
+import (
+	"astral/internal/domain/user"
+	"encoding/xml"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServeS3 разбирает путь вида /{bucket}/{key} и передает запрос нужной операции,
+// операция над бакетом или объектом определяется методом и параметрами запроса как в S3
+func (c *Controller) ServeS3(ctx *gin.Context) {
+	tokenAny, exists := ctx.Get(USER_CONTEXT_KEY)
+	token, ok := tokenAny.(*user.Token)
+	if !exists || !ok {
+		c.writeError(ctx, errAccessDenied)
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(ctx.Param("path"), "/"), "/")
+	query := ctx.Request.URL.Query()
+	method := ctx.Request.Method
+
+	switch {
+	case bucket == "":
+		if method != http.MethodGet {
+			c.writeError(ctx, errNotImplemented)
+			return
+		}
+		c.listBuckets(ctx, token.Login)
+
+	case key == "":
+		switch {
+		case method == http.MethodGet && query.Has("location"):
+			c.getBucketLocation(ctx, token.Login, bucket)
+		case method == http.MethodGet && !hasSubresource(query):
+			c.listObjects(ctx, token.Login, bucket)
+		case method == http.MethodHead:
+			c.headBucket(ctx, token.Login, bucket)
+		case method == http.MethodPost && query.Has("delete"):
+			c.deleteObjects(ctx, token.Login, bucket)
+		default:
+			c.writeError(ctx, errNotImplemented)
+		}
+
+	default:
+		uploadID := query.Get("uploadId")
+		switch {
+		case method == http.MethodPost && query.Has("uploads"):
+			c.createMultipartUpload(ctx, token.Login, bucket, key)
+		case method == http.MethodPut && uploadID != "" && query.Has("partNumber"):
+			c.uploadPart(ctx, token.Login, bucket, key, uploadID)
+		case method == http.MethodPost && uploadID != "":
+			c.completeMultipartUpload(ctx, token.Login, bucket, key, uploadID)
+		case method == http.MethodDelete && uploadID != "":
+			c.abortMultipartUpload(ctx, token.Login, bucket, key, uploadID)
+		case method == http.MethodGet && uploadID != "":
+			c.listParts(ctx, token.Login, bucket, key, uploadID)
+		case hasSubresource(query):
+			c.writeError(ctx, errNotImplemented)
+		case method == http.MethodGet:
+			c.getObject(ctx, token.Login, bucket, key)
+		case method == http.MethodHead:
+			c.headObject(ctx, token.Login, bucket, key)
+		case method == http.MethodPut:
+			c.putObject(ctx, token.Login, bucket, key)
+		case method == http.MethodDelete:
+			c.deleteObject(ctx, token.Login, bucket, key)
+		default:
+			c.writeError(ctx, errNotImplemented)
+		}
+	}
+}
+
+// Подресурсы S3 (acl, tagging, versioning и т.п.) не поддерживаются, их нельзя молча обрабатывать как обычный запрос
+var subresources = []string{
+	"acl", "cors", "lifecycle", "policy", "tagging", "versioning", "versions", "versionId",
+	"website", "uploads", "replication", "encryption", "logging", "notification", "retention",
+	"legal-hold", "object-lock", "attributes", "restore", "select", "torrent",
+}
+
+func hasSubresource(query map[string][]string) bool {
+	for _, name := range subresources {
+		if _, ok := query[name]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeXML(ctx *gin.Context, status int, body any) {
+	data, err := xml.Marshal(body)
+	if err != nil {
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+
+	ctx.Data(status, "application/xml", append([]byte(xml.Header), data...))
+}
@@ -0,0 +1,355 @@
+package s3controller
+
+import (
+	"astral/internal/domain/user"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	SIGV4_ALGORITHM = "AWS4-HMAC-SHA256"
+	SIGV4_SERVICE   = "s3"
+	SIGV4_REQUEST   = "aws4_request"
+
+	AMZ_DATE_FORMAT   = "20060102T150405Z"
+	SCOPE_DATE_FORMAT = "20060102"
+
+	MAX_CLOCK_SKEW   = 15 * time.Minute
+	MAX_PRESIGN_TIME = 7 * 24 * time.Hour
+
+	UNSIGNED_PAYLOAD         = "UNSIGNED-PAYLOAD"
+	STREAMING_PAYLOAD        = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	STREAMING_PAYLOAD_TRAIL  = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER"
+	STREAMING_UNSIGNED_TRAIL = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+
+	EMPTY_SHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	USER_CONTEXT_KEY    = "user"
+	PAYLOAD_CONTEXT_KEY = "s3_payload"
+)
+
+// signature содержит разобранные параметры подписи SigV4 из заголовка Authorization или presigned URL
+type signature struct {
+	accessKey     string
+	scopeDate     string
+	region        string
+	signedHeaders []string
+	signature     string
+	amzDate       time.Time
+	expires       time.Duration
+	presigned     bool
+}
+
+func (s *signature) scope() string {
+	return strings.Join([]string{s.scopeDate, s.region, SIGV4_SERVICE, SIGV4_REQUEST}, "/")
+}
+
+// Authenticate проверяет подпись AWS SigV4 ключом доступа пользователя и подменяет тело запроса
+// проверяющим читателем, чтобы содержимое не могло разойтись с подписанным хэшем
+func (c *Controller) Authenticate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		r := ctx.Request
+
+		var sig *signature
+		var err error
+		if strings.HasPrefix(r.Header.Get("Authorization"), SIGV4_ALGORITHM+" ") {
+			sig, err = parseAuthorizationHeader(r)
+		} else if r.URL.Query().Get("X-Amz-Algorithm") != "" {
+			sig, err = parsePresignedQuery(r)
+		} else {
+			err = errAccessDenied
+		}
+		if err != nil {
+			c.writeError(ctx, err)
+			return
+		}
+
+		if err := sig.checkTime(time.Now().UTC()); err != nil {
+			c.writeError(ctx, err)
+			return
+		}
+
+		accessKey, err := c.authService.GetAccessKey(sig.accessKey)
+		if err != nil {
+			c.logger.Info("s3 authorization failed", "client_ip", ctx.ClientIP(), "accessKey", sig.accessKey, "error", err)
+			c.recordAuthFailed(ctx, "", sig.accessKey, errInvalidAccessKeyID)
+			c.writeError(ctx, errInvalidAccessKeyID)
+			return
+		}
+
+		payloadHash := UNSIGNED_PAYLOAD
+		if !sig.presigned {
+			payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+			if payloadHash == "" {
+				c.writeError(ctx, errMissingSecurityHeader)
+				return
+			}
+		}
+
+		signingKey := deriveSigningKey(accessKey.Secret, sig.scopeDate, sig.region)
+		expected := sig.compute(r, signingKey, payloadHash)
+		if !hmac.Equal([]byte(expected), []byte(sig.signature)) {
+			c.logger.Info("s3 signature mismatch", "client_ip", ctx.ClientIP(), "accessKey", sig.accessKey)
+			c.recordAuthFailed(ctx, accessKey.Login, sig.accessKey, errSignatureDoesNotMatch)
+			c.writeError(ctx, errSignatureDoesNotMatch)
+			return
+		}
+
+		payload, err := newPayloadReader(r, payloadHash, sig, signingKey)
+		if err != nil {
+			c.writeError(ctx, err)
+			return
+		}
+		if payload != nil {
+			r.Body = payload
+			r.ContentLength = payload.size
+			ctx.Set(PAYLOAD_CONTEXT_KEY, payload)
+		}
+
+		ctx.Set(USER_CONTEXT_KEY, user.NewToken("", accessKey.Login))
+		ctx.Next()
+	}
+}
+
+// checkTime проверяет время подписи: обычный запрос должен быть подписан не дальше MAX_CLOCK_SKEW
+// от now, presigned URL действует expires с момента подписи
+func (s *signature) checkTime(now time.Time) error {
+	if s.presigned {
+		if now.Before(s.amzDate.Add(-MAX_CLOCK_SKEW)) {
+			return errRequestTimeTooSkewed
+		}
+		if now.After(s.amzDate.Add(s.expires)) {
+			return errExpiredRequest
+		}
+		return nil
+	}
+
+	if now.Sub(s.amzDate) > MAX_CLOCK_SKEW || s.amzDate.Sub(now) > MAX_CLOCK_SKEW {
+		return errRequestTimeTooSkewed
+	}
+
+	return nil
+}
+
+// compute считает ожидаемую подпись запроса ключом signingKey
+func (s *signature) compute(r *http.Request, signingKey []byte, payloadHash string) string {
+	stringToSign := strings.Join([]string{
+		SIGV4_ALGORITHM,
+		s.amzDate.Format(AMZ_DATE_FORMAT),
+		s.scope(),
+		hashHex([]byte(canonicalRequest(r, s, payloadHash))),
+	}, "\n")
+
+	return hex.EncodeToString(hmacSHA256(signingKey, []byte(stringToSign)))
+}
+
+func parseAuthorizationHeader(r *http.Request) (*signature, error) {
+	sig := &signature{}
+
+	fields := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), SIGV4_ALGORITHM+" "), ",")
+	for _, field := range fields {
+		key, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return nil, errAuthorizationHeader
+		}
+
+		switch key {
+		case "Credential":
+			if err := sig.parseCredential(value); err != nil {
+				return nil, errAuthorizationHeader
+			}
+		case "SignedHeaders":
+			sig.signedHeaders = strings.Split(value, ";")
+		case "Signature":
+			sig.signature = value
+		}
+	}
+
+	if sig.accessKey == "" || len(sig.signedHeaders) == 0 || sig.signature == "" {
+		return nil, errAuthorizationHeader
+	}
+
+	dateValue := r.Header.Get("X-Amz-Date")
+	if dateValue == "" {
+		dateValue = r.Header.Get("Date")
+	}
+
+	amzDate, err := time.Parse(AMZ_DATE_FORMAT, dateValue)
+	if err != nil {
+		amzDate, err = http.ParseTime(dateValue)
+		if err != nil {
+			return nil, errMissingSecurityHeader
+		}
+	}
+	sig.amzDate = amzDate.UTC()
+
+	if sig.amzDate.Format(SCOPE_DATE_FORMAT) != sig.scopeDate || !slices.Contains(sig.signedHeaders, "host") {
+		return nil, errAuthorizationHeader
+	}
+
+	return sig, nil
+}
+
+func parsePresignedQuery(r *http.Request) (*signature, error) {
+	query := r.URL.Query()
+	if query.Get("X-Amz-Algorithm") != SIGV4_ALGORITHM {
+		return nil, errAuthorizationQuery
+	}
+
+	sig := &signature{
+		presigned:     true,
+		signedHeaders: strings.Split(query.Get("X-Amz-SignedHeaders"), ";"),
+		signature:     query.Get("X-Amz-Signature"),
+	}
+
+	if err := sig.parseCredential(query.Get("X-Amz-Credential")); err != nil {
+		return nil, errAuthorizationQuery
+	}
+
+	amzDate, err := time.Parse(AMZ_DATE_FORMAT, query.Get("X-Amz-Date"))
+	if err != nil {
+		return nil, errAuthorizationQuery
+	}
+	sig.amzDate = amzDate
+
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil || expires <= 0 || time.Duration(expires)*time.Second > MAX_PRESIGN_TIME {
+		return nil, errAuthorizationQuery
+	}
+	sig.expires = time.Duration(expires) * time.Second
+
+	if sig.signature == "" || sig.amzDate.Format(SCOPE_DATE_FORMAT) != sig.scopeDate || !slices.Contains(sig.signedHeaders, "host") {
+		return nil, errAuthorizationQuery
+	}
+
+	return sig, nil
+}
+
+// parseCredential разбирает строку вида AKID/20240101/us-east-1/s3/aws4_request
+func (s *signature) parseCredential(credential string) error {
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[3] != SIGV4_SERVICE || parts[4] != SIGV4_REQUEST {
+		return errAuthorizationHeader
+	}
+
+	s.accessKey = parts[0]
+	s.scopeDate = parts[1]
+	s.region = parts[2]
+
+	return nil
+}
+
+func canonicalRequest(r *http.Request, sig *signature, payloadHash string) string {
+	return strings.Join([]string{
+		r.Method,
+		uriEncode(r.URL.Path, false),
+		canonicalQuery(r.URL.Query(), sig.presigned),
+		canonicalHeaders(r, sig.signedHeaders),
+		strings.Join(sig.signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+func canonicalQuery(query url.Values, presigned bool) string {
+	var pairs [][2]string
+	for key, values := range query {
+		if presigned && key == "X-Amz-Signature" {
+			continue
+		}
+
+		for _, value := range values {
+			pairs = append(pairs, [2]string{uriEncode(key, true), uriEncode(value, true)})
+		}
+	}
+
+	// Сортировка по ключу, затем по значению, а не по склеенной строке: иначе a-b окажется раньше a
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+
+	encoded := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		encoded = append(encoded, pair[0]+"="+pair[1])
+	}
+
+	return strings.Join(encoded, "&")
+}
+
+func canonicalHeaders(r *http.Request, signedHeaders []string) string {
+	var b strings.Builder
+	for _, name := range signedHeaders {
+		var value string
+		switch name {
+		case "host":
+			value = r.Host
+		case "content-length":
+			value = r.Header.Get("Content-Length")
+			if value == "" {
+				value = strconv.FormatInt(r.ContentLength, 10)
+			}
+		default:
+			values := r.Header.Values(name)
+			for i := range values {
+				values[i] = strings.Join(strings.Fields(values[i]), " ")
+			}
+			value = strings.Join(values, ",")
+		}
+
+		b.WriteString(name)
+		b.WriteString(":")
+		b.WriteString(strings.TrimSpace(value))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// uriEncode кодирует строку по правилам SigV4: незарезервированные символы остаются как есть, остальные - %XX
+func uriEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		ch := value[i]
+		switch {
+		case (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9'),
+			ch == '-', ch == '_', ch == '.', ch == '~':
+			b.WriteByte(ch)
+		case ch == '/' && !encodeSlash:
+			b.WriteByte(ch)
+		default:
+			b.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{ch})))
+		}
+	}
+
+	return b.String()
+}
+
+func deriveSigningKey(secret, date, region string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), []byte(date))
+	key = hmacSHA256(key, []byte(region))
+	key = hmacSHA256(key, []byte(SIGV4_SERVICE))
+	return hmacSHA256(key, []byte(SIGV4_REQUEST))
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,21 @@
+package tagscontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/file"
+	"astral/internal/domain/tag"
+	"astral/internal/presentation/controller/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordTagging фиксирует изменение тегов документа как обновление его метаданных
+func (c *Controller) recordTagging(ctx *gin.Context, actor string, doc file.File, current tag.Tag, tagAction string) {
+	event := utils.NewAuditEvent(ctx, audit.ActionMetadataUpdate, actor)
+	event.Details = map[string]string{
+		"tag":        current.Name,
+		"tag_action": tagAction,
+	}
+
+	c.activityService.RecordDocument(event, doc)
+}
@@ -0,0 +1,33 @@
+package tagscontroller
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/presentation/controller/utils"
+	"astral/internal/presentation/response"
+	"log/slog"
+)
+
+type Controller struct {
+	logger          *slog.Logger
+	responseBuilder *response.ResponseBuilder
+	tagsService     contracts.TagsInterface
+	activityService contracts.ActivityInterface
+	utils           utils.Utils
+}
+
+func NewController(
+	logger *slog.Logger,
+	responseBuilder *response.ResponseBuilder,
+	tags contracts.TagsInterface,
+	activity contracts.ActivityInterface,
+	utils utils.Utils,
+) *Controller {
+	logger = logger.With("controller", "tags")
+	return &Controller{
+		logger:          logger,
+		responseBuilder: responseBuilder,
+		tagsService:     tags,
+		activityService: activity,
+		utils:           utils,
+	}
+}
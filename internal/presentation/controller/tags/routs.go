@@ -0,0 +1,31 @@
+package tagscontroller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type Router struct {
+	controller *Controller
+}
+
+func NewRouter(controller *Controller) *Router {
+	return &Router{
+		controller: controller,
+	}
+}
+
+// @Summary Register tags routes
+// @Description Group of endpoints for user tags and tagging documents
+func (r *Router) RegisterRoutes(tags *gin.RouterGroup) {
+	tags.POST("/tags", r.controller.CreateTag)
+	tags.GET("/tags", r.controller.GetTags)
+	tags.GET("/tags/documents", r.controller.FindDocuments)
+	tags.GET("/tags/:tag_id", r.controller.GetTag)
+	tags.PATCH("/tags/:tag_id", r.controller.UpdateTag)
+	tags.DELETE("/tags/:tag_id", r.controller.DeleteTag)
+	tags.POST("/tags/:tag_id/merge", r.controller.MergeTag)
+
+	tags.GET("/docs/:docs_id/tags", r.controller.GetDocumentTags)
+	tags.PUT("/docs/:docs_id/tags/:tag_id", r.controller.AttachTag)
+	tags.DELETE("/docs/:docs_id/tags/:tag_id", r.controller.DetachTag)
+}
@@ -0,0 +1,349 @@
+package tagscontroller
+
+import (
+	"astral/internal/domain/tag"
+	controllererrors "astral/internal/presentation/controller/errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create tag
+// @Description Create a named tag with an optional color and parent tag. Tag names are unique per user.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param request body createTagRequest true "Tag data"
+// @Success 200 {object} tagResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Parent tag not found"
+// @Failure 409 {object} response.ErrorResponse "Conflict"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/tags [post]
+func (c *Controller) CreateTag(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	var req createTagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid json"))
+		return
+	}
+
+	res, err := c.tagsService.CreateTag(token.Login, req.Name, req.Color, req.ParentID)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary List tags
+// @Description All tags of the user sorted by name, nesting is given by parent_id
+// @Tags tags
+// @Produce json
+// @Success 200 {object} tagsResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/tags [get]
+func (c *Controller) GetTags(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.tagsService.GetTags(token.Login)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Get tag
+// @Tags tags
+// @Produce json
+// @Param tag_id path int true "Tag ID"
+// @Success 200 {object} tagResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/tags/{tag_id} [get]
+func (c *Controller) GetTag(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	id, ok := c.tagID(ctx)
+	if !ok {
+		return
+	}
+
+	res, err := c.tagsService.GetTag(token.Login, id)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Update tag
+// @Description Rename, recolor or move the tag. Documents reference tags by ID, so a rename applies to every tagged document at once. parent_id: null moves the tag to the root.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag_id path int true "Tag ID"
+// @Param request body updateTagRequest true "Fields to change"
+// @Success 200 {object} tagResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "Conflict"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/tags/{tag_id} [patch]
+func (c *Controller) UpdateTag(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	id, ok := c.tagID(ctx)
+	if !ok {
+		return
+	}
+
+	var req updateTagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid json"))
+		return
+	}
+
+	res, err := c.tagsService.UpdateTag(token.Login, id, tag.Update{
+		Name:      req.Name,
+		Color:     req.Color,
+		ParentID:  req.ParentID.Value,
+		SetParent: req.ParentID.Set,
+	})
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Delete tag
+// @Description Delete tag and detach it from all documents, child tags move up to the parent of the deleted tag
+// @Tags tags
+// @Produce json
+// @Param tag_id path int true "Tag ID"
+// @Success 200 {object} deleteTagResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/tags/{tag_id} [delete]
+func (c *Controller) DeleteTag(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	id, ok := c.tagID(ctx)
+	if !ok {
+		return
+	}
+
+	res, err := c.tagsService.DeleteTag(token.Login, id)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	isDeleted := map[string]bool{
+		strconv.FormatInt(res.ID, 10): true,
+	}
+
+	c.responseBuilder.Ok(ctx, isDeleted, nil)
+}
+
+// @Summary Merge tag
+// @Description Merge the tag into another one: its documents and child tags move to the target tag and the tag is deleted
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag_id path int true "Tag ID to merge"
+// @Param request body mergeTagRequest true "Target tag"
+// @Success 200 {object} tagResponse "Target tag"
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/tags/{tag_id}/merge [post]
+func (c *Controller) MergeTag(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	id, ok := c.tagID(ctx)
+	if !ok {
+		return
+	}
+
+	var req mergeTagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.Into <= 0 {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("into field is required"))
+		return
+	}
+
+	res, err := c.tagsService.MergeTags(token.Login, id, req.Into)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Find documents by tags
+// @Description Own documents matching a boolean tag query. Operators AND, OR, NOT and parentheses are supported, names with spaces are quoted. A document tagged with a nested tag also matches all its ancestors.
+// @Tags tags
+// @Produce json
+// @Param q query string true "Tag query, e.g. invoices AND (2024 OR 2025) AND NOT \"draft copy\""
+// @Success 200 {object} documentsResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/tags/documents [get]
+func (c *Controller) FindDocuments(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.tagsService.FindDocuments(token.Login, ctx.Query("q"))
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary List document tags
+// @Tags tags
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Success 200 {object} tagsResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/tags [get]
+func (c *Controller) GetDocumentTags(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.tagsService.GetDocumentTags(token.Login, ctx.Param("docs_id"))
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Attach tag to document
+// @Description Attach a tag to an own document, attaching an already attached tag is not an error
+// @Tags tags
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param tag_id path int true "Tag ID"
+// @Success 200 {object} tagResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/tags/{tag_id} [put]
+func (c *Controller) AttachTag(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	id, ok := c.tagID(ctx)
+	if !ok {
+		return
+	}
+
+	doc, res, err := c.tagsService.AttachTag(token.Login, ctx.Param("docs_id"), id)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordTagging(ctx, token.Login, *doc, *res, "attach")
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Detach tag from document
+// @Tags tags
+// @Produce json
+// @Param docs_id path string true "Document ID"
+// @Param tag_id path int true "Tag ID"
+// @Success 200 {object} tagResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/docs/{docs_id}/tags/{tag_id} [delete]
+func (c *Controller) DetachTag(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	id, ok := c.tagID(ctx)
+	if !ok {
+		return
+	}
+
+	doc, res, err := c.tagsService.DetachTag(token.Login, ctx.Param("docs_id"), id)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+	c.recordTagging(ctx, token.Login, *doc, *res, "detach")
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+func (c *Controller) tagID(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("tag_id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid tag_id value"))
+		return 0, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,52 @@
+package tagscontroller
+
+import (
+	"astral/internal/domain/file"
+	"astral/internal/domain/tag"
+	"encoding/json"
+)
+
+type createTagRequest struct {
+	Name     string `json:"name"`
+	Color    string `json:"color" example:"#ff8800"`
+	ParentID *int64 `json:"parent_id"`
+}
+
+type updateTagRequest struct {
+	Name     *string    `json:"name"`
+	Color    *string    `json:"color" example:"#ff8800"`
+	ParentID optionalID `json:"parent_id" swaggertype:"integer"`
+}
+
+type mergeTagRequest struct {
+	Into int64 `json:"into"`
+}
+
+// optionalID отличает отсутствующее поле от явного null: null переносит тег в корень
+type optionalID struct {
+	Set   bool
+	Value *int64
+}
+
+func (o *optionalID) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
+}
+
+type tagResponse struct {
+	Response tag.Tag `json:"response"`
+}
+
+type tagsResponse struct {
+	Response []tag.Tag `json:"response"`
+}
+
+type documentsResponse struct {
+	Response []file.File `json:"response"`
+}
+
+type deleteTagResponse struct {
+	Response struct {
+		ID bool `json:"tag_id"`
+	} `json:"response"`
+}
@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"astral/internal/domain/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewAuditEvent создает событие аудита с источником запроса: IP и User-Agent клиента
+func NewAuditEvent(ctx *gin.Context, action audit.Action, actor string) audit.Event {
+	return audit.Event{
+		Action:    action,
+		Actor:     actor,
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+}
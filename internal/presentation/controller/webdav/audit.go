@@ -0,0 +1,57 @@
+package webdavcontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	"astral/internal/presentation/controller/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recorder описывает операции одного WebDAV запроса для сервиса действий
+type recorder struct {
+	activity contracts.ActivityInterface
+	source   audit.Event
+	method   string
+}
+
+func newRecorder(
+	ctx *gin.Context,
+	activityService contracts.ActivityInterface,
+	login string,
+) *recorder {
+	source := utils.NewAuditEvent(ctx, "", login)
+	source.Details = map[string]string{"protocol": "webdav"}
+
+	return &recorder{
+		activity: activityService,
+		source:   source,
+		method:   ctx.Request.Method,
+	}
+}
+
+func (r *recorder) record(action audit.Action, doc file.File, details map[string]string) {
+	event := r.source
+	event.Action = action
+
+	event.Details = map[string]string{}
+	for k, v := range r.source.Details {
+		event.Details[k] = v
+	}
+	for k, v := range details {
+		event.Details[k] = v
+	}
+
+	r.activity.RecordDocument(event, doc)
+}
+
+// download фиксирует скачивание только для GET: PROPFIND и COPY тоже открывают файлы на чтение
+func (r *recorder) download(doc file.File) {
+	if r.method != http.MethodGet {
+		return
+	}
+
+	r.record(audit.ActionDownload, doc, map[string]string{"path": doc.Path()})
+}
@@ -0,0 +1,49 @@
+package webdavcontroller
+
+import (
+	"astral/internal/domain/contracts"
+	"log/slog"
+	"sync"
+
+	"golang.org/x/net/webdav"
+)
+
+const (
+	DAV_PREFIX = "/webdav"
+)
+
+type Controller struct {
+	logger          *slog.Logger
+	filesService    contracts.FilesInterface
+	activityService contracts.ActivityInterface
+	locks           map[string]webdav.LockSystem
+	mu              sync.Mutex
+}
+
+func NewController(
+	logger *slog.Logger,
+	files contracts.FilesInterface,
+	activity contracts.ActivityInterface,
+) *Controller {
+	logger = logger.With("controller", "webdav")
+	return &Controller{
+		logger:          logger,
+		filesService:    files,
+		activityService: activity,
+		locks:           make(map[string]webdav.LockSystem),
+	}
+}
+
+// Блокировки WebDAV адресуются путями, поэтому у каждого пользователя свое пространство блокировок
+func (c *Controller) lockSystem(login string) webdav.LockSystem {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ls, ok := c.locks[login]
+	if !ok {
+		ls = webdav.NewMemLS()
+		c.locks[login] = ls
+	}
+
+	return ls
+}
@@ -0,0 +1,236 @@
+package webdavcontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/file"
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+type fileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+	mime    string
+	etag    string
+}
+
+func newFileInfo(doc file.File) *fileInfo {
+	info := &fileInfo{
+		name: doc.Name,
+		size: int64(doc.Size),
+		mime: doc.Mime,
+		etag: `"` + doc.ID + `"`,
+	}
+
+	if doc.CreatedAt != nil {
+		info.modTime = *doc.CreatedAt
+	}
+
+	return info
+}
+
+func newDirInfo(name string) *fileInfo {
+	return &fileInfo{
+		name: name,
+		dir:  true,
+	}
+}
+
+func (i *fileInfo) Name() string       { return i.name }
+func (i *fileInfo) Size() int64        { return i.size }
+func (i *fileInfo) ModTime() time.Time { return i.modTime }
+func (i *fileInfo) IsDir() bool        { return i.dir }
+func (i *fileInfo) Sys() any           { return nil }
+
+func (i *fileInfo) Mode() os.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+
+	return 0644
+}
+
+func (i *fileInfo) ContentType(ctx context.Context) (string, error) {
+	if i.dir || i.mime == "" {
+		return "", webdav.ErrNotImplemented
+	}
+
+	return i.mime, nil
+}
+
+func (i *fileInfo) ETag(ctx context.Context) (string, error) {
+	if i.dir || i.etag == "" {
+		return "", webdav.ErrNotImplemented
+	}
+
+	return i.etag, nil
+}
+
+// readFile отдает содержимое документа, webdav требует возможности Seek для Range запросов
+type readFile struct {
+	info   *fileInfo
+	reader io.ReadSeeker
+}
+
+func newReadFile(doc file.File, reader io.Reader) (*readFile, error) {
+	seeker, ok := reader.(io.ReadSeeker)
+	if !ok {
+		if closer, ok := reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		seeker = bytes.NewReader(data)
+	}
+
+	return &readFile{
+		info:   newFileInfo(doc),
+		reader: seeker,
+	}, nil
+}
+
+func (f *readFile) Read(p []byte) (int, error) { return f.reader.Read(p) }
+func (f *readFile) Seek(offset int64, whence int) (int64, error) {
+	return f.reader.Seek(offset, whence)
+}
+func (f *readFile) Write(p []byte) (int, error)              { return 0, os.ErrPermission }
+func (f *readFile) Readdir(count int) ([]os.FileInfo, error) { return nil, os.ErrInvalid }
+func (f *readFile) Stat() (os.FileInfo, error)               { return f.info, nil }
+
+func (f *readFile) Close() error {
+	if closer, ok := f.reader.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// writeFile копит содержимое во временном файле и при закрытии загружает документ или заменяет содержимое существующего
+type writeFile struct {
+	fs       *fileSystem
+	folder   string
+	name     string
+	existing *file.File
+	tmp      *os.File
+}
+
+func newWriteFile(fs *fileSystem, folder, name string, existing *file.File) (*writeFile, error) {
+	tmp, err := os.CreateTemp("", "astral-webdav-*")
+	if err != nil {
+		return nil, err
+	}
+
+	return &writeFile{
+		fs:       fs,
+		folder:   folder,
+		name:     name,
+		existing: existing,
+		tmp:      tmp,
+	}, nil
+}
+
+func (f *writeFile) Read(p []byte) (int, error)                   { return f.tmp.Read(p) }
+func (f *writeFile) Seek(offset int64, whence int) (int64, error) { return f.tmp.Seek(offset, whence) }
+func (f *writeFile) Write(p []byte) (int, error)                  { return f.tmp.Write(p) }
+func (f *writeFile) Readdir(count int) ([]os.FileInfo, error)     { return nil, os.ErrInvalid }
+
+func (f *writeFile) Stat() (os.FileInfo, error) {
+	stat, err := f.tmp.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileInfo{
+		name:    f.name,
+		size:    stat.Size(),
+		modTime: stat.ModTime(),
+		mime:    detectMime(f.name),
+	}, nil
+}
+
+func (f *writeFile) Close() error {
+	defer os.Remove(f.tmp.Name())
+	defer f.tmp.Close()
+
+	stat, err := f.tmp.Stat()
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if f.existing != nil {
+		// Замена - это новое содержимое того же документа: ID, доступы, метаданные и JSON данные сохраняются
+		res, err := f.fs.files.ReplaceFile(f.existing.ID, f.fs.login, f.tmp, int(stat.Size()), nil)
+		if err != nil {
+			return mapError(err)
+		}
+		f.fs.recorder.record(audit.ActionUpload, *res, map[string]string{
+			"path":        res.Path(),
+			"previous_id": res.ID,
+		})
+
+		return nil
+	}
+
+	res, err := f.fs.files.UploadFiles(file.File{
+		Name: f.name,
+		File: true,
+		Mime: detectMime(f.name),
+		Size: int(stat.Size()),
+		Metadata: map[string]string{
+			file.FOLDER_METADATA_KEY: f.folder,
+		},
+		Reader: f.tmp,
+		User:   f.fs.login,
+	})
+	if err != nil {
+		return mapError(err)
+	}
+	f.fs.recorder.record(audit.ActionUpload, *res, map[string]string{"path": res.Path()})
+
+	return nil
+}
+
+type dirFile struct {
+	info     *fileInfo
+	children []os.FileInfo
+	offset   int
+}
+
+func (f *dirFile) Read(p []byte) (int, error)                   { return 0, os.ErrInvalid }
+func (f *dirFile) Seek(offset int64, whence int) (int64, error) { return 0, os.ErrInvalid }
+func (f *dirFile) Write(p []byte) (int, error)                  { return 0, os.ErrInvalid }
+func (f *dirFile) Stat() (os.FileInfo, error)                   { return f.info, nil }
+func (f *dirFile) Close() error                                 { return nil }
+
+func (f *dirFile) Readdir(count int) ([]os.FileInfo, error) {
+	rest := f.children[f.offset:]
+	if count <= 0 {
+		f.offset = len(f.children)
+		return rest, nil
+	}
+
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+
+	if count > len(rest) {
+		count = len(rest)
+	}
+	f.offset += count
+
+	return rest[:count], nil
+}
@@ -0,0 +1,359 @@
+package webdavcontroller
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	filesrepo "astral/internal/repository/files"
+	fileservice "astral/internal/services/files"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"mime"
+	"os"
+	"path"
+	"strings"
+
+	"golang.org/x/net/webdav"
+)
+
+type fileSystem struct {
+	files    contracts.FilesInterface
+	login    string
+	logger   *slog.Logger
+	recorder *recorder
+}
+
+func newFileSystem(files contracts.FilesInterface, login string, logger *slog.Logger, recorder *recorder) *fileSystem {
+	return &fileSystem{
+		files:    files,
+		login:    login,
+		logger:   logger,
+		recorder: recorder,
+	}
+}
+
+func (fs *fileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	dir, base := file.SplitPath(name)
+	if base == "" {
+		return os.ErrExist
+	}
+
+	docs, err := fs.list()
+	if err != nil {
+		return err
+	}
+
+	if findDocument(docs, name) != nil || dirExists(docs, file.CleanPath(name)) {
+		return os.ErrExist
+	}
+
+	if !dirExists(docs, dir) {
+		return os.ErrNotExist
+	}
+
+	_, err = fs.files.UploadFiles(file.File{
+		Name: base,
+		File: false,
+		Metadata: map[string]string{
+			file.FOLDER_METADATA_KEY:     dir,
+			file.COLLECTION_METADATA_KEY: "true",
+		},
+		Reader: bytes.NewReader(nil),
+		User:   fs.login,
+	})
+	return mapError(err)
+}
+
+func (fs *fileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	docs, err := fs.list()
+	if err != nil {
+		return nil, err
+	}
+
+	current := file.CleanPath(name)
+	doc := findDocument(docs, name)
+
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC) != 0 {
+		dir, base := file.SplitPath(name)
+		if base == "" || (doc == nil && dirExists(docs, current)) {
+			return nil, os.ErrPermission
+		}
+
+		if doc == nil && flag&os.O_CREATE == 0 {
+			return nil, os.ErrNotExist
+		}
+
+		if !dirExists(docs, dir) {
+			return nil, os.ErrNotExist
+		}
+
+		return newWriteFile(fs, dir, base, doc)
+	}
+
+	if doc != nil {
+		fileData, err := fs.files.GetFileByID(doc.ID, fs.login)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		fs.recorder.download(*doc)
+
+		return newReadFile(*doc, fileData.Reader)
+	}
+
+	if dirExists(docs, current) {
+		return &dirFile{
+			info:     newDirInfo(path.Base("/" + current)),
+			children: children(docs, current),
+		}, nil
+	}
+
+	return nil, os.ErrNotExist
+}
+
+func (fs *fileSystem) RemoveAll(ctx context.Context, name string) error {
+	current := file.CleanPath(name)
+	if current == "" {
+		return os.ErrPermission
+	}
+
+	docs, err := fs.list()
+	if err != nil {
+		return err
+	}
+
+	if doc := findDocument(docs, name); doc != nil {
+		if _, err = fs.files.DeleteFile(doc.ID, fs.login); err != nil {
+			return mapError(err)
+		}
+		fs.recorder.record(audit.ActionDelete, *doc, nil)
+
+		return nil
+	}
+
+	removed := false
+	for _, doc := range docs {
+		if !underDir(doc, current) {
+			continue
+		}
+
+		if _, err := fs.files.DeleteFile(doc.ID, fs.login); err != nil {
+			return mapError(err)
+		}
+		fs.recorder.record(audit.ActionDelete, doc, nil)
+		removed = true
+	}
+
+	if !removed {
+		return os.ErrNotExist
+	}
+
+	return nil
+}
+
+func (fs *fileSystem) Rename(ctx context.Context, oldName, newName string) error {
+	oldPath, newPath := file.CleanPath(oldName), file.CleanPath(newName)
+	if oldPath == "" || newPath == "" {
+		return os.ErrPermission
+	}
+
+	docs, err := fs.list()
+	if err != nil {
+		return err
+	}
+
+	newDir, newBase := file.SplitPath(newName)
+	if !dirExists(docs, newDir) {
+		return os.ErrNotExist
+	}
+
+	if doc := findDocument(docs, oldName); doc != nil {
+		return fs.relocate(*doc, newDir, newBase)
+	}
+
+	if !dirExists(docs, oldPath) {
+		return os.ErrNotExist
+	}
+
+	if strings.HasPrefix(newPath+"/", oldPath+"/") {
+		return os.ErrInvalid
+	}
+
+	for _, doc := range docs {
+		if !underDir(doc, oldPath) {
+			continue
+		}
+
+		folder := doc.Folder()
+		if folder == oldPath || strings.HasPrefix(folder, oldPath+"/") {
+			folder = newPath + strings.TrimPrefix(folder, oldPath)
+			err = fs.relocate(doc, folder, doc.Name)
+		} else {
+			// Маркер самой переименовываемой папки
+			err = fs.relocate(doc, newDir, newBase)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (fs *fileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	current := file.CleanPath(name)
+	if current == "" {
+		return newDirInfo("/"), nil
+	}
+
+	docs, err := fs.list()
+	if err != nil {
+		return nil, err
+	}
+
+	if doc := findDocument(docs, name); doc != nil {
+		return newFileInfo(*doc), nil
+	}
+
+	if dirExists(docs, current) {
+		return newDirInfo(path.Base(name)), nil
+	}
+
+	return nil, os.ErrNotExist
+}
+
+func (fs *fileSystem) list() ([]file.File, error) {
+	docs, err := fs.files.GetFilesByUser(fs.login, contracts.FilterData{})
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return docs, nil
+}
+
+// relocate переносит документ в другую папку или под другое имя. Меняются только сведения
+// о документе, поэтому ID, содержимое, доступы и метаданные остаются прежними
+func (fs *fileSystem) relocate(doc file.File, folder, name string) error {
+	metadata := make(map[string]string, len(doc.Metadata))
+	for k, v := range doc.Metadata {
+		metadata[k] = v
+	}
+	metadata[file.FOLDER_METADATA_KEY] = folder
+
+	res, err := fs.files.UpdateFileInfo(doc.ID, fs.login, file.File{
+		Name:     name,
+		Public:   doc.Public,
+		Grant:    doc.Grant,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return mapError(err)
+	}
+
+	fs.recorder.record(audit.ActionMetadataUpdate, *res, map[string]string{
+		"previous_path": doc.Path(),
+		"path":          path.Join(folder, name),
+	})
+
+	return nil
+}
+
+func findDocument(docs []file.File, name string) *file.File {
+	dir, base := file.SplitPath(name)
+	for i := range docs {
+		if !docs[i].IsCollection() && docs[i].Name == base && docs[i].Folder() == dir {
+			return &docs[i]
+		}
+	}
+
+	return nil
+}
+
+func dirExists(docs []file.File, dir string) bool {
+	if dir == "" {
+		return true
+	}
+
+	for _, doc := range docs {
+		folder := doc.Folder()
+		if folder == dir || strings.HasPrefix(folder, dir+"/") {
+			return true
+		}
+
+		if doc.IsCollection() && doc.Path() == dir {
+			return true
+		}
+	}
+
+	return false
+}
+
+// underDir сообщает, находится ли документ внутри папки dir или является ее маркером
+func underDir(doc file.File, dir string) bool {
+	folder := doc.Folder()
+	if folder == dir || strings.HasPrefix(folder, dir+"/") {
+		return true
+	}
+
+	return doc.IsCollection() && doc.Path() == dir
+}
+
+func children(docs []file.File, dir string) []os.FileInfo {
+	var infos []os.FileInfo
+	seenDirs := make(map[string]bool)
+
+	for _, doc := range docs {
+		folder := doc.Folder()
+
+		if folder == dir {
+			if doc.IsCollection() {
+				if !seenDirs[doc.Name] {
+					seenDirs[doc.Name] = true
+					infos = append(infos, newDirInfo(doc.Name))
+				}
+				continue
+			}
+
+			infos = append(infos, newFileInfo(doc))
+			continue
+		}
+
+		prefix := dir + "/"
+		if dir == "" {
+			prefix = ""
+		}
+
+		if strings.HasPrefix(folder, prefix) {
+			sub := strings.SplitN(strings.TrimPrefix(folder, prefix), "/", 2)[0]
+			if sub != "" && !seenDirs[sub] {
+				seenDirs[sub] = true
+				infos = append(infos, newDirInfo(sub))
+			}
+		}
+	}
+
+	return infos
+}
+
+func detectMime(name string) string {
+	if mimeType := mime.TypeByExtension(path.Ext(name)); mimeType != "" {
+		return mimeType
+	}
+
+	return "application/octet-stream"
+}
+
+func mapError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, filesrepo.ErrFileNotFound):
+		return os.ErrNotExist
+	case errors.Is(err, fileservice.ErrAccessDenied), errors.Is(err, fileservice.ErrDocumentLocked):
+		return os.ErrPermission
+	default:
+		return err
+	}
+}
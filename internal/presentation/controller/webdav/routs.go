@@ -0,0 +1,28 @@
+package webdavcontroller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type Router struct {
+	controller *Controller
+}
+
+func NewRouter(controller *Controller) *Router {
+	return &Router{
+		controller: controller,
+	}
+}
+
+var davMethods = []string{
+	"OPTIONS", "GET", "HEAD", "POST", "PUT", "DELETE",
+	"MKCOL", "MOVE", "COPY", "LOCK", "UNLOCK", "PROPFIND", "PROPPATCH",
+}
+
+// @Summary Register WebDAV routes
+// @Description WebDAV access to the user's documents
+func (r *Router) RegisterRoutes(dav *gin.RouterGroup) {
+	for _, method := range davMethods {
+		dav.Handle(method, "/*path", r.controller.ServeDAV)
+	}
+}
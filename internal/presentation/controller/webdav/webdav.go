@@ -0,0 +1,32 @@
+package webdavcontroller
+
+import (
+	"astral/internal/domain/user"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/webdav"
+)
+
+// ServeDAV обслуживает WebDAV запросы к пространству авторизованного пользователя
+func (c *Controller) ServeDAV(ctx *gin.Context) {
+	tokenAny, exists := ctx.Get("user")
+	token, ok := tokenAny.(*user.Token)
+	if !exists || !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	handler := &webdav.Handler{
+		Prefix:     DAV_PREFIX,
+		FileSystem: newFileSystem(c.filesService, token.Login, c.logger, newRecorder(ctx, c.activityService, token.Login)),
+		LockSystem: c.lockSystem(token.Login),
+		Logger: func(r *http.Request, err error) {
+			if err != nil {
+				c.logger.Debug("webdav request failed", "method", r.Method, "path", r.URL.Path, "login", token.Login, "error", err)
+			}
+		},
+	}
+
+	handler.ServeHTTP(ctx.Writer, ctx.Request)
+}
@@ -0,0 +1,30 @@
+package webhookscontroller
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/presentation/controller/utils"
+	"astral/internal/presentation/response"
+	"log/slog"
+)
+
+type Controller struct {
+	logger          *slog.Logger
+	responseBuilder *response.ResponseBuilder
+	webhooksService contracts.WebhooksInterface
+	utils           utils.Utils
+}
+
+func NewController(
+	logger *slog.Logger,
+	responseBuilder *response.ResponseBuilder,
+	webhooks contracts.WebhooksInterface,
+	utils utils.Utils,
+) *Controller {
+	logger = logger.With("controller", "webhooks")
+	return &Controller{
+		logger:          logger,
+		responseBuilder: responseBuilder,
+		webhooksService: webhooks,
+		utils:           utils,
+	}
+}
@@ -0,0 +1,28 @@
+package webhookscontroller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type Router struct {
+	controller *Controller
+}
+
+func NewRouter(controller *Controller) *Router {
+	return &Router{
+		controller: controller,
+	}
+}
+
+// @Summary Register webhook routes
+// @Description Group of endpoints for managing webhooks and their deliveries
+func (r *Router) RegisterRoutes(webhooks *gin.RouterGroup) {
+	webhooks.POST("/webhooks", r.controller.CreateWebhook)
+	webhooks.GET("/webhooks", r.controller.GetWebhooks)
+	webhooks.GET("/webhooks/:id", r.controller.GetWebhook)
+	webhooks.DELETE("/webhooks/:id", r.controller.DeleteWebhook)
+
+	webhooks.GET("/webhooks/:id/deliveries", r.controller.GetDeliveries)
+	webhooks.GET("/webhooks/:id/deliveries/:delivery_id", r.controller.GetDelivery)
+	webhooks.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", r.controller.Redeliver)
+}
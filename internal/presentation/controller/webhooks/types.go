@@ -0,0 +1,31 @@
+package webhookscontroller
+
+import "astral/internal/domain/webhook"
+
+type webhookRequest struct {
+	URL    string              `json:"url"`
+	Events []webhook.EventType `json:"events"`
+	Secret string              `json:"secret"`
+}
+
+type webhookResponse struct {
+	Response webhook.Webhook `json:"response"`
+}
+
+type webhooksResponse struct {
+	Response []webhook.Webhook `json:"response"`
+}
+
+type deleteWebhookResponse struct {
+	Response struct {
+		ID bool `json:"id"`
+	} `json:"response"`
+}
+
+type deliveryResponse struct {
+	Response webhook.Delivery `json:"response"`
+}
+
+type deliveriesResponse struct {
+	Response []webhook.Delivery `json:"response"`
+}
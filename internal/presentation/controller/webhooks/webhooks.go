@@ -0,0 +1,241 @@
+package webhookscontroller
+
+import (
+	controllererrors "astral/internal/presentation/controller/errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create webhook
+// @Description Register an endpoint for document events. Every delivery is a POST with the X-Astral-Signature header: "sha256=" + hex HMAC-SHA256 of "<X-Astral-Timestamp>.<body>" with the webhook secret. The secret is generated when omitted and returned only once.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body webhookRequest true "Webhook data, events: document.uploaded, document.updated, document.deleted, document.shared, document.commented, document.corrupted, document.transferred"
+// @Success 200 {object} webhookResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/webhooks [post]
+func (c *Controller) CreateWebhook(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	var req webhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid json"))
+		return
+	}
+
+	if req.URL == "" {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("url field is required"))
+		return
+	}
+
+	res, err := c.webhooksService.CreateWebhook(token.Login, req.URL, req.Events, req.Secret)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary List webhooks
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} webhooksResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/webhooks [get]
+func (c *Controller) GetWebhooks(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.webhooksService.GetWebhooks(token.Login)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Get webhook
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} webhookResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/webhooks/{id} [get]
+func (c *Controller) GetWebhook(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.webhooksService.GetWebhook(token.Login, ctx.Param("id"))
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Delete webhook
+// @Description Delete webhook together with its delivery log
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} deleteWebhookResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/webhooks/{id} [delete]
+func (c *Controller) DeleteWebhook(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	res, err := c.webhooksService.DeleteWebhook(token.Login, ctx.Param("id"))
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	isDeleted := map[string]bool{
+		res.ID: true,
+	}
+
+	c.responseBuilder.Ok(ctx, isDeleted, nil)
+}
+
+// @Summary List webhook deliveries
+// @Description Delivery log of the webhook, newest first
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param before query int false "Return deliveries with ID less than this value (pagination)"
+// @Param limit query int false "Number of deliveries to return" minimum(1) maximum(500) default(50)
+// @Success 200 {object} deliveriesResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/webhooks/{id}/deliveries [get]
+func (c *Controller) GetDeliveries(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	var before int64
+	if value := ctx.Query("before"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed <= 0 {
+			c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid before value"))
+			return
+		}
+		before = parsed
+	}
+
+	var limit int
+	if value := ctx.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid limit value"))
+			return
+		}
+		limit = parsed
+	}
+
+	res, err := c.webhooksService.GetDeliveries(token.Login, ctx.Param("id"), before, limit)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Get webhook delivery
+// @Description Delivery with its payload and the log of every attempt with response codes
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param delivery_id path int true "Delivery ID"
+// @Success 200 {object} deliveryResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/webhooks/{id}/deliveries/{delivery_id} [get]
+func (c *Controller) GetDelivery(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(ctx.Param("delivery_id"), 10, 64)
+	if err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid delivery_id value"))
+		return
+	}
+
+	res, err := c.webhooksService.GetDelivery(token.Login, ctx.Param("id"), deliveryID)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
+
+// @Summary Redeliver webhook delivery
+// @Description Put the delivery back into the queue, it is sent again with the same payload and event ID
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param delivery_id path int true "Delivery ID"
+// @Success 200 {object} deliveryResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /api/webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (c *Controller) Redeliver(ctx *gin.Context) {
+	token := c.utils.GetTokenFromHeader(ctx)
+	if token == nil {
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(ctx.Param("delivery_id"), 10, 64)
+	if err != nil {
+		c.responseBuilder.Error(ctx, controllererrors.NewErrInvalidInputData("invalid delivery_id value"))
+		return
+	}
+
+	res, err := c.webhooksService.Redeliver(token.Login, ctx.Param("id"), deliveryID)
+	if err != nil {
+		c.responseBuilder.Error(ctx, err)
+		return
+	}
+
+	c.responseBuilder.Ok(ctx, res, nil)
+}
@@ -0,0 +1,66 @@
+package grpcserver
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/file"
+	"context"
+	"net"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// newAuditEvent создает событие аудита с источником вызова: адрес клиента и user-agent из метаданных
+func newAuditEvent(ctx context.Context, action audit.Action, actor string) audit.Event {
+	event := audit.Event{
+		Action: action,
+		Actor:  actor,
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		event.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(event.IP); err == nil {
+			event.IP = host
+		}
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("user-agent"); len(values) > 0 {
+			event.UserAgent = values[0]
+		}
+	}
+
+	return event
+}
+
+func (s *documentsServer) recordDocumentEvent(ctx context.Context, action audit.Action, actor string, doc file.File, details map[string]string) {
+	event := newAuditEvent(ctx, action, actor)
+
+	event.Details = map[string]string{"protocol": "grpc"}
+	for k, v := range details {
+		event.Details[k] = v
+	}
+
+	s.activityService.RecordDocument(event, doc)
+}
+
+// recordShare фиксирует выдачу доступа, если документ загружен с грантами или публичным
+func (s *documentsServer) recordShare(ctx context.Context, actor string, doc file.File) {
+	event := newAuditEvent(ctx, audit.ActionShare, actor)
+	event.Details = map[string]string{"protocol": "grpc"}
+
+	s.activityService.RecordShare(event, doc)
+}
+
+func (s *authServer) recordAuthEvent(ctx context.Context, action audit.Action, login string, details map[string]string) {
+	event := newAuditEvent(ctx, action, login)
+	event.Owner = login
+	event.TargetType = audit.TargetUser
+	event.TargetID = login
+	event.Details = map[string]string{"protocol": "grpc"}
+	for k, v := range details {
+		event.Details[k] = v
+	}
+
+	s.auditService.Record(event)
+}
@@ -0,0 +1,87 @@
+package grpcserver
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/dto"
+	controllererrors "astral/internal/presentation/controller/errors"
+	astralv1 "astral/internal/presentation/grpc/gen/astral/v1"
+	"context"
+	"log/slog"
+)
+
+type authServer struct {
+	astralv1.UnimplementedAuthServiceServer
+
+	logger       *slog.Logger
+	authService  contracts.AuthInterface
+	auditService contracts.AuditInterface
+}
+
+func (s *authServer) Register(ctx context.Context, req *astralv1.RegisterRequest) (*astralv1.RegisterResponse, error) {
+	if req.GetLogin() == "" {
+		return nil, toStatus(controllererrors.NewErrInvalidInputData("login field is required"))
+	}
+
+	if req.GetPassword() == "" {
+		return nil, toStatus(controllererrors.NewErrInvalidInputData("password field is required"))
+	}
+
+	if req.GetAdminToken() == "" {
+		return nil, toStatus(controllererrors.NewErrInvalidInputData("admin_token field is required"))
+	}
+
+	res, err := s.authService.Registration(req.GetAdminToken(), dto.UserData{
+		Login:    req.GetLogin(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &astralv1.RegisterResponse{Login: res.Login}, nil
+}
+
+func (s *authServer) Login(ctx context.Context, req *astralv1.LoginRequest) (*astralv1.LoginResponse, error) {
+	if req.GetLogin() == "" {
+		return nil, toStatus(controllererrors.NewErrInvalidInputData("login field is required"))
+	}
+
+	if req.GetPassword() == "" {
+		return nil, toStatus(controllererrors.NewErrInvalidInputData("password field is required"))
+	}
+
+	res, err := s.authService.Login(dto.UserData{
+		Login:    req.GetLogin(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		s.recordAuthEvent(ctx, audit.ActionAuthFailed, req.GetLogin(), map[string]string{"method": "password", "reason": err.Error()})
+		return nil, toStatus(err)
+	}
+	s.recordAuthEvent(ctx, audit.ActionLogin, res.Login, map[string]string{"method": "password"})
+
+	return &astralv1.LoginResponse{Token: res.Token}, nil
+}
+
+func (s *authServer) CloseSession(ctx context.Context, req *astralv1.CloseSessionRequest) (*astralv1.CloseSessionResponse, error) {
+	token, err := tokenFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetToken() == "" {
+		return nil, toStatus(controllererrors.NewErrInvalidInputData("token field is required"))
+	}
+
+	res, err := s.authService.CloseSession(dto.TokenData{
+		Login: token.Login,
+		Token: req.GetToken(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	s.recordAuthEvent(ctx, audit.ActionLogout, token.Login, nil)
+
+	return &astralv1.CloseSessionResponse{Token: res.Token, Closed: true}, nil
+}
@@ -0,0 +1,277 @@
+package grpcserver
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	controllererrors "astral/internal/presentation/controller/errors"
+	astralv1 "astral/internal/presentation/grpc/gen/astral/v1"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type documentsServer struct {
+	astralv1.UnimplementedDocumentsServiceServer
+
+	logger          *slog.Logger
+	filesService    contracts.FilesInterface
+	activityService contracts.ActivityInterface
+}
+
+func (s *documentsServer) UploadDocument(stream astralv1.DocumentsService_UploadDocumentServer) error {
+	ctx := stream.Context()
+	token, err := tokenFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return toStatus(ErrMetaRequired)
+	}
+
+	meta := first.GetMeta()
+	if meta == nil {
+		return toStatus(ErrMetaRequired)
+	}
+
+	if meta.GetName() == "" {
+		return toStatus(controllererrors.NewErrInvalidInputData("name field is required"))
+	}
+
+	if meta.GetSize() < 0 {
+		return toStatus(controllererrors.NewErrInvalidInputData("invalid size"))
+	}
+
+	var data json.RawMessage
+	if len(meta.GetData()) > 0 {
+		if !json.Valid(meta.GetData()) {
+			return toStatus(controllererrors.NewErrInvalidInputData("invalid data"))
+		}
+		data = meta.GetData()
+	}
+
+	reader := &chunkReader{stream: stream, remaining: meta.GetSize()}
+	fileData := file.File{
+		Name:     meta.GetName(),
+		File:     meta.GetFile(),
+		Public:   meta.GetPublic(),
+		Mime:     meta.GetMime(),
+		Grant:    meta.GetGrant(),
+		Size:     int(meta.GetSize()),
+		Metadata: meta.GetMetadata(),
+		Data:     data,
+		Reader:   reader,
+		User:     token.Login,
+	}
+
+	res, err := s.filesService.UploadFiles(fileData)
+	if err != nil {
+		// Ошибку потока важнее показать клиенту, чем ошибку хранилища, которое не дочитало данные
+		if reader.err != nil {
+			return toStatus(reader.err)
+		}
+		return toStatus(err)
+	}
+	s.recordDocumentEvent(ctx, audit.ActionUpload, token.Login, *res, map[string]string{"mime": res.Mime})
+	s.recordShare(ctx, token.Login, *res)
+
+	return stream.SendAndClose(&astralv1.UploadDocumentResponse{Document: toProtoDocument(*res)})
+}
+
+func (s *documentsServer) ListDocuments(ctx context.Context, req *astralv1.ListDocumentsRequest) (*astralv1.ListDocumentsResponse, error) {
+	token, err := tokenFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	login := req.GetLogin()
+	if login == "" {
+		login = token.Login
+	}
+
+	if req.GetLimit() < 0 {
+		return nil, toStatus(controllererrors.NewErrInvalidInputData("invalid limit value"))
+	}
+
+	filter := contracts.NewFilterData(req.GetValue(), req.GetKey(), int(req.GetLimit()))
+	files, err := s.filesService.GetFilesByUser(login, *filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &astralv1.ListDocumentsResponse{Docs: make([]*astralv1.Document, 0, len(files))}
+	for _, doc := range files {
+		resp.Docs = append(resp.Docs, toProtoDocument(doc))
+	}
+
+	return resp, nil
+}
+
+func (s *documentsServer) GetDocument(ctx context.Context, req *astralv1.GetDocumentRequest) (*astralv1.GetDocumentResponse, error) {
+	token, err := tokenFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetId() == "" {
+		return nil, toStatus(controllererrors.NewErrInvalidInputData("id field is required"))
+	}
+
+	fileData, err := s.filesService.GetFileByID(req.GetId(), token.Login)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	closeReader(fileData.Reader)
+
+	return &astralv1.GetDocumentResponse{Document: toProtoDocument(*fileData)}, nil
+}
+
+func (s *documentsServer) DownloadDocument(req *astralv1.DownloadDocumentRequest, stream astralv1.DocumentsService_DownloadDocumentServer) error {
+	ctx := stream.Context()
+	token, err := tokenFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if req.GetId() == "" {
+		return toStatus(controllererrors.NewErrInvalidInputData("id field is required"))
+	}
+
+	fileData, err := s.filesService.GetFileByID(req.GetId(), token.Login)
+	if err != nil {
+		return toStatus(err)
+	}
+	defer closeReader(fileData.Reader)
+
+	s.recordDocumentEvent(ctx, audit.ActionDownload, token.Login, *fileData, nil)
+
+	err = stream.Send(&astralv1.DownloadDocumentResponse{
+		Payload: &astralv1.DownloadDocumentResponse_Document{Document: toProtoDocument(*fileData)},
+	})
+	if err != nil {
+		return err
+	}
+
+	// У JSON документа нет содержимого, клиенту достаточно метаданных
+	if !fileData.File || fileData.Reader == nil {
+		return nil
+	}
+
+	buf := make([]byte, CHUNK_SIZE)
+	for {
+		n, err := fileData.Reader.Read(buf)
+		if n > 0 {
+			sendErr := stream.Send(&astralv1.DownloadDocumentResponse{
+				Payload: &astralv1.DownloadDocumentResponse_Chunk{Chunk: buf[:n]},
+			})
+			if sendErr != nil {
+				return sendErr
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			s.logger.Error("failed to send file", "fileID", fileData.ID, "error", err)
+			return toStatus(err)
+		}
+	}
+}
+
+func (s *documentsServer) DeleteDocument(ctx context.Context, req *astralv1.DeleteDocumentRequest) (*astralv1.DeleteDocumentResponse, error) {
+	token, err := tokenFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetId() == "" {
+		return nil, toStatus(controllererrors.NewErrInvalidInputData("id field is required"))
+	}
+
+	fileData, err := s.filesService.DeleteFile(req.GetId(), token.Login)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	s.recordDocumentEvent(ctx, audit.ActionDelete, token.Login, *fileData, nil)
+
+	return &astralv1.DeleteDocumentResponse{Id: fileData.ID, Deleted: true}, nil
+}
+
+// chunkReader читает содержимое документа из клиентского потока и следит, чтобы его размер
+// совпал с заявленным в метаданных
+type chunkReader struct {
+	stream    astralv1.DocumentsService_UploadDocumentServer
+	buf       []byte
+	remaining int64
+	err       error
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	for len(r.buf) == 0 {
+		req, err := r.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			if r.remaining > 0 {
+				r.err = ErrSizeMismatch
+				return 0, r.err
+			}
+			return 0, io.EOF
+		}
+		if err != nil {
+			r.err = err
+			return 0, err
+		}
+
+		if req.GetMeta() != nil {
+			r.err = ErrUnexpectedMeta
+			return 0, r.err
+		}
+
+		chunk := req.GetChunk()
+		if int64(len(chunk)) > r.remaining {
+			r.err = ErrSizeMismatch
+			return 0, r.err
+		}
+		r.remaining -= int64(len(chunk))
+		r.buf = chunk
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func toProtoDocument(doc file.File) *astralv1.Document {
+	res := &astralv1.Document{
+		Id:       doc.ID,
+		Name:     doc.Name,
+		File:     doc.File,
+		Public:   doc.Public,
+		Mime:     doc.Mime,
+		Grant:    doc.Grant,
+		Size:     int64(doc.Size),
+		Metadata: doc.Metadata,
+		Data:     doc.Data,
+	}
+	if doc.CreatedAt != nil {
+		res.CreatedAt = timestamppb.New(*doc.CreatedAt)
+	}
+
+	return res
+}
+
+func closeReader(reader io.Reader) {
+	if closer, ok := reader.(io.Closer); ok {
+		closer.Close()
+	}
+}
@@ -0,0 +1,52 @@
+package grpcserver
+
+import (
+	controllererrors "astral/internal/presentation/controller/errors"
+	authrepo "astral/internal/repository/auth"
+	docdatarepo "astral/internal/repository/docdata"
+	filesrepo "astral/internal/repository/files"
+	authservice "astral/internal/services/authorization"
+	fileservice "astral/internal/services/files"
+	validationservice "astral/internal/services/validation"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrMetaRequired   = errors.New("first message must contain document meta")
+	ErrUnexpectedMeta = errors.New("document meta must be sent only once")
+	ErrSizeMismatch   = errors.New("document size does not match uploaded data")
+)
+
+// toStatus переводит ошибки сервисов в коды gRPC так же, как ResponseBuilder переводит их в HTTP статусы
+func toStatus(err error) error {
+	switch err {
+	case authrepo.ErrUserAlreadyExists:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case authrepo.ErrNoRows, filesrepo.ErrFileNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case fileservice.ErrAccessDenied, authservice.ErrAccessDenied, authservice.ErrDeletionScheduled, authservice.ErrAccountDisabled:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case fileservice.ErrDocumentLocked:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case fileservice.ErrChecksumMismatch:
+		return status.Error(codes.DataLoss, err.Error())
+	case authservice.ErrInvalidToken:
+		return status.Error(codes.Unauthenticated, err.Error())
+	case ErrMetaRequired, ErrUnexpectedMeta, ErrSizeMismatch, docdatarepo.ErrInvalidQuery:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	switch err.(type) {
+	case controllererrors.ErrInvalidInputData, filesrepo.ErrFileUpload, validationservice.ErrValidationUserData:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	return status.Error(codes.Internal, "Internal server error")
+}
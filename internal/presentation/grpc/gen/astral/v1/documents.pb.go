@@ -0,0 +1,947 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: astral/v1/documents.proto
+
+package astralv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Document struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name      string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	File      bool                   `protobuf:"varint,3,opt,name=file,proto3" json:"file,omitempty"`
+	Public    bool                   `protobuf:"varint,4,opt,name=public,proto3" json:"public,omitempty"`
+	Mime      string                 `protobuf:"bytes,5,opt,name=mime,proto3" json:"mime,omitempty"`
+	Grant     []string               `protobuf:"bytes,6,rep,name=grant,proto3" json:"grant,omitempty"`
+	Size      int64                  `protobuf:"varint,7,opt,name=size,proto3" json:"size,omitempty"`
+	Metadata  map[string]string      `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// JSON данные документа в исходном виде
+	Data          []byte `protobuf:"bytes,10,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Document) Reset() {
+	*x = Document{}
+	mi := &file_astral_v1_documents_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Document) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Document) ProtoMessage() {}
+
+func (x *Document) ProtoReflect() protoreflect.Message {
+	mi := &file_astral_v1_documents_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Document.ProtoReflect.Descriptor instead.
+func (*Document) Descriptor() ([]byte, []int) {
+	return file_astral_v1_documents_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Document) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Document) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Document) GetFile() bool {
+	if x != nil {
+		return x.File
+	}
+	return false
+}
+
+func (x *Document) GetPublic() bool {
+	if x != nil {
+		return x.Public
+	}
+	return false
+}
+
+func (x *Document) GetMime() string {
+	if x != nil {
+		return x.Mime
+	}
+	return ""
+}
+
+func (x *Document) GetGrant() []string {
+	if x != nil {
+		return x.Grant
+	}
+	return nil
+}
+
+func (x *Document) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Document) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Document) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Document) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type DocumentMeta struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Name   string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	File   bool                   `protobuf:"varint,2,opt,name=file,proto3" json:"file,omitempty"`
+	Public bool                   `protobuf:"varint,3,opt,name=public,proto3" json:"public,omitempty"`
+	Mime   string                 `protobuf:"bytes,4,opt,name=mime,proto3" json:"mime,omitempty"`
+	Grant  []string               `protobuf:"bytes,5,rep,name=grant,proto3" json:"grant,omitempty"`
+	// Размер содержимого в байтах, сумма частей должна с ним совпадать
+	Size     int64             `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`
+	Metadata map[string]string `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// JSON данные документа, по ним работают фильтры data и data_contains в ListDocuments
+	Data          []byte `protobuf:"bytes,8,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DocumentMeta) Reset() {
+	*x = DocumentMeta{}
+	mi := &file_astral_v1_documents_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DocumentMeta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DocumentMeta) ProtoMessage() {}
+
+func (x *DocumentMeta) ProtoReflect() protoreflect.Message {
+	mi := &file_astral_v1_documents_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DocumentMeta.ProtoReflect.Descriptor instead.
+func (*DocumentMeta) Descriptor() ([]byte, []int) {
+	return file_astral_v1_documents_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DocumentMeta) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DocumentMeta) GetFile() bool {
+	if x != nil {
+		return x.File
+	}
+	return false
+}
+
+func (x *DocumentMeta) GetPublic() bool {
+	if x != nil {
+		return x.Public
+	}
+	return false
+}
+
+func (x *DocumentMeta) GetMime() string {
+	if x != nil {
+		return x.Mime
+	}
+	return ""
+}
+
+func (x *DocumentMeta) GetGrant() []string {
+	if x != nil {
+		return x.Grant
+	}
+	return nil
+}
+
+func (x *DocumentMeta) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *DocumentMeta) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *DocumentMeta) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type UploadDocumentRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*UploadDocumentRequest_Meta
+	//	*UploadDocumentRequest_Chunk
+	Payload       isUploadDocumentRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadDocumentRequest) Reset() {
+	*x = UploadDocumentRequest{}
+	mi := &file_astral_v1_documents_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadDocumentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadDocumentRequest) ProtoMessage() {}
+
+func (x *UploadDocumentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_astral_v1_documents_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadDocumentRequest.ProtoReflect.Descriptor instead.
+func (*UploadDocumentRequest) Descriptor() ([]byte, []int) {
+	return file_astral_v1_documents_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UploadDocumentRequest) GetPayload() isUploadDocumentRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *UploadDocumentRequest) GetMeta() *DocumentMeta {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadDocumentRequest_Meta); ok {
+			return x.Meta
+		}
+	}
+	return nil
+}
+
+func (x *UploadDocumentRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadDocumentRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isUploadDocumentRequest_Payload interface {
+	isUploadDocumentRequest_Payload()
+}
+
+type UploadDocumentRequest_Meta struct {
+	Meta *DocumentMeta `protobuf:"bytes,1,opt,name=meta,proto3,oneof"`
+}
+
+type UploadDocumentRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*UploadDocumentRequest_Meta) isUploadDocumentRequest_Payload() {}
+
+func (*UploadDocumentRequest_Chunk) isUploadDocumentRequest_Payload() {}
+
+type UploadDocumentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Document      *Document              `protobuf:"bytes,1,opt,name=document,proto3" json:"document,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadDocumentResponse) Reset() {
+	*x = UploadDocumentResponse{}
+	mi := &file_astral_v1_documents_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadDocumentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadDocumentResponse) ProtoMessage() {}
+
+func (x *UploadDocumentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_astral_v1_documents_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadDocumentResponse.ProtoReflect.Descriptor instead.
+func (*UploadDocumentResponse) Descriptor() ([]byte, []int) {
+	return file_astral_v1_documents_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UploadDocumentResponse) GetDocument() *Document {
+	if x != nil {
+		return x.Document
+	}
+	return nil
+}
+
+type ListDocumentsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Логин владельца, по умолчанию - текущий пользователь
+	Login         string `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`
+	Key           string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Limit         int32  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDocumentsRequest) Reset() {
+	*x = ListDocumentsRequest{}
+	mi := &file_astral_v1_documents_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDocumentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDocumentsRequest) ProtoMessage() {}
+
+func (x *ListDocumentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_astral_v1_documents_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDocumentsRequest.ProtoReflect.Descriptor instead.
+func (*ListDocumentsRequest) Descriptor() ([]byte, []int) {
+	return file_astral_v1_documents_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListDocumentsRequest) GetLogin() string {
+	if x != nil {
+		return x.Login
+	}
+	return ""
+}
+
+func (x *ListDocumentsRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ListDocumentsRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *ListDocumentsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListDocumentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Docs          []*Document            `protobuf:"bytes,1,rep,name=docs,proto3" json:"docs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDocumentsResponse) Reset() {
+	*x = ListDocumentsResponse{}
+	mi := &file_astral_v1_documents_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDocumentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDocumentsResponse) ProtoMessage() {}
+
+func (x *ListDocumentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_astral_v1_documents_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDocumentsResponse.ProtoReflect.Descriptor instead.
+func (*ListDocumentsResponse) Descriptor() ([]byte, []int) {
+	return file_astral_v1_documents_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListDocumentsResponse) GetDocs() []*Document {
+	if x != nil {
+		return x.Docs
+	}
+	return nil
+}
+
+type GetDocumentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDocumentRequest) Reset() {
+	*x = GetDocumentRequest{}
+	mi := &file_astral_v1_documents_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDocumentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDocumentRequest) ProtoMessage() {}
+
+func (x *GetDocumentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_astral_v1_documents_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDocumentRequest.ProtoReflect.Descriptor instead.
+func (*GetDocumentRequest) Descriptor() ([]byte, []int) {
+	return file_astral_v1_documents_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetDocumentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetDocumentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Document      *Document              `protobuf:"bytes,1,opt,name=document,proto3" json:"document,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDocumentResponse) Reset() {
+	*x = GetDocumentResponse{}
+	mi := &file_astral_v1_documents_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDocumentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDocumentResponse) ProtoMessage() {}
+
+func (x *GetDocumentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_astral_v1_documents_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDocumentResponse.ProtoReflect.Descriptor instead.
+func (*GetDocumentResponse) Descriptor() ([]byte, []int) {
+	return file_astral_v1_documents_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetDocumentResponse) GetDocument() *Document {
+	if x != nil {
+		return x.Document
+	}
+	return nil
+}
+
+type DownloadDocumentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadDocumentRequest) Reset() {
+	*x = DownloadDocumentRequest{}
+	mi := &file_astral_v1_documents_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadDocumentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadDocumentRequest) ProtoMessage() {}
+
+func (x *DownloadDocumentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_astral_v1_documents_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadDocumentRequest.ProtoReflect.Descriptor instead.
+func (*DownloadDocumentRequest) Descriptor() ([]byte, []int) {
+	return file_astral_v1_documents_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DownloadDocumentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DownloadDocumentResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*DownloadDocumentResponse_Document
+	//	*DownloadDocumentResponse_Chunk
+	Payload       isDownloadDocumentResponse_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadDocumentResponse) Reset() {
+	*x = DownloadDocumentResponse{}
+	mi := &file_astral_v1_documents_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadDocumentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadDocumentResponse) ProtoMessage() {}
+
+func (x *DownloadDocumentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_astral_v1_documents_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadDocumentResponse.ProtoReflect.Descriptor instead.
+func (*DownloadDocumentResponse) Descriptor() ([]byte, []int) {
+	return file_astral_v1_documents_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DownloadDocumentResponse) GetPayload() isDownloadDocumentResponse_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *DownloadDocumentResponse) GetDocument() *Document {
+	if x != nil {
+		if x, ok := x.Payload.(*DownloadDocumentResponse_Document); ok {
+			return x.Document
+		}
+	}
+	return nil
+}
+
+func (x *DownloadDocumentResponse) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*DownloadDocumentResponse_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isDownloadDocumentResponse_Payload interface {
+	isDownloadDocumentResponse_Payload()
+}
+
+type DownloadDocumentResponse_Document struct {
+	Document *Document `protobuf:"bytes,1,opt,name=document,proto3,oneof"`
+}
+
+type DownloadDocumentResponse_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*DownloadDocumentResponse_Document) isDownloadDocumentResponse_Payload() {}
+
+func (*DownloadDocumentResponse_Chunk) isDownloadDocumentResponse_Payload() {}
+
+type DeleteDocumentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDocumentRequest) Reset() {
+	*x = DeleteDocumentRequest{}
+	mi := &file_astral_v1_documents_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDocumentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDocumentRequest) ProtoMessage() {}
+
+func (x *DeleteDocumentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_astral_v1_documents_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDocumentRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDocumentRequest) Descriptor() ([]byte, []int) {
+	return file_astral_v1_documents_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteDocumentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteDocumentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Deleted       bool                   `protobuf:"varint,2,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDocumentResponse) Reset() {
+	*x = DeleteDocumentResponse{}
+	mi := &file_astral_v1_documents_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDocumentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDocumentResponse) ProtoMessage() {}
+
+func (x *DeleteDocumentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_astral_v1_documents_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDocumentResponse.ProtoReflect.Descriptor instead.
+func (*DeleteDocumentResponse) Descriptor() ([]byte, []int) {
+	return file_astral_v1_documents_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeleteDocumentResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeleteDocumentResponse) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+var File_astral_v1_documents_proto protoreflect.FileDescriptor
+
+const file_astral_v1_documents_proto_rawDesc = "" +
+	"\n" +
+	"\x19astral/v1/documents.proto\x12\tastral.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xe3\x02\n" +
+	"\bDocument\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04file\x18\x03 \x01(\bR\x04file\x12\x16\n" +
+	"\x06public\x18\x04 \x01(\bR\x06public\x12\x12\n" +
+	"\x04mime\x18\x05 \x01(\tR\x04mime\x12\x14\n" +
+	"\x05grant\x18\x06 \x03(\tR\x05grant\x12\x12\n" +
+	"\x04size\x18\a \x01(\x03R\x04size\x12=\n" +
+	"\bmetadata\x18\b \x03(\v2!.astral.v1.Document.MetadataEntryR\bmetadata\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x12\n" +
+	"\x04data\x18\n" +
+	" \x01(\fR\x04data\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa0\x02\n" +
+	"\fDocumentMeta\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04file\x18\x02 \x01(\bR\x04file\x12\x16\n" +
+	"\x06public\x18\x03 \x01(\bR\x06public\x12\x12\n" +
+	"\x04mime\x18\x04 \x01(\tR\x04mime\x12\x14\n" +
+	"\x05grant\x18\x05 \x03(\tR\x05grant\x12\x12\n" +
+	"\x04size\x18\x06 \x01(\x03R\x04size\x12A\n" +
+	"\bmetadata\x18\a \x03(\v2%.astral.v1.DocumentMeta.MetadataEntryR\bmetadata\x12\x12\n" +
+	"\x04data\x18\b \x01(\fR\x04data\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"i\n" +
+	"\x15UploadDocumentRequest\x12-\n" +
+	"\x04meta\x18\x01 \x01(\v2\x17.astral.v1.DocumentMetaH\x00R\x04meta\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\t\n" +
+	"\apayload\"I\n" +
+	"\x16UploadDocumentResponse\x12/\n" +
+	"\bdocument\x18\x01 \x01(\v2\x13.astral.v1.DocumentR\bdocument\"j\n" +
+	"\x14ListDocumentsRequest\x12\x14\n" +
+	"\x05login\x18\x01 \x01(\tR\x05login\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"@\n" +
+	"\x15ListDocumentsResponse\x12'\n" +
+	"\x04docs\x18\x01 \x03(\v2\x13.astral.v1.DocumentR\x04docs\"$\n" +
+	"\x12GetDocumentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"F\n" +
+	"\x13GetDocumentResponse\x12/\n" +
+	"\bdocument\x18\x01 \x01(\v2\x13.astral.v1.DocumentR\bdocument\")\n" +
+	"\x17DownloadDocumentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"p\n" +
+	"\x18DownloadDocumentResponse\x121\n" +
+	"\bdocument\x18\x01 \x01(\v2\x13.astral.v1.DocumentH\x00R\bdocument\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\t\n" +
+	"\apayload\"'\n" +
+	"\x15DeleteDocumentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"B\n" +
+	"\x16DeleteDocumentResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\adeleted\x18\x02 \x01(\bR\adeleted2\xc3\x03\n" +
+	"\x10DocumentsService\x12W\n" +
+	"\x0eUploadDocument\x12 .astral.v1.UploadDocumentRequest\x1a!.astral.v1.UploadDocumentResponse(\x01\x12R\n" +
+	"\rListDocuments\x12\x1f.astral.v1.ListDocumentsRequest\x1a .astral.v1.ListDocumentsResponse\x12L\n" +
+	"\vGetDocument\x12\x1d.astral.v1.GetDocumentRequest\x1a\x1e.astral.v1.GetDocumentResponse\x12]\n" +
+	"\x10DownloadDocument\x12\".astral.v1.DownloadDocumentRequest\x1a#.astral.v1.DownloadDocumentResponse0\x01\x12U\n" +
+	"\x0eDeleteDocument\x12 .astral.v1.DeleteDocumentRequest\x1a!.astral.v1.DeleteDocumentResponseB:Z8astral/internal/presentation/grpc/gen/astral/v1;astralv1b\x06proto3"
+
+var (
+	file_astral_v1_documents_proto_rawDescOnce sync.Once
+	file_astral_v1_documents_proto_rawDescData []byte
+)
+
+func file_astral_v1_documents_proto_rawDescGZIP() []byte {
+	file_astral_v1_documents_proto_rawDescOnce.Do(func() {
+		file_astral_v1_documents_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_astral_v1_documents_proto_rawDesc), len(file_astral_v1_documents_proto_rawDesc)))
+	})
+	return file_astral_v1_documents_proto_rawDescData
+}
+
+var file_astral_v1_documents_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_astral_v1_documents_proto_goTypes = []any{
+	(*Document)(nil),                 // 0: astral.v1.Document
+	(*DocumentMeta)(nil),             // 1: astral.v1.DocumentMeta
+	(*UploadDocumentRequest)(nil),    // 2: astral.v1.UploadDocumentRequest
+	(*UploadDocumentResponse)(nil),   // 3: astral.v1.UploadDocumentResponse
+	(*ListDocumentsRequest)(nil),     // 4: astral.v1.ListDocumentsRequest
+	(*ListDocumentsResponse)(nil),    // 5: astral.v1.ListDocumentsResponse
+	(*GetDocumentRequest)(nil),       // 6: astral.v1.GetDocumentRequest
+	(*GetDocumentResponse)(nil),      // 7: astral.v1.GetDocumentResponse
+	(*DownloadDocumentRequest)(nil),  // 8: astral.v1.DownloadDocumentRequest
+	(*DownloadDocumentResponse)(nil), // 9: astral.v1.DownloadDocumentResponse
+	(*DeleteDocumentRequest)(nil),    // 10: astral.v1.DeleteDocumentRequest
+	(*DeleteDocumentResponse)(nil),   // 11: astral.v1.DeleteDocumentResponse
+	nil,                              // 12: astral.v1.Document.MetadataEntry
+	nil,                              // 13: astral.v1.DocumentMeta.MetadataEntry
+	(*timestamppb.Timestamp)(nil),    // 14: google.protobuf.Timestamp
+}
+var file_astral_v1_documents_proto_depIdxs = []int32{
+	12, // 0: astral.v1.Document.metadata:type_name -> astral.v1.Document.MetadataEntry
+	14, // 1: astral.v1.Document.created_at:type_name -> google.protobuf.Timestamp
+	13, // 2: astral.v1.DocumentMeta.metadata:type_name -> astral.v1.DocumentMeta.MetadataEntry
+	1,  // 3: astral.v1.UploadDocumentRequest.meta:type_name -> astral.v1.DocumentMeta
+	0,  // 4: astral.v1.UploadDocumentResponse.document:type_name -> astral.v1.Document
+	0,  // 5: astral.v1.ListDocumentsResponse.docs:type_name -> astral.v1.Document
+	0,  // 6: astral.v1.GetDocumentResponse.document:type_name -> astral.v1.Document
+	0,  // 7: astral.v1.DownloadDocumentResponse.document:type_name -> astral.v1.Document
+	2,  // 8: astral.v1.DocumentsService.UploadDocument:input_type -> astral.v1.UploadDocumentRequest
+	4,  // 9: astral.v1.DocumentsService.ListDocuments:input_type -> astral.v1.ListDocumentsRequest
+	6,  // 10: astral.v1.DocumentsService.GetDocument:input_type -> astral.v1.GetDocumentRequest
+	8,  // 11: astral.v1.DocumentsService.DownloadDocument:input_type -> astral.v1.DownloadDocumentRequest
+	10, // 12: astral.v1.DocumentsService.DeleteDocument:input_type -> astral.v1.DeleteDocumentRequest
+	3,  // 13: astral.v1.DocumentsService.UploadDocument:output_type -> astral.v1.UploadDocumentResponse
+	5,  // 14: astral.v1.DocumentsService.ListDocuments:output_type -> astral.v1.ListDocumentsResponse
+	7,  // 15: astral.v1.DocumentsService.GetDocument:output_type -> astral.v1.GetDocumentResponse
+	9,  // 16: astral.v1.DocumentsService.DownloadDocument:output_type -> astral.v1.DownloadDocumentResponse
+	11, // 17: astral.v1.DocumentsService.DeleteDocument:output_type -> astral.v1.DeleteDocumentResponse
+	13, // [13:18] is the sub-list for method output_type
+	8,  // [8:13] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_astral_v1_documents_proto_init() }
+func file_astral_v1_documents_proto_init() {
+	if File_astral_v1_documents_proto != nil {
+		return
+	}
+	file_astral_v1_documents_proto_msgTypes[2].OneofWrappers = []any{
+		(*UploadDocumentRequest_Meta)(nil),
+		(*UploadDocumentRequest_Chunk)(nil),
+	}
+	file_astral_v1_documents_proto_msgTypes[9].OneofWrappers = []any{
+		(*DownloadDocumentResponse_Document)(nil),
+		(*DownloadDocumentResponse_Chunk)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_astral_v1_documents_proto_rawDesc), len(file_astral_v1_documents_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_astral_v1_documents_proto_goTypes,
+		DependencyIndexes: file_astral_v1_documents_proto_depIdxs,
+		MessageInfos:      file_astral_v1_documents_proto_msgTypes,
+	}.Build()
+	File_astral_v1_documents_proto = out.File
+	file_astral_v1_documents_proto_goTypes = nil
+	file_astral_v1_documents_proto_depIdxs = nil
+}
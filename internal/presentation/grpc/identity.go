@@ -0,0 +1,125 @@
+package grpcserver
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/user"
+	astralv1 "astral/internal/presentation/grpc/gen/astral/v1"
+	authservice "astral/internal/services/authorization"
+	"context"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type ctxKey string
+
+const USER_CTX_KEY ctxKey = "user"
+
+// publicMethods вызываются без токена, как /api/register и /api/auth
+var publicMethods = map[string]bool{
+	astralv1.AuthService_Register_FullMethodName: true,
+	astralv1.AuthService_Login_FullMethodName:    true,
+}
+
+// identity повторяет middleware UserIdentity: JWT берется из метаданных authorization
+// в виде "Bearer <token>" и кладется в контекст вызова
+type identity struct {
+	logger       *slog.Logger
+	authService  contracts.AuthInterface
+	auditService contracts.AuditInterface
+}
+
+func newIdentity(logger *slog.Logger, authService contracts.AuthInterface, auditService contracts.AuditInterface) *identity {
+	return &identity{
+		logger:       logger,
+		authService:  authService,
+		auditService: auditService,
+	}
+}
+
+func (i *identity) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		ctx, err := i.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func (i *identity) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if publicMethods[info.FullMethod] {
+			return handler(srv, stream)
+		}
+
+		ctx, err := i.authorize(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &identityStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func (i *identity) authorize(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, toStatus(authservice.ErrAccessDenied)
+	}
+
+	tokenWords := strings.Split(values[0], " ")
+	if len(tokenWords) < 2 {
+		return nil, toStatus(authservice.ErrAccessDenied)
+	}
+
+	tokenData, err := i.authService.Authorization(tokenWords[1])
+	if err != nil {
+		i.logger.Info("grpc authorization failed", "method", method, "error", err)
+		i.recordAuthFailed(ctx, method, err)
+		return nil, toStatus(err)
+	}
+
+	return context.WithValue(ctx, USER_CTX_KEY, tokenData), nil
+}
+
+func (i *identity) recordAuthFailed(ctx context.Context, method string, err error) {
+	event := newAuditEvent(ctx, audit.ActionAuthFailed, "")
+	event.TargetType = audit.TargetUser
+	event.Details = map[string]string{
+		"method": "grpc_jwt",
+		"path":   method,
+		"reason": err.Error(),
+	}
+
+	i.auditService.Record(event)
+}
+
+// identityStream подменяет контекст потока, чтобы обработчик видел пользователя
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
+
+// tokenFromContext возвращает пользователя, положенного в контекст перехватчиком
+func tokenFromContext(ctx context.Context) (*user.Token, error) {
+	token, ok := ctx.Value(USER_CTX_KEY).(*user.Token)
+	if !ok || token == nil {
+		return nil, toStatus(authservice.ErrInvalidToken)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,92 @@
+package grpcserver
+
+import (
+	"astral/internal/domain/contracts"
+	astralv1 "astral/internal/presentation/grpc/gen/astral/v1"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	CHUNK_SIZE       = 64 << 10
+	MAX_MESSAGE_SIZE = 4 << 20
+)
+
+// Server - gRPC сервер рядом с gin: те же сервисы, те же JWT и правила доступа, что и в REST API
+type Server struct {
+	logger     *slog.Logger
+	grpcServer *grpc.Server
+	port       string
+}
+
+func NewServer(
+	logger *slog.Logger,
+	port string,
+	authService contracts.AuthInterface,
+	filesService contracts.FilesInterface,
+	auditService contracts.AuditInterface,
+	activityService contracts.ActivityInterface,
+) *Server {
+	logger = logger.With("type", "presentation.grpc")
+	identity := newIdentity(logger, authService, auditService)
+
+	grpcServer := grpc.NewServer(
+		grpc.MaxRecvMsgSize(MAX_MESSAGE_SIZE),
+		grpc.ChainUnaryInterceptor(identity.Unary()),
+		grpc.ChainStreamInterceptor(identity.Stream()),
+	)
+
+	astralv1.RegisterAuthServiceServer(grpcServer, &authServer{
+		logger:       logger,
+		authService:  authService,
+		auditService: auditService,
+	})
+	astralv1.RegisterDocumentsServiceServer(grpcServer, &documentsServer{
+		logger:          logger,
+		filesService:    filesService,
+		activityService: activityService,
+	})
+
+	return &Server{
+		logger:     logger,
+		grpcServer: grpcServer,
+		port:       port,
+	}
+}
+
+// Run слушает порт и блокируется до остановки сервера
+func (s *Server) Run() error {
+	listener, err := net.Listen("tcp", ":"+s.port)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("Starting the gRPC server", slog.String("port", s.port))
+
+	if err := s.grpcServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+
+	return nil
+}
+
+// Shutdown дожидается завершения активных вызовов, а по истечении контекста обрывает их
+func (s *Server) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
@@ -4,12 +4,28 @@ import (
 	_ "astral/docs"
 	"astral/env"
 	"astral/internal/domain/contracts"
+	archivescontroller "astral/internal/presentation/controller/archives"
+	imagescontroller "astral/internal/presentation/controller/images"
+	auditcontroller "astral/internal/presentation/controller/audit"
 	authcontroller "astral/internal/presentation/controller/auth"
+	accountcontroller "astral/internal/presentation/controller/account"
+	admincontroller "astral/internal/presentation/controller/admin"
+	commentscontroller "astral/internal/presentation/controller/comments"
 	filescontroller "astral/internal/presentation/controller/files"
+	linkscontroller "astral/internal/presentation/controller/links"
+	jsondocscontroller "astral/internal/presentation/controller/jsondocs"
+	lockscontroller "astral/internal/presentation/controller/locks"
+	metricscontroller "astral/internal/presentation/controller/metrics"
+	notificationscontroller "astral/internal/presentation/controller/notifications"
+	s3controller "astral/internal/presentation/controller/s3"
+	tagscontroller "astral/internal/presentation/controller/tags"
+	webdavcontroller "astral/internal/presentation/controller/webdav"
+	webhookscontroller "astral/internal/presentation/controller/webhooks"
 	"astral/internal/presentation/controller/utils"
 	"astral/internal/presentation/middleware"
 	"astral/internal/presentation/response"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -24,6 +40,22 @@ type Handler struct {
 	fileService 	  contracts.FilesInterface
 	authService 	  contracts.AuthInterface
 	validationService contracts.ValidationInterface
+	replicationService contracts.ReplicationInterface
+	scrubService      contracts.ScrubInterface
+	s3Service         contracts.S3Interface
+	jsonDocsService   contracts.JSONDocsInterface
+	tagsService       contracts.TagsInterface
+	commentsService   contracts.CommentsInterface
+	locksService      contracts.LocksInterface
+	archivesService   contracts.ArchivesInterface
+	auditService      contracts.AuditInterface
+	webhooksService   contracts.WebhooksInterface
+	notificationsService contracts.NotificationsInterface
+	activityService   contracts.ActivityInterface
+	accountService    contracts.AccountInterface
+	adminService      contracts.AdminInterface
+	linksService      contracts.LinksInterface
+	imagesService     contracts.ImagesInterface
 	enviroments       env.Env
 }
 
@@ -32,14 +64,46 @@ func NewHandler(
 	fileService 		contracts.FilesInterface,
 	authService 		contracts.AuthInterface,
 	validationService 	contracts.ValidationInterface,
+	replicationService  contracts.ReplicationInterface,
+	scrubService        contracts.ScrubInterface,
+	s3Service           contracts.S3Interface,
+	jsonDocsService     contracts.JSONDocsInterface,
+	tagsService         contracts.TagsInterface,
+	commentsService     contracts.CommentsInterface,
+	locksService        contracts.LocksInterface,
+	archivesService     contracts.ArchivesInterface,
+	auditService        contracts.AuditInterface,
+	webhooksService     contracts.WebhooksInterface,
+	notificationsService contracts.NotificationsInterface,
+	activityService     contracts.ActivityInterface,
+	accountService      contracts.AccountInterface,
+	adminService        contracts.AdminInterface,
+	linksService        contracts.LinksInterface,
+	imagesService       contracts.ImagesInterface,
 	enviroments         env.Env,
 ) *Handler {
 	return &Handler{
-		logger: 		   logger,
-		fileService:	   fileService,
-		authService: 	   authService,
-		validationService: validationService,
-		enviroments:       enviroments,
+		logger: 		    logger,
+		fileService:	    fileService,
+		authService: 	    authService,
+		validationService:  validationService,
+		replicationService: replicationService,
+		scrubService:       scrubService,
+		s3Service:          s3Service,
+		jsonDocsService:    jsonDocsService,
+		tagsService:        tagsService,
+		commentsService:    commentsService,
+		locksService:       locksService,
+		archivesService:    archivesService,
+		auditService:       auditService,
+		webhooksService:    webhooksService,
+		notificationsService: notificationsService,
+		activityService:    activityService,
+		accountService:     accountService,
+		adminService:       adminService,
+		linksService:       linksService,
+		imagesService:      imagesService,
+		enviroments:        enviroments,
 	}
 }
 
@@ -54,20 +118,26 @@ func NewHandler(
 // @description                 JWT Authorization header using the Bearer scheme. Example: "Bearer {token}"
 func (c *Handler) InitRouts(environments *env.Env) *gin.Engine {
 	router := gin.New()
+	// Адрес клиента из заголовков берется только за доверенным прокси, иначе его подделает кто угодно:
+	// по нему проверяются подписанные ссылки и пишется журнал аудита
+	if err := router.SetTrustedProxies(environments.Http.TrustedProxies); err != nil {
+		c.logger.Error("invalid trusted proxies, client address headers are ignored", "proxies", environments.Http.TrustedProxies, "error", err)
+		router.SetTrustedProxies(nil)
+	}
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "HEAD", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Authorization", "Content-type"},
+		AllowMethods:     []string{"GET", "POST", "HEAD", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Authorization", "Content-type", "Last-Event-ID", "If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since", "X-Admin-Token"},
 		ExposeHeaders:    []string{"Content-Length, Content-Type, ETag, Last-Modified"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
 	router.Use(func(c *gin.Context) {
-		if c.Request.Method == "OPTIONS" {
+		if c.Request.Method == "OPTIONS" && !strings.HasPrefix(c.Request.URL.Path, webdavcontroller.DAV_PREFIX) {
 			c.Header("Access-Control-Allow-Origin", "*")
-			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, HEAD OPTIONS")
-			c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, HEAD OPTIONS")
+			c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type, Last-Event-ID, If-Match, If-None-Match")
 			c.Header("Access-Control-Expose-Headers", "Content-Length, Content-Type, ETag, Last-Modified")
 			c.Header("Access-Control-Max-Age", "43200")
 			c.AbortWithStatus(204)
@@ -82,26 +152,88 @@ func (c *Handler) InitRouts(environments *env.Env) *gin.Engine {
 		})
 	})
 
+	metricsController := metricscontroller.NewController(c.logger, c.replicationService, c.scrubService, c.enviroments.Metrics.Token)
+	metricsRouter := metricscontroller.NewRouter(metricsController)
+	metricsRouter.RegisterRoutes(router)
+
 	rBuilder := response.NewResponseBuilder()
 
 	router.Use(gin.Recovery())
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	middlewareController := middleware.NewAuthMiddleware(rBuilder, c.authService, c.logger)
+	middlewareController := middleware.NewAuthMiddleware(rBuilder, c.authService, c.auditService, c.logger)
 	api := router.Group("/api")
+	dav := router.Group(webdavcontroller.DAV_PREFIX, middlewareController.DavIdentity())
+	s3 := router.Group(s3controller.S3_PREFIX)
 	router.Use(middlewareController.UserIdentity())
 	router.Use(middlewareController.ErrorHandler())
 	secureApi := router.Group("/api")
 
 	utilsController := utils.NewUtils(c.logger, c.authService, *rBuilder)
 
-	authController := authcontroller.NewController(c.logger, rBuilder, c.authService, c.enviroments.AdminToken, *utilsController)
+	authController := authcontroller.NewController(c.logger, rBuilder, c.authService, c.auditService, c.enviroments.AdminToken, *utilsController)
 	authRouter := authcontroller.NewRouter(authController)
 	authRouter.RegisterRoutes(api, secureApi)
 
-	filesController := filescontroller.NewController(c.logger, rBuilder, c.fileService, c.enviroments.AdminToken)
-	filesRouter := filescontroller.NewRouter(filesController)
+	accountController := accountcontroller.NewController(c.logger, rBuilder, c.accountService, c.auditService, *utilsController)
+	accountRouter := accountcontroller.NewRouter(accountController)
+	accountRouter.RegisterRoutes(api, secureApi)
+
+	adminController := admincontroller.NewController(c.logger, rBuilder, c.adminService, c.activityService)
+	adminRouter := admincontroller.NewRouter(adminController)
+	adminRouter.RegisterRoutes(api)
+
+	filesController := filescontroller.NewController(c.logger, rBuilder, c.fileService, c.activityService, c.enviroments.AdminToken)
+	filesRouter := filescontroller.NewRouter(filesController, middlewareController.Conditional)
 	filesRouter.RegisterRoutes(secureApi)
 
+	linksController := linkscontroller.NewController(c.logger, rBuilder, c.linksService, c.activityService, c.enviroments.Links.BaseURL, *utilsController)
+	linksRouter := linkscontroller.NewRouter(linksController, middlewareController.Conditional)
+	linksRouter.RegisterRoutes(api, secureApi)
+
+	jsonDocsController := jsondocscontroller.NewController(c.logger, rBuilder, c.jsonDocsService, c.activityService, *utilsController)
+	jsonDocsRouter := jsondocscontroller.NewRouter(jsonDocsController, middlewareController.Conditional)
+	jsonDocsRouter.RegisterRoutes(secureApi)
+
+	tagsController := tagscontroller.NewController(c.logger, rBuilder, c.tagsService, c.activityService, *utilsController)
+	tagsRouter := tagscontroller.NewRouter(tagsController)
+	tagsRouter.RegisterRoutes(secureApi)
+
+	commentsController := commentscontroller.NewController(c.logger, rBuilder, c.commentsService, c.activityService, *utilsController)
+	commentsRouter := commentscontroller.NewRouter(commentsController)
+	commentsRouter.RegisterRoutes(secureApi)
+
+	locksController := lockscontroller.NewController(c.logger, rBuilder, c.locksService, c.auditService, *utilsController)
+	locksRouter := lockscontroller.NewRouter(locksController)
+	locksRouter.RegisterRoutes(secureApi)
+
+	archivesController := archivescontroller.NewController(c.logger, rBuilder, c.archivesService, c.activityService, *utilsController)
+	archivesRouter := archivescontroller.NewRouter(archivesController, middlewareController.Conditional)
+	archivesRouter.RegisterRoutes(secureApi)
+
+	imagesController := imagescontroller.NewController(c.logger, rBuilder, c.imagesService, c.activityService, *utilsController)
+	imagesRouter := imagescontroller.NewRouter(imagesController)
+	imagesRouter.RegisterRoutes(secureApi)
+
+	auditController := auditcontroller.NewController(c.logger, rBuilder, c.auditService, *utilsController)
+	auditRouter := auditcontroller.NewRouter(auditController)
+	auditRouter.RegisterRoutes(secureApi)
+
+	webhooksController := webhookscontroller.NewController(c.logger, rBuilder, c.webhooksService, *utilsController)
+	webhooksRouter := webhookscontroller.NewRouter(webhooksController)
+	webhooksRouter.RegisterRoutes(secureApi)
+
+	notificationsController := notificationscontroller.NewController(c.logger, rBuilder, c.notificationsService, c.enviroments.Notifications.Heartbeat, *utilsController)
+	notificationsRouter := notificationscontroller.NewRouter(notificationsController)
+	notificationsRouter.RegisterRoutes(secureApi)
+
+	webdavController := webdavcontroller.NewController(c.logger, c.fileService, c.activityService)
+	webdavRouter := webdavcontroller.NewRouter(webdavController)
+	webdavRouter.RegisterRoutes(dav)
+
+	s3Controller := s3controller.NewController(c.logger, c.s3Service, c.authService, c.activityService)
+	s3Router := s3controller.NewRouter(s3Controller)
+	s3Router.RegisterRoutes(s3)
+
 	return router
 }
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/presentation/controller/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordAuthFailed фиксирует неудачную аутентификацию, login - заявленный клиентом логин, если он известен
+func (m *Middleware) recordAuthFailed(ctx *gin.Context, login, method string, err error) {
+	event := utils.NewAuditEvent(ctx, audit.ActionAuthFailed, login)
+	event.Owner = login
+	event.TargetType = audit.TargetUser
+	event.TargetID = login
+	event.Details = map[string]string{
+		"method": method,
+		"path":   ctx.Request.URL.Path,
+		"reason": err.Error(),
+	}
+
+	m.auditService.Record(event)
+}
@@ -14,13 +14,15 @@ type Middleware struct {
 	logger        *slog.Logger
 	response	  *response.ResponseBuilder
 	authService   contracts.AuthInterface
+	auditService  contracts.AuditInterface
 }
 
-func NewAuthMiddleware(response *response.ResponseBuilder, authService contracts.AuthInterface, logger *slog.Logger) *Middleware {
+func NewAuthMiddleware(response *response.ResponseBuilder, authService contracts.AuthInterface, auditService contracts.AuditInterface, logger *slog.Logger) *Middleware {
 	return &Middleware{
-		logger:      logger.With("type", "middleware.AuthMiddleware"),
-		response: 	 response,
-		authService: authService,
+		logger:       logger.With("type", "middleware.AuthMiddleware"),
+		response: 	  response,
+		authService:  authService,
+		auditService: auditService,
 	}
 }
 
@@ -36,6 +38,7 @@ func (m *Middleware) UserIdentity() gin.HandlerFunc {
 		token = tokenWords[1]
 		tokenData, err := m.authService.Authorization(token)
 		if err != nil {
+			m.recordAuthFailed(ctx, "", "jwt", err)
 			m.response.Error(ctx, err)
 			return
 		}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	controllererrors "astral/internal/presentation/controller/errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Validators - текущие валидаторы ресурса для условных запросов (RFC 9110, раздел 8.8)
+type Validators struct {
+	// ETag - сущностный тег в кавычках, слабый с префиксом W/
+	ETag         string
+	LastModified time.Time
+}
+
+// ValidatorsFunc ищет валидаторы запрошенного ресурса.
+// nil без ошибки означает, что ресурса нет; если функция сама ответила клиенту, она прерывает контекст
+type ValidatorsFunc func(ctx *gin.Context) (*Validators, error)
+
+// Conditional проверяет предусловия If-Match, If-Unmodified-Since, If-None-Match и If-Modified-Since
+// до вызова обработчика: отвечает 304 на неизменившийся GET/HEAD и 412 на несработавшее условие
+func (m *Middleware) Conditional(lookup ValidatorsFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		safe := isSafeMethod(ctx.Request.Method)
+		if !safe && !hasPreconditions(ctx.Request) {
+			ctx.Next()
+			return
+		}
+
+		validators, err := lookup(ctx)
+		if ctx.IsAborted() {
+			return
+		}
+		if err != nil {
+			m.response.Error(ctx, err)
+			return
+		}
+
+		if safe && validators != nil {
+			if validators.ETag != "" {
+				ctx.Header("ETag", validators.ETag)
+			}
+			if !validators.LastModified.IsZero() {
+				ctx.Header("Last-Modified", validators.LastModified.UTC().Format(http.TimeFormat))
+			}
+		}
+
+		switch EvaluatePreconditions(ctx.Request, validators) {
+		case http.StatusNotModified:
+			m.logger.Debug("not modified", "path", ctx.Request.URL.Path)
+			ctx.AbortWithStatus(http.StatusNotModified)
+			return
+		case http.StatusPreconditionFailed:
+			m.logger.Info("precondition failed", "path", ctx.Request.URL.Path, "method", ctx.Request.Method)
+			m.response.Error(ctx, controllererrors.ErrPreconditionFailed)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// EvaluatePreconditions применяет предусловия запроса в порядке RFC 9110, раздел 13.2.2.
+// Возвращает 0, если запрос нужно выполнить, иначе 304 или 412. validators == nil - ресурса нет
+func EvaluatePreconditions(r *http.Request, validators *Validators) int {
+	etag := ""
+	var modified time.Time
+	if validators != nil {
+		etag = validators.ETag
+		modified = validators.LastModified
+	}
+
+	if ifMatch := r.Header.Values("If-Match"); len(ifMatch) > 0 {
+		if validators == nil || !matchETag(ifMatch, etag, true) {
+			return http.StatusPreconditionFailed
+		}
+	} else if since, ok := parseHTTPDate(r.Header.Get("If-Unmodified-Since")); ok && !modified.IsZero() {
+		if modified.Truncate(time.Second).After(since) {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	safe := isSafeMethod(r.Method)
+	if ifNoneMatch := r.Header.Values("If-None-Match"); len(ifNoneMatch) > 0 {
+		if validators != nil && matchETag(ifNoneMatch, etag, false) {
+			if safe {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	} else if since, ok := parseHTTPDate(r.Header.Get("If-Modified-Since")); ok && safe && !modified.IsZero() {
+		if !modified.Truncate(time.Second).After(since) {
+			return http.StatusNotModified
+		}
+	}
+
+	return 0
+}
+
+// StrongETag оборачивает значение в кавычки сильного сущностного тега
+func StrongETag(value string) string {
+	return `"` + value + `"`
+}
+
+// WeakETag помечает тег как слабый: представления семантически равны, но не обязательно побайтно
+func WeakETag(value string) string {
+	return `W/"` + value + `"`
+}
+
+// matchETag сравнивает тег с перечнем из заголовка: сильно для If-Match, слабо для If-None-Match
+func matchETag(values []string, etag string, strong bool) bool {
+	for _, value := range values {
+		for _, candidate := range splitETags(value) {
+			if candidate == "*" {
+				return true
+			}
+			if etag == "" {
+				continue
+			}
+			if strong {
+				if !isWeakETag(candidate) && !isWeakETag(etag) && candidate == etag {
+					return true
+				}
+				continue
+			}
+			if opaqueTag(candidate) == opaqueTag(etag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitETags разбирает список тегов через запятую; запятые внутри кавычек частью списка не считаются
+func splitETags(value string) []string {
+	var tags []string
+	for {
+		value = strings.TrimLeft(value, " \t,")
+		if value == "" {
+			return tags
+		}
+		if value[0] == '*' {
+			tags = append(tags, "*")
+			value = value[1:]
+			continue
+		}
+
+		start := 0
+		if strings.HasPrefix(value, "W/") {
+			start = 2
+		}
+		if len(value) <= start || value[start] != '"' {
+			// Некорректный элемент пропускаем до следующей запятой
+			next := strings.IndexByte(value, ',')
+			if next < 0 {
+				return tags
+			}
+			value = value[next:]
+			continue
+		}
+
+		end := strings.IndexByte(value[start+1:], '"')
+		if end < 0 {
+			return tags
+		}
+		end += start + 2
+		tags = append(tags, value[:end])
+		value = value[end:]
+	}
+}
+
+func isWeakETag(etag string) bool {
+	return strings.HasPrefix(etag, "W/")
+}
+
+func opaqueTag(etag string) string {
+	return strings.TrimPrefix(etag, "W/")
+}
+
+func parseHTTPDate(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func hasPreconditions(r *http.Request) bool {
+	for _, header := range []string{"If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since"} {
+		if r.Header.Get(header) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEvaluatePreconditions(t *testing.T) {
+	modified := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := modified.Add(-time.Hour).Format(http.TimeFormat)
+	at := modified.Format(http.TimeFormat)
+	after := modified.Add(time.Hour).Format(http.TimeFormat)
+
+	strong := &Validators{ETag: `"v2"`, LastModified: modified}
+	weak := &Validators{ETag: `W/"v2"`, LastModified: modified}
+	// Время изменения хранится точнее секунды, а в заголовках передается с точностью до секунды
+	precise := &Validators{ETag: `"v2"`, LastModified: modified.Add(300 * time.Millisecond)}
+
+	tests := []struct {
+		name       string
+		method     string
+		headers    map[string]string
+		validators *Validators
+		want       int
+	}{
+		{"no preconditions", http.MethodGet, nil, strong, 0},
+
+		// If-Match: только сильное сравнение, 412 при несовпадении на любом методе
+		{"if-match same tag", http.MethodPut, map[string]string{"If-Match": `"v2"`}, strong, 0},
+		{"if-match in a list", http.MethodPut, map[string]string{"If-Match": `"v1", "v2"`}, strong, 0},
+		{"if-match other tag", http.MethodPut, map[string]string{"If-Match": `"v1"`}, strong, http.StatusPreconditionFailed},
+		{"if-match other tag on get", http.MethodGet, map[string]string{"If-Match": `"v1"`}, strong, http.StatusPreconditionFailed},
+		{"if-match weak request tag", http.MethodPut, map[string]string{"If-Match": `W/"v2"`}, strong, http.StatusPreconditionFailed},
+		{"if-match weak resource tag", http.MethodPut, map[string]string{"If-Match": `"v2"`}, weak, http.StatusPreconditionFailed},
+		{"if-match star", http.MethodPut, map[string]string{"If-Match": `*`}, strong, 0},
+		{"if-match star without resource", http.MethodPut, map[string]string{"If-Match": `*`}, nil, http.StatusPreconditionFailed},
+		{"if-match malformed", http.MethodPut, map[string]string{"If-Match": `v2`}, strong, http.StatusPreconditionFailed},
+
+		// If-Unmodified-Since проверяется, только если нет If-Match
+		{"if-unmodified-since not modified", http.MethodPut, map[string]string{"If-Unmodified-Since": at}, strong, 0},
+		{"if-unmodified-since modified", http.MethodPut, map[string]string{"If-Unmodified-Since": before}, strong, http.StatusPreconditionFailed},
+		{"if-unmodified-since ignores sub-second", http.MethodPut, map[string]string{"If-Unmodified-Since": at}, precise, 0},
+		{"if-unmodified-since invalid date", http.MethodPut, map[string]string{"If-Unmodified-Since": "yesterday"}, strong, 0},
+		{"if-match takes precedence over if-unmodified-since", http.MethodPut, map[string]string{"If-Match": `"v2"`, "If-Unmodified-Since": before}, strong, 0},
+
+		// If-None-Match: слабое сравнение, 304 на GET/HEAD и 412 на остальных методах
+		{"if-none-match same tag on get", http.MethodGet, map[string]string{"If-None-Match": `"v2"`}, strong, http.StatusNotModified},
+		{"if-none-match same tag on head", http.MethodHead, map[string]string{"If-None-Match": `"v2"`}, strong, http.StatusNotModified},
+		{"if-none-match same tag on put", http.MethodPut, map[string]string{"If-None-Match": `"v2"`}, strong, http.StatusPreconditionFailed},
+		{"if-none-match weak request tag", http.MethodGet, map[string]string{"If-None-Match": `W/"v2"`}, strong, http.StatusNotModified},
+		{"if-none-match weak resource tag", http.MethodGet, map[string]string{"If-None-Match": `"v2"`}, weak, http.StatusNotModified},
+		{"if-none-match other tag", http.MethodGet, map[string]string{"If-None-Match": `"v1", W/"v3"`}, strong, 0},
+		{"if-none-match comma inside tag", http.MethodGet, map[string]string{"If-None-Match": `"a,b", "v2"`}, strong, http.StatusNotModified},
+		{"if-none-match star on put", http.MethodPut, map[string]string{"If-None-Match": `*`}, strong, http.StatusPreconditionFailed},
+		{"if-none-match star without resource", http.MethodPut, map[string]string{"If-None-Match": `*`}, nil, 0},
+
+		// If-Modified-Since проверяется только на GET/HEAD и только без If-None-Match
+		{"if-modified-since not modified", http.MethodGet, map[string]string{"If-Modified-Since": at}, strong, http.StatusNotModified},
+		{"if-modified-since later", http.MethodGet, map[string]string{"If-Modified-Since": after}, strong, http.StatusNotModified},
+		{"if-modified-since modified", http.MethodGet, map[string]string{"If-Modified-Since": before}, strong, 0},
+		{"if-modified-since ignores sub-second", http.MethodGet, map[string]string{"If-Modified-Since": at}, precise, http.StatusNotModified},
+		{"if-modified-since on put", http.MethodPut, map[string]string{"If-Modified-Since": at}, strong, 0},
+		{"if-modified-since invalid date", http.MethodGet, map[string]string{"If-Modified-Since": "yesterday"}, strong, 0},
+		{"if-none-match takes precedence over if-modified-since", http.MethodGet, map[string]string{"If-None-Match": `"v1"`, "If-Modified-Since": at}, strong, 0},
+
+		// Порядок шагов: сначала If-Match, затем If-None-Match
+		{"if-match fails before if-none-match", http.MethodGet, map[string]string{"If-Match": `"v1"`, "If-None-Match": `"v2"`}, strong, http.StatusPreconditionFailed},
+		{"if-match passes then if-none-match", http.MethodGet, map[string]string{"If-Match": `"v2"`, "If-None-Match": `"v2"`}, strong, http.StatusNotModified},
+		{"if-unmodified-since fails before if-modified-since", http.MethodGet, map[string]string{"If-Unmodified-Since": before, "If-Modified-Since": at}, strong, http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/api/docs/1", nil)
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+
+			if got := EvaluatePreconditions(r, tt.validators); got != tt.want {
+				t.Fatalf("EvaluatePreconditions = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitETags(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{`"a"`, []string{`"a"`}},
+		{`"a", W/"b",  "c"`, []string{`"a"`, `W/"b"`, `"c"`}},
+		{`*`, []string{`*`}},
+		{`"a,b"`, []string{`"a,b"`}},
+		{`bare, "a"`, []string{`"a"`}},
+		{`"unterminated`, nil},
+		{``, nil},
+	}
+
+	for _, tt := range tests {
+		got := splitETags(tt.value)
+		if len(got) != len(tt.want) {
+			t.Errorf("splitETags(%q) = %q, want %q", tt.value, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("splitETags(%q) = %q, want %q", tt.value, got, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"astral/internal/domain/user"
+	authservice "astral/internal/services/authorization"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DavIdentity принимает как Bearer JWT, так и Basic авторизацию с паролем приложения или JWT вместо пароля,
+// потому что файловые менеджеры и офисные пакеты умеют только Basic
+func (m *Middleware) DavIdentity() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var tokenData *user.Token
+		var err error
+		var login, method string
+
+		header := ctx.GetHeader("Authorization")
+		if strings.HasPrefix(header, "Bearer ") {
+			method = "jwt"
+			tokenData, err = m.authService.Authorization(strings.TrimPrefix(header, "Bearer "))
+		} else {
+			var password string
+			var ok bool
+			login, password, ok = ctx.Request.BasicAuth()
+			if !ok {
+				m.davUnauthorized(ctx)
+				return
+			}
+
+			if strings.Count(password, ".") == 2 {
+				method = "jwt"
+				tokenData, err = m.authService.Authorization(password)
+				if err == nil && tokenData.Login != login {
+					err = authservice.ErrInvalidToken
+				}
+			} else {
+				method = "app_password"
+				tokenData, err = m.authService.AuthorizationByAppPassword(login, password)
+			}
+		}
+
+		if err != nil {
+			m.logger.Info("webdav authorization failed", "client_ip", ctx.ClientIP(), "error", err)
+			m.recordAuthFailed(ctx, login, "webdav_"+method, err)
+			m.davUnauthorized(ctx)
+			return
+		}
+
+		ctx.Set("user", tokenData)
+		ctx.Next()
+	}
+}
+
+func (m *Middleware) davUnauthorized(ctx *gin.Context) {
+	ctx.Header("WWW-Authenticate", `Basic realm="Astral", charset="UTF-8"`)
+	m.response.Error(ctx, authservice.ErrInvalidToken)
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"astral/internal/domain/file"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// DocumentETag - сильный тег: меняется вместе с содержимым (контрольная сумма), метаданными и ревизией JSON-данных
+func DocumentETag(doc file.File) string {
+	var modified int64
+	if doc.CreatedAt != nil {
+		modified = doc.CreatedAt.UnixNano()
+	}
+	data := fmt.Sprintf("%s-%s-%d-%t-%s-%s-%d-%d-%s",
+		doc.ID,
+		doc.Name,
+		doc.Size,
+		doc.Public,
+		doc.Mime,
+		doc.Checksum,
+		doc.Revision,
+		modified,
+		strings.Join(doc.Grant, ","),
+	)
+
+	hash := sha256.Sum256([]byte(data))
+	return StrongETag(hex.EncodeToString(hash[:]))
+}
+
+// DocumentValidators - дата изменения есть только у файлов: JSON-данные меняются мимо объекта в хранилище,
+// и его время их правку не отражает, поэтому для них работает только ETag с ревизией
+func DocumentValidators(doc file.File) *Validators {
+	validators := &Validators{
+		ETag: DocumentETag(doc),
+	}
+	if doc.File && doc.CreatedAt != nil {
+		validators.LastModified = *doc.CreatedAt
+	}
+	return validators
+}
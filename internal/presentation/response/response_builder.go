@@ -3,8 +3,27 @@ package response
 import (
 	controllererrors "astral/internal/presentation/controller/errors"
 	authrepo "astral/internal/repository/auth"
+	auditservice "astral/internal/services/audit"
+	webhooksrepo "astral/internal/repository/webhooks"
+	webhooksservice "astral/internal/services/webhooks"
+	notificationsservice "astral/internal/services/notifications"
 	filesrepo "astral/internal/repository/files"
+	docdatarepo "astral/internal/repository/docdata"
+	jsondocsservice "astral/internal/services/jsondocs"
+	tagsrepo "astral/internal/repository/tags"
+	tagsservice "astral/internal/services/tags"
+	commentsrepo "astral/internal/repository/comments"
+	commentsservice "astral/internal/services/comments"
+	locksrepo "astral/internal/repository/locks"
+	locksservice "astral/internal/services/locks"
+	archivesservice "astral/internal/services/archives"
 	authservice "astral/internal/services/authorization"
+	accountrepo "astral/internal/repository/account"
+	accountservice "astral/internal/services/account"
+	adminrepo "astral/internal/repository/admin"
+	adminservice "astral/internal/services/admin"
+	linksservice "astral/internal/services/links"
+	imagesservice "astral/internal/services/images"
 	fileservice "astral/internal/services/files"
 	validationservice "astral/internal/services/validation"
 	"net/http"
@@ -48,6 +67,104 @@ func (f *ResponseBuilder) Error(ctx *gin.Context, err error) {
 		ctx.AbortWithStatusJSON(http.StatusForbidden, getErrorResponse(http.StatusForbidden, err.Error()))
 	case authservice.ErrAccessDenied:
 		ctx.AbortWithStatusJSON(http.StatusForbidden, getErrorResponse(http.StatusForbidden, err.Error()))
+	case auditservice.ErrAccessDenied:
+		ctx.AbortWithStatusJSON(http.StatusForbidden, getErrorResponse(http.StatusForbidden, err.Error()))
+	case webhooksrepo.ErrWebhookNotFound, webhooksrepo.ErrDeliveryNotFound:
+		ctx.AbortWithStatusJSON(http.StatusNotFound, getErrorResponse(http.StatusNotFound, err.Error()))
+	case webhooksservice.ErrInvalidURL, webhooksservice.ErrInvalidEvents:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, getErrorResponse(http.StatusBadRequest, err.Error()))
+	case notificationsservice.ErrInvalidEventID, docdatarepo.ErrInvalidQuery:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, getErrorResponse(http.StatusBadRequest, err.Error()))
+	case jsondocsservice.ErrDocumentNotFound, docdatarepo.ErrDataNotFound:
+		ctx.AbortWithStatusJSON(http.StatusNotFound, getErrorResponse(http.StatusNotFound, err.Error()))
+	case jsondocsservice.ErrAccessDenied:
+		ctx.AbortWithStatusJSON(http.StatusForbidden, getErrorResponse(http.StatusForbidden, err.Error()))
+	case jsondocsservice.ErrInvalidPatch:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, getErrorResponse(http.StatusBadRequest, err.Error()))
+	case jsondocsservice.ErrPatchFailed:
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, getErrorResponse(http.StatusUnprocessableEntity, err.Error()))
+	case docdatarepo.ErrRevisionMismatch, controllererrors.ErrPreconditionFailed:
+		ctx.AbortWithStatusJSON(http.StatusPreconditionFailed, getErrorResponse(http.StatusPreconditionFailed, err.Error()))
+	case controllererrors.ErrPreconditionRequired:
+		ctx.AbortWithStatusJSON(http.StatusPreconditionRequired, getErrorResponse(http.StatusPreconditionRequired, err.Error()))
+	case controllererrors.ErrUnsupportedMediaType:
+		ctx.AbortWithStatusJSON(http.StatusUnsupportedMediaType, getErrorResponse(http.StatusUnsupportedMediaType, err.Error()))
+	case tagsrepo.ErrTagNotFound:
+		ctx.AbortWithStatusJSON(http.StatusNotFound, getErrorResponse(http.StatusNotFound, err.Error()))
+	case tagsrepo.ErrTagExists, tagsservice.ErrTagCycle:
+		ctx.AbortWithStatusJSON(http.StatusConflict, getErrorResponse(http.StatusConflict, err.Error()))
+	case tagsservice.ErrInvalidName, tagsservice.ErrInvalidColor, tagsservice.ErrInvalidQuery, tagsservice.ErrUnknownTag:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, getErrorResponse(http.StatusBadRequest, err.Error()))
+	case commentsrepo.ErrCommentNotFound:
+		ctx.AbortWithStatusJSON(http.StatusNotFound, getErrorResponse(http.StatusNotFound, err.Error()))
+	case commentsservice.ErrAccessDenied:
+		ctx.AbortWithStatusJSON(http.StatusForbidden, getErrorResponse(http.StatusForbidden, err.Error()))
+	case commentsservice.ErrInvalidBody, commentsservice.ErrInvalidAnchor, commentsservice.ErrNotThread:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, getErrorResponse(http.StatusBadRequest, err.Error()))
+	case fileservice.ErrChecksumMismatch:
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, getErrorResponse(http.StatusUnprocessableEntity, err.Error()))
+	case fileservice.ErrDocumentLocked, locksrepo.ErrLockHeld:
+		ctx.AbortWithStatusJSON(http.StatusLocked, getErrorResponse(http.StatusLocked, err.Error()))
+	case locksrepo.ErrLockNotFound:
+		ctx.AbortWithStatusJSON(http.StatusNotFound, getErrorResponse(http.StatusNotFound, err.Error()))
+	case locksrepo.ErrNotHolder, locksservice.ErrAccessDenied:
+		ctx.AbortWithStatusJSON(http.StatusForbidden, getErrorResponse(http.StatusForbidden, err.Error()))
+	case locksservice.ErrInvalidTTL, locksservice.ErrInvalidMode, locksservice.ErrInvalidReason:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, getErrorResponse(http.StatusBadRequest, err.Error()))
+	case archivesservice.ErrEntryNotFound:
+		ctx.AbortWithStatusJSON(http.StatusNotFound, getErrorResponse(http.StatusNotFound, err.Error()))
+	case archivesservice.ErrAccessDenied:
+		ctx.AbortWithStatusJSON(http.StatusForbidden, getErrorResponse(http.StatusForbidden, err.Error()))
+	case archivesservice.ErrNotArchive:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, getErrorResponse(http.StatusBadRequest, err.Error()))
+	case archivesservice.ErrInvalidArchive:
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, getErrorResponse(http.StatusUnprocessableEntity, err.Error()))
+	case archivesservice.ErrArchiveTooLarge:
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, getErrorResponse(http.StatusRequestEntityTooLarge, err.Error()))
+	case accountrepo.ErrUserNotFound, accountrepo.ErrExportNotFound:
+		ctx.AbortWithStatusJSON(http.StatusNotFound, getErrorResponse(http.StatusNotFound, err.Error()))
+	case accountrepo.ErrDeletionScheduled, accountrepo.ErrDeletionNotScheduled, accountservice.ErrExportNotReady:
+		ctx.AbortWithStatusJSON(http.StatusConflict, getErrorResponse(http.StatusConflict, err.Error()))
+	case accountservice.ErrAccessDenied, authservice.ErrDeletionScheduled, authservice.ErrAccountDisabled:
+		ctx.AbortWithStatusJSON(http.StatusForbidden, getErrorResponse(http.StatusForbidden, err.Error()))
+	case accountservice.ErrGracePeriodExpired, accountservice.ErrExportExpired:
+		ctx.AbortWithStatusJSON(http.StatusGone, getErrorResponse(http.StatusGone, err.Error()))
+	case fileservice.ErrUserNotFound:
+		ctx.AbortWithStatusJSON(http.StatusNotFound, getErrorResponse(http.StatusNotFound, err.Error()))
+	case fileservice.ErrSameOwner:
+		ctx.AbortWithStatusJSON(http.StatusConflict, getErrorResponse(http.StatusConflict, err.Error()))
+	case fileservice.ErrNotPending, fileservice.ErrNotActive, filesrepo.ErrObjectChanged:
+		ctx.AbortWithStatusJSON(http.StatusConflict, getErrorResponse(http.StatusConflict, err.Error()))
+	case fileservice.ErrSizeMismatch:
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, getErrorResponse(http.StatusUnprocessableEntity, err.Error()))
+	case filesrepo.ErrPresignDisabled:
+		ctx.AbortWithStatusJSON(http.StatusNotImplemented, getErrorResponse(http.StatusNotImplemented, err.Error()))
+	case linksservice.ErrInvalidSignature, linksservice.ErrAddressMismatch:
+		ctx.AbortWithStatusJSON(http.StatusForbidden, getErrorResponse(http.StatusForbidden, err.Error()))
+	case linksservice.ErrLinkExpired:
+		ctx.AbortWithStatusJSON(http.StatusGone, getErrorResponse(http.StatusGone, err.Error()))
+	case linksservice.ErrInvalidTTL, linksservice.ErrInvalidIP, linksservice.ErrInvalidDisposition:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, getErrorResponse(http.StatusBadRequest, err.Error()))
+	case linksservice.ErrDocumentNotFound:
+		ctx.AbortWithStatusJSON(http.StatusNotFound, getErrorResponse(http.StatusNotFound, err.Error()))
+	case linksservice.ErrLinksDisabled:
+		ctx.AbortWithStatusJSON(http.StatusNotImplemented, getErrorResponse(http.StatusNotImplemented, err.Error()))
+	case imagesservice.ErrAccessDenied:
+		ctx.AbortWithStatusJSON(http.StatusForbidden, getErrorResponse(http.StatusForbidden, err.Error()))
+	case imagesservice.ErrNotImage, imagesservice.ErrInvalidSize, imagesservice.ErrInvalidFit, imagesservice.ErrInvalidFormat, imagesservice.ErrInvalidQuality, imagesservice.ErrInvalidCrop:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, getErrorResponse(http.StatusBadRequest, err.Error()))
+	case imagesservice.ErrInvalidImage:
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, getErrorResponse(http.StatusUnprocessableEntity, err.Error()))
+	case imagesservice.ErrImageTooLarge:
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, getErrorResponse(http.StatusRequestEntityTooLarge, err.Error()))
+	case imagesservice.ErrBusy:
+		ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, getErrorResponse(http.StatusServiceUnavailable, err.Error()))
+	case adminrepo.ErrUserNotFound, adminservice.ErrDocumentNotFound:
+		ctx.AbortWithStatusJSON(http.StatusNotFound, getErrorResponse(http.StatusNotFound, err.Error()))
+	case adminservice.ErrAccessDenied:
+		ctx.AbortWithStatusJSON(http.StatusForbidden, getErrorResponse(http.StatusForbidden, err.Error()))
+	case adminservice.ErrInvalidStatus:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, getErrorResponse(http.StatusBadRequest, err.Error()))
 	case authservice.ErrInvalidToken:
 		ctx.AbortWithStatusJSON(http.StatusUnauthorized, getErrorResponse(http.StatusUnauthorized, err.Error()))
 		
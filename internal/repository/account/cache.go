@@ -0,0 +1,49 @@
+package accountrepo
+
+import (
+	locksrepo "astral/internal/repository/locks"
+	notificationsrepo "astral/internal/repository/notifications"
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+const SCAN_BATCH_SIZE = 1000
+
+// DeleteCacheKeys удаляет из Redis кэш документов и списков пользователя, блокировки его
+// документов и историю уведомлений
+func (p *AccountPersister) DeleteCacheKeys(ctx context.Context, login string, documentIDs []string) error {
+	const op = "repository.account.cache.DeleteCacheKeys"
+
+	keys := []string{notificationsrepo.HISTORY_KEY_PREFIX + login}
+	for _, ID := range documentIDs {
+		// Ключ документа в кэше сервиса документов - file::"<id>"
+		quoted, err := json.Marshal(ID)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, "file::"+string(quoted), locksrepo.LOCK_KEY_PREFIX+ID)
+	}
+
+	var cursor uint64
+	for {
+		batch, next, err := p.cache.Scan(ctx, cursor, "list:"+login+":*", SCAN_BATCH_SIZE).Result()
+		if err != nil {
+			p.logger.Error("failed to scan cache keys", "func", op, "login", login, "error", err)
+			return errors.New("failed to delete cache keys")
+		}
+		keys = append(keys, batch...)
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	if err := p.cache.Del(ctx, keys...).Err(); err != nil {
+		p.logger.Error("failed to delete cache keys", "func", op, "login", login, "error", err)
+		return errors.New("failed to delete cache keys")
+	}
+
+	return nil
+}
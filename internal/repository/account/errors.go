@@ -0,0 +1,10 @@
+package accountrepo
+
+import "errors"
+
+var (
+	ErrUserNotFound         = errors.New("user not found")
+	ErrDeletionScheduled    = errors.New("account deletion is already scheduled")
+	ErrDeletionNotScheduled = errors.New("account deletion is not scheduled")
+	ErrExportNotFound       = errors.New("export not found")
+)
@@ -0,0 +1,217 @@
+package accountrepo
+
+import (
+	"astral/internal/domain/account"
+	pg "astral/internal/repository/db/postgres"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+const (
+	TABLE_TAKEOUT_EXPORTS = "takeout_exports"
+
+	// Время, на которое задание скрывается от других воркеров после выборки. Если воркер упал,
+	// не закончив архив, задание выбирается заново по истечении аренды
+	EXPORT_LEASE = "INTERVAL '30 minutes'"
+)
+
+var exportColumns = []any{"id", "user_login", "status", "size", "error", "created_at", "completed_at", "expires_at"}
+
+func (p *AccountPersister) CreateExport(ctx context.Context, login string) (*account.Export, error) {
+	const op = "repository.account.exports.CreateExport"
+
+	query, _, err := p.dial.Insert(TABLE_TAKEOUT_EXPORTS).
+		Rows(
+			goqu.Record{
+				"user_login": login,
+				"status":     string(account.ExportPending),
+			},
+		).Returning(exportColumns...).ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build create export query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to build create export query")
+	}
+
+	export, err := scanExport(p.storage.DB.QueryRowContext(ctx, query))
+	if err != nil {
+		p.logger.Error("failed to execute create export query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to execute create export query")
+	}
+
+	return export, nil
+}
+
+func (p *AccountPersister) GetExport(ctx context.Context, login, ID string) (*account.Export, error) {
+	const op = "repository.account.exports.GetExport"
+
+	query, _, err := p.dial.From(TABLE_TAKEOUT_EXPORTS).
+		Select(exportColumns...).
+		Where(goqu.C("id").Eq(ID), goqu.C("user_login").Eq(login)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get export query", "func", op, "login", login, "id", ID, "error", err)
+		return nil, errors.New("failed to build get export query")
+	}
+
+	export, err := scanExport(p.storage.DB.QueryRowContext(ctx, query))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || pg.IsInvalidTextRepresentation(err) {
+			return nil, ErrExportNotFound
+		}
+
+		p.logger.Error("failed to execute get export query", "func", op, "login", login, "id", ID, "error", err)
+		return nil, errors.New("failed to execute get export query")
+	}
+
+	return export, nil
+}
+
+func (p *AccountPersister) ClaimExport(ctx context.Context) (*account.Export, error) {
+	const op = "repository.account.exports.ClaimExport"
+
+	due := p.dial.From(TABLE_TAKEOUT_EXPORTS).
+		Select("id").
+		Where(goqu.Or(
+			goqu.C("status").Eq(string(account.ExportPending)),
+			goqu.And(
+				goqu.C("status").Eq(string(account.ExportRunning)),
+				goqu.C("lease_until").Lte(goqu.L("CURRENT_TIMESTAMP")),
+			),
+		)).
+		Order(goqu.C("created_at").Asc()).
+		Limit(1).
+		ForUpdate(exp.SkipLocked)
+
+	query, _, err := p.dial.Update(TABLE_TAKEOUT_EXPORTS).
+		Set(goqu.Record{
+			"status":      string(account.ExportRunning),
+			"lease_until": goqu.L("CURRENT_TIMESTAMP + " + EXPORT_LEASE),
+		}).
+		Where(goqu.C("id").In(due)).
+		Returning(exportColumns...).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build claim export query", "func", op, "error", err)
+		return nil, errors.New("failed to build claim export query")
+	}
+
+	export, err := scanExport(p.storage.DB.QueryRowContext(ctx, query))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		p.logger.Error("failed to execute claim export query", "func", op, "error", err)
+		return nil, errors.New("failed to execute claim export query")
+	}
+
+	return export, nil
+}
+
+func (p *AccountPersister) CompleteExport(ctx context.Context, ID string, size int64, expiresAt time.Time) error {
+	const op = "repository.account.exports.CompleteExport"
+
+	return p.updateExport(ctx, op, ID, goqu.Record{
+		"status":       string(account.ExportReady),
+		"size":         size,
+		"lease_until":  nil,
+		"completed_at": goqu.L("CURRENT_TIMESTAMP"),
+		"expires_at":   expiresAt.UTC(),
+	})
+}
+
+func (p *AccountPersister) FailExport(ctx context.Context, ID, reason string) error {
+	const op = "repository.account.exports.FailExport"
+
+	return p.updateExport(ctx, op, ID, goqu.Record{
+		"status":       string(account.ExportFailed),
+		"error":        reason,
+		"lease_until":  nil,
+		"completed_at": goqu.L("CURRENT_TIMESTAMP"),
+	})
+}
+
+func (p *AccountPersister) ExpireExport(ctx context.Context, ID string) error {
+	const op = "repository.account.exports.ExpireExport"
+
+	return p.updateExport(ctx, op, ID, goqu.Record{
+		"status": string(account.ExportExpired),
+	})
+}
+
+func (p *AccountPersister) ListExpiredExports(ctx context.Context) ([]account.Export, error) {
+	const op = "repository.account.exports.ListExpiredExports"
+
+	query, _, err := p.dial.From(TABLE_TAKEOUT_EXPORTS).
+		Select(exportColumns...).
+		Where(
+			goqu.C("status").Eq(string(account.ExportReady)),
+			goqu.C("expires_at").Lte(goqu.L("CURRENT_TIMESTAMP")),
+		).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build list expired exports query", "func", op, "error", err)
+		return nil, errors.New("failed to build list expired exports query")
+	}
+
+	rows, err := p.storage.DB.QueryContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute list expired exports query", "func", op, "error", err)
+		return nil, errors.New("failed to execute list expired exports query")
+	}
+	defer rows.Close()
+
+	var exports []account.Export
+	for rows.Next() {
+		export, err := scanExport(rows)
+		if err != nil {
+			p.logger.Error("failed to scan export", "func", op, "error", err)
+			return nil, errors.New("failed to scan export")
+		}
+		exports = append(exports, *export)
+	}
+
+	return exports, nil
+}
+
+func (p *AccountPersister) updateExport(ctx context.Context, op, ID string, record goqu.Record) error {
+	query, _, err := p.dial.Update(TABLE_TAKEOUT_EXPORTS).
+		Set(record).
+		Where(goqu.C("id").Eq(ID)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build update export query", "func", op, "id", ID, "error", err)
+		return errors.New("failed to build update export query")
+	}
+
+	if _, err := p.storage.DB.ExecContext(ctx, query); err != nil {
+		p.logger.Error("failed to execute update export query", "func", op, "id", ID, "error", err)
+		return errors.New("failed to execute update export query")
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanExport(row scanner) (*account.Export, error) {
+	var export account.Export
+	var status string
+	var reason sql.NullString
+	err := row.Scan(&export.ID, &export.Login, &status, &export.Size, &reason, &export.CreatedAt, &export.CompletedAt, &export.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	export.Status = account.ExportStatus(status)
+	export.Error = reason.String
+
+	return &export, nil
+}
@@ -0,0 +1,111 @@
+package accountrepo
+
+import (
+	"astral/internal/domain/account"
+	"astral/internal/domain/replication"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	// Архивы выгрузок лежат отдельно от документов: _takeout/<логин>/<ID выгрузки>.zip
+	TAKEOUT_PREFIX = "_takeout"
+
+	EXPORT_CONTENT_TYPE = "application/zip"
+)
+
+// ExportKey возвращает ключ объекта с архивом выгрузки
+func ExportKey(export account.Export) string {
+	return fmt.Sprintf("%s/%s/%s.zip", TAKEOUT_PREFIX, export.Login, export.ID)
+}
+
+// PutExportObject сохраняет архив выгрузки. Архив временный, поэтому в реплику не копируется
+func (p *AccountPersister) PutExportObject(ctx context.Context, export account.Export, content io.Reader, size int64) error {
+	const op = "repository.account.objects.PutExportObject"
+
+	key := ExportKey(export)
+	_, err := p.objects.Client.PutObject(ctx, p.objects.BucketName, key, content, size, minio.PutObjectOptions{
+		ContentType: EXPORT_CONTENT_TYPE,
+	})
+	if err != nil {
+		p.logger.Error("failed to upload export", "func", op, "key", key, "error", err)
+		return errors.New("failed to upload export")
+	}
+
+	return nil
+}
+
+func (p *AccountPersister) OpenExportObject(ctx context.Context, export account.Export) (io.ReadCloser, error) {
+	const op = "repository.account.objects.OpenExportObject"
+
+	key := ExportKey(export)
+	obj, err := p.objects.Client.GetObject(ctx, p.objects.BucketName, key, minio.GetObjectOptions{})
+	if err != nil {
+		p.logger.Error("failed to get export", "func", op, "key", key, "error", err)
+		return nil, errors.New("failed to get export")
+	}
+
+	// GetObject ленивый, отсутствие объекта видно только после Stat
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrExportNotFound
+		}
+
+		p.logger.Error("failed to stat export", "func", op, "key", key, "error", err)
+		return nil, errors.New("failed to get export")
+	}
+
+	return obj, nil
+}
+
+func (p *AccountPersister) RemoveExportObject(ctx context.Context, export account.Export) error {
+	const op = "repository.account.objects.RemoveExportObject"
+
+	key := ExportKey(export)
+	err := p.objects.Client.RemoveObject(ctx, p.objects.BucketName, key, minio.RemoveObjectOptions{})
+	if err != nil && minio.ToErrorResponse(err).Code != "NoSuchKey" {
+		p.logger.Error("failed to remove export", "func", op, "key", key, "error", err)
+		return errors.New("failed to remove export")
+	}
+
+	return nil
+}
+
+// RemoveObjects удаляет объекты с префиксом из основного хранилища и ставит их удаление
+// в очередь репликации
+func (p *AccountPersister) RemoveObjects(ctx context.Context, prefix string) ([]string, error) {
+	const op = "repository.account.objects.RemoveObjects"
+
+	objectCh := p.objects.Client.ListObjects(ctx, p.objects.BucketName, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	})
+
+	var keys []string
+	for objInfo := range objectCh {
+		if objInfo.Err != nil {
+			p.logger.Error("error listing objects", "func", op, "prefix", prefix, "error", objInfo.Err)
+			return keys, errors.New("error listing objects")
+		}
+
+		err := p.objects.Client.RemoveObject(ctx, p.objects.BucketName, objInfo.Key, minio.RemoveObjectOptions{})
+		if err != nil && minio.ToErrorResponse(err).Code != "NoSuchKey" {
+			p.logger.Error("failed to remove object", "func", op, "key", objInfo.Key, "error", err)
+			return keys, errors.New("failed to remove object")
+		}
+		keys = append(keys, objInfo.Key)
+
+		if p.queue != nil {
+			if err := p.queue.Enqueue(ctx, replication.OperationDelete, objInfo.Key); err != nil {
+				p.logger.Error("failed to enqueue replication task", "func", op, "key", objInfo.Key, "error", err)
+			}
+		}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,283 @@
+package accountrepo
+
+import (
+	"astral/internal/domain/account"
+	"astral/internal/domain/event"
+	authrepo "astral/internal/repository/auth"
+	commentsrepo "astral/internal/repository/comments"
+	miniostorage "astral/internal/repository/db/minio"
+	pg "astral/internal/repository/db/postgres"
+	outboxrepo "astral/internal/repository/outbox"
+	replicationrepo "astral/internal/repository/replication"
+	s3repo "astral/internal/repository/s3"
+	tagsrepo "astral/internal/repository/tags"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
+)
+
+type AccountPersister struct {
+	dial    goqu.DialectWrapper
+	storage *pg.Storage
+	objects miniostorage.MinioStorage
+	queue   replicationrepo.QueueRepo
+	cache   *redis.Client
+	logger  *slog.Logger
+}
+
+// queue может быть nil, если репликация выключена
+func NewAccountPersister(
+	storage *pg.Storage,
+	objects miniostorage.MinioStorage,
+	queue replicationrepo.QueueRepo,
+	cache *redis.Client,
+	logger *slog.Logger,
+) *AccountPersister {
+	return &AccountPersister{
+		dial:    goqu.Dialect(pg.DRIVER),
+		storage: storage,
+		objects: objects,
+		queue:   queue,
+		cache:   cache,
+		logger:  logger,
+	}
+}
+
+func (p *AccountPersister) ScheduleDeletion(ctx context.Context, login string, deleteAfter time.Time, events ...event.Event) (*account.Deletion, error) {
+	const op = "repository.account.persister.ScheduleDeletion"
+
+	selectQuery, _, err := p.dial.From(authrepo.TABLE_USERS).
+		Select("delete_after").
+		Where(goqu.C("login").Eq(login)).
+		ForUpdate(exp.Wait).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build select user query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to build schedule deletion query")
+	}
+
+	updateQuery, _, err := p.dial.Update(authrepo.TABLE_USERS).
+		Set(goqu.Record{
+			"deletion_requested_at": goqu.L("CURRENT_TIMESTAMP"),
+			"delete_after":          deleteAfter.UTC(),
+		}).
+		Where(goqu.C("login").Eq(login)).
+		Returning("login", "deletion_requested_at", "delete_after").
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build schedule deletion query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to build schedule deletion query")
+	}
+
+	revokeQueries, err := p.revokeQueries(login)
+	if err != nil {
+		p.logger.Error("failed to build revoke queries", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to build schedule deletion query")
+	}
+
+	var deletion account.Deletion
+	err = p.storage.WithTx(ctx, func(tx *sqlx.Tx) error {
+		var scheduled sql.NullTime
+		if err := tx.QueryRowContext(ctx, selectQuery).Scan(&scheduled); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		if scheduled.Valid {
+			return ErrDeletionScheduled
+		}
+
+		err := tx.QueryRowContext(ctx, updateQuery).Scan(&deletion.Login, &deletion.RequestedAt, &deletion.DeleteAfter)
+		if err != nil {
+			return err
+		}
+
+		for _, query := range revokeQueries {
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				return err
+			}
+		}
+
+		return outboxrepo.Write(ctx, tx, events...)
+	})
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrDeletionScheduled) {
+			p.logger.Info("failed to schedule deletion", "func", op, "login", login, "error", err)
+			return nil, err
+		}
+
+		p.logger.Error("failed to execute schedule deletion query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to execute schedule deletion query")
+	}
+
+	return &deletion, nil
+}
+
+// revokeQueries закрывает все сессии пользователя. Токены помечаются удаленными, как при выходе,
+// пароли приложений и ключи доступа удаляются
+func (p *AccountPersister) revokeQueries(login string) ([]string, error) {
+	tokens, _, err := p.dial.Update(authrepo.TABLE_TOKENS).
+		Set(goqu.Record{"deleted_at": goqu.L("CURRENT_TIMESTAMP")}).
+		Where(goqu.C("user_login").Eq(login), goqu.C("deleted_at").IsNull()).
+		ToSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	queries := []string{tokens}
+	for _, table := range []string{authrepo.TABLE_APP_PASSWORDS, authrepo.TABLE_ACCESS_KEYS} {
+		query, _, err := p.dial.Delete(table).
+			Where(goqu.C("user_login").Eq(login)).
+			ToSQL()
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, query)
+	}
+
+	return queries, nil
+}
+
+func (p *AccountPersister) CancelDeletion(ctx context.Context, login string, events ...event.Event) error {
+	const op = "repository.account.persister.CancelDeletion"
+
+	query, _, err := p.dial.Update(authrepo.TABLE_USERS).
+		Set(goqu.Record{
+			"deletion_requested_at": nil,
+			"delete_after":          nil,
+		}).
+		Where(goqu.C("login").Eq(login), goqu.C("delete_after").IsNotNull()).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build cancel deletion query", "func", op, "login", login, "error", err)
+		return errors.New("failed to build cancel deletion query")
+	}
+
+	err = p.storage.WithTx(ctx, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrDeletionNotScheduled
+		}
+
+		return outboxrepo.Write(ctx, tx, events...)
+	})
+	if err != nil {
+		if errors.Is(err, ErrDeletionNotScheduled) {
+			return ErrDeletionNotScheduled
+		}
+
+		p.logger.Error("failed to execute cancel deletion query", "func", op, "login", login, "error", err)
+		return errors.New("failed to execute cancel deletion query")
+	}
+
+	return nil
+}
+
+func (p *AccountPersister) ListDueDeletions(ctx context.Context) ([]string, error) {
+	const op = "repository.account.persister.ListDueDeletions"
+
+	query, _, err := p.dial.From(authrepo.TABLE_USERS).
+		Select("login").
+		Where(goqu.C("delete_after").Lte(goqu.L("CURRENT_TIMESTAMP"))).
+		Order(goqu.C("delete_after").Asc()).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build due deletions query", "func", op, "error", err)
+		return nil, errors.New("failed to build due deletions query")
+	}
+
+	var logins []string
+	if err := p.storage.DB.SelectContext(ctx, &logins, query); err != nil {
+		p.logger.Error("failed to execute due deletions query", "func", op, "error", err)
+		return nil, errors.New("failed to execute due deletions query")
+	}
+
+	return logins, nil
+}
+
+func (p *AccountPersister) DeleteUser(ctx context.Context, login string, documentIDs []string, events ...event.Event) error {
+	const op = "repository.account.persister.DeleteUser"
+
+	comments, _, err := p.dial.Delete(commentsrepo.TABLE_COMMENTS).
+		Where(goqu.C("document_owner").Eq(login)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build delete comments query", "func", op, "login", login, "error", err)
+		return errors.New("failed to build delete user query")
+	}
+
+	queries := []string{comments}
+	if len(documentIDs) > 0 {
+		tags, _, err := p.dial.Delete(tagsrepo.TABLE_DOCUMENT_TAGS).
+			Where(goqu.C("document_id").In(documentIDs)).
+			ToSQL()
+		if err != nil {
+			p.logger.Error("failed to build delete document tags query", "func", op, "login", login, "error", err)
+			return errors.New("failed to build delete user query")
+		}
+		queries = append(queries, tags)
+	}
+
+	// Остальные строки пользователя удаляются каскадом
+	user, _, err := p.dial.Delete(authrepo.TABLE_USERS).
+		Where(goqu.C("login").Eq(login)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build delete user query", "func", op, "login", login, "error", err)
+		return errors.New("failed to build delete user query")
+	}
+	queries = append(queries, user)
+
+	err = p.storage.WithTx(ctx, func(tx *sqlx.Tx) error {
+		for _, query := range queries {
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				return err
+			}
+		}
+
+		return outboxrepo.Write(ctx, tx, events...)
+	})
+	if err != nil {
+		p.logger.Error("failed to execute delete user query", "func", op, "login", login, "error", err)
+		return errors.New("failed to execute delete user query")
+	}
+
+	return nil
+}
+
+func (p *AccountPersister) ListMultipartUploads(ctx context.Context, login string) ([]string, error) {
+	const op = "repository.account.persister.ListMultipartUploads"
+
+	query, _, err := p.dial.From(s3repo.TABLE_MULTIPART_UPLOADS).
+		Select("upload_id").
+		Where(goqu.C("user_login").Eq(login)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build list multipart uploads query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to build list multipart uploads query")
+	}
+
+	var uploads []string
+	if err := p.storage.DB.SelectContext(ctx, &uploads, query); err != nil {
+		p.logger.Error("failed to execute list multipart uploads query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to execute list multipart uploads query")
+	}
+
+	return uploads, nil
+}
@@ -0,0 +1,40 @@
+package accountrepo
+
+import (
+	"astral/internal/domain/account"
+	"astral/internal/domain/event"
+	"context"
+	"io"
+	"time"
+)
+
+type AccountRepo interface {
+	// ScheduleDeletion назначает удаление и в той же транзакции отзывает все токены, пароли
+	// приложений и ключи доступа пользователя
+	ScheduleDeletion(ctx context.Context, login string, deleteAfter time.Time, events ...event.Event) (*account.Deletion, error)
+	CancelDeletion(ctx context.Context, login string, events ...event.Event) error
+	ListDueDeletions(ctx context.Context) ([]string, error)
+	// DeleteUser удаляет строку пользователя и строки, которые не удаляются каскадом: комментарии
+	// и теги к документам пользователя
+	DeleteUser(ctx context.Context, login string, documentIDs []string, events ...event.Event) error
+
+	ListSessions(ctx context.Context, login string) ([]account.Session, error)
+	ListMultipartUploads(ctx context.Context, login string) ([]string, error)
+
+	CreateExport(ctx context.Context, login string) (*account.Export, error)
+	GetExport(ctx context.Context, login, ID string) (*account.Export, error)
+	// ClaimExport забирает следующее задание на выгрузку, nil - заданий нет
+	ClaimExport(ctx context.Context) (*account.Export, error)
+	CompleteExport(ctx context.Context, ID string, size int64, expiresAt time.Time) error
+	FailExport(ctx context.Context, ID, reason string) error
+	ListExpiredExports(ctx context.Context) ([]account.Export, error)
+	ExpireExport(ctx context.Context, ID string) error
+
+	PutExportObject(ctx context.Context, export account.Export, content io.Reader, size int64) error
+	OpenExportObject(ctx context.Context, export account.Export) (io.ReadCloser, error)
+	RemoveExportObject(ctx context.Context, export account.Export) error
+	// RemoveObjects удаляет все объекты с префиксом и возвращает их ключи
+	RemoveObjects(ctx context.Context, prefix string) ([]string, error)
+
+	DeleteCacheKeys(ctx context.Context, login string, documentIDs []string) error
+}
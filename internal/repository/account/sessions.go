@@ -0,0 +1,54 @@
+package accountrepo
+
+import (
+	"astral/internal/domain/account"
+	authrepo "astral/internal/repository/auth"
+	"context"
+	"errors"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+// ListSessions возвращает действующие токены, пароли приложений и ключи доступа пользователя.
+// ID токенов и ключей возвращаются как есть, маскировать их должен вызывающий
+func (p *AccountPersister) ListSessions(ctx context.Context, login string) ([]account.Session, error) {
+	const op = "repository.account.sessions.ListSessions"
+
+	tokens := p.dial.From(authrepo.TABLE_TOKENS).
+		Select(goqu.V(string(account.SessionToken)).As("kind"), goqu.C("token").As("id"), goqu.V("").As("name"), goqu.C("created_at")).
+		Where(goqu.C("user_login").Eq(login), goqu.C("deleted_at").IsNull())
+	appPasswords := p.dial.From(authrepo.TABLE_APP_PASSWORDS).
+		Select(goqu.V(string(account.SessionAppPassword)).As("kind"), goqu.L("id::text").As("id"), goqu.C("name"), goqu.C("created_at")).
+		Where(goqu.C("user_login").Eq(login))
+	accessKeys := p.dial.From(authrepo.TABLE_ACCESS_KEYS).
+		Select(goqu.V(string(account.SessionAccessKey)).As("kind"), goqu.C("access_key_id").As("id"), goqu.V("").As("name"), goqu.C("created_at")).
+		Where(goqu.C("user_login").Eq(login))
+
+	query, _, err := tokens.UnionAll(appPasswords).UnionAll(accessKeys).ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build list sessions query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to build list sessions query")
+	}
+
+	rows, err := p.storage.DB.QueryContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute list sessions query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to execute list sessions query")
+	}
+	defer rows.Close()
+
+	sessions := []account.Session{}
+	for rows.Next() {
+		var session account.Session
+		var kind string
+		if err := rows.Scan(&kind, &session.ID, &session.Name, &session.CreatedAt); err != nil {
+			p.logger.Error("failed to scan session", "func", op, "login", login, "error", err)
+			return nil, errors.New("failed to scan session")
+		}
+
+		session.Kind = account.SessionKind(kind)
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
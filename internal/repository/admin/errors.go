@@ -0,0 +1,7 @@
+package adminrepo
+
+import "errors"
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+)
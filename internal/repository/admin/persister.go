@@ -0,0 +1,293 @@
+package adminrepo
+
+import (
+	"astral/internal/domain/admin"
+	"astral/internal/domain/event"
+	authrepo "astral/internal/repository/auth"
+	miniostorage "astral/internal/repository/db/minio"
+	pg "astral/internal/repository/db/postgres"
+	outboxrepo "astral/internal/repository/outbox"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jmoiron/sqlx"
+)
+
+type AdminPersister struct {
+	dial    goqu.DialectWrapper
+	storage *pg.Storage
+	objects miniostorage.MinioStorage
+	logger  *slog.Logger
+}
+
+func NewAdminPersister(storage *pg.Storage, objects miniostorage.MinioStorage, logger *slog.Logger) *AdminPersister {
+	return &AdminPersister{
+		dial:    goqu.Dialect(pg.DRIVER),
+		storage: storage,
+		objects: objects,
+		logger:  logger,
+	}
+}
+
+func (p *AdminPersister) ListUsers(ctx context.Context, filter admin.UserFilter) ([]admin.User, error) {
+	const op = "repository.admin.persister.ListUsers"
+
+	ds := p.users()
+	if filter.Query != "" {
+		ds = ds.Where(goqu.I("u.login").ILike("%" + escapeLike(filter.Query) + "%"))
+	}
+
+	switch filter.Status {
+	case admin.StatusActive:
+		ds = ds.Where(goqu.I("u.disabled_at").IsNull(), goqu.I("u.delete_after").IsNull())
+	case admin.StatusDisabled:
+		ds = ds.Where(goqu.I("u.disabled_at").IsNotNull(), goqu.I("u.delete_after").IsNull())
+	case admin.StatusPendingDeletion:
+		ds = ds.Where(goqu.I("u.delete_after").IsNotNull())
+	}
+
+	query, _, err := ds.
+		Order(goqu.I("u.login").Asc()).
+		Limit(uint(filter.Limit)).
+		Offset(uint(filter.Offset)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build list users query", "func", op, "error", err)
+		return nil, errors.New("failed to build list users query")
+	}
+
+	rows, err := p.storage.DB.QueryContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute list users query", "func", op, "error", err)
+		return nil, errors.New("failed to execute list users query")
+	}
+	defer rows.Close()
+
+	users := []admin.User{}
+	for rows.Next() {
+		usr, err := scanUser(rows)
+		if err != nil {
+			p.logger.Error("failed to scan user", "func", op, "error", err)
+			return nil, errors.New("failed to scan user")
+		}
+		users = append(users, *usr)
+	}
+
+	return users, nil
+}
+
+func (p *AdminPersister) GetUser(ctx context.Context, login string) (*admin.User, error) {
+	const op = "repository.admin.persister.GetUser"
+
+	query, _, err := p.users().
+		Where(goqu.I("u.login").Eq(login)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get user query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to build get user query")
+	}
+
+	usr, err := scanUser(p.storage.DB.QueryRowContext(ctx, query))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+
+		p.logger.Error("failed to execute get user query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to execute get user query")
+	}
+
+	return usr, nil
+}
+
+func (p *AdminPersister) SetDisabled(ctx context.Context, login string, disabled bool, reason string, events ...event.Event) error {
+	const op = "repository.admin.persister.SetDisabled"
+
+	record := goqu.Record{"disabled_at": nil, "disabled_reason": nil}
+	if disabled {
+		record = goqu.Record{"disabled_at": goqu.L("CURRENT_TIMESTAMP"), "disabled_reason": reason}
+	}
+
+	query, _, err := p.dial.Update(authrepo.TABLE_USERS).
+		Set(record).
+		Where(goqu.C("login").Eq(login)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build set disabled query", "func", op, "login", login, "error", err)
+		return errors.New("failed to build set disabled query")
+	}
+
+	queries := []string{query}
+	if disabled {
+		revoke, err := p.revokeQuery(login)
+		if err != nil {
+			p.logger.Error("failed to build revoke sessions query", "func", op, "login", login, "error", err)
+			return errors.New("failed to build set disabled query")
+		}
+		queries = append(queries, revoke)
+	}
+
+	if err := p.updateUser(ctx, queries, events); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+
+		p.logger.Error("failed to execute set disabled query", "func", op, "login", login, "error", err)
+		return errors.New("failed to execute set disabled query")
+	}
+
+	return nil
+}
+
+func (p *AdminPersister) RevokeSessions(ctx context.Context, login string, events ...event.Event) (int64, error) {
+	const op = "repository.admin.persister.RevokeSessions"
+
+	query, err := p.revokeQuery(login)
+	if err != nil {
+		p.logger.Error("failed to build revoke sessions query", "func", op, "login", login, "error", err)
+		return 0, errors.New("failed to build revoke sessions query")
+	}
+
+	var revoked int64
+	err = p.storage.WithTx(ctx, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		if revoked, err = res.RowsAffected(); err != nil {
+			return err
+		}
+
+		return outboxrepo.Write(ctx, tx, events...)
+	})
+	if err != nil {
+		p.logger.Error("failed to execute revoke sessions query", "func", op, "login", login, "error", err)
+		return 0, errors.New("failed to execute revoke sessions query")
+	}
+
+	return revoked, nil
+}
+
+func (p *AdminPersister) SetPassword(ctx context.Context, login, hash string, events ...event.Event) error {
+	const op = "repository.admin.persister.SetPassword"
+
+	query, _, err := p.dial.Update(authrepo.TABLE_USERS).
+		Set(goqu.Record{
+			"password":   hash,
+			"updated_at": goqu.L("CURRENT_TIMESTAMP"),
+		}).
+		Where(goqu.C("login").Eq(login)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build set password query", "func", op, "login", login, "error", err)
+		return errors.New("failed to build set password query")
+	}
+
+	revoke, err := p.revokeQuery(login)
+	if err != nil {
+		p.logger.Error("failed to build revoke sessions query", "func", op, "login", login, "error", err)
+		return errors.New("failed to build set password query")
+	}
+
+	if err := p.updateUser(ctx, []string{query, revoke}, events); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+
+		p.logger.Error("failed to execute set password query", "func", op, "login", login, "error", err)
+		return errors.New("failed to execute set password query")
+	}
+
+	return nil
+}
+
+// updateUser выполняет запросы в одной транзакции, первый из них - изменение строки users.
+// Если пользователя нет, возвращается ErrUserNotFound
+func (p *AdminPersister) updateUser(ctx context.Context, queries []string, events []event.Event) error {
+	return p.storage.WithTx(ctx, func(tx *sqlx.Tx) error {
+		for i, query := range queries {
+			res, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			if i == 0 {
+				affected, err := res.RowsAffected()
+				if err != nil {
+					return err
+				}
+				if affected == 0 {
+					return ErrUserNotFound
+				}
+			}
+		}
+
+		return outboxrepo.Write(ctx, tx, events...)
+	})
+}
+
+// revokeQuery помечает удаленными все действующие токены пользователя, как при выходе
+func (p *AdminPersister) revokeQuery(login string) (string, error) {
+	query, _, err := p.dial.Update(authrepo.TABLE_TOKENS).
+		Set(goqu.Record{"deleted_at": goqu.L("CURRENT_TIMESTAMP")}).
+		Where(goqu.C("user_login").Eq(login), goqu.C("deleted_at").IsNull()).
+		ToSQL()
+
+	return query, err
+}
+
+// users выбирает учетные записи вместе с числом действующих токенов
+func (p *AdminPersister) users() *goqu.SelectDataset {
+	sessions := p.dial.From(authrepo.TABLE_TOKENS).
+		Select(goqu.COUNT("*")).
+		Where(
+			goqu.I(authrepo.TABLE_TOKENS+".user_login").Eq(goqu.I("u.login")),
+			goqu.I(authrepo.TABLE_TOKENS+".deleted_at").IsNull(),
+		)
+
+	return p.dial.From(goqu.T(authrepo.TABLE_USERS).As("u")).
+		Select(
+			goqu.I("u.login"),
+			goqu.I("u.created_at"),
+			goqu.I("u.updated_at"),
+			goqu.I("u.disabled_at"),
+			goqu.I("u.disabled_reason"),
+			goqu.I("u.delete_after"),
+			sessions.As("sessions"),
+		)
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row scanner) (*admin.User, error) {
+	var usr admin.User
+	var reason sql.NullString
+	err := row.Scan(&usr.Login, &usr.CreatedAt, &usr.UpdatedAt, &usr.DisabledAt, &reason, &usr.DeleteAfter, &usr.Sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	usr.DisabledReason = reason.String
+	switch {
+	case usr.DeleteAfter != nil:
+		usr.Status = admin.StatusPendingDeletion
+	case usr.DisabledAt != nil:
+		usr.Status = admin.StatusDisabled
+	default:
+		usr.Status = admin.StatusActive
+	}
+
+	return &usr, nil
+}
+
+// escapeLike экранирует символы шаблона LIKE, чтобы запрос искал подстроку как есть
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,21 @@
+package adminrepo
+
+import (
+	"astral/internal/domain/admin"
+	"astral/internal/domain/event"
+	"context"
+)
+
+type AdminRepo interface {
+	ListUsers(ctx context.Context, filter admin.UserFilter) ([]admin.User, error)
+	GetUser(ctx context.Context, login string) (*admin.User, error)
+	// SetDisabled блокирует учетную запись с причиной reason и закрывает все ее сессии или, при
+	// disabled = false, снимает блокировку
+	SetDisabled(ctx context.Context, login string, disabled bool, reason string, events ...event.Event) error
+	// RevokeSessions закрывает все сессии пользователя и возвращает их число
+	RevokeSessions(ctx context.Context, login string, events ...event.Event) (int64, error)
+	// SetPassword меняет хэш пароля и закрывает все сессии пользователя
+	SetPassword(ctx context.Context, login, hash string, events ...event.Event) error
+
+	Usage(ctx context.Context, login string) (*admin.Usage, error)
+}
@@ -0,0 +1,48 @@
+package adminrepo
+
+import (
+	"astral/internal/domain/admin"
+	filesrepo "astral/internal/repository/files"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// Usage считает объекты пользователя в хранилище. Исходный размер берется из метаданных
+// сжатого объекта, для несжатого он совпадает с размером объекта
+func (p *AdminPersister) Usage(ctx context.Context, login string) (*admin.Usage, error) {
+	const op = "repository.admin.usage.Usage"
+
+	objectCh := p.objects.Client.ListObjects(ctx, p.objects.BucketName, minio.ListObjectsOptions{
+		Prefix:       login + "/",
+		Recursive:    true,
+		WithMetadata: true,
+	})
+
+	usage := &admin.Usage{}
+	for objInfo := range objectCh {
+		if objInfo.Err != nil {
+			p.logger.Error("error listing objects", "func", op, "login", login, "error", objInfo.Err)
+			return nil, errors.New("error listing objects")
+		}
+
+		size := objInfo.Size
+		for k, v := range objInfo.UserMetadata {
+			if strings.TrimPrefix(strings.ToLower(k), "x-amz-meta-") != filesrepo.META_SIZE {
+				continue
+			}
+			if original, err := strconv.ParseInt(v, 10, 64); err == nil {
+				size = original
+			}
+		}
+
+		usage.Documents++
+		usage.Bytes += size
+		usage.StoredBytes += objInfo.Size
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,185 @@
+package auditrepo
+
+import (
+	"astral/internal/domain/audit"
+	pg "astral/internal/repository/db/postgres"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+const (
+	TABLE_AUDIT_EVENTS = "audit_events"
+)
+
+type AuditPersister struct {
+	dial    goqu.DialectWrapper
+	storage *pg.Storage
+	logger  *slog.Logger
+}
+
+func NewAuditPersister(storage *pg.Storage, logger *slog.Logger) *AuditPersister {
+	return &AuditPersister{
+		dial:    goqu.Dialect(pg.DRIVER),
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (p *AuditPersister) Append(ctx context.Context, event audit.Event) error {
+	const op = "repository.audit.persister.Append"
+
+	details, err := json.Marshal(event.Details)
+	if err != nil || event.Details == nil {
+		details = []byte("{}")
+	}
+
+	query, _, err := p.dial.Insert(TABLE_AUDIT_EVENTS).
+		Rows(
+			goqu.Record{
+				"action":      string(event.Action),
+				"actor":       nullString(event.Actor),
+				"owner":       nullString(event.Owner),
+				"target_type": nullString(string(event.TargetType)),
+				"target_id":   nullString(event.TargetID),
+				"ip":          nullString(event.IP),
+				"user_agent":  nullString(event.UserAgent),
+				"details":     string(details),
+			},
+		).ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build append audit event query", "func", op, "action", event.Action, "error", err)
+		return errors.New("failed to build append audit event query")
+	}
+
+	_, err = p.storage.DB.ExecContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute append audit event query", "func", op, "action", event.Action, "error", err)
+		return errors.New("failed to execute append audit event query")
+	}
+
+	return nil
+}
+
+func (p *AuditPersister) Query(ctx context.Context, filter audit.Filter) ([]audit.Event, error) {
+	var events []audit.Event
+	err := p.Stream(ctx, filter, func(event audit.Event) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// Stream читает события курсором от новых к старым и передает их по одному, не загружая выборку в память
+func (p *AuditPersister) Stream(ctx context.Context, filter audit.Filter, fn func(audit.Event) error) error {
+	const op = "repository.audit.persister.Stream"
+
+	ds := p.dial.From(TABLE_AUDIT_EVENTS).
+		Select("id", "action", "actor", "owner", "target_type", "target_id", "ip", "user_agent", "details", "created_at").
+		Where(filterExpressions(filter)...).
+		Order(goqu.C("id").Desc())
+	if filter.Limit > 0 {
+		ds = ds.Limit(uint(filter.Limit))
+	}
+
+	query, _, err := ds.ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build query audit events query", "func", op, "error", err)
+		return errors.New("failed to build query audit events query")
+	}
+
+	rows, err := p.storage.DB.QueryContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute query audit events query", "func", op, "error", err)
+		return errors.New("failed to execute query audit events query")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event audit.Event
+		var action string
+		var actor, owner, targetType, targetID, ip, userAgent sql.NullString
+		var details []byte
+
+		err := rows.Scan(&event.ID, &action, &actor, &owner, &targetType, &targetID, &ip, &userAgent, &details, &event.CreatedAt)
+		if err != nil {
+			p.logger.Error("failed to scan audit event", "func", op, "error", err)
+			return errors.New("failed to scan audit event")
+		}
+
+		event.Action = audit.Action(action)
+		event.Actor = actor.String
+		event.Owner = owner.String
+		event.TargetType = audit.TargetType(targetType.String)
+		event.TargetID = targetID.String
+		event.IP = ip.String
+		event.UserAgent = userAgent.String
+		if err := json.Unmarshal(details, &event.Details); err != nil {
+			p.logger.Warn("failed to unmarshal audit event details", "func", op, "id", event.ID, "error", err)
+		}
+		if len(event.Details) == 0 {
+			event.Details = nil
+		}
+
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		p.logger.Error("failed to read audit events", "func", op, "error", err)
+		return errors.New("failed to read audit events")
+	}
+
+	return nil
+}
+
+func filterExpressions(filter audit.Filter) []goqu.Expression {
+	var where []goqu.Expression
+
+	if filter.Subject != "" {
+		where = append(where, goqu.Or(
+			goqu.C("actor").Eq(filter.Subject),
+			goqu.C("owner").Eq(filter.Subject),
+		))
+	}
+	if filter.Actor != "" {
+		where = append(where, goqu.C("actor").Eq(filter.Actor))
+	}
+	if filter.Owner != "" {
+		where = append(where, goqu.C("owner").Eq(filter.Owner))
+	}
+	if filter.Action != "" {
+		where = append(where, goqu.C("action").Eq(string(filter.Action)))
+	}
+	if filter.TargetID != "" {
+		where = append(where, goqu.C("target_id").Eq(filter.TargetID))
+	}
+	if filter.From != nil {
+		where = append(where, goqu.C("created_at").Gte(*filter.From))
+	}
+	if filter.To != nil {
+		where = append(where, goqu.C("created_at").Lt(*filter.To))
+	}
+	if filter.BeforeID > 0 {
+		where = append(where, goqu.C("id").Lt(filter.BeforeID))
+	}
+
+	return where
+}
+
+func nullString(value string) any {
+	if value == "" {
+		return nil
+	}
+
+	return value
+}
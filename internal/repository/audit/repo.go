@@ -0,0 +1,12 @@
+package auditrepo
+
+import (
+	"astral/internal/domain/audit"
+	"context"
+)
+
+type AuditRepo interface {
+	Append(ctx context.Context, event audit.Event) error
+	Query(ctx context.Context, filter audit.Filter) ([]audit.Event, error)
+	Stream(ctx context.Context, filter audit.Filter, fn func(audit.Event) error) error
+}
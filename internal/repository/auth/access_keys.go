@@ -0,0 +1,125 @@
+package authrepo
+
+import (
+	"astral/internal/domain/dto"
+	"astral/internal/domain/user"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+func (p *AuthPersister) CreateAccessKey(ctx context.Context, key dto.AccessKeyData) (*user.AccessKey, error) {
+	const op = "repository.user.persister.CreateAccessKey"
+
+	query, _, err := p.dial.Insert(TABLE_ACCESS_KEYS).
+		Rows(
+			goqu.Record{
+				"access_key_id": key.ID,
+				"user_login":    key.Login,
+				"secret_key":    key.EncryptedSecret,
+			},
+		).Returning("access_key_id", "user_login", "created_at").ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build access key creation query", "func", op, "login", key.Login, "error", err)
+		return nil, errors.New("failed to build access key creation query")
+	}
+
+	var accessKey user.AccessKey
+	err = p.storage.DB.QueryRowContext(ctx, query).Scan(&accessKey.ID, &accessKey.Login, &accessKey.CreatedAt)
+	if err != nil {
+		p.logger.Error("failed to execute create access key query", "func", op, "login", key.Login, "error", err)
+		return nil, errors.New("failed to execute create access key query")
+	}
+
+	return &accessKey, nil
+}
+
+func (p *AuthPersister) GetAccessKeysByLogin(ctx context.Context, login string) ([]user.AccessKey, error) {
+	const op = "repository.user.persister.GetAccessKeysByLogin"
+
+	query, _, err := p.dial.From(TABLE_ACCESS_KEYS).
+		Select("access_key_id", "user_login", "created_at").
+		Where(goqu.C("user_login").Eq(login)).
+		Order(goqu.C("created_at").Asc()).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get access keys query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to build get access keys query")
+	}
+
+	rows, err := p.storage.DB.QueryContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute get access keys query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to execute get access keys query")
+	}
+	defer rows.Close()
+
+	keys := []user.AccessKey{}
+	for rows.Next() {
+		var accessKey user.AccessKey
+		err = rows.Scan(&accessKey.ID, &accessKey.Login, &accessKey.CreatedAt)
+		if err != nil {
+			p.logger.Error("failed to scan access key", "func", op, "login", login, "error", err)
+			return nil, errors.New("failed to scan access key")
+		}
+
+		keys = append(keys, accessKey)
+	}
+
+	return keys, nil
+}
+
+func (p *AuthPersister) GetAccessKeyByID(ctx context.Context, id string) (*dto.AccessKeyData, error) {
+	const op = "repository.user.persister.GetAccessKeyByID"
+
+	query, _, err := p.dial.From(TABLE_ACCESS_KEYS).
+		Select("access_key_id", "user_login", "secret_key").
+		Where(goqu.C("access_key_id").Eq(id)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get access key query", "func", op, "id", id, "error", err)
+		return nil, errors.New("failed to build get access key query")
+	}
+
+	var key dto.AccessKeyData
+	err = p.storage.DB.QueryRowContext(ctx, query).Scan(&key.ID, &key.Login, &key.EncryptedSecret)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+
+		p.logger.Error("failed to execute get access key query", "func", op, "id", id, "error", err)
+		return nil, errors.New("failed to execute get access key query")
+	}
+
+	return &key, nil
+}
+
+func (p *AuthPersister) DeleteAccessKey(ctx context.Context, login, id string) (*user.AccessKey, error) {
+	const op = "repository.user.persister.DeleteAccessKey"
+
+	query, _, err := p.dial.Delete(TABLE_ACCESS_KEYS).
+		Where(goqu.C("access_key_id").Eq(id), goqu.C("user_login").Eq(login)).
+		Returning("access_key_id", "user_login", "created_at").
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build delete access key query", "func", op, "login", login, "id", id, "error", err)
+		return nil, errors.New("failed to build delete access key query")
+	}
+
+	var accessKey user.AccessKey
+	err = p.storage.DB.QueryRowContext(ctx, query).Scan(&accessKey.ID, &accessKey.Login, &accessKey.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			p.logger.Info("access key not found", "func", op, "login", login, "id", id)
+			return nil, ErrNoRows
+		}
+
+		p.logger.Error("failed to execute delete access key query", "func", op, "login", login, "id", id, "error", err)
+		return nil, errors.New("failed to execute delete access key query")
+	}
+
+	return &accessKey, nil
+}
@@ -0,0 +1,126 @@
+package authrepo
+
+import (
+	"astral/internal/domain/dto"
+	"astral/internal/domain/user"
+	pg "astral/internal/repository/db/postgres"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+func (p *AuthPersister) CreateAppPassword(ctx context.Context, password dto.AppPasswordData) (*user.AppPassword, error) {
+	const op = "repository.user.persister.CreateAppPassword"
+
+	query, _, err := p.dial.Insert(TABLE_APP_PASSWORDS).
+		Rows(
+			goqu.Record{
+				"user_login":    password.Login,
+				"name":          password.Name,
+				"password_hash": password.PasswordHash,
+			},
+		).Returning("id", "user_login", "name", "created_at").ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build app password creation query", "func", op, "login", password.Login, "error", err)
+		return nil, errors.New("failed to build app password creation query")
+	}
+
+	var appPassword user.AppPassword
+	err = p.storage.DB.QueryRowContext(ctx, query).Scan(&appPassword.ID, &appPassword.Login, &appPassword.Name, &appPassword.CreatedAt)
+	if err != nil {
+		p.logger.Error("failed to execute create app password query", "func", op, "login", password.Login, "error", err)
+		return nil, errors.New("failed to execute create app password query")
+	}
+
+	return &appPassword, nil
+}
+
+func (p *AuthPersister) GetAppPasswordsByLogin(ctx context.Context, login string) ([]user.AppPassword, error) {
+	const op = "repository.user.persister.GetAppPasswordsByLogin"
+
+	query, _, err := p.dial.From(TABLE_APP_PASSWORDS).
+		Select("id", "user_login", "name", "created_at").
+		Where(goqu.C("user_login").Eq(login)).
+		Order(goqu.C("created_at").Asc()).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get app passwords query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to build get app passwords query")
+	}
+
+	rows, err := p.storage.DB.QueryContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute get app passwords query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to execute get app passwords query")
+	}
+	defer rows.Close()
+
+	passwords := []user.AppPassword{}
+	for rows.Next() {
+		var appPassword user.AppPassword
+		err = rows.Scan(&appPassword.ID, &appPassword.Login, &appPassword.Name, &appPassword.CreatedAt)
+		if err != nil {
+			p.logger.Error("failed to scan app password", "func", op, "login", login, "error", err)
+			return nil, errors.New("failed to scan app password")
+		}
+
+		passwords = append(passwords, appPassword)
+	}
+
+	return passwords, nil
+}
+
+func (p *AuthPersister) GetAppPasswordByHash(ctx context.Context, hash string) (*user.AppPassword, error) {
+	const op = "repository.user.persister.GetAppPasswordByHash"
+
+	query, _, err := p.dial.From(TABLE_APP_PASSWORDS).
+		Select("id", "user_login", "name", "created_at").
+		Where(goqu.C("password_hash").Eq(hash)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get app password query", "func", op, "error", err)
+		return nil, errors.New("failed to build get app password query")
+	}
+
+	var appPassword user.AppPassword
+	err = p.storage.DB.QueryRowContext(ctx, query).Scan(&appPassword.ID, &appPassword.Login, &appPassword.Name, &appPassword.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRows
+		}
+
+		p.logger.Error("failed to execute get app password query", "func", op, "error", err)
+		return nil, errors.New("failed to execute get app password query")
+	}
+
+	return &appPassword, nil
+}
+
+func (p *AuthPersister) DeleteAppPassword(ctx context.Context, login, id string) (*user.AppPassword, error) {
+	const op = "repository.user.persister.DeleteAppPassword"
+
+	query, _, err := p.dial.Delete(TABLE_APP_PASSWORDS).
+		Where(goqu.C("id").Eq(id), goqu.C("user_login").Eq(login)).
+		Returning("id", "user_login", "name", "created_at").
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build delete app password query", "func", op, "login", login, "id", id, "error", err)
+		return nil, errors.New("failed to build delete app password query")
+	}
+
+	var appPassword user.AppPassword
+	err = p.storage.DB.QueryRowContext(ctx, query).Scan(&appPassword.ID, &appPassword.Login, &appPassword.Name, &appPassword.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || pg.IsInvalidTextRepresentation(err) {
+			p.logger.Info("app password not found", "func", op, "login", login, "id", id)
+			return nil, ErrNoRows
+		}
+
+		p.logger.Error("failed to execute delete app password query", "func", op, "login", login, "id", id, "error", err)
+		return nil, errors.New("failed to execute delete app password query")
+	}
+
+	return &appPassword, nil
+}
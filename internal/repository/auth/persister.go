@@ -2,8 +2,10 @@ package authrepo
 
 import (
 	"astral/internal/domain/dto"
+	"astral/internal/domain/event"
 	"astral/internal/domain/user"
 	pg "astral/internal/repository/db/postgres"
+	outboxrepo "astral/internal/repository/outbox"
 	"context"
 	"database/sql"
 	"errors"
@@ -11,11 +13,14 @@ import (
 	"time"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/jmoiron/sqlx"
 )
 
 const (
 	TABLE_USERS  = "users"
 	TABLE_TOKENS = "tokens"
+	TABLE_APP_PASSWORDS = "app_passwords"
+	TABLE_ACCESS_KEYS = "access_keys"
 )
 
 type AuthPersister struct {
@@ -32,7 +37,8 @@ func NewUserPersister(storage *pg.Storage, logger *slog.Logger) *AuthPersister {
 	}
 }
 
-func (p *AuthPersister) CreateUser(ctx context.Context, userData dto.UserData) (*user.User, error) {
+// CreateUser создает пользователя, events пишутся в outbox в той же транзакции
+func (p *AuthPersister) CreateUser(ctx context.Context, userData dto.UserData, events ...event.Event) (*user.User, error) {
 	const op = "repository.user.persister.CreateUser"
 
 	query, _, err := p.dial.Insert(TABLE_USERS).
@@ -48,7 +54,14 @@ func (p *AuthPersister) CreateUser(ctx context.Context, userData dto.UserData) (
 	}
 
 	var usr user.User
-	err = p.storage.DB.QueryRowContext(ctx, query).Scan(&usr.Login, &usr.Password, &usr.CreatedAt, &usr.UpdatedAt)
+	err = p.storage.WithTx(ctx, func(tx *sqlx.Tx) error {
+		err := tx.QueryRowContext(ctx, query).Scan(&usr.Login, &usr.Password, &usr.CreatedAt, &usr.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		return outboxrepo.Write(ctx, tx, events...)
+	})
 	if err != nil {
 		if pg.IsDuplicateKeyError(err) {
 			p.logger.Info("user with this login already exists", "func", op, "login", userData.Login, "error", ErrUserAlreadyExists)
@@ -62,7 +75,7 @@ func (p *AuthPersister) CreateUser(ctx context.Context, userData dto.UserData) (
 	return &usr, nil
 }
 
-func (p *AuthPersister) CreateToken(ctx context.Context, token dto.TokenData) (*user.Token, error) {
+func (p *AuthPersister) CreateToken(ctx context.Context, token dto.TokenData, events ...event.Event) (*user.Token, error) {
 	const op = "repository.user.persister.CreateToken"
  
 	query, _, err := p.dial.Insert(TABLE_TOKENS).
@@ -78,7 +91,14 @@ func (p *AuthPersister) CreateToken(ctx context.Context, token dto.TokenData) (*
 	}
 	
 	var tkn user.Token
-	err = p.storage.DB.QueryRowContext(ctx, query).Scan(&tkn.Token, &tkn.Login, &tkn.CreatedAt, &tkn.DeletedAt)
+	err = p.storage.WithTx(ctx, func(tx *sqlx.Tx) error {
+		err := tx.QueryRowContext(ctx, query).Scan(&tkn.Token, &tkn.Login, &tkn.CreatedAt, &tkn.DeletedAt)
+		if err != nil {
+			return err
+		}
+
+		return outboxrepo.Write(ctx, tx, events...)
+	})
 	if err != nil {
 		if pg.IsDuplicateKeyError(err) {
 			p.logger.Info("user with this token already exists", "func", op, "login", token.Login, "error", ErrUserAlreadyExists)
@@ -96,7 +116,7 @@ func (p *AuthPersister) GetUserByLogin(ctx context.Context, login string) (*user
 	const op = "repository.user.persister.GetUserByLogin"
 
 	query, _, err := p.dial.From(TABLE_USERS).
-		Select("login", "password", "created_at", "updated_at").
+		Select("login", "password", "created_at", "updated_at", "delete_after", "disabled_at").
 		Where(goqu.C("login").Eq(login)).
 		ToSQL()
 	if err != nil {
@@ -105,7 +125,7 @@ func (p *AuthPersister) GetUserByLogin(ctx context.Context, login string) (*user
 	}
 
 	var user user.User
-	err = p.storage.DB.QueryRowContext(ctx, query).Scan(&user.Login, &user.Password, &user.CreatedAt, &user.UpdatedAt)
+	err = p.storage.DB.QueryRowContext(ctx, query).Scan(&user.Login, &user.Password, &user.CreatedAt, &user.UpdatedAt, &user.DeleteAfter, &user.DisabledAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			p.logger.Error("failed to find user by login", "func", op, "login", login, "error", ErrNoRows)
@@ -158,7 +178,7 @@ func (p *AuthPersister) GetTokensByLogin(ctx context.Context, login string) ([]u
 	return tokens, nil
 }
 
-func (p *AuthPersister) DeleteToken(ctx context.Context, token string) (*user.Token, error) {
+func (p *AuthPersister) DeleteToken(ctx context.Context, token string, events ...event.Event) (*user.Token, error) {
 	const op = "repository.user.persister.DeleteToken"
 	query, _, err := p.dial.Update(TABLE_TOKENS).
 		Set(goqu.Record{"deleted_at": time.Now()}).
@@ -171,12 +191,22 @@ func (p *AuthPersister) DeleteToken(ctx context.Context, token string) (*user.To
 	}
 
 	var tkn user.Token
-	err = p.storage.DB.QueryRowContext(ctx, query).Scan(&tkn.Token, &tkn.Login)
+	err = p.storage.WithTx(ctx, func(tx *sqlx.Tx) error {
+		err := tx.QueryRowContext(ctx, query).Scan(&tkn.Token, &tkn.Login)
+		if err != nil {
+			return err
+		}
+
+		return outboxrepo.Write(ctx, tx, events...)
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			p.logger.Error("failed to find token", "func", op, "token", token, "error", ErrNoRows)
 			return nil, ErrNoRows
 		}
+
+		p.logger.Error("failed to execute delete token query", "func", op, "token", token, "error", err)
+		return nil, errors.New("failed to execute delete token query")
 	}
 
 	return &tkn, nil
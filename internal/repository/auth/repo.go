@@ -2,14 +2,23 @@ package authrepo
 
 import (
 	"astral/internal/domain/dto"
+	"astral/internal/domain/event"
 	"astral/internal/domain/user"
 	"context"
 )
 
 type AuthRepo interface {
-	CreateUser(ctx context.Context, user dto.UserData) (*user.User, error)
+	CreateUser(ctx context.Context, user dto.UserData, events ...event.Event) (*user.User, error)
 	GetUserByLogin(ctx context.Context, login string) (*user.User, error)
-	CreateToken(ctx context.Context, token dto.TokenData) (*user.Token, error)
+	CreateToken(ctx context.Context, token dto.TokenData, events ...event.Event) (*user.Token, error)
 	GetTokensByLogin(ctx context.Context, login string) ([]user.Token, error)
-	DeleteToken(ctx context.Context, token string) (*user.Token, error)
+	DeleteToken(ctx context.Context, token string, events ...event.Event) (*user.Token, error)
+	CreateAppPassword(ctx context.Context, password dto.AppPasswordData) (*user.AppPassword, error)
+	GetAppPasswordsByLogin(ctx context.Context, login string) ([]user.AppPassword, error)
+	GetAppPasswordByHash(ctx context.Context, hash string) (*user.AppPassword, error)
+	DeleteAppPassword(ctx context.Context, login, id string) (*user.AppPassword, error)
+	CreateAccessKey(ctx context.Context, key dto.AccessKeyData) (*user.AccessKey, error)
+	GetAccessKeysByLogin(ctx context.Context, login string) ([]user.AccessKey, error)
+	GetAccessKeyByID(ctx context.Context, id string) (*dto.AccessKeyData, error)
+	DeleteAccessKey(ctx context.Context, login, id string) (*user.AccessKey, error)
 }
@@ -0,0 +1,18 @@
+package brokerrepo
+
+import (
+	"astral/internal/domain/event"
+	"context"
+)
+
+const (
+	HEADER_EVENT_ID   = "event-id"
+	HEADER_EVENT_TYPE = "event-type"
+)
+
+// Broker публикует события наружу, телом сообщения служит событие целиком в JSON. Publish возвращает nil только после подтверждения брокером,
+// на этом держится доставка at-least-once из outbox
+type Broker interface {
+	Publish(ctx context.Context, event event.Event) error
+	Close() error
+}
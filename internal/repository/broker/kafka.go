@@ -0,0 +1,48 @@
+package brokerrepo
+
+import (
+	"astral/internal/domain/event"
+	"context"
+	"encoding/json"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaBroker пишет события в один топик, ключ сообщения - логин пользователя,
+// поэтому события одного пользователя упорядочены внутри партиции
+type KafkaBroker struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaBroker(brokers []string, topic string) *KafkaBroker {
+	return &KafkaBroker{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic,
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+func (b *KafkaBroker) Publish(ctx context.Context, e event.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return b.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(e.Key),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: HEADER_EVENT_ID, Value: []byte(e.ID)},
+			{Key: HEADER_EVENT_TYPE, Value: []byte(e.Type)},
+		},
+		Time: e.CreatedAt,
+	})
+}
+
+func (b *KafkaBroker) Close() error {
+	return b.writer.Close()
+}
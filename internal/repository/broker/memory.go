@@ -0,0 +1,64 @@
+package brokerrepo
+
+import (
+	"astral/internal/domain/event"
+	"context"
+	"sync"
+)
+
+// MemoryBroker хранит опубликованные события в памяти процесса, используется в тестах
+// и при запуске без внешнего брокера
+type MemoryBroker struct {
+	mu     sync.Mutex
+	events []event.Event
+	subs   []chan event.Event
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, e event.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.events = append(b.events, e)
+	for _, sub := range b.subs {
+		select {
+		case sub <- e:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Events возвращает копию всех опубликованных событий в порядке публикации
+func (b *MemoryBroker) Events() []event.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]event.Event(nil), b.events...)
+}
+
+// Subscribe возвращает канал новых событий, при переполнении буфера события для подписчика пропускаются
+func (b *MemoryBroker) Subscribe(buffer int) <-chan event.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := make(chan event.Event, buffer)
+	b.subs = append(b.subs, sub)
+	return sub
+}
+
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, sub := range b.subs {
+		close(sub)
+	}
+	b.subs = nil
+
+	return nil
+}
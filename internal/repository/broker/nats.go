@@ -0,0 +1,72 @@
+package brokerrepo
+
+import (
+	"astral/internal/domain/event"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NatsBroker публикует события в JetStream: подтверждение PubAck означает, что событие сохранено,
+// а заголовок Nats-Msg-Id с ID события отбрасывает дубликаты повторных отправок в окне дедупликации
+type NatsBroker struct {
+	conn          *nats.Conn
+	js            jetstream.JetStream
+	subjectPrefix string
+}
+
+// NewNatsBroker подключается к NATS. Если указан stream, он создается или обновляется так,
+// чтобы принимать все субъекты с префиксом
+func NewNatsBroker(ctx context.Context, url, stream, subjectPrefix string) (*NatsBroker, error) {
+	const op = "repository.broker.NewNatsBroker"
+
+	conn, err := nats.Connect(url, nats.Name("astral"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if stream != "" {
+		_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+			Name:     stream,
+			Subjects: []string{subjectPrefix + ".>"},
+		})
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return &NatsBroker{
+		conn:          conn,
+		js:            js,
+		subjectPrefix: subjectPrefix,
+	}, nil
+}
+
+func (b *NatsBroker) Publish(ctx context.Context, e event.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(b.subjectPrefix + "." + string(e.Type))
+	msg.Data = data
+	msg.Header.Set(HEADER_EVENT_ID, e.ID)
+	msg.Header.Set(HEADER_EVENT_TYPE, string(e.Type))
+
+	_, err = b.js.PublishMsg(ctx, msg, jetstream.WithMsgID(e.ID))
+	return err
+}
+
+func (b *NatsBroker) Close() error {
+	return b.conn.Drain()
+}
@@ -0,0 +1,7 @@
+package commentsrepo
+
+import "errors"
+
+var (
+	ErrCommentNotFound = errors.New("comment not found")
+)
@@ -0,0 +1,273 @@
+package commentsrepo
+
+import (
+	"astral/internal/domain/comment"
+	"astral/internal/domain/dto"
+	pg "astral/internal/repository/db/postgres"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/lib/pq"
+)
+
+const (
+	TABLE_COMMENTS = "comments"
+)
+
+type CommentsPersister struct {
+	dial    goqu.DialectWrapper
+	storage *pg.Storage
+	logger  *slog.Logger
+}
+
+func NewCommentsPersister(storage *pg.Storage, logger *slog.Logger) *CommentsPersister {
+	return &CommentsPersister{
+		dial:    goqu.Dialect(pg.DRIVER),
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (p *CommentsPersister) CreateComment(ctx context.Context, data dto.CommentData) (*comment.Comment, error) {
+	const op = "repository.comments.persister.CreateComment"
+
+	record := goqu.Record{
+		"document_id":    data.DocumentID,
+		"document_owner": data.Owner,
+		"parent_id":      data.ParentID,
+		"author":         data.Author,
+		"body":           data.Body,
+		"mentions":       pq.StringArray(data.Mentions),
+	}
+	if data.Anchor != nil {
+		record["anchor_page"] = data.Anchor.Page
+		record["anchor_start"] = data.Anchor.Start
+		record["anchor_end"] = data.Anchor.End
+	}
+
+	query, _, err := p.dial.Insert(TABLE_COMMENTS).
+		Rows(record).
+		Returning(commentColumns()...).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build create comment query", "func", op, "document_id", data.DocumentID, "error", err)
+		return nil, errors.New("failed to build create comment query")
+	}
+
+	res, err := scanComment(p.storage.DB.QueryRowContext(ctx, query))
+	if err != nil {
+		p.logger.Error("failed to execute create comment query", "func", op, "document_id", data.DocumentID, "error", err)
+		return nil, errors.New("failed to execute create comment query")
+	}
+
+	return res, nil
+}
+
+func (p *CommentsPersister) GetComments(ctx context.Context, documentID string) ([]comment.Comment, error) {
+	const op = "repository.comments.persister.GetComments"
+
+	query, _, err := p.dial.From(TABLE_COMMENTS).
+		Select(commentColumns()...).
+		Where(goqu.C("document_id").Eq(documentID)).
+		Order(goqu.C("id").Asc()).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get comments query", "func", op, "document_id", documentID, "error", err)
+		return nil, errors.New("failed to build get comments query")
+	}
+
+	rows, err := p.storage.DB.QueryContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute get comments query", "func", op, "document_id", documentID, "error", err)
+		return nil, errors.New("failed to execute get comments query")
+	}
+	defer rows.Close()
+
+	comments := []comment.Comment{}
+	for rows.Next() {
+		res, err := scanComment(rows)
+		if err != nil {
+			p.logger.Error("failed to scan comment", "func", op, "document_id", documentID, "error", err)
+			return nil, errors.New("failed to scan comment")
+		}
+
+		comments = append(comments, *res)
+	}
+	if err := rows.Err(); err != nil {
+		p.logger.Error("failed to iterate comments", "func", op, "document_id", documentID, "error", err)
+		return nil, errors.New("failed to iterate comments")
+	}
+
+	return comments, nil
+}
+
+func (p *CommentsPersister) GetComment(ctx context.Context, documentID string, id int64) (*comment.Comment, error) {
+	const op = "repository.comments.persister.GetComment"
+
+	query, _, err := p.dial.From(TABLE_COMMENTS).
+		Select(commentColumns()...).
+		Where(goqu.C("id").Eq(id), goqu.C("document_id").Eq(documentID)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get comment query", "func", op, "id", id, "error", err)
+		return nil, errors.New("failed to build get comment query")
+	}
+
+	return p.queryComment(ctx, op, query, id)
+}
+
+func (p *CommentsPersister) UpdateComment(ctx context.Context, documentID string, id int64, body string, mentions []string) (*comment.Comment, error) {
+	const op = "repository.comments.persister.UpdateComment"
+
+	query, _, err := p.dial.Update(TABLE_COMMENTS).
+		Set(goqu.Record{
+			"body":       body,
+			"mentions":   pq.StringArray(mentions),
+			"updated_at": goqu.L("CURRENT_TIMESTAMP"),
+		}).
+		Where(goqu.C("id").Eq(id), goqu.C("document_id").Eq(documentID), goqu.C("deleted").IsFalse()).
+		Returning(commentColumns()...).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build update comment query", "func", op, "id", id, "error", err)
+		return nil, errors.New("failed to build update comment query")
+	}
+
+	return p.queryComment(ctx, op, query, id)
+}
+
+func (p *CommentsPersister) SetResolved(ctx context.Context, documentID string, id int64, resolved bool, by string) (*comment.Comment, error) {
+	const op = "repository.comments.persister.SetResolved"
+
+	record := goqu.Record{
+		"resolved":    resolved,
+		"resolved_by": nil,
+		"resolved_at": nil,
+	}
+	if resolved {
+		record["resolved_by"] = by
+		record["resolved_at"] = goqu.L("CURRENT_TIMESTAMP")
+	}
+
+	query, _, err := p.dial.Update(TABLE_COMMENTS).
+		Set(record).
+		Where(goqu.C("id").Eq(id), goqu.C("document_id").Eq(documentID)).
+		Returning(commentColumns()...).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build resolve comment query", "func", op, "id", id, "error", err)
+		return nil, errors.New("failed to build resolve comment query")
+	}
+
+	return p.queryComment(ctx, op, query, id)
+}
+
+func (p *CommentsPersister) DeleteComment(ctx context.Context, documentID string, id int64) error {
+	const op = "repository.comments.persister.DeleteComment"
+
+	query, _, err := p.dial.Update(TABLE_COMMENTS).
+		Set(goqu.Record{
+			"deleted":      true,
+			"body":         "",
+			"mentions":     pq.StringArray{},
+			"anchor_page":  nil,
+			"anchor_start": nil,
+			"anchor_end":   nil,
+			"updated_at":   goqu.L("CURRENT_TIMESTAMP"),
+		}).
+		Where(goqu.C("id").Eq(id), goqu.C("document_id").Eq(documentID), goqu.C("deleted").IsFalse()).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build delete comment query", "func", op, "id", id, "error", err)
+		return errors.New("failed to build delete comment query")
+	}
+
+	res, err := p.storage.DB.ExecContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute delete comment query", "func", op, "id", id, "error", err)
+		return errors.New("failed to execute delete comment query")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		p.logger.Error("failed to get affected rows", "func", op, "id", id, "error", err)
+		return errors.New("failed to get affected rows")
+	}
+	if affected == 0 {
+		return ErrCommentNotFound
+	}
+
+	return nil
+}
+
+func (p *CommentsPersister) queryComment(ctx context.Context, op, query string, id int64) (*comment.Comment, error) {
+	res, err := scanComment(p.storage.DB.QueryRowContext(ctx, query))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCommentNotFound
+		}
+
+		p.logger.Error("failed to execute comment query", "func", op, "id", id, "error", err)
+		return nil, errors.New("failed to execute comment query")
+	}
+
+	return res, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanComment(row scanner) (*comment.Comment, error) {
+	var res comment.Comment
+	var parentID, anchorStart, anchorEnd sql.NullInt64
+	var anchorPage sql.NullInt32
+	var resolvedBy sql.NullString
+	var resolvedAt, updatedAt sql.NullTime
+	var mentions pq.StringArray
+	if err := row.Scan(
+		&res.ID, &res.DocumentID, &res.Owner, &parentID, &res.Author, &res.Body, &mentions,
+		&anchorPage, &anchorStart, &anchorEnd,
+		&res.Resolved, &resolvedBy, &resolvedAt, &res.Deleted, &res.CreatedAt, &updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	res.Mentions = mentions
+	res.ResolvedBy = resolvedBy.String
+	if parentID.Valid {
+		res.ParentID = &parentID.Int64
+	}
+	if resolvedAt.Valid {
+		res.ResolvedAt = &resolvedAt.Time
+	}
+	if updatedAt.Valid {
+		res.UpdatedAt = &updatedAt.Time
+	}
+	if anchorPage.Valid || anchorStart.Valid || anchorEnd.Valid {
+		res.Anchor = &comment.Anchor{}
+		if anchorPage.Valid {
+			page := int(anchorPage.Int32)
+			res.Anchor.Page = &page
+		}
+		if anchorStart.Valid {
+			res.Anchor.Start = &anchorStart.Int64
+		}
+		if anchorEnd.Valid {
+			res.Anchor.End = &anchorEnd.Int64
+		}
+	}
+
+	return &res, nil
+}
+
+func commentColumns() []any {
+	return []any{
+		"id", "document_id", "document_owner", "parent_id", "author", "body", "mentions",
+		"anchor_page", "anchor_start", "anchor_end",
+		"resolved", "resolved_by", "resolved_at", "deleted", "created_at", "updated_at",
+	}
+}
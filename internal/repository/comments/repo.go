@@ -0,0 +1,18 @@
+package commentsrepo
+
+import (
+	"astral/internal/domain/comment"
+	"astral/internal/domain/dto"
+	"context"
+)
+
+type CommentsRepo interface {
+	CreateComment(ctx context.Context, data dto.CommentData) (*comment.Comment, error)
+	// GetComments возвращает все комментарии документа плоским списком в порядке создания
+	GetComments(ctx context.Context, documentID string) ([]comment.Comment, error)
+	GetComment(ctx context.Context, documentID string, id int64) (*comment.Comment, error)
+	UpdateComment(ctx context.Context, documentID string, id int64, body string, mentions []string) (*comment.Comment, error)
+	SetResolved(ctx context.Context, documentID string, id int64, resolved bool, by string) (*comment.Comment, error)
+	// DeleteComment помечает комментарий удаленным и стирает его текст, упоминания и привязку
+	DeleteComment(ctx context.Context, documentID string, id int64) error
+}
@@ -0,0 +1,36 @@
+package miniostorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+)
+
+// EXPIRATION_RULE_ID - правило жизненного цикла, которое сервис ставит на служебные бакеты
+const EXPIRATION_RULE_ID = "astral-expiration"
+
+// SetExpiration заменяет правила жизненного цикла бакета одним: объекты удаляются через ttl,
+// округленный вверх до дня - точнее MinIO не умеет
+func (s *MinioStorage) SetExpiration(ctx context.Context, ttl time.Duration) error {
+	const op = "storage.minio.SetExpiration"
+
+	days := int((ttl + 24*time.Hour - 1) / (24 * time.Hour))
+	if days < 1 {
+		days = 1
+	}
+
+	config := lifecycle.NewConfiguration()
+	config.Rules = []lifecycle.Rule{{
+		ID:         EXPIRATION_RULE_ID,
+		Status:     "Enabled",
+		Expiration: lifecycle.Expiration{Days: lifecycle.ExpirationDays(days)},
+	}}
+
+	if err := s.Client.SetBucketLifecycle(ctx, s.BucketName, config); err != nil {
+		return fmt.Errorf("%s: failed to set bucket lifecycle: %w", op, err)
+	}
+
+	return nil
+}
@@ -11,6 +11,9 @@ import (
 	"github.com/minio/minio-go/v7/pkg/credentials"
 )
 
+// DEFAULT_REGION - регион MinIO, если он не задан в настройках
+const DEFAULT_REGION = "us-east-1"
+
 type MinioStorage struct {
 	Client     *minio.Client
 	BucketName string
@@ -43,6 +46,37 @@ func NewMinioStorage(cfg *env.MinIO) (*MinioStorage, error) {
 	return mStorage, nil
 }
 
+// NewPresignStorage создает клиент для подписи ссылок на тот же бакет, но по адресу presign.Endpoint,
+// доступному клиентам: адрес входит в подпись. Запросов к хранилищу клиент не делает, поэтому
+// регион задается явно, иначе подпись потребовала бы запроса региона бакета
+func NewPresignStorage(cfg *env.MinIO, presign *env.Presign) (*MinioStorage, error) {
+	const op = "storage.minio.NewPresign"
+
+	endpoint, useSSL := cfg.Endpoint, cfg.UseSSL
+	if presign.Endpoint != "" {
+		endpoint, useSSL = presign.Endpoint, presign.UseSSL
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = DEFAULT_REGION
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: useSSL,
+		Region: region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to create MinIO client: %w", op, err)
+	}
+
+	return &MinioStorage{
+		Client:     client,
+		BucketName: cfg.BucketName,
+	}, nil
+}
+
 func (s *MinioStorage) ensureBucketExists(ctx context.Context, bucketName string) error {
 	const op = "storage.minio.ensureBucketExists"
 
@@ -14,3 +14,12 @@ func IsDuplicateKeyError(err error) bool {
 	}
 	return false
 }
+
+func IsInvalidTextRepresentation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// PostgreSQL error code 22P02 = invalid_text_representation, например невалидный uuid
+		return pqErr.Code == "22P02"
+	}
+	return false
+}
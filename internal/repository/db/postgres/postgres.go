@@ -2,6 +2,7 @@ package pg
 
 import (
 	"astral/env"
+	"context"
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
@@ -34,6 +35,21 @@ func NewDBConnection(cfg *env.PgSql) (*Storage, error) {
 	return &Storage{DB: db}, nil
 }
 
+// WithTx выполняет fn в транзакции: коммит при nil, откат при ошибке
+func (s *Storage) WithTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (s *Storage) Stop() error {
 	return s.DB.Close()
 }
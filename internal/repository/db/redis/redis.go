@@ -83,6 +83,11 @@ func (s *CashStorage) CashedFile(ctx context.Context, key string, value file.Fil
 		Grant: value.Grant,
 		Size: value.Size,
 		Metadata: value.Metadata,
+		JSON: value.Data,
+		Revision: value.Revision,
+		Encoding: value.Encoding,
+		Checksum: value.Checksum,
+		Status: string(value.Status),
 		CreatedAt: value.CreatedAt,
 		User: value.User,
 		Data: data,
@@ -128,6 +133,11 @@ func (s *CashStorage) GetCashedFile(ctx context.Context, key string) *file.File
         Grant:      cachedFile.Grant,
         Size:       cachedFile.Size,
         Metadata:   cachedFile.Metadata,
+        Data:       cachedFile.JSON,
+        Revision:   cachedFile.Revision,
+        Encoding:   cachedFile.Encoding,
+        Checksum:   cachedFile.Checksum,
+        Status:     file.Status(cachedFile.Status),
         CreatedAt:  cachedFile.CreatedAt,
         User:       cachedFile.User,
         Reader:     bytes.NewReader(cachedFile.Data),
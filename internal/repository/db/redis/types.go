@@ -1,6 +1,9 @@
 package redis
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type CashedFile struct {
 	ID        string            `json:"id"`
@@ -11,6 +14,11 @@ type CashedFile struct {
 	Grant     []string          `json:"grant"`
 	Size      int               `json:"size,omitempty"`
 	Metadata  map[string]string `json:"metadata,omitempty"`
+	JSON      json.RawMessage   `json:"json,omitempty"`
+	Revision  int64             `json:"revision,omitempty"`
+	Encoding  string            `json:"encoding,omitempty"`
+	Checksum  string            `json:"checksum,omitempty"`
+	Status    string            `json:"status,omitempty"`
 	CreatedAt *time.Time        `json:"created"`
 	Data      []byte            `json:"data,omitempty"`
 	User      string            `json:"user"`
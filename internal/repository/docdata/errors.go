@@ -0,0 +1,9 @@
+package docdatarepo
+
+import "errors"
+
+var (
+	ErrInvalidQuery     = errors.New("invalid data query")
+	ErrDataNotFound     = errors.New("document data not found")
+	ErrRevisionMismatch = errors.New("document revision mismatch")
+)
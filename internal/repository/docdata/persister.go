@@ -0,0 +1,221 @@
+package docdatarepo
+
+import (
+	pg "astral/internal/repository/db/postgres"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/lib/pq"
+)
+
+const (
+	TABLE_DOCUMENT_DATA = "document_data"
+
+	PG_SYNTAX_ERROR   = "42601"
+	PG_DATA_EXCEPTION = "22"
+)
+
+type DataPersister struct {
+	dial    goqu.DialectWrapper
+	storage *pg.Storage
+	logger  *slog.Logger
+}
+
+func NewDataPersister(storage *pg.Storage, logger *slog.Logger) *DataPersister {
+	return &DataPersister{
+		dial:    goqu.Dialect(pg.DRIVER),
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (p *DataPersister) Save(ctx context.Context, documentID, login string, data json.RawMessage) error {
+	const op = "repository.docdata.persister.Save"
+
+	query, _, err := p.dial.Insert(TABLE_DOCUMENT_DATA).
+		Rows(goqu.Record{
+			"document_id": documentID,
+			"user_login":  login,
+			"data":        goqu.L("?::jsonb", string(data)),
+		}).
+		OnConflict(goqu.DoUpdate("document_id", goqu.Record{
+			"data":       goqu.L("EXCLUDED.data"),
+			"revision":   goqu.L("?.revision + 1", goqu.T(TABLE_DOCUMENT_DATA)),
+			"updated_at": goqu.L("CURRENT_TIMESTAMP"),
+		})).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build query", "func", op, "error", err)
+		return errors.New("failed to save document data")
+	}
+
+	if _, err := p.storage.DB.ExecContext(ctx, query); err != nil {
+		p.logger.Error("failed to save document data", "func", op, "documentID", documentID, "error", err)
+		return errors.New("failed to save document data")
+	}
+
+	return nil
+}
+
+func (p *DataPersister) Get(ctx context.Context, documentID string) (*Record, error) {
+	const op = "repository.docdata.persister.Get"
+
+	query, _, err := p.dial.From(TABLE_DOCUMENT_DATA).
+		Select(recordColumns()...).
+		Where(goqu.C("document_id").Eq(documentID)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build query", "func", op, "error", err)
+		return nil, errors.New("failed to get document data")
+	}
+
+	var record Record
+	if err := p.storage.DB.GetContext(ctx, &record, query); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		p.logger.Error("failed to get document data", "func", op, "documentID", documentID, "error", err)
+		return nil, errors.New("failed to get document data")
+	}
+
+	return &record, nil
+}
+
+func (p *DataPersister) GetMany(ctx context.Context, documentIDs []string) (map[string]Record, error) {
+	const op = "repository.docdata.persister.GetMany"
+
+	res := make(map[string]Record)
+	if len(documentIDs) == 0 {
+		return res, nil
+	}
+
+	query, _, err := p.dial.From(TABLE_DOCUMENT_DATA).
+		Select(recordColumns()...).
+		Where(goqu.C("document_id").In(documentIDs)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build query", "func", op, "error", err)
+		return nil, errors.New("failed to get document data")
+	}
+
+	var records []Record
+	if err := p.storage.DB.SelectContext(ctx, &records, query); err != nil {
+		p.logger.Error("failed to get document data", "func", op, "count", len(documentIDs), "error", err)
+		return nil, errors.New("failed to get document data")
+	}
+
+	for _, record := range records {
+		res[record.DocumentID] = record
+	}
+
+	return res, nil
+}
+
+func (p *DataPersister) Update(ctx context.Context, documentID string, data json.RawMessage, revision int64) (int64, error) {
+	const op = "repository.docdata.persister.Update"
+
+	query, _, err := p.dial.Update(TABLE_DOCUMENT_DATA).
+		Set(goqu.Record{
+			"data":       goqu.L("?::jsonb", string(data)),
+			"revision":   goqu.L("revision + 1"),
+			"updated_at": goqu.L("CURRENT_TIMESTAMP"),
+		}).
+		Where(goqu.C("document_id").Eq(documentID), goqu.C("revision").Eq(revision)).
+		Returning("revision").
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build query", "func", op, "error", err)
+		return 0, errors.New("failed to update document data")
+	}
+
+	var updated int64
+	err = p.storage.DB.GetContext(ctx, &updated, query)
+	if err == nil {
+		return updated, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		p.logger.Error("failed to update document data", "func", op, "documentID", documentID, "error", err)
+		return 0, errors.New("failed to update document data")
+	}
+
+	// Ни одна строка не обновилась: либо данных нет, либо их уже изменил кто-то другой
+	current, err := p.Get(ctx, documentID)
+	if err != nil {
+		return 0, err
+	}
+	if current == nil {
+		return 0, ErrDataNotFound
+	}
+
+	p.logger.Info("document revision mismatch", "func", op, "documentID", documentID, "expected", revision, "actual", current.Revision)
+	return 0, ErrRevisionMismatch
+}
+
+func (p *DataPersister) Delete(ctx context.Context, documentID string) error {
+	const op = "repository.docdata.persister.Delete"
+
+	query, _, err := p.dial.Delete(TABLE_DOCUMENT_DATA).
+		Where(goqu.C("document_id").Eq(documentID)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build query", "func", op, "error", err)
+		return errors.New("failed to delete document data")
+	}
+
+	if _, err := p.storage.DB.ExecContext(ctx, query); err != nil {
+		p.logger.Error("failed to delete document data", "func", op, "documentID", documentID, "error", err)
+		return errors.New("failed to delete document data")
+	}
+
+	return nil
+}
+
+func (p *DataPersister) MatchPath(ctx context.Context, login, path string) ([]string, error) {
+	return p.match(ctx, "repository.docdata.persister.MatchPath", login, goqu.L("data @@ ?::jsonpath", path))
+}
+
+func (p *DataPersister) MatchContains(ctx context.Context, login string, value json.RawMessage) ([]string, error) {
+	return p.match(ctx, "repository.docdata.persister.MatchContains", login, goqu.L("data @> ?::jsonb", string(value)))
+}
+
+func (p *DataPersister) match(ctx context.Context, op, login string, condition exp.Expression) ([]string, error) {
+	query, _, err := p.dial.From(TABLE_DOCUMENT_DATA).
+		Select("document_id").
+		Where(goqu.C("user_login").Eq(login), condition).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build query", "func", op, "error", err)
+		return nil, errors.New("failed to query document data")
+	}
+
+	var ids []string
+	if err := p.storage.DB.SelectContext(ctx, &ids, query); err != nil {
+		// Ошибки разбора JSONPath и JSON от PostgreSQL - это ошибка в запросе клиента
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && (pqErr.Code == PG_SYNTAX_ERROR || pqErr.Code.Class() == PG_DATA_EXCEPTION) {
+			p.logger.Info("invalid data query", "func", op, "login", login, "error", err)
+			return nil, ErrInvalidQuery
+		}
+
+		p.logger.Error("failed to query document data", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to query document data")
+	}
+
+	return ids, nil
+}
+
+func recordColumns() []any {
+	return []any{
+		"document_id",
+		"user_login",
+		goqu.L("data::text").As("data"),
+		"revision",
+		"updated_at",
+	}
+}
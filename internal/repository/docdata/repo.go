@@ -0,0 +1,32 @@
+package docdatarepo
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// DataRepo хранит JSON данные документов. Содержимое документа лежит в MinIO,
+// а JSON - в PostgreSQL, чтобы по нему работали запросы JSONPath и вхождения
+type DataRepo interface {
+	Save(ctx context.Context, documentID, login string, data json.RawMessage) error
+	Get(ctx context.Context, documentID string) (*Record, error)
+	GetMany(ctx context.Context, documentIDs []string) (map[string]Record, error)
+	// Update заменяет данные, только если ревизия совпадает с ожидаемой, и возвращает новую ревизию
+	Update(ctx context.Context, documentID string, data json.RawMessage, revision int64) (int64, error)
+	Delete(ctx context.Context, documentID string) error
+	// MatchPath возвращает документы пользователя, данные которых удовлетворяют предикату JSONPath
+	MatchPath(ctx context.Context, login, path string) ([]string, error)
+	// MatchContains возвращает документы пользователя, данные которых содержат переданный JSON
+	MatchContains(ctx context.Context, login string, value json.RawMessage) ([]string, error)
+}
+
+// Record - данные документа вместе с владельцем и ревизией. Ревизия растет на единицу
+// при каждом изменении данных и служит для оптимистичной блокировки
+type Record struct {
+	DocumentID string          `db:"document_id"`
+	Login      string          `db:"user_login"`
+	Data       json.RawMessage `db:"data"`
+	Revision   int64           `db:"revision"`
+	UpdatedAt  *time.Time      `db:"updated_at"`
+}
@@ -0,0 +1,176 @@
+package filesrepo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	ENCODING_NONE = "none"
+	ENCODING_GZIP = "gzip"
+	ENCODING_ZSTD = "zstd"
+)
+
+var compressibleTypes = map[string]bool{
+	"application/json":       true,
+	"application/xml":        true,
+	"application/javascript": true,
+	"application/x-yaml":     true,
+	"application/yaml":       true,
+	"application/x-ndjson":   true,
+	"image/svg+xml":          true,
+}
+
+// encodedObject - содержимое объекта, подготовленное к записи в хранилище
+type encodedObject struct {
+	reader   io.Reader
+	size     int64
+	encoding string
+}
+
+// encode сжимает содержимое документа, если сжатие включено, тип хорошо сжимается и документ
+// не меньше порога. Если сжатие не дает выигрыша, документ хранится как есть
+func (s *StoragePersister) encode(r io.Reader, size int64, mimeTypes ...string) (*encodedObject, error) {
+	raw := &encodedObject{reader: r, size: size}
+
+	algorithm := s.compression.Algorithm
+	if algorithm == "" || algorithm == ENCODING_NONE || size < int64(s.compression.Threshold) || !compressible(mimeTypes...) {
+		return raw, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, size+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != size {
+		return nil, fmt.Errorf("document size mismatch: expected %d, got %d", size, len(data))
+	}
+
+	compressed, err := compress(algorithm, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(compressed) >= len(data) {
+		return &encodedObject{reader: bytes.NewReader(data), size: size}, nil
+	}
+
+	return &encodedObject{
+		reader:   bytes.NewReader(compressed),
+		size:     int64(len(compressed)),
+		encoding: algorithm,
+	}, nil
+}
+
+func compress(algorithm string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	switch algorithm {
+	case ENCODING_GZIP:
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	case ENCODING_ZSTD:
+		w, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer w.Close()
+
+		return w.EncodeAll(data, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm %q", algorithm)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decode возвращает распакованное содержимое объекта, закрытие закрывает и сам объект
+func decode(r io.ReadCloser, encoding string) (io.ReadCloser, error) {
+	switch encoding {
+	case "", ENCODING_NONE:
+		return r, nil
+	case ENCODING_GZIP:
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+
+		return &decodedReader{Reader: gz, closers: []io.Closer{gz, r}}, nil
+	case ENCODING_ZSTD:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+
+		return &decodedReader{Reader: zr, closers: []io.Closer{zr.IOReadCloser(), r}}, nil
+	}
+
+	r.Close()
+	return nil, fmt.Errorf("unknown object encoding %q", encoding)
+}
+
+type decodedReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *decodedReader) Close() error {
+	var errs []error
+	for _, c := range r.closers {
+		errs = append(errs, c.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// encodingMetadata добавляет к метаданным объекта способ сжатия и исходный размер документа.
+// У несжатого объекта этих ключей быть не должно, иначе его чтение пойдет через распаковку
+func encodingMetadata(metadata map[string]string, encoding string, size int64) {
+	if encoding == "" {
+		delete(metadata, META_ENCODING)
+		delete(metadata, META_SIZE)
+		return
+	}
+
+	metadata[META_ENCODING] = encoding
+	metadata[META_SIZE] = strconv.FormatInt(size, 10)
+}
+
+// objectEncoding возвращает способ сжатия объекта, пустая строка - объект не сжат
+func objectEncoding(objInfo minio.ObjectInfo) string {
+	return normalizeMetadata(objInfo.UserMetadata)[META_ENCODING]
+}
+
+func compressible(mimeTypes ...string) bool {
+	for _, value := range mimeTypes {
+		mediaType, _, err := mime.ParseMediaType(value)
+		if err != nil {
+			continue
+		}
+
+		if strings.HasPrefix(mediaType, "text/") || compressibleTypes[mediaType] ||
+			strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isCompressionAlgorithm(algorithm string) bool {
+	return algorithm == "" || algorithm == ENCODING_NONE || algorithm == ENCODING_GZIP || algorithm == ENCODING_ZSTD
+}
@@ -2,6 +2,20 @@ package filesrepo
 
 import "fmt"
 
+// Служебные ключи пользовательских метаданных объекта в MinIO
+const (
+	META_FILE_NAME = "file_name"
+	META_GRANT     = "grant"
+	META_PUBLIC    = "public"
+	META_FILE      = "file"
+	// META_ENCODING - способ сжатия объекта, META_SIZE - размер документа до сжатия
+	META_ENCODING = "encoding"
+	META_SIZE     = "original_size"
+	// META_CHECKSUM - SHA-256 документа до сжатия, META_STATUS - состояние документа, если оно не active
+	META_CHECKSUM = "sha256"
+	META_STATUS   = "status"
+)
+
 func getFilePath(userID, fileID string) string {
 	return fmt.Sprintf("%s/%s", userID, fileID)
 }
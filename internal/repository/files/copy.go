@@ -0,0 +1,57 @@
+package filesrepo
+
+import (
+	"astral/internal/domain/file"
+	"astral/internal/domain/replication"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+)
+
+// CopyFile копирует объект внутри бакета без скачивания. Содержимое не меняется, поэтому признак
+// сжатия, исходный размер, контрольная сумма и состояние переносятся из исходного объекта
+func (s *StoragePersister) CopyFile(ctx context.Context, userID, fileID, toUserID string, fileData file.File) (*file.File, error) {
+	const op = "storage.minio.CopyFile"
+
+	if err := validateFileName(fileData.Name); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	srcPath := getFilePath(userID, fileID)
+	objInfo, err := s.statObject(ctx, s.storage, srcPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if fileData.ID == "" {
+		fileData.ID = uuid.New().String()
+	}
+	dstPath := getFilePath(toUserID, fileData.ID)
+
+	metadata := objectMetadata(fileData)
+	metadata["Content-Type"] = objInfo.ContentType
+	keepContentMetadata(metadata, normalizeMetadata(objInfo.UserMetadata))
+
+	_, err = s.storage.Client.CopyObject(ctx, minio.CopyDestOptions{
+		Bucket:          s.storage.BucketName,
+		Object:          dstPath,
+		UserMetadata:    metadata,
+		ReplaceMetadata: true,
+	}, minio.CopySrcOptions{
+		Bucket: s.storage.BucketName,
+		Object: srcPath,
+	})
+	if err != nil {
+		s.logger.Error("failed to copy file in minio", "func", op, "from", srcPath, "to", dstPath, "error", err)
+		return nil, errors.New("failed to copy file")
+	}
+
+	if err := s.replicate(ctx, replication.OperationPut, dstPath); err != nil {
+		return nil, err
+	}
+
+	return s.getFileInfo(ctx, dstPath)
+}
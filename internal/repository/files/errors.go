@@ -3,7 +3,9 @@ package filesrepo
 import "errors"
 
 var (
-	ErrFileNotFound = errors.New("file not found")
+	ErrFileNotFound    = errors.New("file not found")
+	ErrPresignDisabled = errors.New("presigned urls are disabled")
+	ErrObjectChanged   = errors.New("object was changed during verification")
 )
 
 type ErrFileUpload struct {
@@ -0,0 +1,123 @@
+package filesrepo
+
+import (
+	"astral/internal/domain/file"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// MinIO возвращает ключи метаданных в каноническом виде заголовков (File_name, X-Amz-Meta-Grant),
+// поэтому перед чтением приводим их к тому виду, в котором они были записаны
+func normalizeMetadata(metadata map[string]string) map[string]string {
+	result := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		key := strings.ToLower(k)
+		key = strings.TrimPrefix(key, "x-amz-meta-")
+		result[key] = v
+	}
+
+	return result
+}
+
+// reservedMetadata - служебные ключи, которые пишет только хранилище. Пользовательские метаданные
+// с такими ключами отбрасываются: иначе клиент выдал бы несжатый объект за сжатый, подменил размер,
+// контрольную сумму, которую сервер не считал, или состояние, выставленное проверкой хранилища
+var reservedMetadata = []string{META_FILE_NAME, META_GRANT, META_PUBLIC, META_FILE, META_ENCODING, META_SIZE, META_CHECKSUM, META_STATUS}
+
+// isReservedMetadata сравнивает ключ в том виде, в котором его вернет MinIO
+func isReservedMetadata(key string) bool {
+	key = strings.TrimPrefix(strings.ToLower(key), "x-amz-meta-")
+	return slices.Contains(reservedMetadata, key)
+}
+
+// objectMetadata собирает метаданные объекта: пользовательские без пустых и служебных ключей и пустых
+// значений и служебные поля документа
+func objectMetadata(fileData file.File) map[string]string {
+	metadata := make(map[string]string)
+	for k, v := range fileData.Metadata {
+		key := strings.TrimSpace(k)
+		value := strings.TrimSpace(v)
+		if key != "" && value != "" && !isReservedMetadata(key) {
+			metadata[key] = value
+		}
+	}
+
+	metadata[META_FILE_NAME] = fileData.Name
+	metadata[META_GRANT] = strings.Join(fileData.Grant, ";")
+	metadata[META_PUBLIC] = strconv.FormatBool(fileData.Public)
+	metadata[META_FILE] = strconv.FormatBool(fileData.File)
+
+	return metadata
+}
+
+func objectToFile(objInfo minio.ObjectInfo) file.File {
+	metadata := normalizeMetadata(objInfo.UserMetadata)
+
+	var grant []string
+	if metadata[META_GRANT] != "" {
+		grant = strings.Split(metadata[META_GRANT], ";")
+	}
+
+	isFile := objInfo.Size != 0
+	if value, ok := metadata[META_FILE]; ok {
+		isFile = value == "true"
+	}
+
+	fileData := file.File{
+		ID:        strings.Join(strings.Split(objInfo.Key, "/")[1:], ""),
+		Name:      metadata[META_FILE_NAME],
+		File:      isFile,
+		Public:    metadata[META_PUBLIC] == "true",
+		Mime:      objInfo.ContentType,
+		Grant:     grant,
+		Size:      int(objInfo.Size),
+		Checksum:  metadata[META_CHECKSUM],
+		Status:    file.StatusActive,
+		CreatedAt: &objInfo.LastModified,
+		User:      strings.Split(objInfo.Key, "/")[0],
+	}
+	if status := metadata[META_STATUS]; status != "" {
+		fileData.Status = file.Status(status)
+	}
+
+	// Сжатый объект хранит исходный размер документа отдельно
+	if encoding := metadata[META_ENCODING]; encoding != "" {
+		fileData.Encoding = encoding
+		if size, err := strconv.Atoi(metadata[META_SIZE]); err == nil {
+			fileData.Size = size
+		}
+	}
+
+	delete(metadata, META_FILE_NAME)
+	delete(metadata, META_GRANT)
+	delete(metadata, META_PUBLIC)
+	delete(metadata, META_FILE)
+	delete(metadata, META_ENCODING)
+	delete(metadata, META_SIZE)
+	delete(metadata, META_CHECKSUM)
+	delete(metadata, META_STATUS)
+	fileData.Metadata = metadata
+
+	return fileData
+}
+
+// contentMetadata - служебные ключи, которые описывают содержимое объекта. Они, как и извлеченные
+// из содержимого метаданные, не меняются при изменении сведений о документе и переносятся из
+// текущих метаданных объекта
+var contentMetadata = []string{META_ENCODING, META_SIZE, META_CHECKSUM, META_STATUS}
+
+func keepContentMetadata(metadata, current map[string]string) {
+	for _, key := range contentMetadata {
+		if value := current[key]; value != "" {
+			metadata[key] = value
+		}
+	}
+	for key, value := range current {
+		if file.IsIntrinsicKey(key) {
+			metadata[key] = value
+		}
+	}
+}
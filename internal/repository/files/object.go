@@ -0,0 +1,101 @@
+package filesrepo
+
+import (
+	"astral/internal/domain/file"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// OpenObject открывает объект документа для чтения с произвольным доступом. Чтения выполняются
+// в контексте ctx, поэтому он должен жить, пока объект не закрыт. Сжатый объект диапазонами
+// не читается, он распаковывается в память целиком
+func (s *StoragePersister) OpenObject(ctx context.Context, userID, fileID string) (file.Object, error) {
+	const op = "storage.minio.OpenObject"
+
+	filePath := getFilePath(userID, fileID)
+	reader, _, encoding, err := s.downloadRaw(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if encoding != "" {
+		decoded, err := s.decode(reader, filePath, encoding)
+		if err != nil {
+			return nil, err
+		}
+		defer decoded.Close()
+
+		data, err := io.ReadAll(decoded)
+		if err != nil {
+			s.logger.Error("failed to read compressed object", "func", op, "path", filePath, "error", err)
+			return nil, errors.New("failed to read file")
+		}
+
+		return memoryObject{bytes.NewReader(data)}, nil
+	}
+
+	obj, ok := reader.(file.Object)
+	if !ok {
+		reader.Close()
+		s.logger.Error("storage object does not support ranged reads", "func", op, "fileID", fileID)
+		return nil, errors.New("storage object does not support ranged reads")
+	}
+
+	return obj, nil
+}
+
+// GetRawFile возвращает содержимое объекта в том виде, в каком оно хранится, его размер
+// и способ сжатия. Так сжатый документ можно отдать клиенту без распаковки
+func (s *StoragePersister) GetRawFile(ctx context.Context, userID, fileID string) (io.ReadCloser, int64, string, error) {
+	return s.downloadRaw(ctx, getFilePath(userID, fileID))
+}
+
+// downloadRaw открывает объект без распаковки и возвращает его размер в хранилище и способ сжатия
+func (s *StoragePersister) downloadRaw(ctx context.Context, path string) (io.ReadCloser, int64, string, error) {
+	const op = "storage.minio.downloadRaw"
+
+	reader, err := s.download(ctx, path)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	stat, ok := reader.(interface {
+		Stat() (minio.ObjectInfo, error)
+	})
+	if !ok {
+		return reader, -1, "", nil
+	}
+
+	objInfo, err := stat.Stat()
+	if err != nil {
+		reader.Close()
+		s.logger.Error("failed to get file info", "func", op, "path", path, "error", err)
+		return nil, 0, "", errors.New("failed to get file info")
+	}
+
+	return reader, objInfo.Size, objectEncoding(objInfo), nil
+}
+
+func (s *StoragePersister) decode(reader io.ReadCloser, path, encoding string) (io.ReadCloser, error) {
+	const op = "storage.minio.decode"
+
+	decoded, err := decode(reader, encoding)
+	if err != nil {
+		s.logger.Error("failed to decompress object", "func", op, "path", path, "encoding", encoding, "error", err)
+		return nil, errors.New("failed to read file")
+	}
+
+	return decoded, nil
+}
+
+type memoryObject struct {
+	*bytes.Reader
+}
+
+func (memoryObject) Close() error {
+	return nil
+}
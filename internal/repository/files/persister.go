@@ -1,8 +1,11 @@
 package filesrepo
 
 import (
+	"astral/env"
 	"astral/internal/domain/file"
+	"astral/internal/domain/replication"
 	miniostorage "astral/internal/repository/db/minio"
+	replicationrepo "astral/internal/repository/replication"
 	"context"
 	"errors"
 	"fmt"
@@ -20,13 +23,29 @@ const (
 )
 
 type StoragePersister struct {
-	logger *slog.Logger
+	logger  *slog.Logger
 	storage miniostorage.MinioStorage
+	replica *miniostorage.MinioStorage
+	queue   replicationrepo.QueueRepo
+	compression env.Compression
+	presigner *miniostorage.MinioStorage
+	presign   env.Presign
 }
 
-func NewFilePersister(storage miniostorage.MinioStorage, logger *slog.Logger) *StoragePersister {
+// replica и queue могут быть nil, если репликация выключена, presigner - если выключены подписанные ссылки
+func NewFilePersister(storage miniostorage.MinioStorage, replica *miniostorage.MinioStorage, queue replicationrepo.QueueRepo, compression env.Compression, presigner *miniostorage.MinioStorage, presign env.Presign, logger *slog.Logger) *StoragePersister {
+	if !isCompressionAlgorithm(compression.Algorithm) {
+		logger.Warn("unknown storage compression algorithm, compression is disabled", "algorithm", compression.Algorithm)
+		compression.Algorithm = ENCODING_NONE
+	}
+
 	return &StoragePersister{
 		storage: storage,
+		replica: replica,
+		queue:   queue,
+		compression: compression,
+		presigner: presigner,
+		presign:   presign,
 		logger:  logger,
 	}
 }
@@ -45,24 +64,23 @@ func (s *StoragePersister) CreateFile(ctx context.Context, userID string, fileDa
 
     contentType := detectContentType(fileData.Name)
 
-	fileID := uuid.New().String()
+	// Переданный ID означает замену содержимого существующего документа
+	fileID := fileData.ID
+	if fileID == "" {
+		fileID = uuid.New().String()
+	}
     filePath := getFilePath(userID, fileID)
 
-    safeMetadata := make(map[string]string)
-    for k, v := range fileData.Metadata {
-        safeKey := strings.TrimSpace(k)
-        safeValue := strings.TrimSpace(v)
-        if safeKey != "" && safeValue != "" {
-            safeMetadata[safeKey] = safeValue
-        }
+    encoded, err := s.encode(fileData.Reader, int64(fileData.Size), contentType, fileData.Mime)
+    if err != nil {
+		s.logger.Error("failed to compress file", "func", op, "filename", fileData.Name, "userID", userID, "error", err)
+		return nil, errors.New("failed to upload file")
     }
 
-	safeMetadata["file_name"] = fileData.Name
-	safeMetadata["grant"] = strings.Join(fileData.Grant, ";")
-	if fileData.Public {
-		safeMetadata["public"] = "true"
-	} else {
-		safeMetadata["public"] = "false"
+    safeMetadata := objectMetadata(fileData)
+	encodingMetadata(safeMetadata, encoded.encoding, int64(fileData.Size))
+	if fileData.Checksum != "" {
+		safeMetadata[META_CHECKSUM] = fileData.Checksum
 	}
 
     putOptions := minio.PutObjectOptions{
@@ -70,17 +88,21 @@ func (s *StoragePersister) CreateFile(ctx context.Context, userID string, fileDa
         UserMetadata: safeMetadata,
     }
 
-    info, err := s.storage.Client.PutObject(ctx, s.storage.BucketName, filePath, fileData.Reader, int64(fileData.Size), putOptions)
+    info, err := s.storage.Client.PutObject(ctx, s.storage.BucketName, filePath, encoded.reader, encoded.size, putOptions)
     if err != nil {
 		s.logger.Error("failed to upload file in minio", "func", op, "filename", fileData.Name, "userID", userID, "error", err)
         return nil, errors.New("failed to upload file")
     }
 
-    if info.Size == 0 {
-		s.logger.Error("failed to upload file", "func", op, "filename", fileData.Name, "userID", userID, "error", err)
+    if info.Size != encoded.size {
+		s.logger.Error("failed to upload file", "func", op, "filename", fileData.Name, "userID", userID, "expected", encoded.size, "uploaded", info.Size)
 		return nil, errors.New("failed to upload file")
     }
 
+	if err := s.replicate(ctx, replication.OperationPut, filePath); err != nil {
+		return nil, err
+	}
+
     result := &file.File{
 		ID: 	   fileID,
         Name:      fileData.Name,
@@ -88,13 +110,58 @@ func (s *StoragePersister) CreateFile(ctx context.Context, userID string, fileDa
         Mime:      fileData.Mime,
 		File:      fileData.File,
         Grant:     fileData.Grant,
+		Size:      fileData.Size,
 		Metadata:  fileData.Metadata,
+		Encoding:  encoded.encoding,
+		Checksum:  fileData.Checksum,
+		Status:    file.StatusActive,
 		CreatedAt: &info.LastModified,
+		User:      userID,
     }
 
     return result, nil
 }
 
+// UpdateFileInfo перезаписывает метаданные объекта копированием на себя, содержимое и ID не меняются
+func (s *StoragePersister) UpdateFileInfo(ctx context.Context, userID string, fileData file.File) (*file.File, error) {
+	const op = "storage.minio.UpdateFileInfo"
+
+	if err := validateFileName(fileData.Name); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	filePath := getFilePath(userID, fileData.ID)
+	objInfo, err := s.statObject(ctx, s.storage, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := objectMetadata(fileData)
+	metadata["Content-Type"] = objInfo.ContentType
+	// Содержимое не меняется, поэтому сохраняем признак сжатия, исходный размер и контрольную сумму
+	keepContentMetadata(metadata, normalizeMetadata(objInfo.UserMetadata))
+
+	_, err = s.storage.Client.CopyObject(ctx, minio.CopyDestOptions{
+		Bucket:          s.storage.BucketName,
+		Object:          filePath,
+		UserMetadata:    metadata,
+		ReplaceMetadata: true,
+	}, minio.CopySrcOptions{
+		Bucket: s.storage.BucketName,
+		Object: filePath,
+	})
+	if err != nil {
+		s.logger.Error("failed to update file info in minio", "func", op, "path", filePath, "error", err)
+		return nil, errors.New("failed to update file info")
+	}
+
+	if err := s.replicate(ctx, replication.OperationPut, filePath); err != nil {
+		return nil, err
+	}
+
+	return s.getFileInfo(ctx, filePath)
+}
+
 func validateFileName(fileName string) error {
     if fileName == "" {
         return NewErrFileUpload("empty file name")
@@ -110,7 +177,12 @@ func validateFileName(fileName string) error {
 
 func (s *StoragePersister) GetFileByID(ctx context.Context, userID, fileID string) (io.ReadCloser, error) {
 	filePath := getFilePath(userID, fileID)
-	return s.download(ctx, filePath)
+	reader, _, encoding, err := s.downloadRaw(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decode(reader, filePath, encoding)
 }
 
 func (s *StoragePersister) DeleteFile(ctx context.Context, fileID, userID string) error {
@@ -129,9 +201,22 @@ func (s *StoragePersister) ListUserFiles(ctx context.Context, userID string) ([]
 func (s *StoragePersister) listFiles(ctx context.Context, prefix string) ([]file.File, error) {
 	const op = "storage.minio.listFiles"
 
-	objectCh := s.storage.Client.ListObjects(ctx, s.storage.BucketName, minio.ListObjectsOptions{
-		Prefix:    prefix,
-		Recursive: true,
+	files, err := s.listObjects(ctx, s.storage, prefix)
+	if err == nil || s.replica == nil {
+		return files, err
+	}
+
+	s.logger.Warn("primary storage failed, listing replica", "func", op, "prefix", prefix, "error", err)
+	return s.listObjects(ctx, *s.replica, prefix)
+}
+
+func (s *StoragePersister) listObjects(ctx context.Context, storage miniostorage.MinioStorage, prefix string) ([]file.File, error) {
+	const op = "storage.minio.listObjects"
+
+	objectCh := storage.Client.ListObjects(ctx, storage.BucketName, minio.ListObjectsOptions{
+		Prefix:       prefix,
+		Recursive:    true,
+		WithMetadata: true,
 	})
 
 	var files []file.File
@@ -142,18 +227,7 @@ func (s *StoragePersister) listFiles(ctx context.Context, prefix string) ([]file
 			return nil, errors.New("error listing files")
 		}
 
-		grant := strings.Split(objInfo.UserMetadata["grant"], ";")
-		files = append(files, file.File{
-			ID:           strings.Join(strings.Split(objInfo.Key, "/")[1:], ""),
-			Name:         objInfo.UserMetadata["file_name"],
-			File:         int(objInfo.Size) != 0,
-			Public:   	  objInfo.UserMetadata["public"] == "true",
-			Mime:  		  objInfo.ContentType,
-			Grant:        grant,
-			Size:         int(objInfo.Size),
-			CreatedAt:    &objInfo.LastModified,
-			Metadata:     objInfo.UserMetadata,
-		})
+		files = append(files, objectToFile(objInfo))
 	}
 
 	return files, nil
@@ -183,6 +257,8 @@ func detectContentType(fileName string) string {
 		return "application/json"
 	case ".xml":
 		return "application/xml"
+	case ".csv":
+		return "text/csv"
 	default:
 		return "application/octet-stream"
 	}
@@ -191,7 +267,19 @@ func detectContentType(fileName string) string {
 func (s *StoragePersister) download(ctx context.Context, path string) (io.ReadCloser, error) {
 	const op = "storage.minio.download"
 
-	obj, err := s.storage.Client.GetObject(ctx, s.storage.BucketName, path, minio.GetObjectOptions{})
+	obj, err := s.getObject(ctx, s.storage, path)
+	if err == nil || errors.Is(err, ErrFileNotFound) || s.replica == nil {
+		return obj, err
+	}
+
+	s.logger.Warn("primary storage failed, reading from replica", "func", op, "path", path, "error", err)
+	return s.getObject(ctx, *s.replica, path)
+}
+
+func (s *StoragePersister) getObject(ctx context.Context, storage miniostorage.MinioStorage, path string) (io.ReadCloser, error) {
+	const op = "storage.minio.getObject"
+
+	obj, err := storage.Client.GetObject(ctx, storage.BucketName, path, minio.GetObjectOptions{})
 	if err != nil {
 		s.logger.Error("failed to get file from minio", "func", op, "error", err)
 		return nil, errors.New("failed to get file from minio")
@@ -199,7 +287,7 @@ func (s *StoragePersister) download(ctx context.Context, path string) (io.ReadCl
 
 	_, err = obj.Stat()
 	if err != nil {
-		if err.(minio.ErrorResponse).Code == "NoSuchKey" {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
 			return nil, ErrFileNotFound
 		}
 
@@ -216,7 +304,7 @@ func (s *StoragePersister) delete(ctx context.Context, fileID, userID string) er
 	path := getFilePath(userID, fileID)
 	err := s.storage.Client.RemoveObject(ctx, s.storage.BucketName, path, minio.RemoveObjectOptions{})
 	if err != nil {
-		if err.(minio.ErrorResponse).Code == "NoSuchKey" {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
 			return ErrFileNotFound
 		}
 
@@ -224,37 +312,54 @@ func (s *StoragePersister) delete(ctx context.Context, fileID, userID string) er
 		return errors.New("failed to delete file info")
 	}
 
-	return nil
+	return s.replicate(ctx, replication.OperationDelete, path)
 }
 
 func (s *StoragePersister) getFileInfo(ctx context.Context, path string) (*file.File, error) {
 	const op = "storage.minio.getFileInfo"
 
-	objInfo, err := s.storage.Client.StatObject(ctx, s.storage.BucketName, path, minio.StatObjectOptions{})
+	objInfo, err := s.statObject(ctx, s.storage, path)
+	if err != nil && !errors.Is(err, ErrFileNotFound) && s.replica != nil {
+		s.logger.Warn("primary storage failed, reading info from replica", "func", op, "path", path, "error", err)
+		objInfo, err = s.statObject(ctx, *s.replica, path)
+	}
 	if err != nil {
-		if err.(minio.ErrorResponse).Code == "NoSuchKey" {
-			return nil, ErrFileNotFound
+		return nil, err
+	}
+
+	fileData := objectToFile(objInfo)
+	return &fileData, nil
+}
+
+func (s *StoragePersister) statObject(ctx context.Context, storage miniostorage.MinioStorage, path string) (minio.ObjectInfo, error) {
+	const op = "storage.minio.statObject"
+
+	objInfo, err := storage.Client.StatObject(ctx, storage.BucketName, path, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return objInfo, ErrFileNotFound
 		}
 
 		s.logger.Error("failed to get file info", "func", op, "path", path, "error", err)
-		return nil, errors.New("failed to get file info")
+		return objInfo, errors.New("failed to get file info")
 	}
 
-	grant := strings.Split(objInfo.UserMetadata["grant"], ";")
-	delete(objInfo.UserMetadata, "grant")
-	delete(objInfo.UserMetadata, "file_name")
-	delete(objInfo.UserMetadata, "public")
-
-	return &file.File{
-			ID:           strings.Join(strings.Split(objInfo.Key, "/")[1:], ""),
-			Name:         objInfo.UserMetadata["file_name"],
-			File:         int(objInfo.Size) != 0,
-			Public:   	  objInfo.UserMetadata["public"] == "true",
-			Mime:  		  objInfo.ContentType,
-			Grant:        grant,
-			Size:         int(objInfo.Size),
-			CreatedAt:    &objInfo.LastModified,
-			Metadata:     objInfo.UserMetadata,
-			User:         strings.Split(objInfo.Key, "/")[0],
-		}, nil
+	return objInfo, nil
+}
+
+// replicate ставит объект в очередь репликации. Ошибка возвращается вызывающему: объект, записанный
+// в основное хранилище без задачи, иначе молча не попадет в реплику
+func (s *StoragePersister) replicate(ctx context.Context, operation replication.Operation, path string) error {
+	const op = "storage.minio.replicate"
+
+	if s.queue == nil {
+		return nil
+	}
+
+	if err := s.queue.Enqueue(ctx, operation, path); err != nil {
+		s.logger.Error("failed to enqueue replication task", "func", op, "operation", operation, "path", path, "error", err)
+		return errors.New("failed to enqueue replication task")
+	}
+
+	return nil
 }
@@ -0,0 +1,210 @@
+package filesrepo
+
+import (
+	"astral/internal/domain/file"
+	"astral/internal/domain/replication"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+)
+
+// PresignPut регистрирует документ в состоянии pending и возвращает ссылку на загрузку содержимого
+// напрямую в MinIO. Пока клиент не загрузил содержимое, объект документа пустой. Метаданные входят
+// в подпись ссылки, поэтому при загрузке объект получает те же метаданные, что и заготовка
+func (s *StoragePersister) PresignPut(ctx context.Context, userID string, fileData file.File) (*file.Presigned, error) {
+	const op = "storage.minio.PresignPut"
+
+	if s.presigner == nil {
+		return nil, ErrPresignDisabled
+	}
+
+	if err := validateFileName(fileData.Name); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if fileData.Size <= 0 {
+		return nil, NewErrFileUpload("file size is required")
+	}
+	if int64(fileData.Size) > s.presign.MaxSize {
+		s.logger.Info("file size exceeds limit", "func", op, "filename", fileData.Name, "userID", userID, "size", fileData.Size)
+		return nil, NewErrFileUpload("file size exceeds limit")
+	}
+
+	contentType := detectContentType(fileData.Name)
+	fileID := uuid.New().String()
+	filePath := getFilePath(userID, fileID)
+
+	metadata := objectMetadata(fileData)
+	metadata[META_STATUS] = string(file.StatusPending)
+
+	_, err := s.storage.Client.PutObject(ctx, s.storage.BucketName, filePath, bytes.NewReader(nil), 0, minio.PutObjectOptions{
+		ContentType:  contentType,
+		UserMetadata: metadata,
+	})
+	if err != nil {
+		s.logger.Error("failed to create pending file in minio", "func", op, "filename", fileData.Name, "userID", userID, "error", err)
+		return nil, errors.New("failed to upload file")
+	}
+
+	headers := http.Header{}
+	headers.Set("Content-Type", contentType)
+	for k, v := range metadata {
+		headers.Set("X-Amz-Meta-"+k, v)
+	}
+
+	expiresAt := time.Now().Add(s.presign.TTL)
+	u, err := s.presigner.Client.PresignHeader(ctx, http.MethodPut, s.presigner.BucketName, filePath, s.presign.TTL, nil, headers)
+	if err != nil {
+		s.logger.Error("failed to presign upload url", "func", op, "path", filePath, "error", err)
+		return nil, errors.New("failed to presign upload url")
+	}
+
+	result := &file.Presigned{
+		ID:        fileID,
+		Method:    http.MethodPut,
+		URL:       u.String(),
+		Headers:   make(map[string]string, len(headers)),
+		ExpiresAt: expiresAt,
+	}
+	for k := range headers {
+		result.Headers[k] = headers.Get(k)
+	}
+
+	return result, nil
+}
+
+// PresignGet возвращает ссылку на скачивание содержимого документа напрямую из MinIO. Сжатый объект
+// отдается как есть с заголовком Content-Encoding, клиент распаковывает его сам
+func (s *StoragePersister) PresignGet(ctx context.Context, userID string, fileData file.File) (*file.Presigned, error) {
+	const op = "storage.minio.PresignGet"
+
+	if s.presigner == nil {
+		return nil, ErrPresignDisabled
+	}
+
+	params := url.Values{}
+	params.Set("response-content-disposition", fmt.Sprintf("attachment; filename=\"%s\"", fileData.Name))
+	if fileData.Mime != "" {
+		params.Set("response-content-type", fileData.Mime)
+	}
+	if fileData.Encoding != "" {
+		params.Set("response-content-encoding", fileData.Encoding)
+	}
+
+	filePath := getFilePath(userID, fileData.ID)
+	expiresAt := time.Now().Add(s.presign.TTL)
+	u, err := s.presigner.Client.PresignedGetObject(ctx, s.presigner.BucketName, filePath, s.presign.TTL, params)
+	if err != nil {
+		s.logger.Error("failed to presign download url", "func", op, "path", filePath, "error", err)
+		return nil, errors.New("failed to presign download url")
+	}
+
+	return &file.Presigned{
+		ID:        fileData.ID,
+		Method:    http.MethodGet,
+		URL:       u.String(),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// HashObject читает объект и возвращает его размер, SHA-256 в hex и ETag прочитанной версии.
+// Если объект перезаписали во время чтения, возвращается ErrObjectChanged
+func (s *StoragePersister) HashObject(ctx context.Context, userID, fileID string) (int64, string, string, error) {
+	const op = "storage.minio.HashObject"
+
+	filePath := getFilePath(userID, fileID)
+	objInfo, err := s.statObject(ctx, s.storage, filePath)
+	if err != nil {
+		return 0, "", "", err
+	}
+
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetMatchETag(objInfo.ETag); err != nil {
+		s.logger.Error("failed to set object etag", "func", op, "path", filePath, "error", err)
+		return 0, "", "", errors.New("failed to read file")
+	}
+
+	obj, err := s.storage.Client.GetObject(ctx, s.storage.BucketName, filePath, opts)
+	if err != nil {
+		s.logger.Error("failed to get file from minio", "func", op, "path", filePath, "error", err)
+		return 0, "", "", errors.New("failed to read file")
+	}
+	defer obj.Close()
+
+	sha := sha256.New()
+	size, err := io.Copy(sha, obj)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "PreconditionFailed" {
+			return 0, "", "", ErrObjectChanged
+		}
+
+		s.logger.Error("failed to read file", "func", op, "path", filePath, "error", err)
+		return 0, "", "", errors.New("failed to read file")
+	}
+
+	return size, hex.EncodeToString(sha.Sum(nil)), objInfo.ETag, nil
+}
+
+// FinalizeObject переводит документ прямой загрузки в active: сохраняет проверенную контрольную сумму
+// и извлеченные из содержимого метаданные intrinsic. Прямая загрузка не сжимается, поэтому признаки
+// сжатия, если клиент добавил их в запрос PUT, убираются. Метаданные меняются, только если объект
+// остался в версии etag, иначе возвращается ErrObjectChanged
+func (s *StoragePersister) FinalizeObject(ctx context.Context, userID, fileID, checksum, etag string, intrinsic map[string]string) (*file.File, error) {
+	const op = "storage.minio.FinalizeObject"
+
+	filePath := getFilePath(userID, fileID)
+	objInfo, err := s.statObject(ctx, s.storage, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := normalizeMetadata(objInfo.UserMetadata)
+	metadata["Content-Type"] = objInfo.ContentType
+	delete(metadata, META_STATUS)
+	delete(metadata, META_ENCODING)
+	delete(metadata, META_SIZE)
+	metadata[META_CHECKSUM] = checksum
+	for key := range metadata {
+		if file.IsIntrinsicKey(key) {
+			delete(metadata, key)
+		}
+	}
+	for key, value := range intrinsic {
+		metadata[key] = value
+	}
+
+	_, err = s.storage.Client.CopyObject(ctx, minio.CopyDestOptions{
+		Bucket:          s.storage.BucketName,
+		Object:          filePath,
+		UserMetadata:    metadata,
+		ReplaceMetadata: true,
+	}, minio.CopySrcOptions{
+		Bucket:    s.storage.BucketName,
+		Object:    filePath,
+		MatchETag: etag,
+	})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "PreconditionFailed" {
+			return nil, ErrObjectChanged
+		}
+
+		s.logger.Error("failed to finalize file in minio", "func", op, "path", filePath, "error", err)
+		return nil, errors.New("failed to finalize file")
+	}
+
+	if err := s.replicate(ctx, replication.OperationPut, filePath); err != nil {
+		return nil, err
+	}
+
+	return s.getFileInfo(ctx, filePath)
+}
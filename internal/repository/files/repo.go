@@ -9,7 +9,21 @@ import (
 type StorageRepo interface {
 	CreateFile(ctx context.Context, userID string, fileData file.File) (*file.File, error)
 	GetFileByID(ctx context.Context, userID, fileID string) (io.ReadCloser, error)
+	OpenObject(ctx context.Context, userID, fileID string) (file.Object, error)
+	GetRawFile(ctx context.Context, userID, fileID string) (io.ReadCloser, int64, string, error)
 	DeleteFile(ctx context.Context, fileID, userID string) error
 	GetFileInfo(ctx context.Context, userID, fileID string) (*file.File, error)
+	UpdateFileInfo(ctx context.Context, userID string, fileData file.File) (*file.File, error)
+	// CopyFile копирует объект userID/fileID в toUserID/fileData.ID на стороне хранилища, пустой
+	// fileData.ID - новый документ. Сведения о документе берутся из fileData
+	CopyFile(ctx context.Context, userID, fileID, toUserID string, fileData file.File) (*file.File, error)
 	ListUserFiles(ctx context.Context, userID string) ([]file.File, error)
+	ListAllFiles(ctx context.Context) ([]file.File, error)
+	SetStatus(ctx context.Context, userID, fileID string, status file.Status) error
+	// PresignPut создает документ в состоянии pending и подписывает ссылку на прямую загрузку
+	// содержимого, PresignGet - на скачивание. Без настроенной подписи возвращают ErrPresignDisabled
+	PresignPut(ctx context.Context, userID string, fileData file.File) (*file.Presigned, error)
+	PresignGet(ctx context.Context, userID string, fileData file.File) (*file.Presigned, error)
+	HashObject(ctx context.Context, userID, fileID string) (int64, string, string, error)
+	FinalizeObject(ctx context.Context, userID, fileID, checksum, etag string, intrinsic map[string]string) (*file.File, error)
 }
@@ -0,0 +1,55 @@
+package filesrepo
+
+import (
+	"astral/internal/domain/file"
+	"astral/internal/domain/replication"
+	"context"
+	"errors"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// SetStatus меняет состояние документа в метаданных объекта, содержимое не меняется
+func (s *StoragePersister) SetStatus(ctx context.Context, userID, fileID string, status file.Status) error {
+	const op = "storage.minio.SetStatus"
+
+	filePath := getFilePath(userID, fileID)
+	objInfo, err := s.statObject(ctx, s.storage, filePath)
+	if err != nil {
+		return err
+	}
+
+	metadata := normalizeMetadata(objInfo.UserMetadata)
+	metadata["Content-Type"] = objInfo.ContentType
+	delete(metadata, META_STATUS)
+	if status != file.StatusActive {
+		metadata[META_STATUS] = string(status)
+	}
+
+	_, err = s.storage.Client.CopyObject(ctx, minio.CopyDestOptions{
+		Bucket:          s.storage.BucketName,
+		Object:          filePath,
+		UserMetadata:    metadata,
+		ReplaceMetadata: true,
+	}, minio.CopySrcOptions{
+		Bucket: s.storage.BucketName,
+		Object: filePath,
+	})
+	if err != nil {
+		s.logger.Error("failed to update file status in minio", "func", op, "path", filePath, "status", status, "error", err)
+		return errors.New("failed to update file status")
+	}
+
+	// Поврежденный объект не реплицируем: в реплике может остаться целая копия
+	if status != file.StatusError {
+		return s.replicate(ctx, replication.OperationPut, filePath)
+	}
+
+	return nil
+}
+
+// ListAllFiles возвращает все объекты хранилища как документы, в том числе служебные объекты
+// без метаданных документа (части multipart загрузок S3)
+func (s *StoragePersister) ListAllFiles(ctx context.Context) ([]file.File, error) {
+	return s.listFiles(ctx, "")
+}
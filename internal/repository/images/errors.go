@@ -0,0 +1,10 @@
+package imagesrepo
+
+import "errors"
+
+var (
+	ErrVariantNotSaved = errors.New("failed to save image variant")
+	ErrVariantNotRead  = errors.New("failed to read image variant")
+
+	ErrVariantNotRemoved = errors.New("failed to remove image variants")
+)
@@ -0,0 +1,209 @@
+package imagesrepo
+
+import (
+	"astral/env"
+	"astral/internal/domain/imaging"
+	miniostorage "astral/internal/repository/db/minio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	// Варианты в Redis лежат в хэшах image:<ключ варианта>
+	CACHE_KEY_PREFIX = "image:"
+
+	META_WIDTH  = "width"
+	META_HEIGHT = "height"
+)
+
+// DocumentPrefix - префикс ключей всех вариантов документа
+func DocumentPrefix(owner, ID string) string {
+	return owner + "/" + ID + "/"
+}
+
+// UserPrefix - префикс ключей всех вариантов документов пользователя
+func UserPrefix(login string) string {
+	return login + "/"
+}
+
+type ImagesPersister struct {
+	storage miniostorage.MinioStorage
+	cache   *redis.Client
+	cfg     env.Images
+	logger  *slog.Logger
+}
+
+// storage - отдельный бакет вариантов: в бакет документов они не попадают, и их не видят
+// сверка, проверка целостности и репликация
+func NewImagesPersister(storage miniostorage.MinioStorage, cache *redis.Client, cfg env.Images, logger *slog.Logger) *ImagesPersister {
+	return &ImagesPersister{
+		storage: storage,
+		cache:   cache,
+		cfg:     cfg,
+		logger:  logger,
+	}
+}
+
+func (p *ImagesPersister) GetVariant(ctx context.Context, key string) (*imaging.Variant, error) {
+	const op = "repository.images.GetVariant"
+
+	if variant := p.getCached(ctx, key); variant != nil {
+		return variant, nil
+	}
+
+	obj, err := p.storage.Client.GetObject(ctx, p.storage.BucketName, key, minio.GetObjectOptions{})
+	if err != nil {
+		p.logger.Error("failed to get variant", "func", op, "key", key, "error", err)
+		return nil, ErrVariantNotRead
+	}
+	defer obj.Close()
+
+	info, err := obj.Stat()
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, nil
+		}
+		p.logger.Error("failed to stat variant", "func", op, "key", key, "error", err)
+		return nil, ErrVariantNotRead
+	}
+
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		p.logger.Error("failed to read variant", "func", op, "key", key, "error", err)
+		return nil, ErrVariantNotRead
+	}
+
+	width, _ := strconv.Atoi(info.UserMetadata[META_WIDTH])
+	height, _ := strconv.Atoi(info.UserMetadata[META_HEIGHT])
+	variant := &imaging.Variant{
+		Data:   data,
+		Mime:   info.ContentType,
+		Width:  width,
+		Height: height,
+		Cached: true,
+	}
+	p.putCached(ctx, key, *variant)
+
+	return variant, nil
+}
+
+func (p *ImagesPersister) PutVariant(ctx context.Context, key string, variant imaging.Variant) error {
+	const op = "repository.images.PutVariant"
+
+	_, err := p.storage.Client.PutObject(ctx, p.storage.BucketName, key, bytes.NewReader(variant.Data), int64(len(variant.Data)), minio.PutObjectOptions{
+		ContentType: variant.Mime,
+		UserMetadata: map[string]string{
+			META_WIDTH:  strconv.Itoa(variant.Width),
+			META_HEIGHT: strconv.Itoa(variant.Height),
+		},
+	})
+	if err != nil {
+		p.logger.Error("failed to put variant", "func", op, "key", key, "error", err)
+		return ErrVariantNotSaved
+	}
+
+	p.putCached(ctx, key, variant)
+	return nil
+}
+
+// RemoveVariants удаляет из бакета и из Redis все варианты с префиксом ключа: owner/ID/ - варианты
+// одного документа, owner/ - все варианты пользователя
+func (p *ImagesPersister) RemoveVariants(ctx context.Context, prefix string) error {
+	const op = "repository.images.RemoveVariants"
+
+	objectCh := p.storage.Client.ListObjects(ctx, p.storage.BucketName, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	})
+
+	for objInfo := range objectCh {
+		if objInfo.Err != nil {
+			p.logger.Error("error listing variants", "func", op, "prefix", prefix, "error", objInfo.Err)
+			return ErrVariantNotRemoved
+		}
+
+		err := p.storage.Client.RemoveObject(ctx, p.storage.BucketName, objInfo.Key, minio.RemoveObjectOptions{})
+		if err != nil && minio.ToErrorResponse(err).Code != "NoSuchKey" {
+			p.logger.Error("failed to remove variant", "func", op, "key", objInfo.Key, "error", err)
+			return ErrVariantNotRemoved
+		}
+	}
+
+	var cursor uint64
+	for {
+		keys, next, err := p.cache.Scan(ctx, cursor, CACHE_KEY_PREFIX+prefix+"*", 1000).Result()
+		if err != nil {
+			p.logger.Error("failed to scan cached variants", "func", op, "prefix", prefix, "error", err)
+			return ErrVariantNotRemoved
+		}
+
+		if len(keys) > 0 {
+			if err := p.cache.Del(ctx, keys...).Err(); err != nil {
+				p.logger.Error("failed to remove cached variants", "func", op, "prefix", prefix, "error", err)
+				return ErrVariantNotRemoved
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// getCached - промах Redis не ошибка: вариант еще может быть в бакете
+func (p *ImagesPersister) getCached(ctx context.Context, key string) *imaging.Variant {
+	const op = "repository.images.getCached"
+
+	values, err := p.cache.HGetAll(ctx, CACHE_KEY_PREFIX+key).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			p.logger.Warn("failed to read cached variant", "func", op, "key", key, "error", err)
+		}
+		return nil
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	width, _ := strconv.Atoi(values[META_WIDTH])
+	height, _ := strconv.Atoi(values[META_HEIGHT])
+	return &imaging.Variant{
+		Data:   []byte(values["data"]),
+		Mime:   values["mime"],
+		Width:  width,
+		Height: height,
+		Cached: true,
+	}
+}
+
+// putCached кладет в Redis только небольшие варианты, большие читаются из бакета
+func (p *ImagesPersister) putCached(ctx context.Context, key string, variant imaging.Variant) {
+	const op = "repository.images.putCached"
+
+	if len(variant.Data) > p.cfg.RedisMaxSize || p.cfg.RedisTTL <= 0 {
+		return
+	}
+
+	cacheKey := CACHE_KEY_PREFIX + key
+	_, err := p.cache.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, cacheKey,
+			"data", variant.Data,
+			"mime", variant.Mime,
+			META_WIDTH, variant.Width,
+			META_HEIGHT, variant.Height,
+		)
+		pipe.Expire(ctx, cacheKey, p.cfg.RedisTTL)
+		return nil
+	})
+	if err != nil {
+		p.logger.Warn("failed to cache variant", "func", op, "key", key, "error", err)
+	}
+}
@@ -0,0 +1,14 @@
+package imagesrepo
+
+import (
+	"astral/internal/domain/imaging"
+	"context"
+)
+
+type ImagesRepo interface {
+	// GetVariant ищет готовый вариант сначала в Redis, затем в бакете вариантов. nil - варианта нет
+	GetVariant(ctx context.Context, key string) (*imaging.Variant, error)
+	PutVariant(ctx context.Context, key string, variant imaging.Variant) error
+	// RemoveVariants удаляет все варианты, ключ которых начинается с prefix
+	RemoveVariants(ctx context.Context, prefix string) error
+}
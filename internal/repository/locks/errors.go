@@ -0,0 +1,9 @@
+package locksrepo
+
+import "errors"
+
+var (
+	ErrLockNotFound = errors.New("document is not locked")
+	ErrLockHeld     = errors.New("document is locked by another user")
+	ErrNotHolder    = errors.New("lock is held by another user")
+)
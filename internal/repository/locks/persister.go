@@ -0,0 +1,168 @@
+package locksrepo
+
+import (
+	"astral/internal/domain/lock"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	LOCK_KEY_PREFIX = "astral:locks:"
+)
+
+// acquireScript записывает блокировку, если ключ свободен или занят тем же держателем,
+// иначе возвращает текущую блокировку. Проверка и запись выполняются атомарно
+var acquireScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current and cjson.decode(current).holder ~= ARGV[2] then
+	return {0, current}
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[3])
+return {1, ARGV[1]}
+`)
+
+// releaseScript удаляет блокировку держателя: 1 - снята, 0 - ее нет, -1 - держит другой
+var releaseScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current then
+	return 0
+end
+if cjson.decode(current).holder ~= ARGV[1] then
+	return -1
+end
+redis.call('DEL', KEYS[1])
+return 1
+`)
+
+var breakScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current then
+	redis.call('DEL', KEYS[1])
+end
+return current
+`)
+
+// LocksPersister хранит блокировки в Redis с TTL до окончания блокировки, поэтому
+// они общие для всех экземпляров Astral и снимаются сами по истечении срока
+type LocksPersister struct {
+	client *redis.Client
+	logger *slog.Logger
+}
+
+func NewLocksPersister(client *redis.Client, logger *slog.Logger) *LocksPersister {
+	return &LocksPersister{
+		client: client,
+		logger: logger,
+	}
+}
+
+func (p *LocksPersister) Acquire(ctx context.Context, l lock.Lock) (*lock.Lock, error) {
+	const op = "repository.locks.Acquire"
+
+	ttl := time.Until(l.ExpiresAt)
+	if ttl <= 0 {
+		return nil, fmt.Errorf("%s: lock is already expired", op)
+	}
+
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := acquireScript.Run(ctx, p.client, []string{LOCK_KEY_PREFIX + l.DocumentID}, data, l.Holder, ttl.Milliseconds()).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(res) != 2 {
+		return nil, fmt.Errorf("%s: unexpected script result", op)
+	}
+
+	current, err := decodeLock(res[1])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if acquired, _ := res[0].(int64); acquired != 1 {
+		return current, ErrLockHeld
+	}
+
+	return current, nil
+}
+
+func (p *LocksPersister) Get(ctx context.Context, documentID string) (*lock.Lock, error) {
+	const op = "repository.locks.Get"
+
+	data, err := p.client.Get(ctx, LOCK_KEY_PREFIX+documentID).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := decodeLock(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res, nil
+}
+
+func (p *LocksPersister) Release(ctx context.Context, documentID, holder string) error {
+	const op = "repository.locks.Release"
+
+	res, err := releaseScript.Run(ctx, p.client, []string{LOCK_KEY_PREFIX + documentID}, holder).Int64()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	switch res {
+	case 0:
+		return ErrLockNotFound
+	case -1:
+		return ErrNotHolder
+	}
+
+	return nil
+}
+
+func (p *LocksPersister) Break(ctx context.Context, documentID string) (*lock.Lock, error) {
+	const op = "repository.locks.Break"
+
+	res, err := breakScript.Run(ctx, p.client, []string{LOCK_KEY_PREFIX + documentID}).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrLockNotFound
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	current, err := decodeLock(res)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return current, nil
+}
+
+func decodeLock(value any) (*lock.Lock, error) {
+	data, ok := value.(string)
+	if !ok {
+		return nil, errors.New("unexpected lock value")
+	}
+
+	var res lock.Lock
+	if err := json.Unmarshal([]byte(data), &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,18 @@
+package locksrepo
+
+import (
+	"astral/internal/domain/lock"
+	"context"
+)
+
+type LocksRepo interface {
+	// Acquire берет блокировку или продлевает ее, если документ уже заблокирован тем же пользователем.
+	// Если блокировку держит другой пользователь, возвращается она и ErrLockHeld
+	Acquire(ctx context.Context, l lock.Lock) (*lock.Lock, error)
+	// Get возвращает действующую блокировку документа или nil, если ее нет
+	Get(ctx context.Context, documentID string) (*lock.Lock, error)
+	// Release снимает блокировку, только если ее держит holder
+	Release(ctx context.Context, documentID, holder string) error
+	// Break снимает блокировку независимо от держателя и возвращает ее
+	Break(ctx context.Context, documentID string) (*lock.Lock, error)
+}
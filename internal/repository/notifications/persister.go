@@ -0,0 +1,142 @@
+package notificationsrepo
+
+import (
+	"astral/internal/domain/notification"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	NOTIFICATIONS_CHANNEL = "astral:notifications"
+	HISTORY_KEY_PREFIX    = "astral:notifications:"
+	DATA_FIELD            = "data"
+)
+
+// NotificationsPersister хранит историю уведомлений каждого пользователя в потоке Redis
+// ограниченной длины, а живые уведомления рассылает через pub/sub
+type NotificationsPersister struct {
+	client    *redis.Client
+	logger    *slog.Logger
+	history   int64
+	retention time.Duration
+}
+
+func NewNotificationsPersister(client *redis.Client, logger *slog.Logger, history int64, retention time.Duration) *NotificationsPersister {
+	return &NotificationsPersister{
+		client:    client,
+		logger:    logger,
+		history:   history,
+		retention: retention,
+	}
+}
+
+// message - уведомление в канале pub/sub, получатель передается явно, потому что в JSON для клиента его нет
+type message struct {
+	Recipient    string                    `json:"recipient"`
+	Notification notification.Notification `json:"notification"`
+}
+
+func (p *NotificationsPersister) Append(ctx context.Context, n notification.Notification) (*notification.Notification, error) {
+	const op = "repository.notifications.Append"
+
+	data, err := json.Marshal(n)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	key := HISTORY_KEY_PREFIX + n.Recipient
+	id, err := p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: p.history,
+		Approx: true,
+		Values: map[string]any{DATA_FIELD: data},
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// История неактивного пользователя удаляется целиком, чтобы ключи не копились
+	if err := p.client.Expire(ctx, key, p.retention).Err(); err != nil {
+		p.logger.Warn("failed to set notifications history ttl", "func", op, "login", n.Recipient, "error", err)
+	}
+
+	n.ID = id
+	data, err = json.Marshal(message{Recipient: n.Recipient, Notification: n})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := p.client.Publish(ctx, NOTIFICATIONS_CHANNEL, data).Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &n, nil
+}
+
+func (p *NotificationsPersister) Since(ctx context.Context, login, afterID string, limit int64) ([]notification.Notification, error) {
+	const op = "repository.notifications.Since"
+
+	entries, err := p.client.XRangeN(ctx, HISTORY_KEY_PREFIX+login, "("+afterID, "+", limit).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res := make([]notification.Notification, 0, len(entries))
+	for _, entry := range entries {
+		data, ok := entry.Values[DATA_FIELD].(string)
+		if !ok {
+			p.logger.Warn("notification without data is skipped", "func", op, "login", login, "id", entry.ID)
+			continue
+		}
+
+		var n notification.Notification
+		if err := json.Unmarshal([]byte(data), &n); err != nil {
+			p.logger.Warn("failed to unmarshal notification", "func", op, "login", login, "id", entry.ID, "error", err)
+			continue
+		}
+		n.ID = entry.ID
+		n.Recipient = login
+
+		res = append(res, n)
+	}
+
+	return res, nil
+}
+
+func (p *NotificationsPersister) Listen(ctx context.Context, fn func(notification.Notification)) error {
+	const op = "repository.notifications.Listen"
+
+	pubsub := p.client.Subscribe(ctx, NOTIFICATIONS_CHANNEL)
+	defer pubsub.Close()
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return fmt.Errorf("%s: %w", op, errors.New("subscription closed"))
+			}
+
+			var m message
+			if err := json.Unmarshal([]byte(msg.Payload), &m); err != nil {
+				p.logger.Warn("failed to unmarshal notification", "func", op, "error", err)
+				continue
+			}
+			m.Notification.Recipient = m.Recipient
+
+			fn(m.Notification)
+		}
+	}
+}
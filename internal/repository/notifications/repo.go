@@ -0,0 +1,15 @@
+package notificationsrepo
+
+import (
+	"astral/internal/domain/notification"
+	"context"
+)
+
+type NotificationsRepo interface {
+	// Append сохраняет уведомление в истории получателя и рассылает его всем экземплярам Astral
+	Append(ctx context.Context, n notification.Notification) (*notification.Notification, error)
+	// Since возвращает уведомления получателя, созданные после afterID, в порядке создания
+	Since(ctx context.Context, login, afterID string, limit int64) ([]notification.Notification, error)
+	// Listen вызывает fn для каждого разосланного уведомления, пока не отменен контекст
+	Listen(ctx context.Context, fn func(notification.Notification)) error
+}
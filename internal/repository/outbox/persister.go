@@ -0,0 +1,275 @@
+package outboxrepo
+
+import (
+	"astral/internal/domain/event"
+	pg "astral/internal/repository/db/postgres"
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+const (
+	TABLE_OUTBOX_EVENTS = "outbox_events"
+
+	// Время, на которое событие скрывается от других экземпляров relay после выборки
+	EVENT_LEASE = "INTERVAL '1 minute'"
+)
+
+// Execer - общее у *sqlx.DB и *sqlx.Tx, через него событие пишется в транзакции изменения состояния
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type OutboxPersister struct {
+	dial    goqu.DialectWrapper
+	storage *pg.Storage
+	logger  *slog.Logger
+}
+
+func NewOutboxPersister(storage *pg.Storage, logger *slog.Logger) *OutboxPersister {
+	return &OutboxPersister{
+		dial:    goqu.Dialect(pg.DRIVER),
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Write добавляет события в outbox через переданное соединение или транзакцию
+func Write(ctx context.Context, exec Execer, events ...event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	rows := make([]any, 0, len(events))
+	for _, e := range events {
+		rows = append(rows, goqu.Record{
+			"event_id":   e.ID,
+			"event_type": string(e.Type),
+			"event_key":  e.Key,
+			"payload":    string(e.Payload),
+			"created_at": e.CreatedAt,
+		})
+	}
+
+	query, _, err := goqu.Dialect(pg.DRIVER).Insert(TABLE_OUTBOX_EVENTS).Rows(rows...).ToSQL()
+	if err != nil {
+		return err
+	}
+
+	_, err = exec.ExecContext(ctx, query)
+	return err
+}
+
+func (p *OutboxPersister) Append(ctx context.Context, events ...event.Event) error {
+	const op = "repository.outbox.persister.Append"
+
+	if err := Write(ctx, p.storage.DB, events...); err != nil {
+		p.logger.Error("failed to append outbox events", "func", op, "count", len(events), "error", err)
+		return errors.New("failed to append outbox events")
+	}
+
+	return nil
+}
+
+func (p *OutboxPersister) Stage(ctx context.Context, hold time.Duration, e event.Event) error {
+	const op = "repository.outbox.persister.Stage"
+
+	query, _, err := p.dial.Insert(TABLE_OUTBOX_EVENTS).
+		Rows(goqu.Record{
+			"event_id":        e.ID,
+			"event_type":      string(e.Type),
+			"event_key":       e.Key,
+			"payload":         string(e.Payload),
+			"created_at":      e.CreatedAt,
+			"next_attempt_at": goqu.L("CURRENT_TIMESTAMP + ? * INTERVAL '1 second'", int64(hold.Seconds())),
+		}).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build stage event query", "func", op, "error", err)
+		return errors.New("failed to build stage event query")
+	}
+
+	if _, err := p.storage.DB.ExecContext(ctx, query); err != nil {
+		p.logger.Error("failed to execute stage event query", "func", op, "eventID", e.ID, "error", err)
+		return errors.New("failed to execute stage event query")
+	}
+
+	return nil
+}
+
+func (p *OutboxPersister) Confirm(ctx context.Context, e event.Event) error {
+	const op = "repository.outbox.persister.Confirm"
+
+	query, _, err := p.dial.Update(TABLE_OUTBOX_EVENTS).
+		Set(goqu.Record{
+			"payload":         string(e.Payload),
+			"next_attempt_at": goqu.L("CURRENT_TIMESTAMP"),
+		}).
+		Where(goqu.C("event_id").Eq(e.ID), goqu.C("published_at").IsNull()).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build confirm event query", "func", op, "error", err)
+		return errors.New("failed to build confirm event query")
+	}
+
+	if _, err := p.storage.DB.ExecContext(ctx, query); err != nil {
+		p.logger.Error("failed to execute confirm event query", "func", op, "eventID", e.ID, "error", err)
+		return errors.New("failed to execute confirm event query")
+	}
+
+	return nil
+}
+
+func (p *OutboxPersister) Discard(ctx context.Context, eventID string) error {
+	const op = "repository.outbox.persister.Discard"
+
+	query, _, err := p.dial.Delete(TABLE_OUTBOX_EVENTS).
+		Where(goqu.C("event_id").Eq(eventID), goqu.C("published_at").IsNull()).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build discard event query", "func", op, "error", err)
+		return errors.New("failed to build discard event query")
+	}
+
+	if _, err := p.storage.DB.ExecContext(ctx, query); err != nil {
+		p.logger.Error("failed to execute discard event query", "func", op, "eventID", eventID, "error", err)
+		return errors.New("failed to execute discard event query")
+	}
+
+	return nil
+}
+
+func (p *OutboxPersister) FetchDue(ctx context.Context, limit int) ([]event.Record, error) {
+	const op = "repository.outbox.persister.FetchDue"
+
+	due := p.dial.From(TABLE_OUTBOX_EVENTS).
+		Select("seq").
+		Where(
+			goqu.C("published_at").IsNull(),
+			goqu.C("next_attempt_at").Lte(goqu.L("CURRENT_TIMESTAMP")),
+		).
+		Order(goqu.C("seq").Asc()).
+		Limit(uint(limit)).
+		ForUpdate(exp.SkipLocked)
+
+	query, _, err := p.dial.Update(TABLE_OUTBOX_EVENTS).
+		Set(goqu.Record{"next_attempt_at": goqu.L("CURRENT_TIMESTAMP + " + EVENT_LEASE)}).
+		Where(goqu.C("seq").In(due)).
+		Returning("seq", "event_id", "event_type", "event_key", "payload", "created_at", "attempts").
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build fetch due events query", "func", op, "error", err)
+		return nil, errors.New("failed to build fetch due events query")
+	}
+
+	rows, err := p.storage.DB.QueryContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute fetch due events query", "func", op, "error", err)
+		return nil, errors.New("failed to execute fetch due events query")
+	}
+	defer rows.Close()
+
+	var records []event.Record
+	for rows.Next() {
+		var record event.Record
+		var eventType string
+		var payload []byte
+		err = rows.Scan(&record.Seq, &record.Event.ID, &eventType, &record.Event.Key, &payload, &record.Event.CreatedAt, &record.Attempts)
+		if err != nil {
+			p.logger.Error("failed to scan outbox event", "func", op, "error", err)
+			return nil, errors.New("failed to scan outbox event")
+		}
+
+		record.Event.Type = event.Type(eventType)
+		record.Event.Payload = payload
+		records = append(records, record)
+	}
+
+	// UPDATE ... RETURNING не сохраняет порядок подзапроса
+	slices.SortFunc(records, func(a, b event.Record) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+
+	return records, nil
+}
+
+func (p *OutboxPersister) MarkPublished(ctx context.Context, seq int64) error {
+	const op = "repository.outbox.persister.MarkPublished"
+
+	query, _, err := p.dial.Update(TABLE_OUTBOX_EVENTS).
+		Set(goqu.Record{
+			"published_at": goqu.L("CURRENT_TIMESTAMP"),
+			"attempts":     goqu.L("attempts + 1"),
+			"last_error":   nil,
+		}).
+		Where(goqu.C("seq").Eq(seq)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build mark published query", "func", op, "seq", seq, "error", err)
+		return errors.New("failed to build mark published query")
+	}
+
+	_, err = p.storage.DB.ExecContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute mark published query", "func", op, "seq", seq, "error", err)
+		return errors.New("failed to execute mark published query")
+	}
+
+	return nil
+}
+
+func (p *OutboxPersister) Fail(ctx context.Context, seq int64, reason string, backoff time.Duration) error {
+	const op = "repository.outbox.persister.Fail"
+
+	query, _, err := p.dial.Update(TABLE_OUTBOX_EVENTS).
+		Set(goqu.Record{
+			"attempts":        goqu.L("attempts + 1"),
+			"last_error":      reason,
+			"next_attempt_at": goqu.L("CURRENT_TIMESTAMP + ? * INTERVAL '1 second'", int64(backoff.Seconds())),
+		}).
+		Where(goqu.C("seq").Eq(seq)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build fail event query", "func", op, "seq", seq, "error", err)
+		return errors.New("failed to build fail event query")
+	}
+
+	_, err = p.storage.DB.ExecContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute fail event query", "func", op, "seq", seq, "error", err)
+		return errors.New("failed to execute fail event query")
+	}
+
+	return nil
+}
+
+func (p *OutboxPersister) DeletePublished(ctx context.Context, olderThan time.Duration) (int64, error) {
+	const op = "repository.outbox.persister.DeletePublished"
+
+	query, _, err := p.dial.Delete(TABLE_OUTBOX_EVENTS).
+		Where(
+			goqu.C("published_at").IsNotNull(),
+			goqu.C("published_at").Lt(goqu.L("CURRENT_TIMESTAMP - ? * INTERVAL '1 second'", int64(olderThan.Seconds()))),
+		).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build delete published query", "func", op, "error", err)
+		return 0, errors.New("failed to build delete published query")
+	}
+
+	res, err := p.storage.DB.ExecContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute delete published query", "func", op, "error", err)
+		return 0, errors.New("failed to execute delete published query")
+	}
+
+	count, _ := res.RowsAffected()
+	return count, nil
+}
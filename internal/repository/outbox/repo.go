@@ -0,0 +1,21 @@
+package outboxrepo
+
+import (
+	"astral/internal/domain/event"
+	"context"
+	"time"
+)
+
+type OutboxRepo interface {
+	Append(ctx context.Context, events ...event.Event) error
+	// Stage резервирует событие изменения, которое пишется мимо PostgreSQL: relay не видит его hold,
+	// Confirm после изменения делает его доступным сразу, Discard после неудачи удаляет. Если ни то,
+	// ни другое не случилось, событие публикуется по истечении hold
+	Stage(ctx context.Context, hold time.Duration, e event.Event) error
+	Confirm(ctx context.Context, e event.Event) error
+	Discard(ctx context.Context, eventID string) error
+	FetchDue(ctx context.Context, limit int) ([]event.Record, error)
+	MarkPublished(ctx context.Context, seq int64) error
+	Fail(ctx context.Context, seq int64, reason string, backoff time.Duration) error
+	DeletePublished(ctx context.Context, olderThan time.Duration) (int64, error)
+}
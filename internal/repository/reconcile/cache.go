@@ -0,0 +1,124 @@
+package reconcilerepo
+
+import (
+	"astral/internal/domain/reconcile"
+	locksrepo "astral/internal/repository/locks"
+	notificationsrepo "astral/internal/repository/notifications"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// Ключи кэша сервиса документов: file::"<id>" и list:<login>:<фильтр>
+	FILE_KEY_PREFIX = "file::"
+	LIST_KEY_PREFIX = "list:"
+
+	SCAN_BATCH_SIZE = 1000
+)
+
+// ListCacheEntries перебирает ключи Redis, которые ссылаются на документы и пользователей
+func (p *ReconcilePersister) ListCacheEntries(ctx context.Context) ([]reconcile.CacheEntry, error) {
+	const op = "repository.reconcile.cache.ListCacheEntries"
+
+	var entries []reconcile.CacheEntry
+	for _, prefix := range []string{FILE_KEY_PREFIX, LIST_KEY_PREFIX, locksrepo.LOCK_KEY_PREFIX, notificationsrepo.HISTORY_KEY_PREFIX} {
+		keys, err := p.scan(ctx, prefix)
+		if err != nil {
+			p.logger.Error("failed to scan cache keys", "func", op, "prefix", prefix, "error", err)
+			return nil, errors.New("failed to list cache entries")
+		}
+
+		for _, key := range keys {
+			entry, err := p.cacheEntry(ctx, prefix, key)
+			if err != nil {
+				p.logger.Error("failed to read cache entry", "func", op, "key", key, "error", err)
+				return nil, errors.New("failed to list cache entries")
+			}
+			if entry != nil {
+				entries = append(entries, *entry)
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+func (p *ReconcilePersister) DeleteCacheEntry(ctx context.Context, key string) error {
+	const op = "repository.reconcile.cache.DeleteCacheEntry"
+
+	if err := p.cache.Del(ctx, key).Err(); err != nil {
+		p.logger.Error("failed to delete cache entry", "func", op, "key", key, "error", err)
+		return errors.New("failed to delete cache entry")
+	}
+
+	return nil
+}
+
+func (p *ReconcilePersister) scan(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	var cursor uint64
+	for {
+		batch, next, err := p.cache.Scan(ctx, cursor, prefix+"*", SCAN_BATCH_SIZE).Result()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, batch...)
+
+		cursor = next
+		if cursor == 0 {
+			return keys, nil
+		}
+	}
+}
+
+// cacheEntry разбирает ключ, nil - ключ подошел под префикс, но не относится к сверяемым
+func (p *ReconcilePersister) cacheEntry(ctx context.Context, prefix, key string) (*reconcile.CacheEntry, error) {
+	suffix := strings.TrimPrefix(key, prefix)
+
+	switch prefix {
+	case FILE_KEY_PREFIX:
+		var documentID string
+		if err := json.Unmarshal([]byte(suffix), &documentID); err != nil {
+			return nil, nil
+		}
+
+		value, err := p.cache.Get(ctx, key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var cached struct {
+			Name     string `json:"name"`
+			Checksum string `json:"checksum"`
+		}
+		// Нечитаемая запись тоже устаревшая: сервис документов ее не разберет
+		json.Unmarshal(value, &cached)
+
+		return &reconcile.CacheEntry{
+			Key:        key,
+			DocumentID: documentID,
+			Copy:       true,
+			Name:       cached.Name,
+			Checksum:   cached.Checksum,
+		}, nil
+	case LIST_KEY_PREFIX:
+		login, _, ok := strings.Cut(suffix, ":")
+		if !ok {
+			return nil, nil
+		}
+		return &reconcile.CacheEntry{Key: key, Login: login}, nil
+	case locksrepo.LOCK_KEY_PREFIX:
+		return &reconcile.CacheEntry{Key: key, DocumentID: suffix}, nil
+	case notificationsrepo.HISTORY_KEY_PREFIX:
+		return &reconcile.CacheEntry{Key: key, Login: suffix}, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,68 @@
+package reconcilerepo
+
+import (
+	"astral/internal/domain/file"
+	"astral/internal/domain/reconcile"
+	"astral/internal/domain/replication"
+	filesrepo "astral/internal/repository/files"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+)
+
+func (p *ReconcilePersister) ListObjects(ctx context.Context) ([]reconcile.Object, error) {
+	const op = "repository.reconcile.objects.ListObjects"
+
+	objectCh := p.objects.Client.ListObjects(ctx, p.objects.BucketName, minio.ListObjectsOptions{
+		Recursive:    true,
+		WithMetadata: true,
+	})
+
+	var objects []reconcile.Object
+	for objInfo := range objectCh {
+		if objInfo.Err != nil {
+			p.logger.Error("error listing objects", "func", op, "bucket", p.objects.BucketName, "error", objInfo.Err)
+			return nil, errors.New("error listing objects")
+		}
+
+		// Ключи метаданных приходят в виде заголовков, как и в репозитории документов
+		metadata := make(map[string]string, len(objInfo.UserMetadata))
+		for k, v := range objInfo.UserMetadata {
+			metadata[strings.TrimPrefix(strings.ToLower(k), "x-amz-meta-")] = v
+		}
+		name, document := metadata[filesrepo.META_FILE_NAME]
+
+		objects = append(objects, reconcile.Object{
+			Key:          objInfo.Key,
+			Size:         objInfo.Size,
+			LastModified: objInfo.LastModified,
+			Document:     document,
+			Name:         name,
+			Checksum:     metadata[filesrepo.META_CHECKSUM],
+			Pending:      metadata[filesrepo.META_STATUS] == string(file.StatusPending),
+		})
+	}
+
+	return objects, nil
+}
+
+// RemoveObject удаляет объект из основного хранилища и ставит удаление в очередь репликации
+func (p *ReconcilePersister) RemoveObject(ctx context.Context, key string) error {
+	const op = "repository.reconcile.objects.RemoveObject"
+
+	err := p.objects.Client.RemoveObject(ctx, p.objects.BucketName, key, minio.RemoveObjectOptions{})
+	if err != nil && minio.ToErrorResponse(err).Code != "NoSuchKey" {
+		p.logger.Error("failed to remove object", "func", op, "key", key, "error", err)
+		return errors.New("failed to remove object")
+	}
+
+	if p.queue != nil {
+		if err := p.queue.Enqueue(ctx, replication.OperationDelete, key); err != nil {
+			p.logger.Error("failed to enqueue replication task", "func", op, "key", key, "error", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package reconcilerepo
+
+import (
+	"astral/internal/domain/reconcile"
+	accountrepo "astral/internal/repository/account"
+	authrepo "astral/internal/repository/auth"
+	commentsrepo "astral/internal/repository/comments"
+	miniostorage "astral/internal/repository/db/minio"
+	pg "astral/internal/repository/db/postgres"
+	docdatarepo "astral/internal/repository/docdata"
+	replicationrepo "astral/internal/repository/replication"
+	s3repo "astral/internal/repository/s3"
+	tagsrepo "astral/internal/repository/tags"
+	webhooksrepo "astral/internal/repository/webhooks"
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/go-redis/redis/v8"
+)
+
+// column - столбец таблицы, который ссылается на документ или пользователя без внешнего ключа
+// (или с ним, если строки остались от удаления в обход каскада)
+type column struct {
+	table string
+	name  string
+}
+
+var documentColumns = []column{
+	{docdatarepo.TABLE_DOCUMENT_DATA, "document_id"},
+	{tagsrepo.TABLE_DOCUMENT_TAGS, "document_id"},
+	{commentsrepo.TABLE_COMMENTS, "document_id"},
+}
+
+var userColumns = []column{
+	{authrepo.TABLE_TOKENS, "user_login"},
+	{authrepo.TABLE_APP_PASSWORDS, "user_login"},
+	{authrepo.TABLE_ACCESS_KEYS, "user_login"},
+	{webhooksrepo.TABLE_WEBHOOKS, "user_login"},
+	{tagsrepo.TABLE_TAGS, "user_login"},
+	{docdatarepo.TABLE_DOCUMENT_DATA, "user_login"},
+	{commentsrepo.TABLE_COMMENTS, "document_owner"},
+	{s3repo.TABLE_MULTIPART_UPLOADS, "user_login"},
+	{accountrepo.TABLE_TAKEOUT_EXPORTS, "user_login"},
+}
+
+type ReconcilePersister struct {
+	dial    goqu.DialectWrapper
+	storage *pg.Storage
+	objects miniostorage.MinioStorage
+	queue   replicationrepo.QueueRepo
+	cache   *redis.Client
+	logger  *slog.Logger
+}
+
+// queue может быть nil, если репликация выключена
+func NewReconcilePersister(
+	storage *pg.Storage,
+	objects miniostorage.MinioStorage,
+	queue replicationrepo.QueueRepo,
+	cache *redis.Client,
+	logger *slog.Logger,
+) *ReconcilePersister {
+	return &ReconcilePersister{
+		dial:    goqu.Dialect(pg.DRIVER),
+		storage: storage,
+		objects: objects,
+		queue:   queue,
+		cache:   cache,
+		logger:  logger,
+	}
+}
+
+func (p *ReconcilePersister) ListUsers(ctx context.Context) (map[string]bool, error) {
+	const op = "repository.reconcile.persister.ListUsers"
+
+	logins, err := p.selectKeys(ctx, authrepo.TABLE_USERS, "login")
+	if err != nil {
+		p.logger.Error("failed to list users", "func", op, "error", err)
+		return nil, errors.New("failed to list users")
+	}
+
+	return logins, nil
+}
+
+func (p *ReconcilePersister) ListMultipartUploads(ctx context.Context) (map[string]bool, error) {
+	const op = "repository.reconcile.persister.ListMultipartUploads"
+
+	uploads, err := p.selectKeys(ctx, s3repo.TABLE_MULTIPART_UPLOADS, "upload_id")
+	if err != nil {
+		p.logger.Error("failed to list multipart uploads", "func", op, "error", err)
+		return nil, errors.New("failed to list multipart uploads")
+	}
+
+	return uploads, nil
+}
+
+func (p *ReconcilePersister) ListExports(ctx context.Context) (map[string]bool, error) {
+	const op = "repository.reconcile.persister.ListExports"
+
+	exports, err := p.selectKeys(ctx, accountrepo.TABLE_TAKEOUT_EXPORTS, "id")
+	if err != nil {
+		p.logger.Error("failed to list exports", "func", op, "error", err)
+		return nil, errors.New("failed to list exports")
+	}
+
+	return exports, nil
+}
+
+func (p *ReconcilePersister) ListDocumentReferences(ctx context.Context) ([]reconcile.Reference, error) {
+	const op = "repository.reconcile.persister.ListDocumentReferences"
+
+	refs, err := p.listReferences(ctx, documentColumns)
+	if err != nil {
+		p.logger.Error("failed to list document references", "func", op, "error", err)
+		return nil, errors.New("failed to list document references")
+	}
+
+	return refs, nil
+}
+
+func (p *ReconcilePersister) ListUserReferences(ctx context.Context) ([]reconcile.Reference, error) {
+	const op = "repository.reconcile.persister.ListUserReferences"
+
+	refs, err := p.listReferences(ctx, userColumns)
+	if err != nil {
+		p.logger.Error("failed to list user references", "func", op, "error", err)
+		return nil, errors.New("failed to list user references")
+	}
+
+	return refs, nil
+}
+
+func (p *ReconcilePersister) DeleteReference(ctx context.Context, ref reconcile.Reference) error {
+	const op = "repository.reconcile.persister.DeleteReference"
+
+	query, _, err := p.dial.Delete(ref.Table).
+		Where(goqu.C(ref.Column).Eq(ref.Key)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build query", "func", op, "error", err)
+		return errors.New("failed to delete reference")
+	}
+
+	if _, err := p.storage.DB.ExecContext(ctx, query); err != nil {
+		p.logger.Error("failed to delete reference", "func", op, "table", ref.Table, "key", ref.Key, "error", err)
+		return errors.New("failed to delete reference")
+	}
+
+	return nil
+}
+
+func (p *ReconcilePersister) selectKeys(ctx context.Context, table, name string) (map[string]bool, error) {
+	query, _, err := p.dial.From(table).
+		Select(goqu.C(name).Cast("TEXT")).
+		ToSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	if err := p.storage.DB.SelectContext(ctx, &keys, query); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		result[key] = true
+	}
+
+	return result, nil
+}
+
+func (p *ReconcilePersister) listReferences(ctx context.Context, columns []column) ([]reconcile.Reference, error) {
+	var refs []reconcile.Reference
+	for _, c := range columns {
+		query, _, err := p.dial.From(c.table).
+			Select(goqu.C(c.name).As("key"), goqu.COUNT("*").As("count")).
+			GroupBy(goqu.C(c.name)).
+			ToSQL()
+		if err != nil {
+			return nil, err
+		}
+
+		var rows []struct {
+			Key   string `db:"key"`
+			Count int    `db:"count"`
+		}
+		if err := p.storage.DB.SelectContext(ctx, &rows, query); err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			refs = append(refs, reconcile.Reference{
+				Table:  c.table,
+				Column: c.name,
+				Key:    row.Key,
+				Count:  row.Count,
+			})
+		}
+	}
+
+	return refs, nil
+}
@@ -0,0 +1,25 @@
+package reconcilerepo
+
+import (
+	"astral/internal/domain/reconcile"
+	"context"
+)
+
+// ReconcileRepo читает PostgreSQL, Redis и MinIO в обход кэша и сервисов: сверке нужны сырые
+// ключи объектов и строки таблиц, а не документы
+type ReconcileRepo interface {
+	ListObjects(ctx context.Context) ([]reconcile.Object, error)
+	RemoveObject(ctx context.Context, key string) error
+
+	ListUsers(ctx context.Context) (map[string]bool, error)
+	ListMultipartUploads(ctx context.Context) (map[string]bool, error)
+	ListExports(ctx context.Context) (map[string]bool, error)
+	// ListDocumentReferences возвращает по таблицам ID документов, на которые есть ссылки
+	ListDocumentReferences(ctx context.Context) ([]reconcile.Reference, error)
+	// ListUserReferences возвращает по таблицам логины, на которые есть ссылки
+	ListUserReferences(ctx context.Context) ([]reconcile.Reference, error)
+	DeleteReference(ctx context.Context, ref reconcile.Reference) error
+
+	ListCacheEntries(ctx context.Context) ([]reconcile.CacheEntry, error)
+	DeleteCacheEntry(ctx context.Context, key string) error
+}
@@ -0,0 +1,7 @@
+package replicationrepo
+
+import "errors"
+
+var (
+	ErrObjectNotFound = errors.New("object not found")
+)
@@ -0,0 +1,110 @@
+package replicationrepo
+
+import (
+	"astral/internal/domain/replication"
+	miniostorage "astral/internal/repository/db/minio"
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/minio/minio-go/v7"
+)
+
+type ObjectsPersister struct {
+	primary miniostorage.MinioStorage
+	replica miniostorage.MinioStorage
+	logger  *slog.Logger
+}
+
+func NewObjectsPersister(primary, replica miniostorage.MinioStorage, logger *slog.Logger) *ObjectsPersister {
+	return &ObjectsPersister{
+		primary: primary,
+		replica: replica,
+		logger:  logger,
+	}
+}
+
+func (p *ObjectsPersister) CopyToReplica(ctx context.Context, objectKey string) error {
+	return p.copy(ctx, p.primary, p.replica, objectKey)
+}
+
+// CopyToPrimary восстанавливает объект, уцелевший только в реплике
+func (p *ObjectsPersister) CopyToPrimary(ctx context.Context, objectKey string) error {
+	return p.copy(ctx, p.replica, p.primary, objectKey)
+}
+
+func (p *ObjectsPersister) copy(ctx context.Context, from, to miniostorage.MinioStorage, objectKey string) error {
+	const op = "repository.replication.objects.copy"
+
+	obj, err := from.Client.GetObject(ctx, from.BucketName, objectKey, minio.GetObjectOptions{})
+	if err != nil {
+		p.logger.Error("failed to get object", "func", op, "bucket", from.BucketName, "key", objectKey, "error", err)
+		return errors.New("failed to get object")
+	}
+	defer obj.Close()
+
+	info, err := obj.Stat()
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return ErrObjectNotFound
+		}
+
+		p.logger.Error("failed to stat object", "func", op, "bucket", from.BucketName, "key", objectKey, "error", err)
+		return errors.New("failed to stat object")
+	}
+
+	_, err = to.Client.PutObject(ctx, to.BucketName, objectKey, obj, info.Size, minio.PutObjectOptions{
+		ContentType:  info.ContentType,
+		UserMetadata: info.UserMetadata,
+	})
+	if err != nil {
+		p.logger.Error("failed to put object", "func", op, "bucket", to.BucketName, "key", objectKey, "error", err)
+		return errors.New("failed to put object")
+	}
+
+	return nil
+}
+
+func (p *ObjectsPersister) RemoveFromReplica(ctx context.Context, objectKey string) error {
+	const op = "repository.replication.objects.RemoveFromReplica"
+
+	err := p.replica.Client.RemoveObject(ctx, p.replica.BucketName, objectKey, minio.RemoveObjectOptions{})
+	if err != nil && minio.ToErrorResponse(err).Code != "NoSuchKey" {
+		p.logger.Error("failed to remove object from replica", "func", op, "key", objectKey, "error", err)
+		return errors.New("failed to remove object from replica")
+	}
+
+	return nil
+}
+
+func (p *ObjectsPersister) ListPrimary(ctx context.Context) (map[string]replication.Object, error) {
+	return p.list(ctx, p.primary)
+}
+
+func (p *ObjectsPersister) ListReplica(ctx context.Context) (map[string]replication.Object, error) {
+	return p.list(ctx, p.replica)
+}
+
+func (p *ObjectsPersister) list(ctx context.Context, storage miniostorage.MinioStorage) (map[string]replication.Object, error) {
+	const op = "repository.replication.objects.list"
+
+	objectCh := storage.Client.ListObjects(ctx, storage.BucketName, minio.ListObjectsOptions{
+		Recursive: true,
+	})
+
+	objects := make(map[string]replication.Object)
+	for objInfo := range objectCh {
+		if objInfo.Err != nil {
+			p.logger.Error("error listing objects", "func", op, "bucket", storage.BucketName, "error", objInfo.Err)
+			return nil, errors.New("error listing objects")
+		}
+
+		objects[objInfo.Key] = replication.Object{
+			Key:  objInfo.Key,
+			Size: objInfo.Size,
+			ETag: objInfo.ETag,
+		}
+	}
+
+	return objects, nil
+}
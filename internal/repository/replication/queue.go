@@ -0,0 +1,210 @@
+package replicationrepo
+
+import (
+	"astral/internal/domain/replication"
+	pg "astral/internal/repository/db/postgres"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+const (
+	TABLE_REPLICATION_QUEUE = "replication_queue"
+
+	// Время, на которое задача скрывается от других воркеров после выборки. Должно быть больше
+	// таймаута копирования, иначе долгую копию подхватит второй воркер
+	TASK_LEASE = "INTERVAL '3 minutes'"
+)
+
+type QueuePersister struct {
+	dial    goqu.DialectWrapper
+	storage *pg.Storage
+	logger  *slog.Logger
+}
+
+func NewQueuePersister(storage *pg.Storage, logger *slog.Logger) *QueuePersister {
+	return &QueuePersister{
+		dial:    goqu.Dialect(pg.DRIVER),
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (p *QueuePersister) Enqueue(ctx context.Context, operation replication.Operation, objectKey string) error {
+	const op = "repository.replication.queue.Enqueue"
+
+	query, _, err := p.dial.Insert(TABLE_REPLICATION_QUEUE).
+		Rows(
+			goqu.Record{
+				"operation":  string(operation),
+				"object_key": objectKey,
+			},
+		).ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build enqueue query", "func", op, "key", objectKey, "error", err)
+		return errors.New("failed to build enqueue query")
+	}
+
+	_, err = p.storage.DB.ExecContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute enqueue query", "func", op, "key", objectKey, "error", err)
+		return errors.New("failed to execute enqueue query")
+	}
+
+	return nil
+}
+
+func (p *QueuePersister) FetchDue(ctx context.Context, limit int) ([]replication.Task, error) {
+	const op = "repository.replication.queue.FetchDue"
+
+	// Задачи одного объекта выполняются строго по порядку: пока не выполнена более ранняя, поздняя
+	// не выбирается. Иначе повтор удаления может прийти в реплику после более новой записи и стереть ее
+	older := p.dial.From(goqu.T(TABLE_REPLICATION_QUEUE).As("older")).
+		Select(goqu.L("1")).
+		Where(
+			goqu.I("older.object_key").Eq(goqu.I(TABLE_REPLICATION_QUEUE+".object_key")),
+			goqu.I("older.id").Lt(goqu.I(TABLE_REPLICATION_QUEUE+".id")),
+		)
+
+	due := p.dial.From(TABLE_REPLICATION_QUEUE).
+		Select("id").
+		Where(
+			goqu.C("next_attempt_at").Lte(goqu.L("CURRENT_TIMESTAMP")),
+			goqu.L("NOT EXISTS ?", older),
+		).
+		Order(goqu.C("id").Asc()).
+		Limit(uint(limit)).
+		ForUpdate(exp.SkipLocked)
+
+	query, _, err := p.dial.Update(TABLE_REPLICATION_QUEUE).
+		Set(goqu.Record{"next_attempt_at": goqu.L("CURRENT_TIMESTAMP + " + TASK_LEASE)}).
+		Where(goqu.C("id").In(due)).
+		Returning("id", "operation", "object_key", "attempts", "created_at", "next_attempt_at").
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build fetch due tasks query", "func", op, "error", err)
+		return nil, errors.New("failed to build fetch due tasks query")
+	}
+
+	rows, err := p.storage.DB.QueryContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute fetch due tasks query", "func", op, "error", err)
+		return nil, errors.New("failed to execute fetch due tasks query")
+	}
+	defer rows.Close()
+
+	var tasks []replication.Task
+	for rows.Next() {
+		var task replication.Task
+		var operation string
+		err = rows.Scan(&task.ID, &operation, &task.ObjectKey, &task.Attempts, &task.CreatedAt, &task.NextAttemptAt)
+		if err != nil {
+			p.logger.Error("failed to scan task", "func", op, "error", err)
+			return nil, errors.New("failed to scan task")
+		}
+
+		task.Operation = replication.Operation(operation)
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+func (p *QueuePersister) Complete(ctx context.Context, taskID int64) error {
+	const op = "repository.replication.queue.Complete"
+
+	query, _, err := p.dial.Delete(TABLE_REPLICATION_QUEUE).
+		Where(goqu.C("id").Eq(taskID)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build complete task query", "func", op, "taskID", taskID, "error", err)
+		return errors.New("failed to build complete task query")
+	}
+
+	_, err = p.storage.DB.ExecContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute complete task query", "func", op, "taskID", taskID, "error", err)
+		return errors.New("failed to execute complete task query")
+	}
+
+	return nil
+}
+
+// Extend продлевает аренду задачи на lease от текущего момента
+func (p *QueuePersister) Extend(ctx context.Context, taskID int64, lease time.Duration) error {
+	const op = "repository.replication.queue.Extend"
+
+	query, _, err := p.dial.Update(TABLE_REPLICATION_QUEUE).
+		Set(goqu.Record{"next_attempt_at": goqu.L("CURRENT_TIMESTAMP + ? * INTERVAL '1 second'", int64(lease.Seconds()))}).
+		Where(goqu.C("id").Eq(taskID)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build extend task lease query", "func", op, "taskID", taskID, "error", err)
+		return errors.New("failed to build extend task lease query")
+	}
+
+	_, err = p.storage.DB.ExecContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute extend task lease query", "func", op, "taskID", taskID, "error", err)
+		return errors.New("failed to execute extend task lease query")
+	}
+
+	return nil
+}
+
+func (p *QueuePersister) Fail(ctx context.Context, taskID int64, reason string, backoff time.Duration) error {
+	const op = "repository.replication.queue.Fail"
+
+	query, _, err := p.dial.Update(TABLE_REPLICATION_QUEUE).
+		Set(goqu.Record{
+			"attempts":        goqu.L("attempts + 1"),
+			"last_error":      reason,
+			"next_attempt_at": goqu.L("CURRENT_TIMESTAMP + ? * INTERVAL '1 second'", int64(backoff.Seconds())),
+		}).
+		Where(goqu.C("id").Eq(taskID)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build fail task query", "func", op, "taskID", taskID, "error", err)
+		return errors.New("failed to build fail task query")
+	}
+
+	_, err = p.storage.DB.ExecContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute fail task query", "func", op, "taskID", taskID, "error", err)
+		return errors.New("failed to execute fail task query")
+	}
+
+	return nil
+}
+
+func (p *QueuePersister) Stats(ctx context.Context) (*replication.Status, error) {
+	const op = "repository.replication.queue.Stats"
+
+	query, _, err := p.dial.From(TABLE_REPLICATION_QUEUE).
+		Select(
+			goqu.COUNT("*"),
+			goqu.COUNT(goqu.Case().When(goqu.C("attempts").Gt(0), 1)),
+			goqu.L("COALESCE(EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - MIN(created_at)), 0)"),
+		).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build stats query", "func", op, "error", err)
+		return nil, errors.New("failed to build stats query")
+	}
+
+	var status replication.Status
+	var lagSeconds float64
+	err = p.storage.DB.QueryRowContext(ctx, query).Scan(&status.Pending, &status.Retrying, &lagSeconds)
+	if err != nil {
+		p.logger.Error("failed to execute stats query", "func", op, "error", err)
+		return nil, errors.New("failed to execute stats query")
+	}
+
+	status.Lag = time.Duration(lagSeconds * float64(time.Second))
+
+	return &status, nil
+}
@@ -0,0 +1,24 @@
+package replicationrepo
+
+import (
+	"astral/internal/domain/replication"
+	"context"
+	"time"
+)
+
+type QueueRepo interface {
+	Enqueue(ctx context.Context, operation replication.Operation, objectKey string) error
+	FetchDue(ctx context.Context, limit int) ([]replication.Task, error)
+	Complete(ctx context.Context, taskID int64) error
+	Extend(ctx context.Context, taskID int64, lease time.Duration) error
+	Fail(ctx context.Context, taskID int64, reason string, backoff time.Duration) error
+	Stats(ctx context.Context) (*replication.Status, error)
+}
+
+type ObjectsRepo interface {
+	CopyToReplica(ctx context.Context, objectKey string) error
+	CopyToPrimary(ctx context.Context, objectKey string) error
+	RemoveFromReplica(ctx context.Context, objectKey string) error
+	ListPrimary(ctx context.Context) (map[string]replication.Object, error)
+	ListReplica(ctx context.Context) (map[string]replication.Object, error)
+}
@@ -0,0 +1,8 @@
+package s3repo
+
+import "errors"
+
+var (
+	ErrUploadNotFound = errors.New("multipart upload not found")
+	ErrPartNotFound   = errors.New("multipart upload part not found")
+)
@@ -0,0 +1,235 @@
+package s3repo
+
+import (
+	"astral/internal/domain/s3"
+	miniostorage "astral/internal/repository/db/minio"
+	pg "astral/internal/repository/db/postgres"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	TABLE_MULTIPART_UPLOADS = "s3_multipart_uploads"
+
+	// Части составных загрузок хранятся вне пользовательских префиксов до завершения загрузки
+	PARTS_PREFIX = "_multipart"
+)
+
+type MultipartPersister struct {
+	dial    goqu.DialectWrapper
+	storage *pg.Storage
+	objects miniostorage.MinioStorage
+	logger  *slog.Logger
+}
+
+func NewMultipartPersister(storage *pg.Storage, objects miniostorage.MinioStorage, logger *slog.Logger) *MultipartPersister {
+	return &MultipartPersister{
+		dial:    goqu.Dialect(pg.DRIVER),
+		storage: storage,
+		objects: objects,
+		logger:  logger,
+	}
+}
+
+func (p *MultipartPersister) CreateUpload(ctx context.Context, upload s3.MultipartUpload) (*s3.MultipartUpload, error) {
+	const op = "repository.s3.persister.CreateUpload"
+
+	metadata, err := json.Marshal(upload.Metadata)
+	if err != nil {
+		p.logger.Error("failed to marshal upload metadata", "func", op, "login", upload.Login, "error", err)
+		return nil, errors.New("failed to marshal upload metadata")
+	}
+
+	query, _, err := p.dial.Insert(TABLE_MULTIPART_UPLOADS).
+		Rows(
+			goqu.Record{
+				"user_login":   upload.Login,
+				"bucket":       upload.Bucket,
+				"object_key":   upload.Key,
+				"content_type": upload.ContentType,
+				"metadata":     string(metadata),
+			},
+		).Returning("upload_id", "user_login", "bucket", "object_key", "content_type", "created_at").ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build create upload query", "func", op, "login", upload.Login, "error", err)
+		return nil, errors.New("failed to build create upload query")
+	}
+
+	var res s3.MultipartUpload
+	var contentType sql.NullString
+	err = p.storage.DB.QueryRowContext(ctx, query).Scan(&res.ID, &res.Login, &res.Bucket, &res.Key, &contentType, &res.CreatedAt)
+	if err != nil {
+		p.logger.Error("failed to execute create upload query", "func", op, "login", upload.Login, "error", err)
+		return nil, errors.New("failed to execute create upload query")
+	}
+	res.ContentType = contentType.String
+	res.Metadata = upload.Metadata
+
+	return &res, nil
+}
+
+func (p *MultipartPersister) GetUpload(ctx context.Context, uploadID string) (*s3.MultipartUpload, error) {
+	const op = "repository.s3.persister.GetUpload"
+
+	query, _, err := p.dial.From(TABLE_MULTIPART_UPLOADS).
+		Select("upload_id", "user_login", "bucket", "object_key", "content_type", "metadata", "created_at").
+		Where(goqu.C("upload_id").Eq(uploadID)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get upload query", "func", op, "uploadID", uploadID, "error", err)
+		return nil, errors.New("failed to build get upload query")
+	}
+
+	var res s3.MultipartUpload
+	var contentType sql.NullString
+	var metadata []byte
+	err = p.storage.DB.QueryRowContext(ctx, query).Scan(&res.ID, &res.Login, &res.Bucket, &res.Key, &contentType, &metadata, &res.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || pg.IsInvalidTextRepresentation(err) {
+			return nil, ErrUploadNotFound
+		}
+
+		p.logger.Error("failed to execute get upload query", "func", op, "uploadID", uploadID, "error", err)
+		return nil, errors.New("failed to execute get upload query")
+	}
+	res.ContentType = contentType.String
+
+	if err := json.Unmarshal(metadata, &res.Metadata); err != nil {
+		p.logger.Error("failed to unmarshal upload metadata", "func", op, "uploadID", uploadID, "error", err)
+		return nil, errors.New("failed to unmarshal upload metadata")
+	}
+
+	return &res, nil
+}
+
+func (p *MultipartPersister) DeleteUpload(ctx context.Context, uploadID string) error {
+	const op = "repository.s3.persister.DeleteUpload"
+
+	query, _, err := p.dial.Delete(TABLE_MULTIPART_UPLOADS).
+		Where(goqu.C("upload_id").Eq(uploadID)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build delete upload query", "func", op, "uploadID", uploadID, "error", err)
+		return errors.New("failed to build delete upload query")
+	}
+
+	_, err = p.storage.DB.ExecContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute delete upload query", "func", op, "uploadID", uploadID, "error", err)
+		return errors.New("failed to execute delete upload query")
+	}
+
+	return nil
+}
+
+func (p *MultipartPersister) PutPart(ctx context.Context, uploadID string, number int, reader io.Reader, size int64) (*s3.Part, error) {
+	const op = "repository.s3.persister.PutPart"
+
+	info, err := p.objects.Client.PutObject(ctx, p.objects.BucketName, getPartPath(uploadID, number), reader, size, minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+	})
+	if err != nil {
+		p.logger.Error("failed to upload part", "func", op, "uploadID", uploadID, "part", number, "error", err)
+		return nil, errors.New("failed to upload part")
+	}
+
+	return &s3.Part{
+		Number: number,
+		Size:   info.Size,
+		ETag:   info.ETag,
+	}, nil
+}
+
+func (p *MultipartPersister) ListParts(ctx context.Context, uploadID string) ([]s3.Part, error) {
+	const op = "repository.s3.persister.ListParts"
+
+	objectCh := p.objects.Client.ListObjects(ctx, p.objects.BucketName, minio.ListObjectsOptions{
+		Prefix:    getPartsPrefix(uploadID),
+		Recursive: true,
+	})
+
+	var parts []s3.Part
+	for objInfo := range objectCh {
+		if objInfo.Err != nil {
+			p.logger.Error("error listing parts", "func", op, "uploadID", uploadID, "error", objInfo.Err)
+			return nil, errors.New("error listing parts")
+		}
+
+		number, err := strconv.Atoi(strings.TrimPrefix(objInfo.Key, getPartsPrefix(uploadID)))
+		if err != nil {
+			continue
+		}
+
+		parts = append(parts, s3.Part{
+			Number: number,
+			Size:   objInfo.Size,
+			ETag:   strings.Trim(objInfo.ETag, `"`),
+		})
+	}
+
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].Number < parts[j].Number
+	})
+
+	return parts, nil
+}
+
+func (p *MultipartPersister) OpenPart(ctx context.Context, uploadID string, number int) (io.ReadCloser, error) {
+	const op = "repository.s3.persister.OpenPart"
+
+	obj, err := p.objects.Client.GetObject(ctx, p.objects.BucketName, getPartPath(uploadID, number), minio.GetObjectOptions{})
+	if err != nil {
+		p.logger.Error("failed to get part", "func", op, "uploadID", uploadID, "part", number, "error", err)
+		return nil, errors.New("failed to get part")
+	}
+
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrPartNotFound
+		}
+
+		p.logger.Error("failed to stat part", "func", op, "uploadID", uploadID, "part", number, "error", err)
+		return nil, errors.New("failed to stat part")
+	}
+
+	return obj, nil
+}
+
+func (p *MultipartPersister) RemoveParts(ctx context.Context, uploadID string) error {
+	const op = "repository.s3.persister.RemoveParts"
+
+	parts, err := p.ListParts(ctx, uploadID)
+	if err != nil {
+		return err
+	}
+
+	for _, part := range parts {
+		err := p.objects.Client.RemoveObject(ctx, p.objects.BucketName, getPartPath(uploadID, part.Number), minio.RemoveObjectOptions{})
+		if err != nil && minio.ToErrorResponse(err).Code != "NoSuchKey" {
+			p.logger.Error("failed to remove part", "func", op, "uploadID", uploadID, "part", part.Number, "error", err)
+			return errors.New("failed to remove part")
+		}
+	}
+
+	return nil
+}
+
+func getPartsPrefix(uploadID string) string {
+	return fmt.Sprintf("%s/%s/", PARTS_PREFIX, uploadID)
+}
+
+func getPartPath(uploadID string, number int) string {
+	return fmt.Sprintf("%s%05d", getPartsPrefix(uploadID), number)
+}
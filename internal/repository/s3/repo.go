@@ -0,0 +1,17 @@
+package s3repo
+
+import (
+	"astral/internal/domain/s3"
+	"context"
+	"io"
+)
+
+type MultipartRepo interface {
+	CreateUpload(ctx context.Context, upload s3.MultipartUpload) (*s3.MultipartUpload, error)
+	GetUpload(ctx context.Context, uploadID string) (*s3.MultipartUpload, error)
+	DeleteUpload(ctx context.Context, uploadID string) error
+	PutPart(ctx context.Context, uploadID string, number int, reader io.Reader, size int64) (*s3.Part, error)
+	ListParts(ctx context.Context, uploadID string) ([]s3.Part, error)
+	OpenPart(ctx context.Context, uploadID string, number int) (io.ReadCloser, error)
+	RemoveParts(ctx context.Context, uploadID string) error
+}
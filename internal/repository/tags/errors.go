@@ -0,0 +1,8 @@
+package tagsrepo
+
+import "errors"
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists   = errors.New("tag with this name already exists")
+)
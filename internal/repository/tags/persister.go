@@ -0,0 +1,466 @@
+package tagsrepo
+
+import (
+	"astral/internal/domain/dto"
+	"astral/internal/domain/tag"
+	pg "astral/internal/repository/db/postgres"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	TABLE_TAGS          = "tags"
+	TABLE_DOCUMENT_TAGS = "document_tags"
+)
+
+type TagsPersister struct {
+	dial    goqu.DialectWrapper
+	storage *pg.Storage
+	logger  *slog.Logger
+}
+
+func NewTagsPersister(storage *pg.Storage, logger *slog.Logger) *TagsPersister {
+	return &TagsPersister{
+		dial:    goqu.Dialect(pg.DRIVER),
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (p *TagsPersister) CreateTag(ctx context.Context, data dto.TagData) (*tag.Tag, error) {
+	const op = "repository.tags.persister.CreateTag"
+
+	query, _, err := p.dial.Insert(TABLE_TAGS).
+		Rows(goqu.Record{
+			"user_login": data.Login,
+			"name":       data.Name,
+			"color":      nullString(data.Color),
+			"parent_id":  data.ParentID,
+		}).
+		Returning(tagColumns()...).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build create tag query", "func", op, "login", data.Login, "error", err)
+		return nil, errors.New("failed to build create tag query")
+	}
+
+	res, err := scanTag(p.storage.DB.QueryRowContext(ctx, query))
+	if err != nil {
+		if pg.IsDuplicateKeyError(err) {
+			return nil, ErrTagExists
+		}
+
+		p.logger.Error("failed to execute create tag query", "func", op, "login", data.Login, "error", err)
+		return nil, errors.New("failed to execute create tag query")
+	}
+
+	return res, nil
+}
+
+func (p *TagsPersister) GetTags(ctx context.Context, login string) ([]tag.Tag, error) {
+	const op = "repository.tags.persister.GetTags"
+
+	query, _, err := p.dial.From(TABLE_TAGS).
+		Select(tagColumns()...).
+		Where(goqu.C("user_login").Eq(login)).
+		Order(goqu.C("name").Asc()).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get tags query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to build get tags query")
+	}
+
+	rows, err := p.storage.DB.QueryContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute get tags query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to execute get tags query")
+	}
+	defer rows.Close()
+
+	tags, err := scanTags(rows)
+	if err != nil {
+		p.logger.Error("failed to scan tags", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to scan tags")
+	}
+
+	return tags, nil
+}
+
+func (p *TagsPersister) GetTag(ctx context.Context, login string, id int64) (*tag.Tag, error) {
+	const op = "repository.tags.persister.GetTag"
+
+	query, _, err := p.dial.From(TABLE_TAGS).
+		Select(tagColumns()...).
+		Where(goqu.C("id").Eq(id), goqu.C("user_login").Eq(login)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get tag query", "func", op, "login", login, "id", id, "error", err)
+		return nil, errors.New("failed to build get tag query")
+	}
+
+	res, err := scanTag(p.storage.DB.QueryRowContext(ctx, query))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTagNotFound
+		}
+
+		p.logger.Error("failed to execute get tag query", "func", op, "login", login, "id", id, "error", err)
+		return nil, errors.New("failed to execute get tag query")
+	}
+
+	return res, nil
+}
+
+func (p *TagsPersister) UpdateTag(ctx context.Context, login string, id int64, update tag.Update) (*tag.Tag, error) {
+	const op = "repository.tags.persister.UpdateTag"
+
+	record := goqu.Record{}
+	if update.Name != nil {
+		record["name"] = *update.Name
+	}
+	if update.Color != nil {
+		record["color"] = nullString(*update.Color)
+	}
+	if update.SetParent {
+		record["parent_id"] = update.ParentID
+	}
+	if len(record) == 0 {
+		return p.GetTag(ctx, login, id)
+	}
+
+	query, _, err := p.dial.Update(TABLE_TAGS).
+		Set(record).
+		Where(goqu.C("id").Eq(id), goqu.C("user_login").Eq(login)).
+		Returning(tagColumns()...).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build update tag query", "func", op, "login", login, "id", id, "error", err)
+		return nil, errors.New("failed to build update tag query")
+	}
+
+	res, err := scanTag(p.storage.DB.QueryRowContext(ctx, query))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTagNotFound
+		}
+		if pg.IsDuplicateKeyError(err) {
+			return nil, ErrTagExists
+		}
+
+		p.logger.Error("failed to execute update tag query", "func", op, "login", login, "id", id, "error", err)
+		return nil, errors.New("failed to execute update tag query")
+	}
+
+	return res, nil
+}
+
+func (p *TagsPersister) DeleteTag(ctx context.Context, login string, id int64) (*tag.Tag, error) {
+	const op = "repository.tags.persister.DeleteTag"
+
+	var res *tag.Tag
+	err := p.storage.WithTx(ctx, func(tx *sqlx.Tx) error {
+		current, err := p.lockTag(ctx, tx, login, id)
+		if err != nil {
+			return err
+		}
+
+		if err := p.reparent(ctx, tx, id, current.ParentID); err != nil {
+			return err
+		}
+
+		query, _, err := p.dial.Delete(TABLE_TAGS).
+			Where(goqu.C("id").Eq(id)).
+			ToSQL()
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+
+		res = current
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrTagNotFound) {
+			return nil, err
+		}
+
+		p.logger.Error("failed to delete tag", "func", op, "login", login, "id", id, "error", err)
+		return nil, errors.New("failed to delete tag")
+	}
+
+	return res, nil
+}
+
+func (p *TagsPersister) MergeTags(ctx context.Context, login string, sourceID, targetID int64, detachTarget bool) (*tag.Tag, error) {
+	const op = "repository.tags.persister.MergeTags"
+
+	err := p.storage.WithTx(ctx, func(tx *sqlx.Tx) error {
+		source, err := p.lockTag(ctx, tx, login, sourceID)
+		if err != nil {
+			return err
+		}
+		if _, err := p.lockTag(ctx, tx, login, targetID); err != nil {
+			return err
+		}
+
+		if detachTarget {
+			query, _, err := p.dial.Update(TABLE_TAGS).
+				Set(goqu.Record{"parent_id": source.ParentID}).
+				Where(goqu.C("id").Eq(targetID)).
+				ToSQL()
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				return err
+			}
+		}
+
+		if err := p.reparent(ctx, tx, sourceID, &targetID); err != nil {
+			return err
+		}
+
+		// Документы, уже отмеченные обоими тегами, теряют связь с source, остальные переходят на target
+		query, _, err := p.dial.Delete(TABLE_DOCUMENT_TAGS).
+			Where(
+				goqu.C("tag_id").Eq(sourceID),
+				goqu.C("document_id").In(p.dial.From(TABLE_DOCUMENT_TAGS).
+					Select("document_id").
+					Where(goqu.C("tag_id").Eq(targetID))),
+			).
+			ToSQL()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+
+		query, _, err = p.dial.Update(TABLE_DOCUMENT_TAGS).
+			Set(goqu.Record{"tag_id": targetID}).
+			Where(goqu.C("tag_id").Eq(sourceID)).
+			ToSQL()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+
+		query, _, err = p.dial.Delete(TABLE_TAGS).
+			Where(goqu.C("id").Eq(sourceID)).
+			ToSQL()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, query)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrTagNotFound) {
+			return nil, err
+		}
+
+		p.logger.Error("failed to merge tags", "func", op, "login", login, "source", sourceID, "target", targetID, "error", err)
+		return nil, errors.New("failed to merge tags")
+	}
+
+	return p.GetTag(ctx, login, targetID)
+}
+
+func (p *TagsPersister) AttachTag(ctx context.Context, documentID string, tagID int64) error {
+	const op = "repository.tags.persister.AttachTag"
+
+	query, _, err := p.dial.Insert(TABLE_DOCUMENT_TAGS).
+		Rows(goqu.Record{
+			"document_id": documentID,
+			"tag_id":      tagID,
+		}).
+		OnConflict(goqu.DoNothing()).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build attach tag query", "func", op, "documentID", documentID, "tagID", tagID, "error", err)
+		return errors.New("failed to build attach tag query")
+	}
+
+	if _, err := p.storage.DB.ExecContext(ctx, query); err != nil {
+		p.logger.Error("failed to execute attach tag query", "func", op, "documentID", documentID, "tagID", tagID, "error", err)
+		return errors.New("failed to execute attach tag query")
+	}
+
+	return nil
+}
+
+func (p *TagsPersister) DetachTag(ctx context.Context, documentID string, tagID int64) error {
+	const op = "repository.tags.persister.DetachTag"
+
+	query, _, err := p.dial.Delete(TABLE_DOCUMENT_TAGS).
+		Where(goqu.C("document_id").Eq(documentID), goqu.C("tag_id").Eq(tagID)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build detach tag query", "func", op, "documentID", documentID, "tagID", tagID, "error", err)
+		return errors.New("failed to build detach tag query")
+	}
+
+	if _, err := p.storage.DB.ExecContext(ctx, query); err != nil {
+		p.logger.Error("failed to execute detach tag query", "func", op, "documentID", documentID, "tagID", tagID, "error", err)
+		return errors.New("failed to execute detach tag query")
+	}
+
+	return nil
+}
+
+func (p *TagsPersister) GetDocumentTags(ctx context.Context, login, documentID string) ([]tag.Tag, error) {
+	const op = "repository.tags.persister.GetDocumentTags"
+
+	columns := make([]any, 0, len(tagColumns()))
+	for _, column := range tagColumns() {
+		columns = append(columns, goqu.T(TABLE_TAGS).Col(column.(string)))
+	}
+
+	query, _, err := p.dial.From(TABLE_TAGS).
+		Select(columns...).
+		Join(goqu.T(TABLE_DOCUMENT_TAGS), goqu.On(goqu.T(TABLE_DOCUMENT_TAGS).Col("tag_id").Eq(goqu.T(TABLE_TAGS).Col("id")))).
+		Where(
+			goqu.T(TABLE_TAGS).Col("user_login").Eq(login),
+			goqu.T(TABLE_DOCUMENT_TAGS).Col("document_id").Eq(documentID),
+		).
+		Order(goqu.T(TABLE_TAGS).Col("name").Asc()).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get document tags query", "func", op, "login", login, "documentID", documentID, "error", err)
+		return nil, errors.New("failed to build get document tags query")
+	}
+
+	rows, err := p.storage.DB.QueryContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute get document tags query", "func", op, "login", login, "documentID", documentID, "error", err)
+		return nil, errors.New("failed to execute get document tags query")
+	}
+	defer rows.Close()
+
+	tags, err := scanTags(rows)
+	if err != nil {
+		p.logger.Error("failed to scan document tags", "func", op, "login", login, "documentID", documentID, "error", err)
+		return nil, errors.New("failed to scan document tags")
+	}
+
+	return tags, nil
+}
+
+func (p *TagsPersister) GetTaggedDocuments(ctx context.Context, login string) (map[string][]int64, error) {
+	const op = "repository.tags.persister.GetTaggedDocuments"
+
+	query, _, err := p.dial.From(TABLE_DOCUMENT_TAGS).
+		Select(goqu.T(TABLE_DOCUMENT_TAGS).Col("document_id"), goqu.T(TABLE_DOCUMENT_TAGS).Col("tag_id")).
+		Join(goqu.T(TABLE_TAGS), goqu.On(goqu.T(TABLE_DOCUMENT_TAGS).Col("tag_id").Eq(goqu.T(TABLE_TAGS).Col("id")))).
+		Where(goqu.T(TABLE_TAGS).Col("user_login").Eq(login)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get tagged documents query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to build get tagged documents query")
+	}
+
+	var rows []struct {
+		DocumentID string `db:"document_id"`
+		TagID      int64  `db:"tag_id"`
+	}
+	if err := p.storage.DB.SelectContext(ctx, &rows, query); err != nil {
+		p.logger.Error("failed to execute get tagged documents query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to execute get tagged documents query")
+	}
+
+	res := make(map[string][]int64)
+	for _, row := range rows {
+		res[row.DocumentID] = append(res[row.DocumentID], row.TagID)
+	}
+
+	return res, nil
+}
+
+// lockTag читает тег пользователя с блокировкой строки до конца транзакции
+func (p *TagsPersister) lockTag(ctx context.Context, tx *sqlx.Tx, login string, id int64) (*tag.Tag, error) {
+	query, _, err := p.dial.From(TABLE_TAGS).
+		Select(tagColumns()...).
+		Where(goqu.C("id").Eq(id), goqu.C("user_login").Eq(login)).
+		ForUpdate(goqu.Wait).
+		ToSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := scanTag(tx.QueryRowContext(ctx, query))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTagNotFound
+	}
+
+	return res, err
+}
+
+func (p *TagsPersister) reparent(ctx context.Context, tx *sqlx.Tx, id int64, parentID *int64) error {
+	query, _, err := p.dial.Update(TABLE_TAGS).
+		Set(goqu.Record{"parent_id": parentID}).
+		Where(goqu.C("parent_id").Eq(id)).
+		ToSQL()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query)
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTag(row scanner) (*tag.Tag, error) {
+	var res tag.Tag
+	var color sql.NullString
+	var parentID sql.NullInt64
+	if err := row.Scan(&res.ID, &res.Login, &res.Name, &color, &parentID, &res.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	res.Color = color.String
+	if parentID.Valid {
+		res.ParentID = &parentID.Int64
+	}
+
+	return &res, nil
+}
+
+func scanTags(rows *sql.Rows) ([]tag.Tag, error) {
+	tags := []tag.Tag{}
+	for rows.Next() {
+		res, err := scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, *res)
+	}
+
+	return tags, rows.Err()
+}
+
+func tagColumns() []any {
+	return []any{"id", "user_login", "name", "color", "parent_id", "created_at"}
+}
+
+func nullString(value string) any {
+	if value == "" {
+		return nil
+	}
+
+	return value
+}
@@ -0,0 +1,25 @@
+package tagsrepo
+
+import (
+	"astral/internal/domain/dto"
+	"astral/internal/domain/tag"
+	"context"
+)
+
+type TagsRepo interface {
+	CreateTag(ctx context.Context, data dto.TagData) (*tag.Tag, error)
+	GetTags(ctx context.Context, login string) ([]tag.Tag, error)
+	GetTag(ctx context.Context, login string, id int64) (*tag.Tag, error)
+	UpdateTag(ctx context.Context, login string, id int64, update tag.Update) (*tag.Tag, error)
+	// DeleteTag удаляет тег, его дочерние теги поднимаются к родителю удаленного
+	DeleteTag(ctx context.Context, login string, id int64) (*tag.Tag, error)
+	// MergeTags переносит документы и дочерние теги source в target и удаляет source.
+	// detachTarget поднимает target к родителю source, если target был его потомком
+	MergeTags(ctx context.Context, login string, sourceID, targetID int64, detachTarget bool) (*tag.Tag, error)
+
+	AttachTag(ctx context.Context, documentID string, tagID int64) error
+	DetachTag(ctx context.Context, documentID string, tagID int64) error
+	GetDocumentTags(ctx context.Context, login, documentID string) ([]tag.Tag, error)
+	// GetTaggedDocuments возвращает теги пользователя по документам: ID документа - ID тегов
+	GetTaggedDocuments(ctx context.Context, login string) (map[string][]int64, error)
+}
@@ -0,0 +1,7 @@
+package transferrepo
+
+import "errors"
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+)
@@ -0,0 +1,132 @@
+package transferrepo
+
+import (
+	"astral/internal/domain/event"
+	authrepo "astral/internal/repository/auth"
+	commentsrepo "astral/internal/repository/comments"
+	pg "astral/internal/repository/db/postgres"
+	docdatarepo "astral/internal/repository/docdata"
+	outboxrepo "astral/internal/repository/outbox"
+	tagsrepo "astral/internal/repository/tags"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jmoiron/sqlx"
+)
+
+type TransferPersister struct {
+	dial    goqu.DialectWrapper
+	storage *pg.Storage
+	logger  *slog.Logger
+}
+
+func NewTransferPersister(storage *pg.Storage, logger *slog.Logger) *TransferPersister {
+	return &TransferPersister{
+		dial:    goqu.Dialect(pg.DRIVER),
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (p *TransferPersister) UserExists(ctx context.Context, login string) (bool, error) {
+	const op = "repository.transfer.persister.UserExists"
+
+	query, _, err := p.dial.From(authrepo.TABLE_USERS).
+		Select(goqu.L("1")).
+		Where(goqu.C("login").Eq(login)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build user exists query", "func", op, "login", login, "error", err)
+		return false, errors.New("failed to build user exists query")
+	}
+
+	var exists int
+	if err := p.storage.DB.QueryRowContext(ctx, query).Scan(&exists); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		p.logger.Error("failed to execute user exists query", "func", op, "login", login, "error", err)
+		return false, errors.New("failed to execute user exists query")
+	}
+
+	return true, nil
+}
+
+func (p *TransferPersister) TransferDocument(ctx context.Context, documentID, from, to string, events ...event.Event) error {
+	const op = "repository.transfer.persister.TransferDocument"
+
+	// Строка нового владельца блокируется на чтение, чтобы его не удалили посреди передачи
+	lock, _, err := p.dial.From(authrepo.TABLE_USERS).
+		Select("login").
+		Where(goqu.C("login").Eq(to)).
+		ForShare(goqu.Wait).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build lock user query", "func", op, "login", to, "error", err)
+		return errors.New("failed to build transfer document query")
+	}
+
+	data, _, err := p.dial.Update(docdatarepo.TABLE_DOCUMENT_DATA).
+		Set(goqu.Record{"user_login": to}).
+		Where(goqu.C("document_id").Eq(documentID)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build transfer data query", "func", op, "documentID", documentID, "error", err)
+		return errors.New("failed to build transfer document query")
+	}
+
+	comments, _, err := p.dial.Update(commentsrepo.TABLE_COMMENTS).
+		Set(goqu.Record{"document_owner": to}).
+		Where(goqu.C("document_id").Eq(documentID)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build transfer comments query", "func", op, "documentID", documentID, "error", err)
+		return errors.New("failed to build transfer document query")
+	}
+
+	// Теги личные: теги прежнего владельца новому не видны, поэтому связи с ними снимаются
+	tags, _, err := p.dial.Delete(tagsrepo.TABLE_DOCUMENT_TAGS).
+		Where(
+			goqu.C("document_id").Eq(documentID),
+			goqu.C("tag_id").In(p.dial.From(tagsrepo.TABLE_TAGS).
+				Select("id").
+				Where(goqu.C("user_login").Eq(from))),
+		).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build detach tags query", "func", op, "documentID", documentID, "error", err)
+		return errors.New("failed to build transfer document query")
+	}
+
+	err = p.storage.WithTx(ctx, func(tx *sqlx.Tx) error {
+		var login string
+		if err := tx.QueryRowContext(ctx, lock).Scan(&login); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		for _, query := range []string{data, comments, tags} {
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				return err
+			}
+		}
+
+		return outboxrepo.Write(ctx, tx, events...)
+	})
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+
+		p.logger.Error("failed to execute transfer document query", "func", op, "documentID", documentID, "from", from, "to", to, "error", err)
+		return errors.New("failed to execute transfer document query")
+	}
+
+	return nil
+}
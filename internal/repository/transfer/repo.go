@@ -0,0 +1,15 @@
+package transferrepo
+
+import (
+	"astral/internal/domain/event"
+	"context"
+)
+
+// TransferRepo переносит записи PostgreSQL, которые привязаны к владельцу документа. Сам объект
+// документа копируется в хранилище отдельно
+type TransferRepo interface {
+	UserExists(ctx context.Context, login string) (bool, error)
+	// TransferDocument в одной транзакции передает to JSON данные документа и ветки комментариев,
+	// снимает личные теги from и пишет события. Если пользователя to нет, возвращает ErrUserNotFound
+	TransferDocument(ctx context.Context, documentID, from, to string, events ...event.Event) error
+}
@@ -0,0 +1,5 @@
+package uploadsrepo
+
+import "errors"
+
+var ErrUploadNotFound = errors.New("pending upload not found")
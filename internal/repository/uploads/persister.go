@@ -0,0 +1,102 @@
+package uploadsrepo
+
+import (
+	pg "astral/internal/repository/db/postgres"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+const TABLE_PENDING_UPLOADS = "pending_uploads"
+
+type UploadsPersister struct {
+	dial    goqu.DialectWrapper
+	storage *pg.Storage
+	logger  *slog.Logger
+}
+
+func NewUploadsPersister(storage *pg.Storage, logger *slog.Logger) *UploadsPersister {
+	return &UploadsPersister{
+		dial:    goqu.Dialect(pg.DRIVER),
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (p *UploadsPersister) Create(ctx context.Context, upload Upload) error {
+	const op = "repository.uploads.persister.Create"
+
+	record := goqu.Record{
+		"document_id":   upload.DocumentID,
+		"user_login":    upload.Login,
+		"expected_size": upload.Size,
+	}
+	if upload.Checksum != "" {
+		record["expected_sha256"] = upload.Checksum
+	}
+
+	query, _, err := p.dial.Insert(TABLE_PENDING_UPLOADS).Rows(record).ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build query", "func", op, "error", err)
+		return errors.New("failed to create pending upload")
+	}
+
+	if _, err := p.storage.DB.ExecContext(ctx, query); err != nil {
+		p.logger.Error("failed to create pending upload", "func", op, "documentID", upload.DocumentID, "error", err)
+		return errors.New("failed to create pending upload")
+	}
+
+	return nil
+}
+
+func (p *UploadsPersister) Get(ctx context.Context, documentID, login string) (*Upload, error) {
+	const op = "repository.uploads.persister.Get"
+
+	query, _, err := p.dial.From(TABLE_PENDING_UPLOADS).
+		Select(
+			"document_id",
+			"user_login",
+			"expected_size",
+			goqu.L("COALESCE(expected_sha256, '')").As("expected_sha256"),
+			"created_at",
+		).
+		Where(goqu.C("document_id").Eq(documentID), goqu.C("user_login").Eq(login)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build query", "func", op, "error", err)
+		return nil, errors.New("failed to get pending upload")
+	}
+
+	var upload Upload
+	if err := p.storage.DB.GetContext(ctx, &upload, query); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUploadNotFound
+		}
+		p.logger.Error("failed to get pending upload", "func", op, "documentID", documentID, "error", err)
+		return nil, errors.New("failed to get pending upload")
+	}
+
+	return &upload, nil
+}
+
+func (p *UploadsPersister) Delete(ctx context.Context, documentID string) error {
+	const op = "repository.uploads.persister.Delete"
+
+	query, _, err := p.dial.Delete(TABLE_PENDING_UPLOADS).
+		Where(goqu.C("document_id").Eq(documentID)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build query", "func", op, "error", err)
+		return errors.New("failed to delete pending upload")
+	}
+
+	if _, err := p.storage.DB.ExecContext(ctx, query); err != nil {
+		p.logger.Error("failed to delete pending upload", "func", op, "documentID", documentID, "error", err)
+		return errors.New("failed to delete pending upload")
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package uploadsrepo
+
+import (
+	"context"
+	"time"
+)
+
+// UploadsRepo хранит заявленные при выдаче ссылки размер и контрольную сумму прямых загрузок.
+// Они лежат на стороне сервера: метаданные объекта клиент задает сам в запросе PUT к хранилищу
+type UploadsRepo interface {
+	Create(ctx context.Context, upload Upload) error
+	// Get возвращает загрузку документа пользователя или ErrUploadNotFound
+	Get(ctx context.Context, documentID, login string) (*Upload, error)
+	Delete(ctx context.Context, documentID string) error
+}
+
+type Upload struct {
+	DocumentID string     `db:"document_id"`
+	Login      string     `db:"user_login"`
+	Size       int64      `db:"expected_size"`
+	Checksum   string     `db:"expected_sha256"`
+	CreatedAt  *time.Time `db:"created_at"`
+}
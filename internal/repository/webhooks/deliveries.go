@@ -0,0 +1,374 @@
+package webhooksrepo
+
+import (
+	"astral/internal/domain/webhook"
+	pg "astral/internal/repository/db/postgres"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+const (
+	// Время, на которое доставка скрывается от других воркеров после выборки
+	DELIVERY_LEASE = "INTERVAL '2 minutes'"
+)
+
+var deliveryColumns = []any{
+	"id", "webhook_id", "event_id", "event", "payload", "status", "attempts",
+	"response_code", "last_error", "created_at", "next_attempt_at", "delivered_at",
+}
+
+// Enqueue ставит событие в очередь для всех активных webhook пользователя, подписанных на этот тип событий
+func (p *WebhooksPersister) Enqueue(ctx context.Context, login string, payload webhook.Payload) (int64, error) {
+	const op = "repository.webhooks.deliveries.Enqueue"
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		p.logger.Error("failed to marshal webhook payload", "func", op, "login", login, "event", payload.Type, "error", err)
+		return 0, errors.New("failed to marshal webhook payload")
+	}
+
+	subscribers := p.dial.From(TABLE_WEBHOOKS).
+		Select(
+			goqu.C("id"),
+			goqu.L("?::uuid", payload.ID),
+			goqu.V(string(payload.Type)),
+			goqu.L("?::jsonb", string(body)),
+		).
+		Where(
+			goqu.C("user_login").Eq(login),
+			goqu.C("active").IsTrue(),
+			goqu.L("? = ANY(events)", string(payload.Type)),
+		)
+
+	query, _, err := p.dial.Insert(TABLE_WEBHOOK_DELIVERIES).
+		Cols("webhook_id", "event_id", "event", "payload").
+		FromQuery(subscribers).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build enqueue delivery query", "func", op, "login", login, "event", payload.Type, "error", err)
+		return 0, errors.New("failed to build enqueue delivery query")
+	}
+
+	res, err := p.storage.DB.ExecContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute enqueue delivery query", "func", op, "login", login, "event", payload.Type, "error", err)
+		return 0, errors.New("failed to execute enqueue delivery query")
+	}
+
+	// lib/pq всегда возвращает число строк, ошибка здесь невозможна
+	count, _ := res.RowsAffected()
+	return count, nil
+}
+
+func (p *WebhooksPersister) FetchDue(ctx context.Context, limit int) ([]webhook.Task, error) {
+	const op = "repository.webhooks.deliveries.FetchDue"
+
+	due := p.dial.From(TABLE_WEBHOOK_DELIVERIES).
+		Select("id").
+		Where(
+			goqu.C("status").Eq(string(webhook.StatusPending)),
+			goqu.C("next_attempt_at").Lte(goqu.L("CURRENT_TIMESTAMP")),
+		).
+		Order(goqu.C("id").Asc()).
+		Limit(uint(limit)).
+		ForUpdate(exp.SkipLocked)
+
+	query, _, err := p.dial.Update(goqu.T(TABLE_WEBHOOK_DELIVERIES).As("d")).
+		Set(goqu.Record{"next_attempt_at": goqu.L("CURRENT_TIMESTAMP + " + DELIVERY_LEASE)}).
+		From(goqu.T(TABLE_WEBHOOKS).As("w")).
+		Where(
+			goqu.I("d.id").In(due),
+			goqu.I("w.id").Eq(goqu.I("d.webhook_id")),
+		).
+		Returning(
+			goqu.I("d.id"), goqu.I("d.webhook_id"), goqu.I("d.event_id"), goqu.I("d.event"),
+			goqu.I("d.payload"), goqu.I("d.attempts"), goqu.I("d.created_at"),
+			goqu.I("w.url"), goqu.I("w.secret"),
+		).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build fetch due deliveries query", "func", op, "error", err)
+		return nil, errors.New("failed to build fetch due deliveries query")
+	}
+
+	rows, err := p.storage.DB.QueryContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute fetch due deliveries query", "func", op, "error", err)
+		return nil, errors.New("failed to execute fetch due deliveries query")
+	}
+	defer rows.Close()
+
+	var tasks []webhook.Task
+	for rows.Next() {
+		var task webhook.Task
+		var event string
+		var payload []byte
+		err = rows.Scan(
+			&task.Delivery.ID, &task.Delivery.WebhookID, &task.Delivery.EventID, &event,
+			&payload, &task.Delivery.Attempts, &task.Delivery.CreatedAt,
+			&task.URL, &task.EncryptedSecret,
+		)
+		if err != nil {
+			p.logger.Error("failed to scan delivery", "func", op, "error", err)
+			return nil, errors.New("failed to scan delivery")
+		}
+
+		task.Delivery.Event = webhook.EventType(event)
+		task.Delivery.Payload = payload
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+func (p *WebhooksPersister) Complete(ctx context.Context, attempt webhook.Attempt) error {
+	return p.finishAttempt(ctx, "repository.webhooks.deliveries.Complete", attempt, goqu.Record{
+		"status":        string(webhook.StatusDelivered),
+		"attempts":      goqu.L("attempts + 1"),
+		"response_code": attempt.ResponseCode,
+		"last_error":    nil,
+		"delivered_at":  goqu.L("CURRENT_TIMESTAMP"),
+	})
+}
+
+// Fail фиксирует неудачную попытку, final переводит доставку в failed без дальнейших повторов
+func (p *WebhooksPersister) Fail(ctx context.Context, attempt webhook.Attempt, backoff time.Duration, final bool) error {
+	status := webhook.StatusPending
+	if final {
+		status = webhook.StatusFailed
+	}
+
+	return p.finishAttempt(ctx, "repository.webhooks.deliveries.Fail", attempt, goqu.Record{
+		"status":          string(status),
+		"attempts":        goqu.L("attempts + 1"),
+		"response_code":   attempt.ResponseCode,
+		"last_error":      attempt.Error,
+		"next_attempt_at": goqu.L("CURRENT_TIMESTAMP + ? * INTERVAL '1 second'", int64(backoff.Seconds())),
+	})
+}
+
+// finishAttempt записывает попытку в журнал и обновляет состояние доставки в одной транзакции
+func (p *WebhooksPersister) finishAttempt(ctx context.Context, op string, attempt webhook.Attempt, update goqu.Record) error {
+	insertQuery, _, err := p.dial.Insert(TABLE_WEBHOOK_DELIVERY_ATTEMPTS).
+		Rows(
+			goqu.Record{
+				"delivery_id":   attempt.DeliveryID,
+				"response_code": attempt.ResponseCode,
+				"response_body": attempt.ResponseBody,
+				"error":         nullString(attempt.Error),
+				"duration_ms":   attempt.DurationMs,
+			},
+		).ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build insert attempt query", "func", op, "deliveryID", attempt.DeliveryID, "error", err)
+		return errors.New("failed to build insert attempt query")
+	}
+
+	updateQuery, _, err := p.dial.Update(TABLE_WEBHOOK_DELIVERIES).
+		Set(update).
+		Where(goqu.C("id").Eq(attempt.DeliveryID)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build update delivery query", "func", op, "deliveryID", attempt.DeliveryID, "error", err)
+		return errors.New("failed to build update delivery query")
+	}
+
+	tx, err := p.storage.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		p.logger.Error("failed to begin transaction", "func", op, "deliveryID", attempt.DeliveryID, "error", err)
+		return errors.New("failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, insertQuery); err != nil {
+		p.logger.Error("failed to execute insert attempt query", "func", op, "deliveryID", attempt.DeliveryID, "error", err)
+		return errors.New("failed to execute insert attempt query")
+	}
+
+	if _, err := tx.ExecContext(ctx, updateQuery); err != nil {
+		p.logger.Error("failed to execute update delivery query", "func", op, "deliveryID", attempt.DeliveryID, "error", err)
+		return errors.New("failed to execute update delivery query")
+	}
+
+	if err := tx.Commit(); err != nil {
+		p.logger.Error("failed to commit transaction", "func", op, "deliveryID", attempt.DeliveryID, "error", err)
+		return errors.New("failed to commit transaction")
+	}
+
+	return nil
+}
+
+func (p *WebhooksPersister) GetDeliveries(ctx context.Context, webhookID string, beforeID int64, limit int) ([]webhook.Delivery, error) {
+	const op = "repository.webhooks.deliveries.GetDeliveries"
+
+	expressions := []goqu.Expression{goqu.C("webhook_id").Eq(webhookID)}
+	if beforeID > 0 {
+		expressions = append(expressions, goqu.C("id").Lt(beforeID))
+	}
+
+	query, _, err := p.dial.From(TABLE_WEBHOOK_DELIVERIES).
+		Select(deliveryColumns...).
+		Where(expressions...).
+		Order(goqu.C("id").Desc()).
+		Limit(uint(limit)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get deliveries query", "func", op, "webhookID", webhookID, "error", err)
+		return nil, errors.New("failed to build get deliveries query")
+	}
+
+	rows, err := p.storage.DB.QueryContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute get deliveries query", "func", op, "webhookID", webhookID, "error", err)
+		return nil, errors.New("failed to execute get deliveries query")
+	}
+	defer rows.Close()
+
+	deliveries := []webhook.Delivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			p.logger.Error("failed to scan delivery", "func", op, "webhookID", webhookID, "error", err)
+			return nil, errors.New("failed to scan delivery")
+		}
+
+		// В списке тело события не нужно, его можно получить по ID доставки
+		delivery.Payload = nil
+		deliveries = append(deliveries, *delivery)
+	}
+
+	return deliveries, nil
+}
+
+func (p *WebhooksPersister) GetDelivery(ctx context.Context, webhookID string, id int64) (*webhook.Delivery, error) {
+	const op = "repository.webhooks.deliveries.GetDelivery"
+
+	query, _, err := p.dial.From(TABLE_WEBHOOK_DELIVERIES).
+		Select(deliveryColumns...).
+		Where(goqu.C("id").Eq(id), goqu.C("webhook_id").Eq(webhookID)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get delivery query", "func", op, "id", id, "error", err)
+		return nil, errors.New("failed to build get delivery query")
+	}
+
+	delivery, err := scanDelivery(p.storage.DB.QueryRowContext(ctx, query))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || pg.IsInvalidTextRepresentation(err) {
+			return nil, ErrDeliveryNotFound
+		}
+
+		p.logger.Error("failed to execute get delivery query", "func", op, "id", id, "error", err)
+		return nil, errors.New("failed to execute get delivery query")
+	}
+
+	query, _, err = p.dial.From(TABLE_WEBHOOK_DELIVERY_ATTEMPTS).
+		Select("id", "delivery_id", "response_code", "response_body", "error", "duration_ms", "created_at").
+		Where(goqu.C("delivery_id").Eq(id)).
+		Order(goqu.C("id").Asc()).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get attempts query", "func", op, "id", id, "error", err)
+		return nil, errors.New("failed to build get attempts query")
+	}
+
+	rows, err := p.storage.DB.QueryContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute get attempts query", "func", op, "id", id, "error", err)
+		return nil, errors.New("failed to execute get attempts query")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attempt webhook.Attempt
+		var code sql.NullInt64
+		var body, attemptErr sql.NullString
+		err = rows.Scan(&attempt.ID, &attempt.DeliveryID, &code, &body, &attemptErr, &attempt.DurationMs, &attempt.CreatedAt)
+		if err != nil {
+			p.logger.Error("failed to scan attempt", "func", op, "id", id, "error", err)
+			return nil, errors.New("failed to scan attempt")
+		}
+
+		attempt.ResponseCode = nullInt(code)
+		attempt.ResponseBody = body.String
+		attempt.Error = attemptErr.String
+		delivery.Log = append(delivery.Log, attempt)
+	}
+
+	return delivery, nil
+}
+
+// Redeliver возвращает доставку в очередь с немедленной отправкой и сбрасывает счетчик попыток,
+// журнал прошлых попыток сохраняется
+func (p *WebhooksPersister) Redeliver(ctx context.Context, webhookID string, id int64) (*webhook.Delivery, error) {
+	const op = "repository.webhooks.deliveries.Redeliver"
+
+	query, _, err := p.dial.Update(TABLE_WEBHOOK_DELIVERIES).
+		Set(goqu.Record{
+			"status":          string(webhook.StatusPending),
+			"attempts":        0,
+			"next_attempt_at": goqu.L("CURRENT_TIMESTAMP"),
+			"delivered_at":    nil,
+		}).
+		Where(goqu.C("id").Eq(id), goqu.C("webhook_id").Eq(webhookID)).
+		Returning(deliveryColumns...).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build redeliver query", "func", op, "id", id, "error", err)
+		return nil, errors.New("failed to build redeliver query")
+	}
+
+	delivery, err := scanDelivery(p.storage.DB.QueryRowContext(ctx, query))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || pg.IsInvalidTextRepresentation(err) {
+			return nil, ErrDeliveryNotFound
+		}
+
+		p.logger.Error("failed to execute redeliver query", "func", op, "id", id, "error", err)
+		return nil, errors.New("failed to execute redeliver query")
+	}
+
+	return delivery, nil
+}
+
+func scanDelivery(row scanner) (*webhook.Delivery, error) {
+	var delivery webhook.Delivery
+	var event, status string
+	var payload []byte
+	var code sql.NullInt64
+	var lastError sql.NullString
+	err := row.Scan(
+		&delivery.ID, &delivery.WebhookID, &delivery.EventID, &event, &payload, &status, &delivery.Attempts,
+		&code, &lastError, &delivery.CreatedAt, &delivery.NextAttemptAt, &delivery.DeliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Event = webhook.EventType(event)
+	delivery.Status = webhook.DeliveryStatus(status)
+	delivery.Payload = payload
+	delivery.ResponseCode = nullInt(code)
+	delivery.LastError = lastError.String
+
+	return &delivery, nil
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullInt(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+
+	res := int(value.Int64)
+	return &res
+}
@@ -0,0 +1,8 @@
+package webhooksrepo
+
+import "errors"
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
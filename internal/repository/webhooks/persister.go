@@ -0,0 +1,163 @@
+package webhooksrepo
+
+import (
+	"astral/internal/domain/dto"
+	"astral/internal/domain/webhook"
+	pg "astral/internal/repository/db/postgres"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/lib/pq"
+)
+
+const (
+	TABLE_WEBHOOKS                  = "webhooks"
+	TABLE_WEBHOOK_DELIVERIES        = "webhook_deliveries"
+	TABLE_WEBHOOK_DELIVERY_ATTEMPTS = "webhook_delivery_attempts"
+)
+
+type WebhooksPersister struct {
+	dial    goqu.DialectWrapper
+	storage *pg.Storage
+	logger  *slog.Logger
+}
+
+func NewWebhooksPersister(storage *pg.Storage, logger *slog.Logger) *WebhooksPersister {
+	return &WebhooksPersister{
+		dial:    goqu.Dialect(pg.DRIVER),
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (p *WebhooksPersister) CreateWebhook(ctx context.Context, data dto.WebhookData) (*webhook.Webhook, error) {
+	const op = "repository.webhooks.persister.CreateWebhook"
+
+	query, _, err := p.dial.Insert(TABLE_WEBHOOKS).
+		Rows(
+			goqu.Record{
+				"user_login": data.Login,
+				"url":        data.URL,
+				"events":     pq.StringArray(data.Events),
+				"secret":     data.EncryptedSecret,
+			},
+		).Returning("id", "user_login", "url", "events", "active", "created_at").ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build webhook creation query", "func", op, "login", data.Login, "error", err)
+		return nil, errors.New("failed to build webhook creation query")
+	}
+
+	res, err := scanWebhook(p.storage.DB.QueryRowContext(ctx, query))
+	if err != nil {
+		p.logger.Error("failed to execute create webhook query", "func", op, "login", data.Login, "error", err)
+		return nil, errors.New("failed to execute create webhook query")
+	}
+
+	return res, nil
+}
+
+func (p *WebhooksPersister) GetWebhooks(ctx context.Context, login string) ([]webhook.Webhook, error) {
+	const op = "repository.webhooks.persister.GetWebhooks"
+
+	query, _, err := p.dial.From(TABLE_WEBHOOKS).
+		Select("id", "user_login", "url", "events", "active", "created_at").
+		Where(goqu.C("user_login").Eq(login)).
+		Order(goqu.C("created_at").Asc()).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get webhooks query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to build get webhooks query")
+	}
+
+	rows, err := p.storage.DB.QueryContext(ctx, query)
+	if err != nil {
+		p.logger.Error("failed to execute get webhooks query", "func", op, "login", login, "error", err)
+		return nil, errors.New("failed to execute get webhooks query")
+	}
+	defer rows.Close()
+
+	webhooks := []webhook.Webhook{}
+	for rows.Next() {
+		res, err := scanWebhook(rows)
+		if err != nil {
+			p.logger.Error("failed to scan webhook", "func", op, "login", login, "error", err)
+			return nil, errors.New("failed to scan webhook")
+		}
+
+		webhooks = append(webhooks, *res)
+	}
+
+	return webhooks, nil
+}
+
+func (p *WebhooksPersister) GetWebhook(ctx context.Context, login, id string) (*webhook.Webhook, error) {
+	const op = "repository.webhooks.persister.GetWebhook"
+
+	query, _, err := p.dial.From(TABLE_WEBHOOKS).
+		Select("id", "user_login", "url", "events", "active", "created_at").
+		Where(goqu.C("id").Eq(id), goqu.C("user_login").Eq(login)).
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build get webhook query", "func", op, "login", login, "id", id, "error", err)
+		return nil, errors.New("failed to build get webhook query")
+	}
+
+	res, err := scanWebhook(p.storage.DB.QueryRowContext(ctx, query))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || pg.IsInvalidTextRepresentation(err) {
+			return nil, ErrWebhookNotFound
+		}
+
+		p.logger.Error("failed to execute get webhook query", "func", op, "login", login, "id", id, "error", err)
+		return nil, errors.New("failed to execute get webhook query")
+	}
+
+	return res, nil
+}
+
+func (p *WebhooksPersister) DeleteWebhook(ctx context.Context, login, id string) (*webhook.Webhook, error) {
+	const op = "repository.webhooks.persister.DeleteWebhook"
+
+	query, _, err := p.dial.Delete(TABLE_WEBHOOKS).
+		Where(goqu.C("id").Eq(id), goqu.C("user_login").Eq(login)).
+		Returning("id", "user_login", "url", "events", "active", "created_at").
+		ToSQL()
+	if err != nil {
+		p.logger.Error("failed to build delete webhook query", "func", op, "login", login, "id", id, "error", err)
+		return nil, errors.New("failed to build delete webhook query")
+	}
+
+	res, err := scanWebhook(p.storage.DB.QueryRowContext(ctx, query))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || pg.IsInvalidTextRepresentation(err) {
+			p.logger.Info("webhook not found", "func", op, "login", login, "id", id)
+			return nil, ErrWebhookNotFound
+		}
+
+		p.logger.Error("failed to execute delete webhook query", "func", op, "login", login, "id", id, "error", err)
+		return nil, errors.New("failed to execute delete webhook query")
+	}
+
+	return res, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhook(row scanner) (*webhook.Webhook, error) {
+	var res webhook.Webhook
+	var events pq.StringArray
+	if err := row.Scan(&res.ID, &res.Login, &res.URL, &events, &res.Active, &res.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		res.Events = append(res.Events, webhook.EventType(event))
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,23 @@
+package webhooksrepo
+
+import (
+	"astral/internal/domain/dto"
+	"astral/internal/domain/webhook"
+	"context"
+	"time"
+)
+
+type WebhooksRepo interface {
+	CreateWebhook(ctx context.Context, data dto.WebhookData) (*webhook.Webhook, error)
+	GetWebhooks(ctx context.Context, login string) ([]webhook.Webhook, error)
+	GetWebhook(ctx context.Context, login, id string) (*webhook.Webhook, error)
+	DeleteWebhook(ctx context.Context, login, id string) (*webhook.Webhook, error)
+
+	Enqueue(ctx context.Context, login string, payload webhook.Payload) (int64, error)
+	FetchDue(ctx context.Context, limit int) ([]webhook.Task, error)
+	Complete(ctx context.Context, attempt webhook.Attempt) error
+	Fail(ctx context.Context, attempt webhook.Attempt, backoff time.Duration, final bool) error
+	GetDeliveries(ctx context.Context, webhookID string, beforeID int64, limit int) ([]webhook.Delivery, error)
+	GetDelivery(ctx context.Context, webhookID string, id int64) (*webhook.Delivery, error)
+	Redeliver(ctx context.Context, webhookID string, id int64) (*webhook.Delivery, error)
+}
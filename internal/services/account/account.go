@@ -0,0 +1,213 @@
+package accountservice
+
+import (
+	"astral/internal/domain/account"
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/dto"
+	"astral/internal/domain/event"
+	"astral/internal/domain/user"
+	accountrepo "astral/internal/repository/account"
+	authrepo "astral/internal/repository/auth"
+	imagesrepo "astral/internal/repository/images"
+	s3repo "astral/internal/repository/s3"
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	DEFAULT_TIMEOUT = time.Second * 5
+	PURGE_TIMEOUT   = time.Minute * 10
+)
+
+// AccountService выгружает данные пользователя и удаляет учетные записи. Выгрузки собираются,
+// а удаления выполняются фоновым воркером после отсрочки grace
+type AccountService struct {
+	repo      accountrepo.AccountRepo
+	users     authrepo.AuthRepo
+	auth      contracts.AuthInterface
+	files     contracts.FilesInterface
+	variants  imagesrepo.ImagesRepo
+	logger    *slog.Logger
+	grace     time.Duration
+	exportTTL time.Duration
+	interval  time.Duration
+}
+
+func NewAccountService(
+	repo accountrepo.AccountRepo,
+	users authrepo.AuthRepo,
+	auth contracts.AuthInterface,
+	files contracts.FilesInterface,
+	variants imagesrepo.ImagesRepo,
+	logger *slog.Logger,
+	grace time.Duration,
+	exportTTL time.Duration,
+	interval time.Duration,
+) *AccountService {
+	return &AccountService{
+		repo:      repo,
+		users:     users,
+		auth:      auth,
+		files:     files,
+		variants:  variants,
+		logger:    logger.With("service", "AccountService"),
+		grace:     grace,
+		exportTTL: exportTTL,
+		interval:  interval,
+	}
+}
+
+// Run раз в interval собирает ожидающие выгрузки, удаляет просроченные архивы и учетные
+// записи, у которых закончилась отсрочка
+func (s *AccountService) Run(ctx context.Context) {
+	const op = "services.account.Run"
+	s.logger.Info("account worker started", "func", op, "interval", s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("account worker stopped", "func", op)
+			return
+		case <-ticker.C:
+			s.processExports(ctx)
+			s.expireExports(ctx)
+			s.purgeAccounts(ctx)
+		}
+	}
+}
+
+// RequestDeletion назначает удаление учетной записи через grace. Все сессии, пароли приложений
+// и ключи доступа отзываются сразу, вход до конца отсрочки закрыт
+func (s *AccountService) RequestDeletion(login string) (*account.Deletion, error) {
+	const op = "services.account.RequestDeletion"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	scheduled, err := event.New(event.TypeUserDeletionScheduled, login, event.UserPayload{Login: login})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.ScheduleDeletion(ctx, login, time.Now().Add(s.grace), scheduled)
+}
+
+// RestoreAccount отменяет удаление до конца отсрочки. Сессии были отозваны при запросе
+// удаления, поэтому после восстановления выдается новый токен
+func (s *AccountService) RestoreAccount(userData dto.UserData) (*user.Token, error) {
+	const op = "services.account.RestoreAccount"
+	s.logger.Info("Usecase start", "func", op, "login", userData.Login)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	usr, err := s.users.GetUserByLogin(ctx, userData.Login)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(usr.Password), []byte(userData.Password)); err != nil {
+		s.logger.Warn("invalid password", "func", op, "login", userData.Login)
+		return nil, ErrAccessDenied
+	}
+
+	if usr.DeleteAfter == nil {
+		return nil, accountrepo.ErrDeletionNotScheduled
+	}
+
+	if time.Now().After(*usr.DeleteAfter) {
+		return nil, ErrGracePeriodExpired
+	}
+
+	cancelled, err := event.New(event.TypeUserDeletionCancelled, usr.Login, event.UserPayload{Login: usr.Login})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CancelDeletion(ctx, usr.Login, cancelled); err != nil {
+		return nil, err
+	}
+
+	return s.auth.Login(userData)
+}
+
+func (s *AccountService) purgeAccounts(ctx context.Context) {
+	const op = "services.account.purgeAccounts"
+
+	listCtx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+	logins, err := s.repo.ListDueDeletions(listCtx)
+	cancel()
+	if err != nil {
+		s.logger.Error("failed to list due deletions", "func", op, "error", err)
+		return
+	}
+
+	for _, login := range logins {
+		if err := s.purge(ctx, login); err != nil {
+			s.logger.Error("failed to delete account", "func", op, "login", login, "error", err)
+			continue
+		}
+
+		s.logger.Info("account deleted", "func", op, "login", login)
+	}
+}
+
+// purge удаляет объекты пользователя, варианты его изображений, кэш и в последнюю очередь строку в users: пока она
+// есть, удаление повторяется на следующем проходе. Если удаление прервется после части
+// объектов, оставшиеся ссылки на уже удаленные документы найдет сверка хранилища
+func (s *AccountService) purge(ctx context.Context, login string) error {
+	ctx, cancel := context.WithTimeout(ctx, PURGE_TIMEOUT)
+	defer cancel()
+
+	keys, err := s.repo.RemoveObjects(ctx, login+"/")
+	if err != nil {
+		return err
+	}
+
+	var documentIDs []string
+	for _, key := range keys {
+		if ID := strings.TrimPrefix(key, login+"/"); !strings.Contains(ID, "/") {
+			documentIDs = append(documentIDs, ID)
+		}
+	}
+
+	uploads, err := s.repo.ListMultipartUploads(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	prefixes := []string{accountrepo.TAKEOUT_PREFIX + "/" + login + "/"}
+	for _, uploadID := range uploads {
+		prefixes = append(prefixes, s3repo.PARTS_PREFIX+"/"+uploadID+"/")
+	}
+
+	for _, prefix := range prefixes {
+		if _, err := s.repo.RemoveObjects(ctx, prefix); err != nil {
+			return err
+		}
+	}
+
+	// Варианты изображений лежат в своем бакете под login/ и без этого дожили бы до IMAGES_CACHE_TTL
+	if err := s.variants.RemoveVariants(ctx, imagesrepo.UserPrefix(login)); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteCacheKeys(ctx, login, documentIDs); err != nil {
+		return err
+	}
+
+	deleted, err := event.New(event.TypeUserDeleted, login, event.UserPayload{Login: login})
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteUser(ctx, login, documentIDs, deleted)
+}
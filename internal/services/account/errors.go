@@ -0,0 +1,10 @@
+package accountservice
+
+import "errors"
+
+var (
+	ErrAccessDenied       = errors.New("access denied")
+	ErrGracePeriodExpired = errors.New("account deletion grace period has expired")
+	ErrExportNotReady     = errors.New("export is not ready yet")
+	ErrExportExpired      = errors.New("export has expired")
+)
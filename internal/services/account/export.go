@@ -0,0 +1,307 @@
+package accountservice
+
+import (
+	"archive/zip"
+	"astral/internal/domain/account"
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	EXPORT_TIMEOUT = time.Minute * 30
+
+	DOCUMENTS_DIR = "documents"
+)
+
+// exportDocument - запись documents.json: метаданные документа и путь к его содержимому в архиве
+type exportDocument struct {
+	file.File
+	Path  string `json:"path,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func (s *AccountService) CreateExport(login string) (*account.Export, error) {
+	const op = "services.account.CreateExport"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.CreateExport(ctx, login)
+}
+
+func (s *AccountService) GetExport(login, ID string) (*account.Export, error) {
+	const op = "services.account.GetExport"
+	s.logger.Info("Usecase start", "func", op, "login", login, "exportID", ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.GetExport(ctx, login, ID)
+}
+
+// OpenExport открывает готовый архив выгрузки. Архив нужно закрыть
+func (s *AccountService) OpenExport(login, ID string) (*account.Export, io.ReadCloser, error) {
+	const op = "services.account.OpenExport"
+	s.logger.Info("Usecase start", "func", op, "login", login, "exportID", ID)
+
+	export, err := s.GetExport(login, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case export.Status == account.ExportExpired,
+		export.ExpiresAt != nil && time.Now().After(*export.ExpiresAt):
+		return nil, nil, ErrExportExpired
+	case export.Status != account.ExportReady:
+		return nil, nil, ErrExportNotReady
+	}
+
+	// Контекст живет вместе с архивом: по нему читается объект
+	ctx, cancel := context.WithTimeout(context.Background(), EXPORT_TIMEOUT)
+
+	content, err := s.repo.OpenExportObject(ctx, *export)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	return export, &exportReader{ReadCloser: content, cancel: cancel}, nil
+}
+
+type exportReader struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *exportReader) Close() error {
+	defer r.cancel()
+	return r.ReadCloser.Close()
+}
+
+func (s *AccountService) processExports(ctx context.Context) {
+	const op = "services.account.processExports"
+
+	for ctx.Err() == nil {
+		claimCtx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+		export, err := s.repo.ClaimExport(claimCtx)
+		cancel()
+		if err != nil {
+			s.logger.Error("failed to claim export", "func", op, "error", err)
+			return
+		}
+		if export == nil {
+			return
+		}
+
+		size, err := s.export(ctx, *export)
+		if err != nil {
+			s.logger.Error("failed to build export", "func", op, "login", export.Login, "exportID", export.ID, "error", err)
+
+			failCtx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+			s.repo.FailExport(failCtx, export.ID, err.Error())
+			cancel()
+			continue
+		}
+
+		doneCtx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+		err = s.repo.CompleteExport(doneCtx, export.ID, size, time.Now().Add(s.exportTTL))
+		cancel()
+		if err != nil {
+			s.logger.Error("failed to complete export", "func", op, "exportID", export.ID, "error", err)
+			continue
+		}
+
+		s.logger.Info("export ready", "func", op, "login", export.Login, "exportID", export.ID, "size", size)
+	}
+}
+
+// expireExports удаляет архивы, срок скачивания которых истек. Запись о выгрузке остается
+func (s *AccountService) expireExports(ctx context.Context) {
+	const op = "services.account.expireExports"
+
+	listCtx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+	exports, err := s.repo.ListExpiredExports(listCtx)
+	cancel()
+	if err != nil {
+		s.logger.Error("failed to list expired exports", "func", op, "error", err)
+		return
+	}
+
+	for _, export := range exports {
+		expireCtx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+		err := s.repo.RemoveExportObject(expireCtx, export)
+		if err == nil {
+			err = s.repo.ExpireExport(expireCtx, export.ID)
+		}
+		cancel()
+
+		if err != nil {
+			s.logger.Error("failed to expire export", "func", op, "exportID", export.ID, "error", err)
+		}
+	}
+}
+
+// export собирает архив во временном файле и сохраняет его в хранилище. В архиве содержимое
+// документов в documents/<папка>/<имя>, их метаданные в documents.json, выданные доступы в
+// shares.json и сессии в sessions.json
+func (s *AccountService) export(ctx context.Context, export account.Export) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, EXPORT_TIMEOUT)
+	defer cancel()
+
+	tmp, err := os.CreateTemp("", "takeout-*.zip")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := s.writeArchive(ctx, tmp, export.Login); err != nil {
+		return 0, err
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	if err := s.repo.PutExportObject(ctx, export, tmp, size); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+func (s *AccountService) writeArchive(ctx context.Context, w io.Writer, login string) error {
+	docs, err := s.files.GetFilesByUser(login, contracts.FilterData{})
+	if err != nil {
+		return err
+	}
+
+	sessionsCtx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+	sessions, err := s.repo.ListSessions(sessionsCtx, login)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	for i := range sessions {
+		if sessions[i].Kind != account.SessionAppPassword {
+			sessions[i].ID = mask(sessions[i].ID)
+		}
+	}
+
+	zw := zip.NewWriter(w)
+
+	documents := make([]exportDocument, 0, len(docs))
+	shares := []account.Share{}
+	paths := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		entry := exportDocument{File: doc}
+		if doc.File && !doc.IsCollection() {
+			// Документ, который не удалось открыть, не прерывает выгрузку: ошибка попадает
+			// в documents.json. Ошибка посреди записи в архив прерывает
+			obj, err := s.files.OpenObject(doc.ID, login)
+			if err != nil {
+				s.logger.Warn("failed to open document", "login", login, "fileID", doc.ID, "error", err)
+				entry.Error = err.Error()
+			} else {
+				entry.Path = uniquePath(paths, doc)
+				err = writeDocument(zw, doc, entry.Path, obj)
+				obj.Close()
+				if err != nil {
+					return err
+				}
+			}
+		}
+		documents = append(documents, entry)
+
+		if doc.Public || len(doc.Grant) > 0 {
+			shares = append(shares, account.Share{
+				DocumentID: doc.ID,
+				Name:       doc.Name,
+				Public:     doc.Public,
+				Grant:      doc.Grant,
+			})
+		}
+	}
+
+	if err := writeJSON(zw, "documents.json", documents); err != nil {
+		return err
+	}
+	if err := writeJSON(zw, "shares.json", shares); err != nil {
+		return err
+	}
+	if err := writeJSON(zw, "sessions.json", sessions); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// writeDocument копирует содержимое документа в архив
+func writeDocument(zw *zip.Writer, doc file.File, name string, content io.Reader) error {
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+	if doc.CreatedAt != nil {
+		header.Modified = *doc.CreatedAt
+	}
+
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, content)
+	return err
+}
+
+func writeJSON(zw *zip.Writer, name string, data any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// uniquePath возвращает путь документа в архиве. Имена в папке не уникальны, поэтому к
+// повторяющемуся имени добавляется ID документа
+func uniquePath(paths map[string]bool, doc file.File) string {
+	name := path.Join(DOCUMENTS_DIR, file.CleanPath(doc.Path()))
+	if paths[name] {
+		ext := path.Ext(name)
+		name = fmt.Sprintf("%s (%s)%s", strings.TrimSuffix(name, ext), doc.ID, ext)
+	}
+	paths[name] = true
+
+	return name
+}
+
+// mask оставляет от токена или ключа последние символы, по которым его можно узнать
+func mask(secret string) string {
+	const visible = 4
+	if len(secret) <= visible {
+		return strings.Repeat("*", len(secret))
+	}
+
+	return strings.Repeat("*", 8) + secret[len(secret)-visible:]
+}
@@ -0,0 +1,79 @@
+package activityservice
+
+import (
+	"astral/internal/domain/audit"
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	"astral/internal/domain/webhook"
+	"strings"
+)
+
+// ActivityService - единственное место, откуда действие пользователя расходится по журналу аудита,
+// webhooks и уведомлениям. Контроллеры REST, WebDAV, S3 и gRPC только описывают действие
+type ActivityService struct {
+	audit         contracts.AuditInterface
+	webhooks      contracts.WebhooksInterface
+	notifications contracts.NotificationsInterface
+}
+
+func NewActivityService(
+	audit contracts.AuditInterface,
+	webhooks contracts.WebhooksInterface,
+	notifications contracts.NotificationsInterface,
+) *ActivityService {
+	return &ActivityService{
+		audit:         audit,
+		webhooks:      webhooks,
+		notifications: notifications,
+	}
+}
+
+// Record сохраняет событие, не связанное с документом: вход, учетная запись, отказ в доступе
+func (s *ActivityService) Record(event audit.Event) {
+	s.audit.Record(event)
+}
+
+// RecordDocument сохраняет действие с документом doc и, если оно интересно подписчикам, отправляет
+// событие webhook и уведомление. Подробности из event.Details дополняют имя документа
+func (s *ActivityService) RecordDocument(event audit.Event, doc file.File) {
+	if event.Owner == "" {
+		event.Owner = doc.User
+	}
+	event.TargetType = audit.TargetDocument
+	event.TargetID = doc.ID
+
+	details := map[string]string{"name": doc.Name}
+	for k, v := range event.Details {
+		details[k] = v
+	}
+	event.Details = details
+
+	s.audit.Record(event)
+
+	if eventType, ok := webhook.EventTypeOf(event.Action, event.Details); ok {
+		s.webhooks.Publish(eventType, event.Actor, doc, event.Details)
+		s.notifications.Publish(eventType, event.Actor, doc, event.Details)
+	}
+}
+
+// RecordShare фиксирует выдачу доступа, если документ создан с грантами или публичным
+func (s *ActivityService) RecordShare(event audit.Event, doc file.File) {
+	if len(doc.Grant) == 0 && !doc.Public {
+		return
+	}
+
+	details := map[string]string{}
+	for k, v := range event.Details {
+		details[k] = v
+	}
+	if len(doc.Grant) > 0 {
+		details["grant"] = strings.Join(doc.Grant, ",")
+	}
+	if doc.Public {
+		details["public"] = "true"
+	}
+
+	event.Action = audit.ActionShare
+	event.Details = details
+	s.RecordDocument(event, doc)
+}
@@ -0,0 +1,289 @@
+package adminservice
+
+import (
+	"astral/internal/domain/admin"
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/dto"
+	"astral/internal/domain/event"
+	adminrepo "astral/internal/repository/admin"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	DEFAULT_TIMEOUT = time.Second * 5
+	USAGE_TIMEOUT   = time.Second * 30
+
+	DEFAULT_LIMIT = 50
+	MAX_LIMIT     = 200
+
+	GENERATED_PASSWORD_LENGTH = 16
+)
+
+// AdminService - управление учетными записями и документами от имени администратора. Каждый
+// метод принимает токен администратора первым аргументом
+type AdminService struct {
+	repo       adminrepo.AdminRepo
+	files      contracts.FilesInterface
+	validation contracts.ValidationInterface
+	logger     *slog.Logger
+	adminToken string
+}
+
+func NewAdminService(
+	repo adminrepo.AdminRepo,
+	files contracts.FilesInterface,
+	validation contracts.ValidationInterface,
+	logger *slog.Logger,
+	adminToken string,
+) *AdminService {
+	return &AdminService{
+		repo:       repo,
+		files:      files,
+		validation: validation,
+		logger:     logger.With("service", "AdminService"),
+		adminToken: adminToken,
+	}
+}
+
+// ListUsers ищет учетные записи по подстроке логина и статусу и считает занятое каждой место
+func (s *AdminService) ListUsers(adminToken string, filter admin.UserFilter) ([]admin.User, error) {
+	const op = "services.admin.ListUsers"
+	s.logger.Info("Usecase start", "func", op, "query", filter.Query, "status", filter.Status)
+
+	if err := s.authorize(op, adminToken); err != nil {
+		return nil, err
+	}
+
+	switch filter.Status {
+	case "", admin.StatusActive, admin.StatusDisabled, admin.StatusPendingDeletion:
+	default:
+		return nil, ErrInvalidStatus
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = DEFAULT_LIMIT
+	}
+	if filter.Limit > MAX_LIMIT {
+		filter.Limit = MAX_LIMIT
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	users, err := s.repo.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	usageCtx, cancel := context.WithTimeout(context.Background(), USAGE_TIMEOUT)
+	defer cancel()
+
+	for i := range users {
+		if users[i].Usage, err = s.repo.Usage(usageCtx, users[i].Login); err != nil {
+			return nil, err
+		}
+	}
+
+	return users, nil
+}
+
+func (s *AdminService) GetUser(adminToken, login string) (*admin.User, error) {
+	const op = "services.admin.GetUser"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	if err := s.authorize(op, adminToken); err != nil {
+		return nil, err
+	}
+
+	return s.user(login)
+}
+
+// DisableUser блокирует учетную запись: все сессии закрываются, вход, пароли приложений и
+// ключи доступа перестают работать до снятия блокировки
+func (s *AdminService) DisableUser(adminToken, login, reason string) (*admin.User, error) {
+	const op = "services.admin.DisableUser"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	if err := s.authorize(op, adminToken); err != nil {
+		return nil, err
+	}
+
+	disabled, err := event.New(event.TypeUserDisabled, login, event.UserPayload{Login: login})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if err := s.repo.SetDisabled(ctx, login, true, reason, disabled); err != nil {
+		return nil, err
+	}
+
+	return s.user(login)
+}
+
+func (s *AdminService) EnableUser(adminToken, login string) (*admin.User, error) {
+	const op = "services.admin.EnableUser"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	if err := s.authorize(op, adminToken); err != nil {
+		return nil, err
+	}
+
+	enabled, err := event.New(event.TypeUserEnabled, login, event.UserPayload{Login: login})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if err := s.repo.SetDisabled(ctx, login, false, "", enabled); err != nil {
+		return nil, err
+	}
+
+	return s.user(login)
+}
+
+// RevokeSessions закрывает все сессии пользователя и возвращает их число
+func (s *AdminService) RevokeSessions(adminToken, login string) (int64, error) {
+	const op = "services.admin.RevokeSessions"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	if err := s.authorize(op, adminToken); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if _, err := s.repo.GetUser(ctx, login); err != nil {
+		return 0, err
+	}
+
+	return s.repo.RevokeSessions(ctx, login)
+}
+
+// ResetPassword задает пользователю новый пароль и закрывает все его сессии. Если пароль не
+// передан, он генерируется и возвращается один раз
+func (s *AdminService) ResetPassword(adminToken, login, password string) (string, error) {
+	const op = "services.admin.ResetPassword"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	if err := s.authorize(op, adminToken); err != nil {
+		return "", err
+	}
+
+	if password == "" {
+		generated, err := generatePassword()
+		if err != nil {
+			s.logger.Error("failed to generate password", "func", op, "login", login, "error", err)
+			return "", err
+		}
+		password = generated
+	}
+
+	if err := s.validation.ValidateUserData(dto.UserData{Login: login, Password: password}); err != nil {
+		return "", err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		s.logger.Error("failed to hash password", "func", op, "login", login, "error", err)
+		return "", err
+	}
+
+	reset, err := event.New(event.TypeUserPasswordReset, login, event.UserPayload{Login: login})
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if err := s.repo.SetPassword(ctx, login, string(hash), reset); err != nil {
+		return "", err
+	}
+
+	return password, nil
+}
+
+// user возвращает учетную запись вместе с занятым местом
+func (s *AdminService) user(login string) (*admin.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	usr, err := s.repo.GetUser(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	usageCtx, cancel := context.WithTimeout(context.Background(), USAGE_TIMEOUT)
+	defer cancel()
+
+	if usr.Usage, err = s.repo.Usage(usageCtx, login); err != nil {
+		return nil, err
+	}
+
+	return usr, nil
+}
+
+// authorize сравнивает токен за постоянное время, чтобы его нельзя было подобрать по времени ответа
+func (s *AdminService) authorize(op, adminToken string) error {
+	if adminToken == "" || subtle.ConstantTimeCompare([]byte(adminToken), []byte(s.adminToken)) != 1 {
+		s.logger.Warn("invalid admin token", "func", op)
+		return ErrAccessDenied
+	}
+
+	return nil
+}
+
+// generatePassword собирает пароль, который проходит проверку сложности: в нем есть буквы
+// в обоих регистрах, цифра и спецсимвол
+func generatePassword() (string, error) {
+	const (
+		upper   = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+		lower   = "abcdefghijkmnopqrstuvwxyz"
+		digits  = "23456789"
+		special = "!@#$%^&*-_=+"
+	)
+
+	sets := []string{upper, lower, digits, special}
+	all := upper + lower + digits + special
+
+	password := make([]byte, GENERATED_PASSWORD_LENGTH)
+	for i := range password {
+		set := all
+		if i < len(sets) {
+			set = sets[i]
+		}
+
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+		if err != nil {
+			return "", err
+		}
+		password[i] = set[n.Int64()]
+	}
+
+	// Перемешивание, чтобы обязательные символы не стояли в начале
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+
+	return string(password), nil
+}
@@ -0,0 +1,117 @@
+package adminservice
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	"context"
+)
+
+// ListDocuments возвращает документы пользователя login вместе с коллекциями
+func (s *AdminService) ListDocuments(adminToken, login string) ([]file.File, error) {
+	const op = "services.admin.ListDocuments"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	if err := s.authorize(op, adminToken); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if _, err := s.repo.GetUser(ctx, login); err != nil {
+		return nil, err
+	}
+
+	return s.files.GetFilesByUser(login, contracts.FilterData{})
+}
+
+func (s *AdminService) GetDocument(adminToken, login, ID string) (*file.File, error) {
+	const op = "services.admin.GetDocument"
+	s.logger.Info("Usecase start", "func", op, "login", login, "fileID", ID)
+
+	if err := s.authorize(op, adminToken); err != nil {
+		return nil, err
+	}
+
+	return s.document(login, ID)
+}
+
+// OpenDocument открывает содержимое документа пользователя. Содержимое нужно закрыть
+func (s *AdminService) OpenDocument(adminToken, login, ID string) (*file.File, file.Object, error) {
+	const op = "services.admin.OpenDocument"
+	s.logger.Info("Usecase start", "func", op, "login", login, "fileID", ID)
+
+	if err := s.authorize(op, adminToken); err != nil {
+		return nil, nil, err
+	}
+
+	doc, err := s.document(login, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !doc.File || doc.IsCollection() {
+		return nil, nil, ErrDocumentNotFound
+	}
+
+	obj, err := s.files.OpenObject(ID, login)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return doc, obj, nil
+}
+
+// DeleteDocument удаляет документ пользователя так же, как его удалил бы владелец
+func (s *AdminService) DeleteDocument(adminToken, login, ID string) (*file.File, error) {
+	const op = "services.admin.DeleteDocument"
+	s.logger.Info("Usecase start", "func", op, "login", login, "fileID", ID)
+
+	if err := s.authorize(op, adminToken); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.document(login, ID); err != nil {
+		return nil, err
+	}
+
+	return s.files.DeleteFile(ID, login)
+}
+
+// document ищет документ среди документов владельца: GetFileByID проверяет доступ
+// пользователя, а администратору нужен документ независимо от выданных доступов
+func (s *AdminService) document(login, ID string) (*file.File, error) {
+	docs, err := s.files.GetFilesByUser(login, contracts.FilterData{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, doc := range docs {
+		if doc.ID == ID {
+			doc.User = login
+			return &doc, nil
+		}
+	}
+
+	return nil, ErrDocumentNotFound
+}
+
+// TransferDocuments передает все документы пользователя login пользователю to, например
+// когда сотрудник уходит. Учетная запись login при этом не меняется
+func (s *AdminService) TransferDocuments(adminToken, login, to string) (*file.Transfer, error) {
+	const op = "services.admin.TransferDocuments"
+	s.logger.Info("Usecase start", "func", op, "login", login, "to", to)
+
+	if err := s.authorize(op, adminToken); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if _, err := s.repo.GetUser(ctx, login); err != nil {
+		return nil, err
+	}
+
+	return s.files.TransferAll(login, to)
+}
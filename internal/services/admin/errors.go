@@ -0,0 +1,9 @@
+package adminservice
+
+import "errors"
+
+var (
+	ErrAccessDenied     = errors.New("access denied")
+	ErrInvalidStatus    = errors.New("invalid user status")
+	ErrDocumentNotFound = errors.New("document not found")
+)
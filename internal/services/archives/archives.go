@@ -0,0 +1,254 @@
+package archivesservice
+
+import (
+	"astral/internal/domain/archive"
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	filesrepo "astral/internal/repository/files"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"path"
+	"slices"
+)
+
+const (
+	MAX_EXTRACT_ENTRIES = 1000
+	MAX_EXTRACT_SIZE    = 1 << 30
+)
+
+// ArchivesService показывает содержимое ZIP и TAR архивов, отдает отдельные записи и
+// распаковывает архивы в пространство владельца отдельными документами
+type ArchivesService struct {
+	files  contracts.FilesInterface
+	logger *slog.Logger
+}
+
+func NewArchivesService(files contracts.FilesInterface, logger *slog.Logger) *ArchivesService {
+	return &ArchivesService{
+		files:  files,
+		logger: logger.With("service", "ArchivesService"),
+	}
+}
+
+// Document возвращает архив, проверив доступ к нему, без чтения содержимого
+func (s *ArchivesService) Document(login, owner, ID string) (*file.File, error) {
+	doc, _, err := s.document(login, owner, ID)
+	return doc, err
+}
+
+// ListEntries возвращает записи архива в порядке их следования
+func (s *ArchivesService) ListEntries(login, owner, ID string) (*file.File, []archive.Entry, error) {
+	const op = "services.archives.ListEntries"
+	s.logger.Info("Usecase start", "func", op, "login", login, "owner", owner, "fileID", ID)
+
+	doc, kind, err := s.document(login, owner, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries := []archive.Entry{}
+	err = s.walk(*doc, kind, func(entry archive.Entry, _ func() (io.ReadCloser, error)) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return doc, entries, nil
+}
+
+// StreamEntry находит запись name и передает ее содержимое в fn. Из хранилища читается
+// только нужная часть архива
+func (s *ArchivesService) StreamEntry(login, owner, ID, name string, fn func(doc file.File, entry archive.Entry, r io.Reader) error) error {
+	const op = "services.archives.StreamEntry"
+	s.logger.Info("Usecase start", "func", op, "login", login, "owner", owner, "fileID", ID, "entry", name)
+
+	doc, kind, err := s.document(login, owner, ID)
+	if err != nil {
+		return err
+	}
+
+	name = file.CleanPath(name)
+	found := false
+	err = s.walk(*doc, kind, func(entry archive.Entry, open func() (io.ReadCloser, error)) error {
+		if entry.Dir || entry.Name != name {
+			return nil
+		}
+		found = true
+
+		r, err := open()
+		if err != nil {
+			return ErrInvalidArchive
+		}
+		defer r.Close()
+
+		if err := fn(*doc, entry, r); err != nil {
+			return err
+		}
+
+		return errStop
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrEntryNotFound
+	}
+
+	return nil
+}
+
+// Extract распаковывает архив владельца в папку folder, по умолчанию - рядом с архивом
+// в папку с его именем. Пути записей сохраняются как вложенные папки. Распаковка идет целиком
+// или никак: при ошибке уже созданные документы удаляются, а те, что удалить не удалось,
+// возвращаются вместе с ошибкой
+func (s *ArchivesService) Extract(login, ID, folder string) (*file.File, []file.File, error) {
+	const op = "services.archives.Extract"
+	s.logger.Info("Usecase start", "func", op, "login", login, "fileID", ID, "folder", folder)
+
+	doc, kind, err := s.document(login, login, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	folder = file.CleanPath(folder)
+	if folder == "" {
+		folder = path.Join(doc.Folder(), archive.TrimExt(doc.Name))
+	}
+
+	// Сначала проверяем объем по заголовкам, чтобы не распаковать архив наполовину
+	var count int
+	var total int64
+	err = s.walk(*doc, kind, func(entry archive.Entry, _ func() (io.ReadCloser, error)) error {
+		if entry.Dir {
+			return nil
+		}
+
+		count++
+		total += entry.Size
+		if count > MAX_EXTRACT_ENTRIES || total > MAX_EXTRACT_SIZE {
+			return ErrArchiveTooLarge
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	created := []file.File{}
+	err = s.walk(*doc, kind, func(entry archive.Entry, open func() (io.ReadCloser, error)) error {
+		if entry.Dir {
+			return nil
+		}
+
+		r, err := open()
+		if err != nil {
+			return ErrInvalidArchive
+		}
+		defer r.Close()
+
+		dir, name := file.SplitPath(path.Join(folder, entry.Name))
+		res, err := s.files.UploadFiles(file.File{
+			Name:     name,
+			File:     true,
+			Mime:     detectMime(name),
+			Size:     int(entry.Size),
+			Metadata: map[string]string{file.FOLDER_METADATA_KEY: dir},
+			Reader:   io.LimitReader(r, entry.Size),
+			User:     login,
+		})
+		if err != nil {
+			s.logger.Error("failed to extract archive entry", "func", op, "fileID", ID, "entry", entry.Name, "error", err)
+			return err
+		}
+
+		created = append(created, *res)
+		return nil
+	})
+	if err != nil {
+		return nil, s.rollback(login, ID, created), err
+	}
+
+	return doc, created, nil
+}
+
+// rollback удаляет документы прерванной распаковки и возвращает те, что удалить не удалось
+func (s *ArchivesService) rollback(login, ID string, created []file.File) []file.File {
+	const op = "services.archives.rollback"
+
+	left := []file.File{}
+	for _, doc := range created {
+		if _, err := s.files.DeleteFile(doc.ID, login); err != nil {
+			s.logger.Error("failed to remove extracted document", "func", op, "fileID", ID, "documentID", doc.ID, "error", err)
+			left = append(left, doc)
+		}
+	}
+
+	return left
+}
+
+func (s *ArchivesService) walk(doc file.File, kind archive.Kind, fn walkFunc) error {
+	const op = "services.archives.walk"
+
+	obj, err := s.files.OpenObject(doc.ID, doc.User)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+
+	err = walk(kind, obj, int64(doc.Size), fn)
+	if errors.Is(err, ErrInvalidArchive) {
+		s.logger.Info("failed to read archive", "func", op, "fileID", doc.ID)
+	}
+
+	return err
+}
+
+// document находит архив владельца, доступный пользователю на чтение
+func (s *ArchivesService) document(login, owner, ID string) (*file.File, archive.Kind, error) {
+	const op = "services.archives.document"
+
+	if owner == "" {
+		owner = login
+	}
+
+	docs, err := s.files.GetFilesByUser(owner, contracts.FilterData{})
+	if err != nil {
+		return nil, "", err
+	}
+
+	for i := range docs {
+		if docs[i].ID != ID {
+			continue
+		}
+
+		doc := docs[i]
+		// Список из кэша приходит без владельца
+		doc.User = owner
+		if doc.User != login && !doc.Public && !slices.Contains(doc.Grant, login) {
+			s.logger.Info("access denied", "func", op, "fileID", ID, "login", login)
+			return nil, "", ErrAccessDenied
+		}
+
+		kind, ok := archive.KindOf(doc.Name)
+		if !ok || !doc.File {
+			return nil, "", ErrNotArchive
+		}
+
+		return &doc, kind, nil
+	}
+
+	return nil, "", filesrepo.ErrFileNotFound
+}
+
+func detectMime(name string) string {
+	if mimeType := mime.TypeByExtension(path.Ext(name)); mimeType != "" {
+		return mimeType
+	}
+
+	return "application/octet-stream"
+}
@@ -0,0 +1,11 @@
+package archivesservice
+
+import "errors"
+
+var (
+	ErrAccessDenied    = errors.New("access denied")
+	ErrNotArchive      = errors.New("document is not a zip or tar archive")
+	ErrInvalidArchive  = errors.New("archive is corrupted or has unsupported format")
+	ErrEntryNotFound   = errors.New("archive entry not found")
+	ErrArchiveTooLarge = errors.New("archive has too many entries or is too large to extract")
+)
@@ -0,0 +1,108 @@
+package archivesservice
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"astral/internal/domain/archive"
+	"astral/internal/domain/file"
+	"compress/gzip"
+	"errors"
+	"io"
+)
+
+// walkFunc получает запись архива и ее содержимое, которое можно читать только до возврата из функции.
+// errStop завершает обход без ошибки
+type walkFunc func(entry archive.Entry, open func() (io.ReadCloser, error)) error
+
+var errStop = errors.New("stop walking archive")
+
+// walk обходит записи архива. ZIP читается через каталог в конце файла диапазонными запросами,
+// TAR - последовательно, содержимое пропускаемых записей не загружается благодаря Seek.
+// Сжатый TAR приходится читать целиком
+func walk(kind archive.Kind, obj file.Object, size int64, fn walkFunc) error {
+	var err error
+	switch kind {
+	case archive.KindZip:
+		err = walkZip(obj, size, fn)
+	case archive.KindTar:
+		err = walkTar(obj, fn)
+	case archive.KindTarGz:
+		var gz *gzip.Reader
+		gz, err = gzip.NewReader(obj)
+		if err != nil {
+			return ErrInvalidArchive
+		}
+		defer gz.Close()
+
+		err = walkTar(gz, fn)
+	default:
+		return ErrNotArchive
+	}
+
+	if errors.Is(err, errStop) {
+		return nil
+	}
+
+	return err
+}
+
+func walkZip(obj io.ReaderAt, size int64, fn walkFunc) error {
+	r, err := zip.NewReader(obj, size)
+	if err != nil {
+		return ErrInvalidArchive
+	}
+
+	for _, f := range r.File {
+		entry := archive.Entry{
+			Name:           file.CleanPath(f.Name),
+			Size:           int64(f.UncompressedSize64),
+			CompressedSize: int64(f.CompressedSize64),
+			Modified:       f.Modified.UTC(),
+			Dir:            f.FileInfo().IsDir(),
+		}
+		if entry.Name == "" {
+			continue
+		}
+
+		if err := fn(entry, f.Open); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func walkTar(r io.Reader, fn walkFunc) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return ErrInvalidArchive
+		}
+
+		// Ссылки и специальные файлы не показываются: у них нет содержимого в архиве
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeDir {
+			continue
+		}
+
+		entry := archive.Entry{
+			Name:     file.CleanPath(header.Name),
+			Size:     header.Size,
+			Modified: header.ModTime.UTC(),
+			Dir:      header.Typeflag == tar.TypeDir,
+		}
+		if entry.Name == "" {
+			continue
+		}
+
+		open := func() (io.ReadCloser, error) {
+			return io.NopCloser(tr), nil
+		}
+		if err := fn(entry, open); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package auditservice
+
+import (
+	"astral/internal/domain/audit"
+	auditrepo "astral/internal/repository/audit"
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	DEFAULT_TIMEOUT = time.Second * 5
+	EXPORT_TIMEOUT  = time.Minute * 5
+
+	DEFAULT_LIMIT = 100
+	MAX_LIMIT     = 1000
+)
+
+type AuditService struct {
+	repo       auditrepo.AuditRepo
+	logger     *slog.Logger
+	adminToken string
+}
+
+func NewAuditService(repo auditrepo.AuditRepo, logger *slog.Logger, adminToken string) *AuditService {
+	return &AuditService{
+		repo:       repo,
+		logger:     logger,
+		adminToken: adminToken,
+	}
+}
+
+// Record сохраняет событие. Ошибка записи не должна ломать действие пользователя, поэтому только логируется
+func (s *AuditService) Record(event audit.Event) {
+	const op = "services.audit.Record"
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if err := s.repo.Append(ctx, event); err != nil {
+		s.logger.Error("failed to record audit event", "func", op, "action", event.Action, "actor", event.Actor, "target", event.TargetID, "error", err)
+	}
+}
+
+func (s *AuditService) Query(login, adminToken string, filter audit.Filter) ([]audit.Event, error) {
+	const op = "services.audit.Query"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	filter, err := s.scope(login, adminToken, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = DEFAULT_LIMIT
+	}
+	filter.Limit = min(filter.Limit, MAX_LIMIT)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.Query(ctx, filter)
+}
+
+// Export отдает все подходящие события по одному, лимит не применяется
+func (s *AuditService) Export(login, adminToken string, filter audit.Filter, fn func(audit.Event) error) error {
+	const op = "services.audit.Export"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	filter, err := s.scope(login, adminToken, filter)
+	if err != nil {
+		return err
+	}
+	filter.Limit = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), EXPORT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.Stream(ctx, filter, fn)
+}
+
+// scope ограничивает выборку: администратор видит весь журнал, пользователь - свои действия и действия с его документами
+func (s *AuditService) scope(login, adminToken string, filter audit.Filter) (audit.Filter, error) {
+	if adminToken != "" {
+		if adminToken != s.adminToken {
+			return filter, ErrAccessDenied
+		}
+
+		return filter, nil
+	}
+
+	filter.Subject = login
+	return filter, nil
+}
@@ -0,0 +1,7 @@
+package auditservice
+
+import "errors"
+
+var (
+	ErrAccessDenied = errors.New("access denied")
+)
@@ -0,0 +1,166 @@
+package authservice
+
+import (
+	"astral/internal/domain/dto"
+	"astral/internal/domain/user"
+	authrepo "astral/internal/repository/auth"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const (
+	ACCESS_KEY_ID_PREFIX = "AK"
+	ACCESS_KEY_ID_BYTES  = 10
+	SECRET_KEY_BYTES     = 30
+)
+
+// CreateAccessKey выдает пару ключей для S3-совместимого API, секрет возвращается только один раз
+func (s *AuthService) CreateAccessKey(login string) (*user.AccessKey, error) {
+	const op = "services.authorization.access_keys.CreateAccessKey"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	idBytes := make([]byte, ACCESS_KEY_ID_BYTES)
+	secretBytes := make([]byte, SECRET_KEY_BYTES)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate access key: %w", err)
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate access key: %w", err)
+	}
+
+	id := ACCESS_KEY_ID_PREFIX + base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(idBytes)
+	secret := base64.RawStdEncoding.EncodeToString(secretBytes)
+
+	// Для проверки подписи SigV4 нужен исходный секрет, поэтому храним его зашифрованным, а не хэшем
+	encrypted, err := s.encryptSecret(secret)
+	if err != nil {
+		s.logger.Error("failed to encrypt secret key", "func", op, "login", login, "error", err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	accessKey, err := s.repo.CreateAccessKey(ctx, dto.AccessKeyData{
+		ID:              id,
+		Login:           login,
+		EncryptedSecret: encrypted,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accessKey.Secret = secret
+	return accessKey, nil
+}
+
+func (s *AuthService) GetAccessKeys(login string) ([]user.AccessKey, error) {
+	const op = "services.authorization.access_keys.GetAccessKeys"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.GetAccessKeysByLogin(ctx, login)
+}
+
+func (s *AuthService) DeleteAccessKey(login, id string) (*user.AccessKey, error) {
+	const op = "services.authorization.access_keys.DeleteAccessKey"
+	s.logger.Info("Usecase start", "func", op, "login", login, "id", id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.DeleteAccessKey(ctx, login, id)
+}
+
+// GetAccessKey возвращает ключ вместе с расшифрованным секретом для проверки подписи запроса
+func (s *AuthService) GetAccessKey(id string) (*user.AccessKey, error) {
+	const op = "services.authorization.access_keys.GetAccessKey"
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	key, err := s.repo.GetAccessKeyByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, authrepo.ErrNoRows) {
+			s.logger.Warn("unknown access key", "func", op, "id", id)
+			return nil, ErrInvalidToken
+		}
+
+		return nil, err
+	}
+
+	if err := s.activeUser(ctx, key.Login); err != nil {
+		s.logger.Warn("account is not active", "func", op, "id", id, "error", err)
+		return nil, err
+	}
+
+	secret, err := s.decryptSecret(key.EncryptedSecret)
+	if err != nil {
+		s.logger.Error("failed to decrypt secret key", "func", op, "id", id, "error", err)
+		return nil, err
+	}
+
+	return &user.AccessKey{
+		ID:     key.ID,
+		Login:  key.Login,
+		Secret: secret,
+	}, nil
+}
+
+func (s *AuthService) secretCipher() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(s.secretJWT))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func (s *AuthService) encryptSecret(secret string) (string, error) {
+	gcm, err := s.secretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *AuthService) decryptSecret(encrypted string) (string, error) {
+	gcm, err := s.secretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("encrypted secret is too short")
+	}
+
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	secret, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(secret), nil
+}
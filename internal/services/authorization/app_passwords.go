@@ -0,0 +1,111 @@
+package authservice
+
+import (
+	"astral/internal/domain/dto"
+	"astral/internal/domain/user"
+	authrepo "astral/internal/repository/auth"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	APP_PASSWORD_BYTES = 20
+)
+
+// CreateAppPassword выдает пароль для сторонних клиентов (WebDAV и т.п.), открытое значение возвращается только один раз
+func (s *AuthService) CreateAppPassword(login, name string) (*user.AppPassword, error) {
+	const op = "services.authorization.app_passwords.CreateAppPassword"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	password, err := generateAppPassword()
+	if err != nil {
+		s.logger.Error("failed to generate app password", "func", op, "login", login, "error", err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	appPassword, err := s.repo.CreateAppPassword(ctx, dto.AppPasswordData{
+		Login:        login,
+		Name:         name,
+		PasswordHash: hashAppPassword(password),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	appPassword.Password = password
+	return appPassword, nil
+}
+
+func (s *AuthService) GetAppPasswords(login string) ([]user.AppPassword, error) {
+	const op = "services.authorization.app_passwords.GetAppPasswords"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.GetAppPasswordsByLogin(ctx, login)
+}
+
+func (s *AuthService) DeleteAppPassword(login, id string) (*user.AppPassword, error) {
+	const op = "services.authorization.app_passwords.DeleteAppPassword"
+	s.logger.Info("Usecase start", "func", op, "login", login, "id", id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.DeleteAppPassword(ctx, login, id)
+}
+
+func (s *AuthService) AuthorizationByAppPassword(login, password string) (*user.Token, error) {
+	const op = "services.authorization.app_passwords.AuthorizationByAppPassword"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	appPassword, err := s.repo.GetAppPasswordByHash(ctx, hashAppPassword(password))
+	if err != nil {
+		if errors.Is(err, authrepo.ErrNoRows) {
+			s.logger.Warn("unknown app password", "func", op, "login", login)
+			return nil, ErrInvalidToken
+		}
+
+		return nil, err
+	}
+
+	if appPassword.Login != login {
+		s.logger.Warn("app password does not belong to user", "func", op, "login", login)
+		return nil, ErrInvalidToken
+	}
+
+	if err := s.activeUser(ctx, login); err != nil {
+		s.logger.Warn("account is not active", "func", op, "login", login, "error", err)
+		return nil, err
+	}
+
+	return user.NewToken("", appPassword.Login), nil
+}
+
+func generateAppPassword() (string, error) {
+	buf := make([]byte, APP_PASSWORD_BYTES)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate app password: %w", err)
+	}
+
+	return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf)), nil
+}
+
+// Пароли приложений генерируются случайно с высокой энтропией, поэтому достаточно быстрого хэша без соли
+func hashAppPassword(password string) string {
+	hash := sha256.Sum256([]byte(strings.ToLower(password)))
+	return hex.EncodeToString(hash[:])
+}
@@ -3,6 +3,7 @@ package authservice
 import (
 	"astral/internal/domain/contracts"
 	"astral/internal/domain/dto"
+	"astral/internal/domain/event"
 	"astral/internal/domain/user"
 	authrepo "astral/internal/repository/auth"
 	"context"
@@ -60,7 +61,12 @@ func (s *AuthService) Registration(adminToken string, userData dto.UserData) (*u
 	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
 	defer cancel()
 
-	user, err := s.repo.CreateUser(ctx, userData)
+	registered, err := event.New(event.TypeUserRegistered, userData.Login, event.UserPayload{Login: userData.Login})
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.CreateUser(ctx, userData, registered)
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +102,12 @@ func (s *AuthService) Login(userData dto.UserData) (*user.Token, error) {
 		Login: token.Login,
 	}
 
-	token, err = s.repo.CreateToken(ctx, tokenData)
+	created, err := event.New(event.TypeSessionCreated, token.Login, event.UserPayload{Login: token.Login})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err = s.repo.CreateToken(ctx, tokenData, created)
 	if err != nil {
 		return nil, err
 	}
@@ -149,7 +160,12 @@ func (s *AuthService) CloseSession(tokenData dto.TokenData) (*user.Token, error)
 
 	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
 	defer cancel()
-	deletedToken, err := s.repo.DeleteToken(ctx, tokenData.Token)
+	closed, err := event.New(event.TypeSessionClosed, tokenData.Login, event.UserPayload{Login: tokenData.Login})
+	if err != nil {
+		return nil, err
+	}
+
+	deletedToken, err := s.repo.DeleteToken(ctx, tokenData.Token, closed)
 	if err != nil {
 		return nil, err
 	}
@@ -181,9 +197,38 @@ func (s *AuthService) validatePassword(userData dto.UserData) (bool, error) {
 		return false, nil
 	}
 
+	if err := s.checkActive(user); err != nil {
+		return false, err
+	}
+
 	return true, nil
 }
 
+// checkActive закрывает вход в учетную запись, заблокированную администратором или ожидающую
+// удаления. Во время отсрочки удаления учетную запись можно только восстановить
+func (s *AuthService) checkActive(user *user.User) error {
+	if user.DisabledAt != nil {
+		return ErrAccountDisabled
+	}
+
+	if user.DeleteAfter != nil {
+		return ErrDeletionScheduled
+	}
+
+	return nil
+}
+
+// activeUser проверяет учетную запись при входе без пароля пользователя: по паролю приложения
+// или ключу доступа
+func (s *AuthService) activeUser(ctx context.Context, login string) error {
+	user, err := s.repo.GetUserByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	return s.checkActive(user)
+}
+
 func (s *AuthService) findToken(token string, tokens []user.Token) *user.Token {	
 	for _, t := range tokens {
 		if t.Token == token {
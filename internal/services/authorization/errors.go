@@ -3,6 +3,8 @@ package authservice
 import "errors"
 
 var (
-	ErrAccessDenied = errors.New("access denied")
-	ErrInvalidToken = errors.New("invalid token")
+	ErrAccessDenied      = errors.New("access denied")
+	ErrInvalidToken      = errors.New("invalid token")
+	ErrDeletionScheduled = errors.New("account is scheduled for deletion")
+	ErrAccountDisabled   = errors.New("account is disabled")
 )
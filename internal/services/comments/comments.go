@@ -0,0 +1,382 @@
+package commentsservice
+
+import (
+	"astral/internal/domain/comment"
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/dto"
+	"astral/internal/domain/file"
+	"astral/internal/domain/webhook"
+	commentsrepo "astral/internal/repository/comments"
+	filesrepo "astral/internal/repository/files"
+	"context"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	DEFAULT_TIMEOUT = time.Second * 5
+
+	MAX_BODY_LENGTH = 10000
+)
+
+var mentionPattern = regexp.MustCompile(`@([a-zA-Z0-9]+)`)
+
+// CommentsService ведет обсуждения документов. Комментарии видят и пишут все, кому доступен документ,
+// править и удалять их может только автор
+type CommentsService struct {
+	repo          commentsrepo.CommentsRepo
+	files         contracts.FilesInterface
+	notifications contracts.NotificationsInterface
+	logger        *slog.Logger
+}
+
+func NewCommentsService(
+	repo commentsrepo.CommentsRepo,
+	files contracts.FilesInterface,
+	notifications contracts.NotificationsInterface,
+	logger *slog.Logger,
+) *CommentsService {
+	return &CommentsService{
+		repo:          repo,
+		files:         files,
+		notifications: notifications,
+		logger:        logger.With("service", "CommentsService"),
+	}
+}
+
+// GetComments возвращает ветки обсуждения документа: корневые комментарии с вложенными ответами
+func (s *CommentsService) GetComments(login, owner, documentID string) ([]comment.Comment, error) {
+	const op = "services.comments.GetComments"
+	s.logger.Info("Usecase start", "func", op, "login", login, "owner", owner, "docID", documentID)
+
+	if _, err := s.document(login, owner, documentID); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	comments, err := s.repo.GetComments(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildThreads(comments), nil
+}
+
+// CreateComment добавляет комментарий или ответ, если передан parentID. Привязка к странице
+// или диапазону байт допускается только у корневого комментария
+func (s *CommentsService) CreateComment(login, owner, documentID string, parentID *int64, body string, anchor *comment.Anchor) (*file.File, *comment.Comment, error) {
+	const op = "services.comments.CreateComment"
+	s.logger.Info("Usecase start", "func", op, "login", login, "owner", owner, "docID", documentID)
+
+	body, err := validateBody(body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, err := s.document(login, owner, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if parentID != nil {
+		if anchor != nil {
+			return nil, nil, ErrInvalidAnchor
+		}
+
+		parent, err := s.comment(documentID, *parentID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if parent.Deleted {
+			return nil, nil, commentsrepo.ErrCommentNotFound
+		}
+	}
+
+	if err := validateAnchor(anchor, *doc); err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	res, err := s.repo.CreateComment(ctx, dto.CommentData{
+		DocumentID: documentID,
+		Owner:      doc.User,
+		ParentID:   parentID,
+		Author:     login,
+		Body:       body,
+		Mentions:   mentionsOf(body, login, *doc),
+		Anchor:     anchor,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.notifyMentions(login, *doc, *res, res.Mentions)
+
+	return doc, res, nil
+}
+
+// UpdateComment меняет текст комментария. Уведомление получают только вновь упомянутые
+func (s *CommentsService) UpdateComment(login, owner, documentID string, id int64, body string) (*file.File, *comment.Comment, error) {
+	const op = "services.comments.UpdateComment"
+	s.logger.Info("Usecase start", "func", op, "login", login, "owner", owner, "docID", documentID, "id", id)
+
+	body, err := validateBody(body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, current, err := s.authoredComment(login, owner, documentID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	mentions := mentionsOf(body, login, *doc)
+	res, err := s.repo.UpdateComment(ctx, documentID, id, body, mentions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var added []string
+	for _, mention := range mentions {
+		if !slices.Contains(current.Mentions, mention) {
+			added = append(added, mention)
+		}
+	}
+	s.notifyMentions(login, *doc, *res, added)
+
+	return doc, res, nil
+}
+
+// DeleteComment удаляет комментарий автора. Ответы на него остаются в ветке
+func (s *CommentsService) DeleteComment(login, owner, documentID string, id int64) (*file.File, *comment.Comment, error) {
+	const op = "services.comments.DeleteComment"
+	s.logger.Info("Usecase start", "func", op, "login", login, "owner", owner, "docID", documentID, "id", id)
+
+	doc, current, err := s.authoredComment(login, owner, documentID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if err := s.repo.DeleteComment(ctx, documentID, id); err != nil {
+		return nil, nil, err
+	}
+
+	return doc, current, nil
+}
+
+// ResolveComment меняет состояние ветки. Решить ветку может ее автор или владелец документа
+func (s *CommentsService) ResolveComment(login, owner, documentID string, id int64, resolved bool) (*file.File, *comment.Comment, error) {
+	const op = "services.comments.ResolveComment"
+	s.logger.Info("Usecase start", "func", op, "login", login, "owner", owner, "docID", documentID, "id", id, "resolved", resolved)
+
+	doc, err := s.document(login, owner, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current, err := s.comment(documentID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if current.ParentID != nil {
+		return nil, nil, ErrNotThread
+	}
+	if current.Author != login && doc.User != login {
+		s.logger.Info("access denied", "func", op, "id", id, "login", login)
+		return nil, nil, ErrAccessDenied
+	}
+	if current.Resolved == resolved {
+		return doc, current, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	res, err := s.repo.SetResolved(ctx, documentID, id, resolved, login)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return doc, res, nil
+}
+
+// document находит документ владельца и проверяет, что он доступен пользователю
+func (s *CommentsService) document(login, owner, documentID string) (*file.File, error) {
+	const op = "services.comments.document"
+
+	if owner == "" {
+		owner = login
+	}
+
+	docs, err := s.files.GetFilesByUser(owner, contracts.FilterData{})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range docs {
+		if docs[i].ID != documentID {
+			continue
+		}
+
+		doc := docs[i]
+		// Список из кэша приходит без владельца
+		doc.User = owner
+		if !canRead(login, doc) {
+			s.logger.Info("access denied", "func", op, "docID", documentID, "login", login)
+			return nil, ErrAccessDenied
+		}
+
+		return &doc, nil
+	}
+
+	return nil, filesrepo.ErrFileNotFound
+}
+
+func (s *CommentsService) comment(documentID string, id int64) (*comment.Comment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.GetComment(ctx, documentID, id)
+}
+
+// authoredComment возвращает неудаленный комментарий, если его автор - пользователь
+func (s *CommentsService) authoredComment(login, owner, documentID string, id int64) (*file.File, *comment.Comment, error) {
+	const op = "services.comments.authoredComment"
+
+	doc, err := s.document(login, owner, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current, err := s.comment(documentID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if current.Deleted {
+		return nil, nil, commentsrepo.ErrCommentNotFound
+	}
+	if current.Author != login {
+		s.logger.Info("access denied", "func", op, "id", id, "login", login)
+		return nil, nil, ErrAccessDenied
+	}
+
+	return doc, current, nil
+}
+
+func (s *CommentsService) notifyMentions(login string, doc file.File, res comment.Comment, mentions []string) {
+	if len(mentions) == 0 {
+		return
+	}
+
+	s.notifications.Publish(webhook.EventCommentMentioned, login, doc, map[string]string{
+		"comment_id": strconv.FormatInt(res.ID, 10),
+		"mentions":   strings.Join(mentions, ","),
+	})
+}
+
+// mentionsOf собирает упомянутых в тексте пользователей. Упоминание учитывается, только если
+// пользователь - владелец документа или получил к нему доступ, автор себя не упоминает
+func mentionsOf(body, author string, doc file.File) []string {
+	mentions := []string{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		login := match[1]
+		if login == author || slices.Contains(mentions, login) {
+			continue
+		}
+		if login == doc.User || slices.Contains(doc.Grant, login) {
+			mentions = append(mentions, login)
+		}
+	}
+
+	return mentions
+}
+
+// buildThreads раскладывает комментарии по веткам. Удаленные комментарии без ответов не показываются
+func buildThreads(comments []comment.Comment) []comment.Comment {
+	children := make(map[int64][]comment.Comment)
+	var roots []comment.Comment
+	for _, c := range comments {
+		if c.ParentID == nil {
+			roots = append(roots, c)
+			continue
+		}
+
+		children[*c.ParentID] = append(children[*c.ParentID], c)
+	}
+
+	var attach func(items []comment.Comment) []comment.Comment
+	attach = func(items []comment.Comment) []comment.Comment {
+		res := []comment.Comment{}
+		for _, c := range items {
+			c.Replies = attach(children[c.ID])
+			if c.Deleted && len(c.Replies) == 0 {
+				continue
+			}
+
+			res = append(res, c)
+		}
+
+		return res
+	}
+
+	return attach(roots)
+}
+
+func validateBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || utf8.RuneCountInString(body) > MAX_BODY_LENGTH {
+		return "", ErrInvalidBody
+	}
+
+	return body, nil
+}
+
+// validateAnchor проверяет привязку: страница считается с единицы, диапазон байт [start, end)
+// должен лежать внутри файла
+func validateAnchor(anchor *comment.Anchor, doc file.File) error {
+	if anchor == nil {
+		return nil
+	}
+
+	if anchor.Page == nil && anchor.Start == nil && anchor.End == nil {
+		return ErrInvalidAnchor
+	}
+
+	if anchor.Page != nil && *anchor.Page < 1 {
+		return ErrInvalidAnchor
+	}
+
+	if (anchor.Start == nil) != (anchor.End == nil) {
+		return ErrInvalidAnchor
+	}
+
+	if anchor.Start != nil {
+		if *anchor.Start < 0 || *anchor.Start >= *anchor.End {
+			return ErrInvalidAnchor
+		}
+		if doc.File && *anchor.End > int64(doc.Size) {
+			return ErrInvalidAnchor
+		}
+	}
+
+	return nil
+}
+
+func canRead(login string, doc file.File) bool {
+	return doc.User == login || doc.Public || slices.Contains(doc.Grant, login)
+}
@@ -0,0 +1,10 @@
+package commentsservice
+
+import "errors"
+
+var (
+	ErrAccessDenied  = errors.New("access denied")
+	ErrInvalidBody   = errors.New("comment body is empty or too long")
+	ErrInvalidAnchor = errors.New("invalid comment anchor")
+	ErrNotThread     = errors.New("only a top-level comment can be resolved")
+)
@@ -0,0 +1,77 @@
+package fileservice
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// CACHE_MAX_SIZE - документы больше этого размера в Redis не кэшируются, их содержимое только проходит насквозь
+const CACHE_MAX_SIZE = 16 << 20
+
+// cacheTee копирует прочитанное содержимое в буфер, пока оно помещается в CACHE_MAX_SIZE.
+// Читатель документа не ждет кэша: содержимое попадает в Redis, когда прочитано целиком
+type cacheTee struct {
+	reader   io.Reader
+	buf      bytes.Buffer
+	overflow bool
+	eof      bool
+}
+
+func newCacheTee(reader io.Reader) *cacheTee {
+	return &cacheTee{reader: reader}
+}
+
+func (t *cacheTee) Read(p []byte) (int, error) {
+	n, err := t.reader.Read(p)
+	if n > 0 && !t.overflow {
+		if t.buf.Len()+n > CACHE_MAX_SIZE {
+			t.overflow = true
+			t.buf = bytes.Buffer{}
+		} else {
+			t.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF {
+		t.eof = true
+	}
+
+	return n, err
+}
+
+// content возвращает содержимое размера size, если оно прочитано целиком и поместилось в буфер
+func (t *cacheTee) content(size int) ([]byte, bool) {
+	if t.overflow || (!t.eof && t.buf.Len() != size) {
+		return nil, false
+	}
+
+	return t.buf.Bytes(), true
+}
+
+// cachingReader отдает содержимое документа потоком. Контекст чтения из хранилища живет до Close,
+// прочитанное до конца содержимое сохраняется в кэш один раз
+type cachingReader struct {
+	tee    *cacheTee
+	source io.ReadCloser
+	cancel context.CancelFunc
+	size   int
+	store  func(data []byte)
+	once   sync.Once
+}
+
+func (r *cachingReader) Read(p []byte) (int, error) {
+	n, err := r.tee.Read(p)
+	if err == io.EOF {
+		if data, ok := r.tee.content(r.size); ok {
+			r.once.Do(func() { r.store(data) })
+		}
+	}
+
+	return n, err
+}
+
+func (r *cachingReader) Close() error {
+	defer r.cancel()
+	return r.source.Close()
+}
@@ -0,0 +1,121 @@
+package fileservice
+
+import (
+	"astral/internal/domain/event"
+	"astral/internal/domain/file"
+	filesrepo "astral/internal/repository/files"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+)
+
+// checksum считает SHA-256 содержимого документа и до записи в хранилище сверяет его с контрольными
+// суммами клиента. Содержимое читается дважды: при подсчете и при загрузке, поэтому reader без
+// Seek читается в память. Сохраняется только посчитанная здесь сумма, пришедшая с документом отбрасывается
+func (s *FilesService) checksum(fileData *file.File) error {
+	const op = "service.files.checksum"
+
+	fileData.Checksum = ""
+	if fileData.Reader == nil {
+		return nil
+	}
+
+	sha := sha256.New()
+	writers := []io.Writer{sha}
+	var md hash.Hash
+	if fileData.Digest != nil && len(fileData.Digest.MD5) != 0 {
+		md = md5.New()
+		writers = append(writers, md)
+	}
+	w := io.MultiWriter(writers...)
+
+	if seeker, ok := fileData.Reader.(io.ReadSeeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			_, err = io.Copy(w, seeker)
+		}
+		if err == nil {
+			_, err = seeker.Seek(start, io.SeekStart)
+		}
+		if err != nil {
+			s.logger.Error("failed to read file", "func", op, "filename", fileData.Name, "error", err)
+			return errors.New("failed to read file")
+		}
+	} else {
+		data, err := io.ReadAll(io.LimitReader(fileData.Reader, filesrepo.MAX_FILE_SIZE+1))
+		if err != nil {
+			s.logger.Error("failed to read file", "func", op, "filename", fileData.Name, "error", err)
+			return errors.New("failed to read file")
+		}
+		w.Write(data)
+		fileData.Reader = bytes.NewReader(data)
+	}
+
+	sum := sha.Sum(nil)
+	if digest := fileData.Digest; digest != nil {
+		if len(digest.SHA256) != 0 && !bytes.Equal(digest.SHA256, sum) ||
+			md != nil && !bytes.Equal(digest.MD5, md.Sum(nil)) {
+			s.logger.Info("checksum mismatch", "func", op, "filename", fileData.Name, "userID", fileData.User)
+			return ErrChecksumMismatch
+		}
+	}
+	fileData.Checksum = hex.EncodeToString(sum)
+
+	return nil
+}
+
+// SetStatus меняет состояние документа владельца owner без проверки доступа. Перевод в error
+// пишет событие document.corrupted
+func (s *FilesService) SetStatus(ID, owner string, status file.Status) (*file.File, error) {
+	const op = "service.files.SetStatus"
+	s.logger.Info("Usecase start", "func", op, "fileID", ID, "owner", owner, "status", status)
+
+	// Событие о порче резервируется до смены состояния, иначе его можно потерять
+	var staged *event.Event
+	if status == file.StatusError {
+		ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+		defer cancel()
+
+		current, err := s.repo.GetFileInfo(ctx, owner, ID)
+		if err != nil {
+			return nil, err
+		}
+		current.Status = status
+
+		staged, err = s.stageEvent(event.TypeDocumentCorrupted, *current)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if err := s.repo.SetStatus(ctx, owner, ID, status); err != nil {
+		if staged != nil {
+			s.discardEvent(staged)
+		}
+		return nil, err
+	}
+	s.invalidate(ID, owner)
+
+	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	fileInfo, err := s.repo.GetFileInfo(ctx, owner, ID)
+	if err != nil {
+		// Состояние уже сменилось, событие уйдет с данными, записанными при резервировании
+		return nil, err
+	}
+
+	if staged != nil {
+		s.confirmEvent(staged, *fileInfo)
+	}
+
+	return fileInfo, nil
+}
@@ -0,0 +1,120 @@
+package fileservice
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	// FILTER_DATA - предикат JSONPath по данным документа, например invoice.total > 1000
+	FILTER_DATA = "data"
+	// FILTER_DATA_CONTAINS - JSON, который должен входить в данные документа, например {"status": "paid"}
+	FILTER_DATA_CONTAINS = "data_contains"
+)
+
+// saveData сохраняет JSON данные загруженного документа. Если сохранить не удалось,
+// документ удаляется, чтобы не остался без своих данных
+func (s *FilesService) saveData(doc file.File, data json.RawMessage) error {
+	const op = "service.files.saveData"
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	err := s.data.Save(ctx, doc.ID, doc.User, data)
+	if err == nil {
+		return nil
+	}
+
+	if delErr := s.repo.DeleteFile(ctx, doc.ID, doc.User); delErr != nil {
+		s.logger.Error("failed to delete document without data", "func", op, "fileID", doc.ID, "error", delErr)
+	}
+
+	return err
+}
+
+func (s *FilesService) attachData(files []file.File) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(files))
+	for _, f := range files {
+		ids = append(ids, f.ID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	data, err := s.data.GetMany(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range files {
+		record, ok := data[files[i].ID]
+		if !ok {
+			continue
+		}
+		files[i].Data = record.Data
+		files[i].Revision = record.Revision
+	}
+
+	return nil
+}
+
+// filterByData выполняет запрос по данным в PostgreSQL и оставляет найденные документы
+func (s *FilesService) filterByData(userID string, files []file.File, filter contracts.FilterData) ([]file.File, error) {
+	if filter.Value == "" {
+		return files, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	var ids []string
+	var err error
+	if filter.Key == FILTER_DATA_CONTAINS {
+		ids, err = s.data.MatchContains(ctx, userID, json.RawMessage(filter.Value))
+	} else {
+		ids, err = s.data.MatchPath(ctx, userID, jsonPath(filter.Value))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		matched[id] = true
+	}
+
+	var filtered []file.File
+	for _, f := range files {
+		if matched[f.ID] {
+			filtered = append(filtered, f)
+		}
+	}
+
+	return filtered, nil
+}
+
+// jsonPath приводит выражение к JSONPath: корень можно писать как $, как data или опускать вовсе,
+// поэтому "data.invoice.total > 1000" и "invoice.total > 1000" означают "$.invoice.total > 1000"
+func jsonPath(expr string) string {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "$") {
+		return expr
+	}
+
+	if rest, ok := strings.CutPrefix(expr, FILTER_DATA); ok && (rest == "" || strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "[") || strings.HasPrefix(rest, " ")) {
+		return "$" + rest
+	}
+
+	return "$." + expr
+}
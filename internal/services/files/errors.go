@@ -3,5 +3,12 @@ package fileservice
 import "errors"
 
 var (
-	ErrAccessDenied = errors.New("access denied")
-)
\ No newline at end of file
+	ErrAccessDenied     = errors.New("access denied")
+	ErrDocumentLocked   = errors.New("document is locked by another user")
+	ErrChecksumMismatch = errors.New("content does not match the checksum")
+	ErrUserNotFound     = errors.New("target user not found")
+	ErrSameOwner        = errors.New("document already belongs to this user")
+	ErrNotPending       = errors.New("document is not waiting for upload")
+	ErrNotActive        = errors.New("document content is not available")
+	ErrSizeMismatch     = errors.New("content size does not match the declared size")
+)
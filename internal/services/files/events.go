@@ -0,0 +1,84 @@
+package fileservice
+
+import (
+	"astral/internal/domain/event"
+	"astral/internal/domain/file"
+	"context"
+	"errors"
+	"time"
+)
+
+// EVENT_HOLD - сколько зарезервированное событие ждет подтверждения. Срок больше любого изменения
+// в хранилище: если сервис упал между записью в MinIO и подтверждением, relay опубликует событие
+// по его истечении. Доставка остается не реже одного раза, ценой возможного события об изменении,
+// до которого дело не дошло
+const EVENT_HOLD = time.Minute * 15
+
+// stageEvent резервирует событие документа в outbox до изменения в хранилище. Документы хранятся
+// в MinIO, общей транзакции с PostgreSQL у них нет, поэтому без записи события изменение не начинается
+func (s *FilesService) stageEvent(eventType event.Type, doc file.File) (*event.Event, error) {
+	const op = "service.files.stageEvent"
+
+	e, err := event.New(eventType, doc.User, documentPayload(doc))
+	if err != nil {
+		s.logger.Error("failed to build document event", "func", op, "type", eventType, "fileID", doc.ID, "error", err)
+		return nil, errors.New("failed to record document event")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if err := s.outbox.Stage(ctx, EVENT_HOLD, e); err != nil {
+		s.logger.Error("failed to record document event", "func", op, "type", eventType, "fileID", doc.ID, "error", err)
+		return nil, errors.New("failed to record document event")
+	}
+
+	return &e, nil
+}
+
+// confirmEvent после изменения в хранилище заменяет данные события итоговым документом и отдает
+// его relay. Если подтвердить не удалось, событие с исходными данными уйдет по истечении EVENT_HOLD
+func (s *FilesService) confirmEvent(e *event.Event, doc file.File) {
+	const op = "service.files.confirmEvent"
+
+	confirmed, err := event.New(e.Type, doc.User, documentPayload(doc))
+	if err == nil {
+		confirmed.ID = e.ID
+		confirmed.CreatedAt = e.CreatedAt
+		e = &confirmed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if err := s.outbox.Confirm(ctx, *e); err != nil {
+		s.logger.Error("failed to confirm document event, it is published after hold", "func", op, "type", e.Type, "fileID", doc.ID, "eventID", e.ID, "error", err)
+	}
+}
+
+// discardEvent удаляет событие изменения, которое не состоялось. Если удалить не удалось, событие
+// уйдет по истечении EVENT_HOLD: лишнее событие лучше потерянного
+func (s *FilesService) discardEvent(e *event.Event) {
+	const op = "service.files.discardEvent"
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if err := s.outbox.Discard(ctx, e.ID); err != nil {
+		s.logger.Error("failed to discard document event", "func", op, "type", e.Type, "eventID", e.ID, "error", err)
+	}
+}
+
+func documentPayload(doc file.File) event.DocumentPayload {
+	return event.DocumentPayload{
+		ID:       doc.ID,
+		Owner:    doc.User,
+		Name:     doc.Name,
+		File:     doc.File,
+		Public:   doc.Public,
+		Mime:     doc.Mime,
+		Grant:    doc.Grant,
+		Size:     doc.Size,
+		Metadata: doc.Metadata,
+	}
+}
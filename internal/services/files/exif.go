@@ -0,0 +1,381 @@
+package fileservice
+
+import (
+	"astral/internal/domain/file"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// Заголовки сегментов APP1 с EXIF и XMP
+	EXIF_HEADER = "Exif\x00\x00"
+	XMP_HEADER  = "http://ns.adobe.com/xap/1.0/\x00"
+
+	// MAX_IFD_ENTRIES - защита от поврежденных файлов с огромным числом записей в каталоге
+	MAX_IFD_ENTRIES = 1024
+)
+
+// Теги EXIF, которые нужны для метаданных и удаления координат
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagDateTimeOriginal = 0x9003
+	tagOffsetOriginal   = 0x9011
+	tagGPSLatitudeRef   = 0x0001
+	tagGPSLatitude      = 0x0002
+	tagGPSLongitudeRef  = 0x0003
+	tagGPSLongitude     = 0x0004
+)
+
+// Размеры значений TIFF по типам: BYTE, ASCII, SHORT, LONG, RATIONAL, SBYTE, UNDEFINED, SSHORT,
+// SLONG, SRATIONAL, FLOAT, DOUBLE
+var tiffTypeSizes = map[uint16]uint32{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+// jpegSegment - сегмент маркера JPEG: start указывает на 0xFF маркера, data - полезные данные
+type jpegSegment struct {
+	marker byte
+	start  int
+	end    int
+	data   []byte
+}
+
+// jpegSegments перечисляет сегменты до начала сжатых данных (SOS)
+func jpegSegments(data []byte) []jpegSegment {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil
+	}
+
+	var segments []jpegSegment
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return segments
+		}
+		marker := data[pos+1]
+		if marker == 0xFF {
+			// Заполняющий байт перед маркером
+			pos++
+			continue
+		}
+		if marker == 0xD9 || marker == 0xDA {
+			return segments
+		}
+		if marker == 0x01 || marker >= 0xD0 && marker <= 0xD7 {
+			pos += 2
+			continue
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return segments
+		}
+		segments = append(segments, jpegSegment{
+			marker: marker,
+			start:  pos,
+			end:    pos + 2 + length,
+			data:   data[pos+4 : pos+2+length],
+		})
+		pos += 2 + length
+	}
+
+	return segments
+}
+
+// exifTIFF возвращает TIFF-блок EXIF из сегментов JPEG
+func exifTIFF(segments []jpegSegment) []byte {
+	for _, segment := range segments {
+		if segment.marker == 0xE1 && bytes.HasPrefix(segment.data, []byte(EXIF_HEADER)) {
+			return segment.data[len(EXIF_HEADER):]
+		}
+	}
+	return nil
+}
+
+type tiffEntry struct {
+	tag    uint16
+	typ    uint16
+	count  uint32
+	offset uint32
+	value  []byte
+}
+
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+func newTIFFReader(data []byte) (*tiffReader, uint32, bool) {
+	if len(data) < 8 {
+		return nil, 0, false
+	}
+
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, 0, false
+	}
+	if order.Uint16(data[2:]) != 42 {
+		return nil, 0, false
+	}
+
+	return &tiffReader{data: data, order: order}, order.Uint32(data[4:]), true
+}
+
+// ifd читает записи каталога по смещению offset. Значения до 4 байт хранятся в самой записи,
+// длиннее - по смещению от начала TIFF
+func (t *tiffReader) ifd(offset uint32) []tiffEntry {
+	if uint64(offset)+2 > uint64(len(t.data)) {
+		return nil
+	}
+
+	count := int(t.order.Uint16(t.data[offset:]))
+	if count > MAX_IFD_ENTRIES || uint64(offset)+2+uint64(count)*12 > uint64(len(t.data)) {
+		return nil
+	}
+
+	entries := make([]tiffEntry, 0, count)
+	for i := 0; i < count; i++ {
+		raw := t.data[int(offset)+2+i*12:]
+		entry := tiffEntry{
+			tag:   t.order.Uint16(raw),
+			typ:   t.order.Uint16(raw[2:]),
+			count: t.order.Uint32(raw[4:]),
+		}
+
+		size := uint64(tiffTypeSizes[entry.typ]) * uint64(entry.count)
+		if size <= 4 {
+			entry.value = raw[8 : 8+size]
+		} else {
+			entry.offset = t.order.Uint32(raw[8:])
+			if uint64(entry.offset)+size > uint64(len(t.data)) {
+				continue
+			}
+			entry.value = t.data[entry.offset : uint64(entry.offset)+size]
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func (t *tiffReader) ascii(entry tiffEntry) string {
+	return strings.TrimSpace(strings.TrimRight(string(entry.value), "\x00"))
+}
+
+func (t *tiffReader) uint(entry tiffEntry) (uint32, bool) {
+	switch {
+	case entry.typ == 3 && len(entry.value) >= 2:
+		return uint32(t.order.Uint16(entry.value)), true
+	case entry.typ == 4 && len(entry.value) >= 4:
+		return t.order.Uint32(entry.value), true
+	}
+	return 0, false
+}
+
+func (t *tiffReader) rationals(entry tiffEntry) []float64 {
+	if entry.typ != 5 {
+		return nil
+	}
+
+	values := make([]float64, 0, entry.count)
+	for i := 0; i+8 <= len(entry.value); i += 8 {
+		num := t.order.Uint32(entry.value[i:])
+		den := t.order.Uint32(entry.value[i+4:])
+		if den == 0 {
+			return nil
+		}
+		values = append(values, float64(num)/float64(den))
+	}
+
+	return values
+}
+
+// exifMetadata извлекает из EXIF камеру, время съемки и координаты
+func exifMetadata(tiffData []byte, metadata map[string]string) {
+	t, offset, ok := newTIFFReader(tiffData)
+	if !ok {
+		return
+	}
+
+	var exifOffset, gpsOffset uint32
+	var dateTime string
+	for _, entry := range t.ifd(offset) {
+		switch entry.tag {
+		case tagMake:
+			setMetadata(metadata, file.INTRINSIC_CAMERA_MAKE, t.ascii(entry))
+		case tagModel:
+			setMetadata(metadata, file.INTRINSIC_CAMERA_MODEL, t.ascii(entry))
+		case tagDateTime:
+			dateTime = t.ascii(entry)
+		case tagExifIFD:
+			exifOffset, _ = t.uint(entry)
+		case tagGPSIFD:
+			gpsOffset, _ = t.uint(entry)
+		}
+	}
+
+	var offsetTime string
+	if exifOffset != 0 {
+		for _, entry := range t.ifd(exifOffset) {
+			switch entry.tag {
+			case tagDateTimeOriginal:
+				dateTime = t.ascii(entry)
+			case tagOffsetOriginal:
+				offsetTime = t.ascii(entry)
+			}
+		}
+	}
+	setMetadata(metadata, file.INTRINSIC_TAKEN_AT, exifTime(dateTime, offsetTime))
+
+	if gpsOffset != 0 {
+		var latRef, lonRef string
+		var lat, lon []float64
+		for _, entry := range t.ifd(gpsOffset) {
+			switch entry.tag {
+			case tagGPSLatitudeRef:
+				latRef = t.ascii(entry)
+			case tagGPSLatitude:
+				lat = t.rationals(entry)
+			case tagGPSLongitudeRef:
+				lonRef = t.ascii(entry)
+			case tagGPSLongitude:
+				lon = t.rationals(entry)
+			}
+		}
+		if latitude, ok := gpsCoordinate(lat, latRef, "S"); ok {
+			if longitude, ok := gpsCoordinate(lon, lonRef, "W"); ok {
+				metadata[file.INTRINSIC_GPS_LATITUDE] = latitude
+				metadata[file.INTRINSIC_GPS_LONGITUDE] = longitude
+			}
+		}
+	}
+}
+
+// exifTime переводит время EXIF "2006:01:02 15:04:05" в RFC 3339. Без смещения, которое пишут
+// не все камеры, время остается местным временем съемки
+func exifTime(value, offset string) string {
+	taken, err := time.Parse("2006:01:02 15:04:05", value)
+	if err != nil {
+		return ""
+	}
+
+	if zone, err := time.Parse("-07:00", offset); err == nil {
+		_, seconds := zone.Zone()
+		return time.Date(taken.Year(), taken.Month(), taken.Day(), taken.Hour(), taken.Minute(), taken.Second(), 0, time.FixedZone("", seconds)).Format(time.RFC3339)
+	}
+
+	return taken.Format("2006-01-02T15:04:05")
+}
+
+// gpsCoordinate собирает градусы, минуты и секунды в десятичные градусы, южная широта и западная
+// долгота отрицательны
+func gpsCoordinate(parts []float64, ref, negative string) (string, bool) {
+	if len(parts) != 3 {
+		return "", false
+	}
+
+	value := parts[0] + parts[1]/60 + parts[2]/3600
+	if strings.EqualFold(ref, negative) {
+		value = -value
+	}
+
+	return fmt.Sprintf("%.6f", value), true
+}
+
+// stripGPS удаляет из JPEG координаты: каталог GPS в EXIF затирается нулями и убирается ссылка
+// на него, сегменты XMP с координатами выбрасываются. Остальные смещения EXIF не меняются
+func stripGPS(data []byte) ([]byte, bool) {
+	segments := jpegSegments(data)
+	if segments == nil {
+		return data, false
+	}
+
+	result := make([]byte, 0, len(data))
+	result = append(result, data[:2]...)
+	stripped := false
+	last := 2
+
+	for _, segment := range segments {
+		result = append(result, data[last:segment.start]...)
+		last = segment.end
+
+		if segment.marker == 0xE1 && bytes.HasPrefix(segment.data, []byte(XMP_HEADER)) && bytes.Contains(segment.data, []byte("GPS")) {
+			stripped = true
+			continue
+		}
+
+		raw := append([]byte(nil), data[segment.start:segment.end]...)
+		if segment.marker == 0xE1 && bytes.HasPrefix(segment.data, []byte(EXIF_HEADER)) {
+			if removeGPSIFD(raw[4+len(EXIF_HEADER):]) {
+				stripped = true
+			}
+		}
+		result = append(result, raw...)
+	}
+	result = append(result, data[last:]...)
+
+	if !stripped {
+		return data, false
+	}
+	return result, true
+}
+
+// removeGPSIFD правит TIFF-блок на месте: затирает каталог GPS с его значениями и удаляет запись
+// GPSInfo из IFD0, сдвигая следующие записи
+func removeGPSIFD(tiffData []byte) bool {
+	t, offset, ok := newTIFFReader(tiffData)
+	if !ok {
+		return false
+	}
+
+	entries := t.ifd(offset)
+	if uint64(offset)+2+uint64(len(entries))*12+4 > uint64(len(tiffData)) {
+		return false
+	}
+	count := int(t.order.Uint16(tiffData[offset:]))
+	if count != len(entries) {
+		return false
+	}
+
+	index := -1
+	var gpsOffset uint32
+	for i, entry := range entries {
+		if entry.tag == tagGPSIFD {
+			index = i
+			gpsOffset, _ = t.uint(entry)
+		}
+	}
+	if index < 0 {
+		return false
+	}
+
+	if gpsOffset != 0 {
+		gpsEntries := t.ifd(gpsOffset)
+		for _, entry := range gpsEntries {
+			if entry.offset != 0 {
+				clear(tiffData[entry.offset : int(entry.offset)+len(entry.value)])
+			}
+		}
+		if size := 2 + len(gpsEntries)*12 + 4; int(gpsOffset)+size <= len(tiffData) {
+			clear(tiffData[gpsOffset : int(gpsOffset)+size])
+		}
+	}
+
+	start := int(offset) + 2
+	end := start + count*12 + 4
+	copy(tiffData[start+index*12:end-12], tiffData[start+(index+1)*12:end])
+	clear(tiffData[end-12 : end])
+	t.order.PutUint16(tiffData[offset:], uint16(count-1))
+
+	return true
+}
@@ -1,20 +1,35 @@
 package fileservice
 
 import (
+	"astral/env"
 	"astral/internal/domain/contracts"
+	"astral/internal/domain/event"
 	"astral/internal/domain/file"
 	"astral/internal/repository/db/redis"
+	docdatarepo "astral/internal/repository/docdata"
 	filesrepo "astral/internal/repository/files"
+	imagesrepo "astral/internal/repository/images"
+	locksrepo "astral/internal/repository/locks"
+	outboxrepo "astral/internal/repository/outbox"
+	transferrepo "astral/internal/repository/transfer"
+	uploadsrepo "astral/internal/repository/uploads"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+// metadataRangePattern - диапазон N-M для числовых метаданных, границы могут быть отрицательными
+var metadataRangePattern = regexp.MustCompile(`^(-?\d+(?:\.\d+)?)-(-?\d+(?:\.\d+)?)$`)
+
 const (
 	FILE_LOAD_TIMEOUT = time.Second*60
 	DEFAULT_TIMEOUT   = time.Second*5
@@ -23,13 +38,27 @@ const (
 type FilesService struct {
 	repo 	filesrepo.StorageRepo
 	cash    redis.CashStorage
+	data    docdatarepo.DataRepo
+	outbox  outboxrepo.OutboxRepo
+	locks   locksrepo.LocksRepo
+	transfer transferrepo.TransferRepo
+	uploads  uploadsrepo.UploadsRepo
+	variants imagesrepo.ImagesRepo
+	metadata env.Metadata
 	logger 	*slog.Logger
 }
 
-func NewFileService(repo filesrepo.StorageRepo, cash redis.CashStorage, logger *slog.Logger) *FilesService {
+func NewFileService(repo filesrepo.StorageRepo, cash redis.CashStorage, data docdatarepo.DataRepo, outbox outboxrepo.OutboxRepo, locks locksrepo.LocksRepo, transfer transferrepo.TransferRepo, uploads uploadsrepo.UploadsRepo, variants imagesrepo.ImagesRepo, metadata env.Metadata, logger *slog.Logger) *FilesService {
 	return &FilesService{
 		repo: 	repo,
 		cash: 	cash,
+		data:   data,
+		outbox: outbox,
+		locks:  locks,
+		transfer: transfer,
+		uploads:  uploads,
+		variants: variants,
+		metadata: metadata,
 		logger: logger,
 	}
 }
@@ -38,24 +67,46 @@ func (s *FilesService) UploadFiles(fileData file.File) (*file.File, error) {
 	const op = "service.files.UploadFiles"
 	s.logger.Info("Usecase start", "func", op, "filename", fileData.Name, "userID", fileData.User)
 
-	ctx, cancel := context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
-	defer cancel()
-	out := make(chan error)
-	go s.cash.CashedFile(ctx, generateKeyForCash("filename:", fileData.Name), fileData, out)
+	if err := s.checksum(&fileData); err != nil {
+		return nil, err
+	}
+	s.intrinsicMetadata(&fileData)
+
+	// ID выдается заранее, чтобы зарезервированное событие указывало на документ
+	if fileData.ID == "" {
+		fileData.ID = uuid.New().String()
+	}
+	staged, err := s.stageEvent(event.TypeDocumentUploaded, fileData)
+	if err != nil {
+		return nil, err
+	}
+
+	tee := newCacheTee(fileData.Reader)
+	fileData.Reader = tee
 
-	ctx, cancel = context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
+	ctx, cancel := context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
 	defer cancel()
 
 	res, err := s.repo.CreateFile(ctx, fileData.User, fileData)
 	if err != nil {
+		s.discardEvent(staged)
+		return nil, err
+	}
+	if data, ok := tee.content(fileData.Size); ok {
+		s.cacheFile(generateKeyForCash("filename:", fileData.Name), fileData, data)
+	}
+
+	if err := s.saveData(*res, fileData.Data); err != nil {
+		s.discardEvent(staged)
 		return nil, err
 	}
+	res.Data = fileData.Data
 
 	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
 	defer cancel()
-	s.cash.DelKeyByPrefix(ctx, generateKeyForCash("list:" + fileData.User, ""))
+	s.cash.DelKeyByPrefix(ctx, listKeyPrefix(fileData.User))
 
-	<-out
+	s.confirmEvent(staged, *res)
 
 	return res, nil
 }
@@ -86,12 +137,25 @@ func (s *FilesService) GetFilesByUser(userID string, filter contracts.FilterData
 		return nil, err
 	}
 
-	filteredFiles := s.applyFilters(files, filter)
+	var filteredFiles []file.File
+	switch filter.Key {
+	case FILTER_DATA, FILTER_DATA_CONTAINS:
+		filteredFiles, err = s.filterByData(userID, files, filter)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		filteredFiles = s.applyFilters(files, filter)
+	}
 
     if filter.Limit > 0 && len(filteredFiles) > filter.Limit {
         filteredFiles = filteredFiles[:filter.Limit]
     }
 
+	if err := s.attachData(filteredFiles); err != nil {
+		return nil, err
+	}
+
 	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
 	defer cancel()
 	s.cash.NewKey(ctx, generateKeyForCash("list:" + userID, filter), filteredFiles)
@@ -106,8 +170,13 @@ func (s *FilesService) GetFileByID(ID, userID string) (*file.File, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
 	defer cancel()
 
+	// Ключ кэша не привязан к пользователю, поэтому доступ проверяется и для документа из кэша
 	fileData := s.cash.GetCashedFile(ctx, generateKeyForCash("file:", ID))
 	if fileData != nil {
+		if !canRead(userID, *fileData) {
+			s.logger.Info("access denied", "func", op, "fileID", ID, "userID", userID)
+			return nil, ErrAccessDenied
+		}
 		return fileData, nil
 	}
 
@@ -119,24 +188,98 @@ func (s *FilesService) GetFileByID(ID, userID string) (*file.File, error) {
 		return nil, err
 	}
 
-	if fileData.Metadata["public"] == "false" && fileData.User != userID && slices.Contains[[]string, string](fileData.Grant, userID) {
+	if !canRead(userID, *fileData) {
 		s.logger.Info("access denied", "func", op, "fileID", ID, "userID", userID)
 		return nil, ErrAccessDenied
 	}
 
-	ctx, cancel = context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
+	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
 	defer cancel()
 
-	reader, err := s.repo.GetFileByID(ctx, userID, ID)
+	record, err := s.data.Get(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil {
+		fileData.Data = record.Data
+		fileData.Revision = record.Revision
+	}
+
+	// Контекст живет вместе с содержимым: документ читается потоком уже после возврата
+	loadCtx, loadCancel := context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
+
+	reader, err := s.repo.GetFileByID(loadCtx, userID, ID)
 	if err != nil {
+		loadCancel()
 		return nil, err
 	}
-	fileData.Reader = reader
 
-	ctx, cancel = context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
+	cached := *fileData
+	fileData.Reader = &cachingReader{
+		tee:    newCacheTee(reader),
+		source: reader,
+		cancel: loadCancel,
+		size:   fileData.Size,
+		store: func(data []byte) {
+			s.cacheFile(generateKeyForCash("file:", ID), cached, data)
+		},
+	}
+
+	return fileData, nil
+}
+
+// cacheFile сохраняет документ с содержимым data в кэш в фоне, не задерживая ответ
+func (s *FilesService) cacheFile(key string, fileData file.File, data []byte) {
+	fileData.Reader = bytes.NewReader(data)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
+		defer cancel()
+		s.cash.CashedFile(ctx, key, fileData, make(chan error, 1))
+	}()
+}
+
+// dropVariants удаляет в фоне варианты изображения: после удаления или замены документа из них
+// нельзя получить прежнее содержимое
+func (s *FilesService) dropVariants(owner, ID string) {
+	const op = "service.files.dropVariants"
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
+		defer cancel()
+		if err := s.variants.RemoveVariants(ctx, imagesrepo.DocumentPrefix(owner, ID)); err != nil {
+			s.logger.Warn("failed to remove image variants", "func", op, "fileID", ID, "owner", owner, "error", err)
+		}
+	}()
+}
+
+// GetFileInfo - метаданные документа без содержимого, мимо кэша: по ним считаются валидаторы условных запросов
+func (s *FilesService) GetFileInfo(ID, userID string) (*file.File, error) {
+	const op = "service.files.GetFileInfo"
+	s.logger.Info("Usecase start", "func", op, "fileID", ID, "userID", userID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
 	defer cancel()
-	out := make(chan error)
-	go s.cash.CashedFile(ctx, generateKeyForCash("file:", ID), *fileData, out)
+
+	fileData, err := s.repo.GetFileInfo(ctx, userID, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canRead(userID, *fileData) {
+		s.logger.Info("access denied", "func", op, "fileID", ID, "userID", userID)
+		return nil, ErrAccessDenied
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	record, err := s.data.Get(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil {
+		fileData.Revision = record.Revision
+	}
 
 	return fileData, nil
 }
@@ -158,13 +301,38 @@ func (s *FilesService) DeleteFile(ID, userID string) (*file.File, error) {
 		return nil, ErrAccessDenied
 	}
 
+	if err := s.CheckLock(ID, userID); err != nil {
+		return nil, err
+	}
+
+	staged, err := s.stageEvent(event.TypeDocumentDeleted, *fileInfo)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
 	defer cancel()
 
 	err = s.repo.DeleteFile(ctx, ID, userID)
 	if err != nil {
+		s.discardEvent(staged)
 		return nil, err
 	}
+	s.dropLock(ID)
+
+	// Документ уже удален из хранилища, оставшиеся данные не видны и не мешают
+	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+	if err := s.data.Delete(ctx, ID); err != nil {
+		s.logger.Warn("failed to delete document data", "func", op, "fileID", ID, "error", err)
+	}
+	if fileInfo.Status == file.StatusPending {
+		ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+		defer cancel()
+		if err := s.uploads.Delete(ctx, ID); err != nil {
+			s.logger.Warn("failed to delete pending upload", "func", op, "fileID", ID, "error", err)
+		}
+	}
 
 	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
 	defer cancel()
@@ -172,7 +340,10 @@ func (s *FilesService) DeleteFile(ID, userID string) (*file.File, error) {
 
 	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
 	defer cancel()
-	s.cash.DelKeyByPrefix(ctx, generateKeyForCash("list:" + userID, ""))
+	s.cash.DelKeyByPrefix(ctx, listKeyPrefix(userID))
+
+	s.dropVariants(userID, ID)
+	s.confirmEvent(staged, *fileInfo)
 
 	return fileInfo, nil
 }
@@ -232,6 +403,9 @@ func (s *FilesService) matchesFilter(file file.File, filter contracts.FilterData
         return s.filterByGrant(file, filter.Value)
     
     default:
+        if key, ok := strings.CutPrefix(filter.Key, "metadata."); ok {
+            return s.filterByMetadataKey(file, key, filter.Value)
+        }
         return false
     }
 }
@@ -301,6 +475,40 @@ func (s *FilesService) filterByMetadata(file file.File, value string) bool {
     return false
 }
 
+// filterByMetadataKey сравнивает значение одного ключа метаданных. Для числовых значений работают
+// формы >N, <N и N-M, как у size, остальные сравниваются по вхождению без учета регистра
+func (s *FilesService) filterByMetadataKey(file file.File, key, value string) bool {
+    current, ok := file.Metadata[strings.ToLower(key)]
+    if !ok {
+        return false
+    }
+
+    number, err := strconv.ParseFloat(current, 64)
+    if err == nil {
+        if bound, ok := strings.CutPrefix(value, ">"); ok {
+            limit, err := strconv.ParseFloat(bound, 64)
+            return err == nil && number > limit
+        }
+
+        if bound, ok := strings.CutPrefix(value, "<"); ok {
+            limit, err := strconv.ParseFloat(bound, 64)
+            return err == nil && number < limit
+        }
+
+        if parts := metadataRangePattern.FindStringSubmatch(value); parts != nil {
+            min, err1 := strconv.ParseFloat(parts[1], 64)
+            max, err2 := strconv.ParseFloat(parts[2], 64)
+            return err1 == nil && err2 == nil && number >= min && number <= max
+        }
+
+        if exact, err := strconv.ParseFloat(value, 64); err == nil {
+            return number == exact
+        }
+    }
+
+    return strings.Contains(strings.ToLower(current), strings.ToLower(value))
+}
+
 func (s *FilesService) filterByGrant(file file.File, value string) bool {
     for _, grant := range file.Grant {
         if strings.EqualFold(grant, value) {
@@ -310,6 +518,17 @@ func (s *FilesService) filterByGrant(file file.File, value string) bool {
     return false
 }
 
+// canRead - документ доступен владельцу, получателям гранта и всем, если он публичный
+func canRead(userID string, fileData file.File) bool {
+	return fileData.Public || fileData.User == userID || slices.Contains[[]string, string](fileData.Grant, userID)
+}
+
+// listKeyPrefix - общий префикс ключей кэша списков пользователя при любых фильтрах.
+// generateKeyForCash с пустым фильтром дает list:<login>:"", под который они не попадают
+func listKeyPrefix(userID string) string {
+	return "list:" + userID + ":"
+}
+
 func generateKeyForCash(query string, data any) string {
 	dataByte, err := json.Marshal(data)
 	if err != nil {
@@ -0,0 +1,34 @@
+package fileservice
+
+import (
+	"astral/internal/domain/file"
+	"testing"
+)
+
+func TestCanRead(t *testing.T) {
+	private := file.File{ID: "1", User: "alice"}
+	granted := file.File{ID: "2", User: "alice", Grant: []string{"bob"}}
+	public := file.File{ID: "3", User: "alice", Public: true}
+
+	tests := []struct {
+		name   string
+		userID string
+		doc    file.File
+		want   bool
+	}{
+		{"owner of a private document", "alice", private, true},
+		{"stranger to a private document", "mallory", private, false},
+		{"grantee", "bob", granted, true},
+		{"stranger to a granted document", "mallory", granted, false},
+		{"anyone to a public document", "mallory", public, true},
+		{"anonymous to a private document", "", private, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canRead(tt.userID, tt.doc); got != tt.want {
+				t.Fatalf("canRead = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package fileservice
+
+import (
+	locksrepo "astral/internal/repository/locks"
+	"context"
+	"errors"
+)
+
+// CheckLock возвращает ErrDocumentLocked, если документ заблокирован для изменений другим пользователем
+func (s *FilesService) CheckLock(ID, userID string) error {
+	const op = "service.files.CheckLock"
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	current, err := s.locks.Get(ctx, ID)
+	if err != nil {
+		s.logger.Error("failed to get document lock", "func", op, "fileID", ID, "error", err)
+		return errors.New("failed to check document lock")
+	}
+
+	if current != nil && current.Blocks(userID) {
+		s.logger.Info("document is locked", "func", op, "fileID", ID, "userID", userID, "holder", current.Holder)
+		return ErrDocumentLocked
+	}
+
+	return nil
+}
+
+// releaseLock снимает блокировку пользователя после загрузки новой версии документа
+func (s *FilesService) releaseLock(ID, userID string) {
+	const op = "service.files.releaseLock"
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	err := s.locks.Release(ctx, ID, userID)
+	if err != nil && !errors.Is(err, locksrepo.ErrLockNotFound) && !errors.Is(err, locksrepo.ErrNotHolder) {
+		s.logger.Warn("failed to release document lock", "func", op, "fileID", ID, "error", err)
+	}
+}
+
+// dropLock снимает блокировку удаленного документа, иначе она дожила бы до своего срока
+func (s *FilesService) dropLock(ID string) {
+	const op = "service.files.dropLock"
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if _, err := s.locks.Break(ctx, ID); err != nil && !errors.Is(err, locksrepo.ErrLockNotFound) {
+		s.logger.Warn("failed to drop document lock", "func", op, "fileID", ID, "error", err)
+	}
+}
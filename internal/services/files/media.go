@@ -0,0 +1,428 @@
+package fileservice
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// Длительность аудио и видео читается из заголовков контейнера, потоки не декодируются
+
+const (
+	// MAX_MEDIA_ELEMENTS - сколько элементов контейнера разбирается, прежде чем поиск прекращается
+	MAX_MEDIA_ELEMENTS = 4096
+	// MEDIA_TAIL_SIZE - сколько байт с конца файла просматривается в поиске последней страницы Ogg
+	MEDIA_TAIL_SIZE = 64 << 10
+	// MP3_SYNC_SCAN - в каком объеме после тегов ищется первый кадр MP3
+	MP3_SYNC_SCAN = 64 << 10
+)
+
+// mp4Duration читает длительность из mvhd внутри moov (MP4, MOV, M4A, 3GP)
+func mp4Duration(r io.ReaderAt, size int64) float64 {
+	moov, moovEnd, ok := findBox(r, 0, size, "moov")
+	if !ok {
+		return 0
+	}
+	mvhd, _, ok := findBox(r, moov, moovEnd, "mvhd")
+	if !ok {
+		return 0
+	}
+
+	header, err := readAt(r, mvhd, 32)
+	if err != nil {
+		return 0
+	}
+
+	var timescale uint32
+	var duration uint64
+	if header[0] == 1 {
+		timescale = binary.BigEndian.Uint32(header[20:])
+		duration = binary.BigEndian.Uint64(header[24:])
+	} else {
+		timescale = binary.BigEndian.Uint32(header[12:])
+		duration = uint64(binary.BigEndian.Uint32(header[16:]))
+	}
+	if timescale == 0 || duration == math.MaxUint64 || duration == math.MaxUint32 && header[0] == 0 {
+		return 0
+	}
+
+	return float64(duration) / float64(timescale)
+}
+
+// findBox ищет бокс typ среди боксов в диапазоне [offset, end) и возвращает границы его содержимого
+func findBox(r io.ReaderAt, offset, end int64, typ string) (int64, int64, bool) {
+	for i := 0; i < MAX_MEDIA_ELEMENTS && offset+8 <= end; i++ {
+		header, err := readAt(r, offset, 8)
+		if err != nil {
+			return 0, 0, false
+		}
+
+		boxSize := int64(binary.BigEndian.Uint32(header))
+		headerSize := int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = end - offset
+		case 1:
+			large, err := readAt(r, offset+8, 8)
+			if err != nil {
+				return 0, 0, false
+			}
+			boxSize = int64(binary.BigEndian.Uint64(large))
+			headerSize = 16
+		}
+		if boxSize < headerSize || offset+boxSize > end {
+			return 0, 0, false
+		}
+
+		if string(header[4:8]) == typ {
+			return offset + headerSize, offset + boxSize, true
+		}
+		offset += boxSize
+	}
+
+	return 0, 0, false
+}
+
+// Идентификаторы элементов Matroska
+const (
+	ebmlSegment       = 0x18538067
+	ebmlInfo          = 0x1549A966
+	ebmlCluster       = 0x1F43B675
+	ebmlTimecodeScale = 0x2AD7B1
+	ebmlDuration      = 0x4489
+)
+
+// matroskaDuration читает Duration и TimecodeScale из Segment Info (MKV, WebM)
+func matroskaDuration(r io.ReaderAt, size int64) float64 {
+	offset := int64(0)
+	for i := 0; i < MAX_MEDIA_ELEMENTS && offset < size; i++ {
+		id, dataOffset, dataSize, ok := ebmlElement(r, offset, size)
+		if !ok {
+			return 0
+		}
+
+		switch id {
+		case ebmlSegment:
+			// Внутрь сегмента: его дочерние элементы идут подряд
+			offset = dataOffset
+			continue
+		case ebmlCluster:
+			// Info всегда предшествует кластерам
+			return 0
+		case ebmlInfo:
+			return matroskaInfoDuration(r, dataOffset, dataOffset+dataSize)
+		}
+		offset = dataOffset + dataSize
+	}
+
+	return 0
+}
+
+func matroskaInfoDuration(r io.ReaderAt, offset, end int64) float64 {
+	scale := uint64(1000000)
+	duration := 0.0
+	for i := 0; i < MAX_MEDIA_ELEMENTS && offset < end; i++ {
+		id, dataOffset, dataSize, ok := ebmlElement(r, offset, end)
+		if !ok || dataSize > 8 {
+			break
+		}
+
+		value, err := readAt(r, dataOffset, dataSize)
+		if err != nil {
+			return 0
+		}
+		switch id {
+		case ebmlTimecodeScale:
+			scale = 0
+			for _, b := range value {
+				scale = scale<<8 | uint64(b)
+			}
+		case ebmlDuration:
+			switch len(value) {
+			case 4:
+				duration = float64(math.Float32frombits(binary.BigEndian.Uint32(value)))
+			case 8:
+				duration = math.Float64frombits(binary.BigEndian.Uint64(value))
+			}
+		}
+		offset = dataOffset + dataSize
+	}
+
+	return duration * float64(scale) / 1e9
+}
+
+// ebmlElement читает идентификатор и размер элемента EBML. Элемент неизвестного размера
+// продолжается до end
+func ebmlElement(r io.ReaderAt, offset, end int64) (uint64, int64, int64, bool) {
+	id, idLength, ok := ebmlVint(r, offset, 4, true)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	size, sizeLength, ok := ebmlVint(r, offset+int64(idLength), 8, false)
+	if !ok {
+		return 0, 0, 0, false
+	}
+
+	dataOffset := offset + int64(idLength) + int64(sizeLength)
+	dataSize := int64(size)
+	if size == 1<<(7*sizeLength)-1 || dataSize < 0 || dataOffset+dataSize > end {
+		dataSize = end - dataOffset
+	}
+	if dataSize < 0 {
+		return 0, 0, 0, false
+	}
+
+	return id, dataOffset, dataSize, true
+}
+
+// ebmlVint читает число переменной длины: длину задает число ведущих нулей первого байта
+func ebmlVint(r io.ReaderAt, offset int64, maxLength int, keepMarker bool) (uint64, int, bool) {
+	first, err := readAt(r, offset, 1)
+	if err != nil || first[0] == 0 {
+		return 0, 0, false
+	}
+
+	length := 1
+	for mask := byte(0x80); first[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > maxLength {
+		return 0, 0, false
+	}
+
+	raw, err := readAt(r, offset, int64(length))
+	if err != nil {
+		return 0, 0, false
+	}
+	value := uint64(raw[0])
+	if !keepMarker {
+		value &= uint64(0xFF >> length)
+	}
+	for _, b := range raw[1:] {
+		value = value<<8 | uint64(b)
+	}
+
+	return value, length, true
+}
+
+// riffDuration считает длительность WAVE по объему данных и байтовой скорости, AVI - по числу
+// кадров и длительности кадра из avih
+func riffDuration(r io.ReaderAt, size int64) float64 {
+	header, err := readAt(r, 0, 12)
+	if err != nil {
+		return 0
+	}
+
+	switch string(header[8:12]) {
+	case "WAVE":
+		var byteRate uint32
+		var dataSize int64
+		walkRIFF(r, 12, size, func(id string, offset, length int64) bool {
+			switch id {
+			case "fmt ":
+				if format, err := readAt(r, offset, 16); err == nil {
+					byteRate = binary.LittleEndian.Uint32(format[8:])
+				}
+			case "data":
+				dataSize = min(length, size-offset)
+				return false
+			}
+			return true
+		})
+		if byteRate == 0 {
+			return 0
+		}
+		return float64(dataSize) / float64(byteRate)
+	case "AVI ":
+		duration := 0.0
+		walkRIFF(r, 12, size, func(id string, offset, length int64) bool {
+			if id != "LIST" {
+				return true
+			}
+			if listType, err := readAt(r, offset, 4); err != nil || string(listType) != "hdrl" {
+				return true
+			}
+			walkRIFF(r, offset+4, offset+length, func(id string, offset, length int64) bool {
+				if id == "avih" {
+					if avih, err := readAt(r, offset, 20); err == nil {
+						frameMicros := binary.LittleEndian.Uint32(avih)
+						frames := binary.LittleEndian.Uint32(avih[16:])
+						duration = float64(frameMicros) * float64(frames) / 1e6
+					}
+					return false
+				}
+				return true
+			})
+			return false
+		})
+		return duration
+	}
+
+	return 0
+}
+
+// walkRIFF обходит чанки в диапазоне [offset, end), пока visit возвращает true
+func walkRIFF(r io.ReaderAt, offset, end int64, visit func(id string, offset, length int64) bool) {
+	for i := 0; i < MAX_MEDIA_ELEMENTS && offset+8 <= end; i++ {
+		header, err := readAt(r, offset, 8)
+		if err != nil {
+			return
+		}
+
+		length := int64(binary.LittleEndian.Uint32(header[4:]))
+		if !visit(string(header[:4]), offset+8, length) {
+			return
+		}
+		// Чанки выравниваются по четной границе
+		offset += 8 + length + length&1
+	}
+}
+
+// flacDuration делит число сэмплов из STREAMINFO на частоту дискретизации
+func flacDuration(r io.ReaderAt) float64 {
+	info, err := readAt(r, 4, 4+34)
+	if err != nil || info[0]&0x7F != 0 {
+		return 0
+	}
+
+	streamInfo := info[4:]
+	sampleRate := uint32(streamInfo[10])<<12 | uint32(streamInfo[11])<<4 | uint32(streamInfo[12])>>4
+	samples := uint64(streamInfo[13]&0x0F)<<32 | uint64(binary.BigEndian.Uint32(streamInfo[14:]))
+	if sampleRate == 0 {
+		return 0
+	}
+
+	return float64(samples) / float64(sampleRate)
+}
+
+var (
+	mp3Bitrates = [2][16]int{
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+	}
+	mp3SampleRates = [3]int{44100, 48000, 32000}
+)
+
+// mp3Duration берет число кадров из заголовка Xing/Info или VBRI, без них считает файл CBR
+// и делит объем звука на битрейт первого кадра
+func mp3Duration(r io.ReaderAt, size int64) float64 {
+	start := int64(0)
+	if id3, err := readAt(r, 0, 10); err == nil && string(id3[:3]) == "ID3" {
+		// Размер тега записан 7-битными байтами
+		start = 10 + (int64(id3[6]&0x7F)<<21 | int64(id3[7]&0x7F)<<14 | int64(id3[8]&0x7F)<<7 | int64(id3[9]&0x7F))
+		if id3[5]&0x10 != 0 {
+			start += 10
+		}
+	}
+
+	window, err := readAt(r, start, min(MP3_SYNC_SCAN, size-start))
+	if err != nil {
+		return 0
+	}
+
+	for i := 0; i+4 <= len(window); i++ {
+		if window[i] != 0xFF || window[i+1]&0xE0 != 0xE0 {
+			continue
+		}
+
+		version := window[i+1] >> 3 & 3
+		layer := window[i+1] >> 1 & 3
+		bitrateIndex := window[i+2] >> 4
+		rateIndex := window[i+2] >> 2 & 3
+		if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+			continue
+		}
+
+		mpeg1 := version == 3
+		table, samplesPerFrame, sampleRate := 1, 576, mp3SampleRates[rateIndex]/2
+		if mpeg1 {
+			table, samplesPerFrame, sampleRate = 0, 1152, mp3SampleRates[rateIndex]
+		} else if version == 0 {
+			sampleRate /= 2
+		}
+		bitrate := mp3Bitrates[table][bitrateIndex] * 1000
+
+		mono := window[i+3]>>6 == 3
+		sideInfo := 32
+		switch {
+		case mpeg1 && mono, !mpeg1 && !mono:
+			sideInfo = 17
+		case !mpeg1 && mono:
+			sideInfo = 9
+		}
+
+		frame := window[i:]
+		if xing := 4 + sideInfo; len(frame) >= xing+12 {
+			tag := string(frame[xing : xing+4])
+			if (tag == "Xing" || tag == "Info") && binary.BigEndian.Uint32(frame[xing+4:])&1 != 0 {
+				frames := binary.BigEndian.Uint32(frame[xing+8:])
+				return float64(frames) * float64(samplesPerFrame) / float64(sampleRate)
+			}
+		}
+		if len(frame) >= 36+18 && string(frame[36:40]) == "VBRI" {
+			frames := binary.BigEndian.Uint32(frame[36+14:])
+			return float64(frames) * float64(samplesPerFrame) / float64(sampleRate)
+		}
+
+		audio := size - start - int64(i)
+		if tag, err := readAt(r, size-128, 3); err == nil && string(tag) == "TAG" {
+			audio -= 128
+		}
+		return float64(audio) * 8 / float64(bitrate)
+	}
+
+	return 0
+}
+
+// oggDuration делит позицию последней страницы потока на частоту из заголовка Vorbis или Opus.
+// Позиция Opus всегда в отсчетах 48 кГц и включает пропускаемые в начале отсчеты
+func oggDuration(r io.ReaderAt, size int64) float64 {
+	first, err := readAt(r, 0, min(size, 512))
+	if err != nil || len(first) < 28 {
+		return 0
+	}
+
+	serial := first[14:18]
+	segments := int(first[26])
+	packet := 27 + segments
+	if packet >= len(first) {
+		return 0
+	}
+
+	var rate float64
+	var preSkip uint64
+	switch body := first[packet:]; {
+	case len(body) >= 16 && string(body[:7]) == "\x01vorbis":
+		rate = float64(binary.LittleEndian.Uint32(body[12:]))
+	case len(body) >= 12 && string(body[:8]) == "OpusHead":
+		rate = 48000
+		preSkip = uint64(binary.LittleEndian.Uint16(body[10:]))
+	default:
+		return 0
+	}
+	if rate == 0 {
+		return 0
+	}
+
+	tailSize := min(size, MEDIA_TAIL_SIZE)
+	tail, err := readAt(r, size-tailSize, tailSize)
+	if err != nil {
+		return 0
+	}
+
+	for i := bytes.LastIndex(tail, []byte("OggS")); i >= 0; i = bytes.LastIndex(tail[:i], []byte("OggS")) {
+		page := tail[i:]
+		if len(page) < 27 || !bytes.Equal(page[14:18], serial) {
+			continue
+		}
+
+		granule := binary.LittleEndian.Uint64(page[6:])
+		if granule == math.MaxUint64 || granule < preSkip {
+			continue
+		}
+		return float64(granule-preSkip) / rate
+	}
+
+	return 0
+}
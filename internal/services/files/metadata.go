@@ -0,0 +1,292 @@
+package fileservice
+
+import (
+	"astral/internal/domain/file"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// MAX_METADATA_VALUE - предельная длина извлеченного значения в символах
+const MAX_METADATA_VALUE = 128
+
+var errScanLimit = errors.New("metadata scan limit exceeded")
+
+// intrinsicMetadata заменяет извлеченные метаданные документа значениями из нового содержимого
+// и при необходимости удаляет координаты из JPEG. Ошибки разбора загрузку не прерывают: документ
+// просто остается без извлеченных метаданных. Reader после вызова стоит в начале содержимого
+func (s *FilesService) intrinsicMetadata(fileData *file.File) {
+	const op = "service.files.intrinsicMetadata"
+
+	fileData.Metadata = file.WithoutIntrinsic(fileData.Metadata)
+	if fileData.Reader == nil || !fileData.File || !s.metadata.Enabled {
+		return
+	}
+
+	content, ok := fileData.Reader.(io.ReadSeeker)
+	if !ok {
+		return
+	}
+	readerAt, ok := fileData.Reader.(io.ReaderAt)
+	if !ok {
+		s.logger.Debug("content is not random access, metadata skipped", "func", op, "filename", fileData.Name)
+		return
+	}
+
+	start, err := content.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return
+	}
+	end, err := content.Seek(0, io.SeekEnd)
+	if err != nil {
+		return
+	}
+	if _, err := content.Seek(start, io.SeekStart); err != nil {
+		return
+	}
+	section := io.NewSectionReader(readerAt, start, end-start)
+
+	metadata := make(map[string]string)
+	if (fileData.StripGPS || s.metadata.StripGPS) && sniffContent(section) == contentJPEG {
+		data, err := io.ReadAll(section)
+		if err != nil {
+			s.logger.Error("failed to read file", "func", op, "filename", fileData.Name, "error", err)
+			return
+		}
+
+		if stripped, ok := stripGPS(data); ok {
+			sum := sha256.Sum256(stripped)
+			fileData.Reader = bytes.NewReader(stripped)
+			fileData.Size = len(stripped)
+			fileData.Checksum = hex.EncodeToString(sum[:])
+			section = io.NewSectionReader(bytes.NewReader(stripped), 0, int64(len(stripped)))
+			metadata[file.INTRINSIC_GPS_STRIPPED] = "true"
+			s.logger.Info("gps stripped", "func", op, "filename", fileData.Name, "userID", fileData.User)
+		}
+	}
+
+	for k, v := range s.extractMetadata(section, fileData.Name) {
+		metadata[k] = v
+	}
+	if len(metadata) == 0 {
+		return
+	}
+
+	if fileData.Metadata == nil {
+		fileData.Metadata = make(map[string]string, len(metadata))
+	}
+	for k, v := range metadata {
+		fileData.Metadata[k] = v
+	}
+}
+
+// storedMetadata извлекает метаданные из уже записанного содержимого документа владельца owner.
+// Так разбирается прямая загрузка в хранилище: удалить координаты из нее уже нельзя
+func (s *FilesService) storedMetadata(ctx context.Context, ID, owner string) map[string]string {
+	const op = "service.files.storedMetadata"
+
+	if !s.metadata.Enabled {
+		return nil
+	}
+
+	obj, err := s.repo.OpenObject(ctx, owner, ID)
+	if err != nil {
+		return nil
+	}
+	defer obj.Close()
+
+	size, err := obj.Seek(0, io.SeekEnd)
+	if err != nil {
+		s.logger.Error("failed to read file", "func", op, "fileID", ID, "error", err)
+		return nil
+	}
+
+	return s.extractMetadata(io.NewSectionReader(obj, 0, size), ID)
+}
+
+// extractMetadata разбирает содержимое в пределах METADATA_MAX_SCAN прочитанных байт. Паника
+// разборщика на поврежденном файле перехватывается: метаданные необязательны
+func (s *FilesService) extractMetadata(section *io.SectionReader, name string) map[string]string {
+	const op = "service.files.extractMetadata"
+
+	metadata := make(map[string]string)
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("failed to extract metadata", "func", op, "filename", name, "error", r)
+			clear(metadata)
+		}
+	}()
+
+	extractMetadata(&scanReader{r: section, left: s.metadata.MaxScan}, section.Size(), metadata)
+	s.logger.Debug("metadata extracted", "func", op, "filename", name, "keys", len(metadata))
+
+	return metadata
+}
+
+type contentKind int
+
+const (
+	contentUnknown contentKind = iota
+	contentJPEG
+	contentImage
+	contentPDF
+	contentZip
+	contentMP4
+	contentMatroska
+	contentRIFF
+	contentFLAC
+	contentMP3
+	contentOgg
+)
+
+// sniffContent определяет формат по сигнатуре в начале содержимого: заявленному клиентом типу
+// доверять нельзя
+func sniffContent(r io.ReaderAt) contentKind {
+	head := make([]byte, 16)
+	n, _ := r.ReadAt(head, 0)
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF}):
+		return contentJPEG
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")),
+		bytes.HasPrefix(head, []byte("GIF8")),
+		bytes.HasPrefix(head, []byte("BM")),
+		bytes.HasPrefix(head, []byte("II*\x00")),
+		bytes.HasPrefix(head, []byte("MM\x00*")),
+		len(head) >= 12 && string(head[:4]) == "RIFF" && string(head[8:12]) == "WEBP":
+		return contentImage
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return contentPDF
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		return contentZip
+	case len(head) >= 8 && string(head[4:8]) == "ftyp":
+		return contentMP4
+	case bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return contentMatroska
+	case bytes.HasPrefix(head, []byte("RIFF")):
+		return contentRIFF
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return contentFLAC
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return contentOgg
+	case bytes.HasPrefix(head, []byte("ID3")),
+		len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0:
+		return contentMP3
+	}
+
+	return contentUnknown
+}
+
+// extractMetadata разбирает содержимое размера size по его формату
+func extractMetadata(r io.ReaderAt, size int64, metadata map[string]string) {
+	switch sniffContent(r) {
+	case contentJPEG:
+		imageMetadata(r, metadata)
+		if head, err := readAt(r, 0, min(size, 1<<20)); err == nil {
+			if tiffData := exifTIFF(jpegSegments(head)); tiffData != nil {
+				exifMetadata(tiffData, metadata)
+			}
+		}
+	case contentImage:
+		imageMetadata(r, metadata)
+	case contentPDF:
+		pdfMetadata(r, size, metadata)
+	case contentZip:
+		officeMetadata(r, size, metadata)
+	case contentMP4:
+		setDuration(metadata, mp4Duration(r, size))
+	case contentMatroska:
+		setDuration(metadata, matroskaDuration(r, size))
+	case contentRIFF:
+		setDuration(metadata, riffDuration(r, size))
+	case contentFLAC:
+		setDuration(metadata, flacDuration(r))
+	case contentMP3:
+		setDuration(metadata, mp3Duration(r, size))
+	case contentOgg:
+		setDuration(metadata, oggDuration(r, size))
+	}
+}
+
+// imageMetadata читает размер изображения из заголовка, сами пиксели не декодируются
+func imageMetadata(r io.ReaderAt, metadata map[string]string) {
+	config, _, err := image.DecodeConfig(io.NewSectionReader(r, 0, 1<<62))
+	if err != nil || config.Width <= 0 || config.Height <= 0 {
+		return
+	}
+
+	metadata[file.INTRINSIC_WIDTH] = strconv.Itoa(config.Width)
+	metadata[file.INTRINSIC_HEIGHT] = strconv.Itoa(config.Height)
+}
+
+func setDuration(metadata map[string]string, seconds float64) {
+	if seconds <= 0 || seconds > 1e7 {
+		return
+	}
+	metadata[file.INTRINSIC_DURATION] = strconv.FormatFloat(seconds, 'f', 3, 64)
+}
+
+// setMetadata сохраняет значение без управляющих символов и лишних пробелов, пустые не сохраняются
+func setMetadata(metadata map[string]string, key, value string) {
+	value = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == unicode.ReplacementChar {
+			return ' '
+		}
+		return r
+	}, value)
+	value = strings.Join(strings.Fields(value), " ")
+	if runes := []rune(value); len(runes) > MAX_METADATA_VALUE {
+		value = strings.TrimSpace(string(runes[:MAX_METADATA_VALUE]))
+	}
+
+	if value != "" {
+		metadata[key] = value
+	}
+}
+
+// readAt читает ровно n байт с позиции offset
+func readAt(r io.ReaderAt, offset, n int64) ([]byte, error) {
+	if n < 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	buf := make([]byte, n)
+	read, err := r.ReadAt(buf, offset)
+	if int64(read) == n {
+		return buf, nil
+	}
+	if err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	return nil, err
+}
+
+// scanReader ограничивает общий объем, который разбор метаданных прочитает из содержимого:
+// большие документы читаются из хранилища по частям, и разбор не должен вычитывать их целиком
+type scanReader struct {
+	r    io.ReaderAt
+	left int64
+}
+
+func (s *scanReader) ReadAt(p []byte, off int64) (int, error) {
+	if int64(len(p)) > s.left {
+		return 0, errScanLimit
+	}
+	n, err := s.r.ReadAt(p, off)
+	s.left -= int64(n)
+	return n, err
+}
@@ -0,0 +1,500 @@
+package fileservice
+
+import (
+	"archive/zip"
+	"astral/env"
+	"astral/internal/domain/file"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"log/slog"
+	"math/rand"
+	"testing"
+)
+
+// tiffOrder - порядок байт TIFF, в котором собираются тестовые каталоги
+type tiffOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+type tiffField struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+func tiffASCII(tag uint16, value string) tiffField {
+	return tiffField{tag: tag, typ: 2, count: uint32(len(value) + 1), value: append([]byte(value), 0)}
+}
+
+func tiffLong(order tiffOrder, tag uint16, value uint32) tiffField {
+	return tiffField{tag: tag, typ: 4, count: 1, value: order.AppendUint32(nil, value)}
+}
+
+func tiffRationals(order tiffOrder, tag uint16, values ...uint32) tiffField {
+	var raw []byte
+	for i := 0; i+1 < len(values); i += 2 {
+		raw = order.AppendUint32(raw, values[i])
+		raw = order.AppendUint32(raw, values[i+1])
+	}
+	return tiffField{tag: tag, typ: 5, count: uint32(len(values) / 2), value: raw}
+}
+
+// appendIFD дописывает каталог в конец TIFF-блока, длинные значения кладутся сразу за ним
+func appendIFD(buf []byte, order tiffOrder, fields []tiffField) []byte {
+	dataOffset := len(buf) + 2 + len(fields)*12 + 4
+
+	var data []byte
+	buf = order.AppendUint16(buf, uint16(len(fields)))
+	for _, f := range fields {
+		buf = order.AppendUint16(buf, f.tag)
+		buf = order.AppendUint16(buf, f.typ)
+		buf = order.AppendUint32(buf, f.count)
+		if len(f.value) <= 4 {
+			buf = append(buf, f.value...)
+			buf = append(buf, make([]byte, 4-len(f.value))...)
+		} else {
+			buf = order.AppendUint32(buf, uint32(dataOffset+len(data)))
+			data = append(data, f.value...)
+		}
+	}
+	buf = order.AppendUint32(buf, 0)
+
+	return append(buf, data...)
+}
+
+// testTIFF собирает EXIF камеры Canon, снятой 2024-03-01 в 12:30:15+03:00, с координатами
+// 55°45'21" S 37°37'4" W, если withGPS
+func testTIFF(order tiffOrder, withGPS bool) []byte {
+	buf := []byte("II*\x00\x08\x00\x00\x00")
+	if order == binary.BigEndian {
+		buf = []byte("MM\x00*\x00\x00\x00\x08")
+	}
+
+	// Смещения вложенных каталогов известны только после записи IFD0, они дописываются в записи
+	// после сборки
+	fields := []tiffField{
+		tiffASCII(tagMake, "Canon"),
+		tiffASCII(tagModel, "EOS R6"),
+		tiffLong(order, tagExifIFD, 0),
+	}
+	if withGPS {
+		fields = append(fields, tiffLong(order, tagGPSIFD, 0))
+	}
+	buf = appendIFD(buf, order, fields)
+	patch := func(index int) {
+		order.PutUint32(buf[8+2+index*12+8:], uint32(len(buf)))
+	}
+
+	patch(2)
+	buf = appendIFD(buf, order, []tiffField{
+		tiffASCII(tagDateTimeOriginal, "2024:03:01 12:30:15"),
+		tiffASCII(tagOffsetOriginal, "+03:00"),
+	})
+
+	if withGPS {
+		patch(3)
+		buf = appendIFD(buf, order, []tiffField{
+			tiffASCII(tagGPSLatitudeRef, "S"),
+			tiffRationals(order, tagGPSLatitude, 55, 1, 45, 1, 21, 1),
+			tiffASCII(tagGPSLongitudeRef, "W"),
+			tiffRationals(order, tagGPSLongitude, 37, 1, 37, 1, 4, 1),
+		})
+	}
+
+	return buf
+}
+
+// jpegSegmentBytes кодирует сегмент APPn с полезными данными payload
+func jpegSegmentBytes(marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// testJPEG кодирует изображение 8x4 и вставляет после SOI сегменты APPn
+func testJPEG(t testing.TB, segments ...[]byte) []byte {
+	t.Helper()
+
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, image.NewGray(image.Rect(0, 0, 8, 4)), nil); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+
+	data := append([]byte(nil), encoded.Bytes()[:2]...)
+	for _, segment := range segments {
+		data = append(data, segment...)
+	}
+	return append(data, encoded.Bytes()[2:]...)
+}
+
+func exifSegment(tiffData []byte) []byte {
+	return jpegSegmentBytes(0xE1, append([]byte(EXIF_HEADER), tiffData...))
+}
+
+func xmpSegment(body string) []byte {
+	return jpegSegmentBytes(0xE1, append([]byte(XMP_HEADER), body...))
+}
+
+func extract(data []byte) map[string]string {
+	metadata := make(map[string]string)
+	extractMetadata(bytes.NewReader(data), int64(len(data)), metadata)
+	return metadata
+}
+
+func TestExifMetadata(t *testing.T) {
+	want := map[string]string{
+		file.INTRINSIC_WIDTH:         "8",
+		file.INTRINSIC_HEIGHT:        "4",
+		file.INTRINSIC_CAMERA_MAKE:   "Canon",
+		file.INTRINSIC_CAMERA_MODEL:  "EOS R6",
+		file.INTRINSIC_TAKEN_AT:      "2024-03-01T12:30:15+03:00",
+		file.INTRINSIC_GPS_LATITUDE:  "-55.755833",
+		file.INTRINSIC_GPS_LONGITUDE: "-37.617778",
+	}
+
+	for name, order := range map[string]tiffOrder{"little endian": binary.LittleEndian, "big endian": binary.BigEndian} {
+		t.Run(name, func(t *testing.T) {
+			got := extract(testJPEG(t, exifSegment(testTIFF(order, true))))
+			if len(got) != len(want) {
+				t.Fatalf("metadata = %v, want %v", got, want)
+			}
+			for k, v := range want {
+				if got[k] != v {
+					t.Errorf("%s = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestStripGPS(t *testing.T) {
+	xmp := xmpSegment(`<x:xmpmeta><rdf:Description exif:GPSLatitude="55,45.35S"/></x:xmpmeta>`)
+	plainXMP := xmpSegment(`<x:xmpmeta><rdf:Description xmp:Rating="5"/></x:xmpmeta>`)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		stripped bool
+		// сколько байт убирается вместе с сегментами XMP
+		removed int
+	}{
+		{"exif gps", testJPEG(t, exifSegment(testTIFF(binary.LittleEndian, true))), true, 0},
+		{"exif gps big endian", testJPEG(t, exifSegment(testTIFF(binary.BigEndian, true))), true, 0},
+		{"xmp gps", testJPEG(t, exifSegment(testTIFF(binary.LittleEndian, false)), xmp), true, len(xmp)},
+		{"exif and xmp gps", testJPEG(t, exifSegment(testTIFF(binary.LittleEndian, true)), plainXMP, xmp), true, len(xmp)},
+		{"no gps", testJPEG(t, exifSegment(testTIFF(binary.LittleEndian, false)), plainXMP), false, 0},
+		{"no exif", testJPEG(t), false, 0},
+		{"not a jpeg", []byte("%PDF-1.7"), false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := append([]byte(nil), tt.data...)
+
+			got, ok := stripGPS(tt.data)
+			if ok != tt.stripped {
+				t.Fatalf("stripped = %v, want %v", ok, tt.stripped)
+			}
+			if !bytes.Equal(tt.data, original) {
+				t.Fatal("input modified in place")
+			}
+			if !ok {
+				if !bytes.Equal(got, tt.data) {
+					t.Fatal("content changed without gps")
+				}
+				return
+			}
+			if len(got) != len(tt.data)-tt.removed {
+				t.Fatalf("size = %d, want %d", len(got), len(tt.data)-tt.removed)
+			}
+			if bytes.Contains(got, []byte("GPSLatitude")) {
+				t.Fatal("xmp coordinates left")
+			}
+
+			metadata := extract(got)
+			if _, ok := metadata[file.INTRINSIC_GPS_LATITUDE]; ok {
+				t.Fatalf("coordinates left: %v", metadata)
+			}
+			// Остальной EXIF и само изображение не повреждаются
+			if metadata[file.INTRINSIC_CAMERA_MAKE] != "Canon" || metadata[file.INTRINSIC_TAKEN_AT] == "" {
+				t.Fatalf("exif damaged: %v", metadata)
+			}
+			if _, err := jpeg.Decode(bytes.NewReader(got)); err != nil {
+				t.Fatalf("jpeg.Decode: %v", err)
+			}
+
+			if again, ok := stripGPS(got); ok || !bytes.Equal(again, got) {
+				t.Fatal("second pass changed the content")
+			}
+		})
+	}
+}
+
+func newMetadataService(metadata env.Metadata) *FilesService {
+	return &FilesService{metadata: metadata, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+}
+
+// TestIntrinsicMetadataChecksum проверяет, что контрольная сумма и размер, которые сохраняются
+// с документом, соответствуют содержимому без координат, а не загруженному
+func TestIntrinsicMetadataChecksum(t *testing.T) {
+	original := testJPEG(t, exifSegment(testTIFF(binary.LittleEndian, true)))
+	originalSum := sha256.Sum256(original)
+
+	tests := []struct {
+		name     string
+		cfg      env.Metadata
+		stripGPS bool
+		stripped bool
+	}{
+		{"stripped by configuration", env.Metadata{Enabled: true, StripGPS: true, MaxScan: 1 << 20}, false, true},
+		{"stripped on request", env.Metadata{Enabled: true, MaxScan: 1 << 20}, true, true},
+		{"kept", env.Metadata{Enabled: true, MaxScan: 1 << 20}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newMetadataService(tt.cfg)
+			fileData := file.File{
+				Name:     "photo.jpg",
+				File:     true,
+				Size:     len(original),
+				StripGPS: tt.stripGPS,
+				Reader:   bytes.NewReader(original),
+			}
+
+			// Тот же порядок, что и при загрузке: сумма считается до извлечения метаданных
+			if err := s.checksum(&fileData); err != nil {
+				t.Fatalf("checksum: %v", err)
+			}
+			s.intrinsicMetadata(&fileData)
+
+			stored, err := io.ReadAll(fileData.Reader)
+			if err != nil {
+				t.Fatalf("read content: %v", err)
+			}
+			sum := sha256.Sum256(stored)
+			if fileData.Checksum != hex.EncodeToString(sum[:]) {
+				t.Fatalf("checksum %s does not match the stored content %x", fileData.Checksum, sum)
+			}
+			if fileData.Size != len(stored) {
+				t.Fatalf("size = %d, stored %d", fileData.Size, len(stored))
+			}
+
+			_, hasGPS := fileData.Metadata[file.INTRINSIC_GPS_LATITUDE]
+			if tt.stripped {
+				if bytes.Equal(stored, original) || sum == originalSum {
+					t.Fatal("content stored with coordinates")
+				}
+				if hasGPS || fileData.Metadata[file.INTRINSIC_GPS_STRIPPED] != "true" {
+					t.Fatalf("metadata = %v", fileData.Metadata)
+				}
+				return
+			}
+			if !bytes.Equal(stored, original) || !hasGPS {
+				t.Fatalf("content changed without stripping, metadata = %v", fileData.Metadata)
+			}
+		})
+	}
+}
+
+func testPDF() []byte {
+	return []byte("%PDF-1.7\n" +
+		"1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n" +
+		"2 0 obj\n<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>\nendobj\n" +
+		"3 0 obj\n<< /Type /Page /Parent 2 0 R >>\nendobj\n" +
+		"4 0 obj\n<< /Type /Page /Parent 2 0 R >>\nendobj\n" +
+		"5 0 obj\n<< /Title (Quarterly \\(Q1\\) report) /Author <FEFF0410043D043D0430> /Producer (Writer) " +
+		"/CreationDate (D:20240301123015+03'00') /ModDate (D:20240302) >>\nendobj\n" +
+		"trailer\n<< /Size 6 /Root 1 0 R /Info 5 0 R >>\n%%EOF\n")
+}
+
+func testWAV() []byte {
+	// 8000 Гц, 8 бит, моно: 16000 байт звука - 2 секунды
+	data := []byte("RIFF\x00\x00\x00\x00WAVE")
+	data = append(data, "fmt "...)
+	data = binary.LittleEndian.AppendUint32(data, 16)
+	data = binary.LittleEndian.AppendUint16(data, 1)
+	data = binary.LittleEndian.AppendUint16(data, 1)
+	data = binary.LittleEndian.AppendUint32(data, 8000)
+	data = binary.LittleEndian.AppendUint32(data, 8000)
+	data = binary.LittleEndian.AppendUint16(data, 1)
+	data = binary.LittleEndian.AppendUint16(data, 8)
+	data = append(data, "data"...)
+	data = binary.LittleEndian.AppendUint32(data, 16000)
+	data = append(data, make([]byte, 16000)...)
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+
+	return data
+}
+
+func testFLAC() []byte {
+	// STREAMINFO: 44100 Гц, 2 канала, 16 бит, 110250 сэмплов - 2.5 секунды
+	streamInfo := make([]byte, 34)
+	streamInfo[10] = 44100 >> 12
+	streamInfo[11] = 44100 >> 4 & 0xFF
+	streamInfo[12] = 44100&0x0F<<4 | 1<<1
+	streamInfo[13] = 15 << 4
+	binary.BigEndian.PutUint32(streamInfo[14:], 110250)
+
+	data := []byte("fLaC\x80\x00\x00\x22")
+	return append(data, streamInfo...)
+}
+
+func testMP4() []byte {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:], 3250)
+
+	box := func(typ string, content []byte) []byte {
+		b := binary.BigEndian.AppendUint32(nil, uint32(8+len(content)))
+		return append(append(b, typ...), content...)
+	}
+
+	return append(box("ftyp", []byte("isom\x00\x00\x02\x00isom")), box("moov", box("mvhd", mvhd))...)
+}
+
+func testDOCX(t testing.TB) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"docProps/core.xml": `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" ` +
+			`xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/">` +
+			`<dc:title>Budget</dc:title><dc:creator>Anna</dc:creator>` +
+			`<dcterms:created>2024-03-01T09:30:15Z</dcterms:created></cp:coreProperties>`,
+		"docProps/app.xml": `<Properties><Application>Microsoft Office Word</Application><Pages>7</Pages></Properties>`,
+	}
+	for name, content := range parts {
+		part, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("zip.Create: %v", err)
+		}
+		part.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("zip.Close: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestExtractMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want map[string]string
+	}{
+		{"pdf", testPDF(), map[string]string{
+			file.INTRINSIC_PAGES:       "2",
+			file.INTRINSIC_TITLE:       "Quarterly (Q1) report",
+			file.INTRINSIC_AUTHOR:      "Анна",
+			file.INTRINSIC_APPLICATION: "Writer",
+			file.INTRINSIC_CREATED:     "2024-03-01T12:30:15+03:00",
+			file.INTRINSIC_MODIFIED:    "2024-03-02T00:00:00",
+		}},
+		{"docx", testDOCX(t), map[string]string{
+			file.INTRINSIC_TITLE:       "Budget",
+			file.INTRINSIC_AUTHOR:      "Anna",
+			file.INTRINSIC_CREATED:     "2024-03-01T09:30:15Z",
+			file.INTRINSIC_APPLICATION: "Microsoft Office Word",
+			file.INTRINSIC_PAGES:       "7",
+		}},
+		{"wav", testWAV(), map[string]string{file.INTRINSIC_DURATION: "2.000"}},
+		{"flac", testFLAC(), map[string]string{file.INTRINSIC_DURATION: "2.500"}},
+		{"mp4", testMP4(), map[string]string{file.INTRINSIC_DURATION: "3.250"}},
+		{"unknown", []byte("plain text"), map[string]string{}},
+		{"empty", nil, map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extract(tt.data)
+			if len(got) != len(tt.want) {
+				t.Fatalf("metadata = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func metadataFixtures(t testing.TB) map[string][]byte {
+	return map[string][]byte{
+		"jpeg": testJPEG(t, exifSegment(testTIFF(binary.LittleEndian, true)), xmpSegment("GPS")),
+		"pdf":  testPDF(),
+		"docx": testDOCX(t),
+		"wav":  testWAV()[:256],
+		"flac": testFLAC(),
+		"mp4":  testMP4(),
+	}
+}
+
+// checkMalformed разбирает поврежденное содержимое без перехвата паники: extractMetadata сервиса
+// паники перехватывает, и ошибка разборщика осталась бы незамеченной
+func checkMalformed(t *testing.T, name string, data []byte) {
+	t.Helper()
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("%s: panic on %d bytes %x: %v", name, len(data), data, r)
+		}
+	}()
+
+	metadata := make(map[string]string)
+	extractMetadata(&scanReader{r: bytes.NewReader(data), left: 1 << 20}, int64(len(data)), metadata)
+	for k, v := range metadata {
+		if !file.IsIntrinsicKey(k) || v == "" {
+			t.Fatalf("%s: unexpected value %q=%q", name, k, v)
+		}
+	}
+
+	if stripped, ok := stripGPS(data); ok && len(stripped) > len(data) {
+		t.Fatalf("%s: stripping grew the content from %d to %d bytes", name, len(data), len(stripped))
+	}
+}
+
+func TestExtractMetadataTruncated(t *testing.T) {
+	for name, data := range metadataFixtures(t) {
+		for n := range len(data) {
+			checkMalformed(t, name, data[:n])
+		}
+	}
+}
+
+func TestExtractMetadataCorrupted(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for name, data := range metadataFixtures(t) {
+		for i := 0; i < 2000; i++ {
+			corrupted := append([]byte(nil), data...)
+			// Сигнатура сохраняется, чтобы содержимое попало в разборщик своего формата
+			for flips := 1 + random.Intn(8); flips > 0; flips-- {
+				pos := 4 + random.Intn(len(corrupted)-4)
+				corrupted[pos] = byte(random.Intn(256))
+			}
+			checkMalformed(t, fmt.Sprintf("%s #%d", name, i), corrupted)
+		}
+	}
+}
+
+func FuzzExtractMetadata(f *testing.F) {
+	for _, data := range metadataFixtures(f) {
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		checkMalformed(t, "fuzz", data)
+	})
+}
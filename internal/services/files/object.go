@@ -0,0 +1,62 @@
+package fileservice
+
+import (
+	"astral/internal/domain/file"
+	"context"
+	"io"
+)
+
+// OpenObject открывает содержимое документа владельца owner для чтения по частям, без кэша и
+// без проверки доступа: ее выполняет вызывающий. Объект нужно закрыть
+func (s *FilesService) OpenObject(ID, owner string) (file.Object, error) {
+	const op = "service.files.OpenObject"
+	s.logger.Info("Usecase start", "func", op, "fileID", ID, "owner", owner)
+
+	// Контекст живет вместе с объектом: по нему выполняются все диапазонные чтения
+	ctx, cancel := context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
+
+	obj, err := s.repo.OpenObject(ctx, owner, ID)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &object{Object: obj, cancel: cancel}, nil
+}
+
+type object struct {
+	file.Object
+	cancel context.CancelFunc
+}
+
+func (o *object) Close() error {
+	defer o.cancel()
+	return o.Object.Close()
+}
+
+// OpenRaw открывает содержимое документа владельца owner в том виде, в каком оно хранится,
+// без распаковки и без проверки доступа. Возвращает размер хранимых данных и способ сжатия
+func (s *FilesService) OpenRaw(ID, owner string) (io.ReadCloser, int64, string, error) {
+	const op = "service.files.OpenRaw"
+	s.logger.Info("Usecase start", "func", op, "fileID", ID, "owner", owner)
+
+	ctx, cancel := context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
+
+	reader, size, encoding, err := s.repo.GetRawFile(ctx, owner, ID)
+	if err != nil {
+		cancel()
+		return nil, 0, "", err
+	}
+
+	return &rawReader{ReadCloser: reader, cancel: cancel}, size, encoding, nil
+}
+
+type rawReader struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *rawReader) Close() error {
+	defer r.cancel()
+	return r.ReadCloser.Close()
+}
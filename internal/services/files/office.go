@@ -0,0 +1,80 @@
+package fileservice
+
+import (
+	"archive/zip"
+	"astral/internal/domain/file"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MAX_OFFICE_PART - предельный распакованный размер части с сведениями о документе Office
+const MAX_OFFICE_PART = 1 << 20
+
+// coreProperties - docProps/core.xml документов Office Open XML (DOCX, XLSX, PPTX)
+type coreProperties struct {
+	Title    string `xml:"title"`
+	Subject  string `xml:"subject"`
+	Creator  string `xml:"creator"`
+	Created  string `xml:"created"`
+	Modified string `xml:"modified"`
+}
+
+// appProperties - docProps/app.xml: приложение и статистика, которую оно сохранило
+type appProperties struct {
+	Application string `xml:"Application"`
+	Pages       string `xml:"Pages"`
+	Slides      string `xml:"Slides"`
+}
+
+// officeMetadata читает свойства документа Office Open XML. Zip-архив без docProps - не документ
+// Office, для него ничего не извлекается
+func officeMetadata(r io.ReaderAt, size int64, metadata map[string]string) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return
+	}
+
+	var core coreProperties
+	if !readOfficePart(archive, "docProps/core.xml", &core) {
+		return
+	}
+	setMetadata(metadata, file.INTRINSIC_TITLE, core.Title)
+	setMetadata(metadata, file.INTRINSIC_SUBJECT, core.Subject)
+	setMetadata(metadata, file.INTRINSIC_AUTHOR, core.Creator)
+	setMetadata(metadata, file.INTRINSIC_CREATED, officeDate(core.Created))
+	setMetadata(metadata, file.INTRINSIC_MODIFIED, officeDate(core.Modified))
+
+	var app appProperties
+	if !readOfficePart(archive, "docProps/app.xml", &app) {
+		return
+	}
+	setMetadata(metadata, file.INTRINSIC_APPLICATION, app.Application)
+	for _, pages := range []string{app.Pages, app.Slides} {
+		if n, err := strconv.Atoi(strings.TrimSpace(pages)); err == nil && n > 0 {
+			metadata[file.INTRINSIC_PAGES] = strconv.Itoa(n)
+			break
+		}
+	}
+}
+
+func readOfficePart(archive *zip.Reader, name string, v any) bool {
+	part, err := archive.Open(name)
+	if err != nil {
+		return false
+	}
+	defer part.Close()
+
+	return xml.NewDecoder(io.LimitReader(part, MAX_OFFICE_PART)).Decode(v) == nil
+}
+
+// officeDate приводит дату W3CDTF из core.xml к RFC 3339
+func officeDate(value string) string {
+	date, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	if err != nil {
+		return ""
+	}
+	return date.Format(time.RFC3339)
+}
@@ -0,0 +1,524 @@
+package fileservice
+
+import (
+	"astral/internal/domain/file"
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	// MAX_PDF_OBJECT_STREAM - предельный размер распакованного потока объектов PDF
+	MAX_PDF_OBJECT_STREAM = 8 << 20
+	// MAX_PDF_OBJECT_STREAMS - сколько потоков объектов распаковывается в одном документе
+	MAX_PDF_OBJECT_STREAMS = 256
+)
+
+var (
+	pdfObjectPattern  = regexp.MustCompile(`(?:^|[^0-9])(\d+)\s+\d+\s+obj\b`)
+	pdfInfoPattern    = regexp.MustCompile(`/Info\s+(\d+)\s+\d+\s+R`)
+	pdfRootPattern    = regexp.MustCompile(`/Root\s+(\d+)\s+\d+\s+R`)
+	pdfEncryptPattern = regexp.MustCompile(`/Encrypt\s*(?:\d+\s+\d+\s+R|<<)`)
+	pdfRefPattern     = regexp.MustCompile(`^(\d+)\s+\d+\s+R$`)
+	pdfRefTailPattern = regexp.MustCompile(`^\s+\d+\s+R\b`)
+)
+
+// pdfDocument - объекты документа по номерам. При инкрементальных изменениях побеждает последнее
+// определение объекта, поэтому таблицы ссылок не нужны
+type pdfDocument struct {
+	objects map[int][]byte
+}
+
+// pdfMetadata читает из PDF число страниц и словарь Info. Документ больше предела разбора
+// читается с начала и с конца: словари каталога и Info почти всегда лежат там
+func pdfMetadata(r io.ReaderAt, size int64, metadata map[string]string) {
+	data, ok := readEnds(r, size)
+	if !ok {
+		return
+	}
+
+	doc := parsePDF(data)
+
+	if match := pdfRootPattern.FindAllSubmatch(data, -1); len(match) != 0 {
+		pages := 0
+		if catalog := pdfDict(doc.object(match[len(match)-1][1])); catalog != nil {
+			if root := pdfDict(doc.resolve(catalog["Pages"])); root != nil {
+				pages, _ = strconv.Atoi(string(doc.resolve(root["Count"])))
+			}
+		}
+		if pages <= 0 {
+			pages = doc.maxPageCount()
+		}
+		if pages > 0 {
+			metadata[file.INTRINSIC_PAGES] = strconv.Itoa(pages)
+		}
+	}
+
+	// Строки зашифрованного документа без ключа не прочитать
+	if pdfEncryptPattern.Match(data) {
+		return
+	}
+
+	match := pdfInfoPattern.FindAllSubmatch(data, -1)
+	if len(match) == 0 {
+		return
+	}
+	info := pdfDict(doc.object(match[len(match)-1][1]))
+	if info == nil {
+		return
+	}
+
+	setMetadata(metadata, file.INTRINSIC_TITLE, pdfString(doc.resolve(info["Title"])))
+	setMetadata(metadata, file.INTRINSIC_SUBJECT, pdfString(doc.resolve(info["Subject"])))
+	setMetadata(metadata, file.INTRINSIC_AUTHOR, pdfString(doc.resolve(info["Author"])))
+	application := pdfString(doc.resolve(info["Creator"]))
+	if application == "" {
+		application = pdfString(doc.resolve(info["Producer"]))
+	}
+	setMetadata(metadata, file.INTRINSIC_APPLICATION, application)
+	setMetadata(metadata, file.INTRINSIC_CREATED, pdfDate(pdfString(doc.resolve(info["CreationDate"]))))
+	setMetadata(metadata, file.INTRINSIC_MODIFIED, pdfDate(pdfString(doc.resolve(info["ModDate"]))))
+}
+
+// readEnds читает содержимое целиком, если оно укладывается в оставшийся предел разбора, иначе
+// по половине предела с начала и с конца
+func readEnds(r io.ReaderAt, size int64) ([]byte, bool) {
+	budget := size
+	if scan, ok := r.(*scanReader); ok && scan.left < size {
+		budget = scan.left
+	}
+
+	if budget >= size {
+		data, err := readAt(r, 0, size)
+		return data, err == nil
+	}
+
+	head, err := readAt(r, 0, budget/2)
+	if err != nil {
+		return nil, false
+	}
+	tail, err := readAt(r, size-budget/2, budget/2)
+	if err != nil {
+		return nil, false
+	}
+
+	return append(append(head, '\n'), tail...), true
+}
+
+func parsePDF(data []byte) *pdfDocument {
+	doc := &pdfDocument{objects: make(map[int][]byte)}
+
+	var streams [][]byte
+	for _, match := range pdfObjectPattern.FindAllSubmatchIndex(data, -1) {
+		num, err := strconv.Atoi(string(data[match[2]:match[3]]))
+		if err != nil {
+			continue
+		}
+
+		body := data[match[1]:]
+		if end := bytes.Index(body, []byte("endobj")); end >= 0 {
+			body = body[:end]
+		}
+		doc.objects[num] = body
+
+		if len(streams) < MAX_PDF_OBJECT_STREAMS && bytes.Contains(pdfDictBytes(body), []byte("/ObjStm")) {
+			streams = append(streams, body)
+		}
+	}
+
+	// Объекты из потоков не перекрывают одноименные обычные: обычные записываются позже
+	for _, stream := range streams {
+		for num, body := range parseObjectStream(stream) {
+			if _, ok := doc.objects[num]; !ok {
+				doc.objects[num] = body
+			}
+		}
+	}
+
+	return doc
+}
+
+// parseObjectStream распаковывает поток объектов (PDF 1.5): в начале N пар "номер смещение",
+// объекты начинаются с позиции First
+func parseObjectStream(body []byte) map[int][]byte {
+	dict := pdfDict(body)
+	if dict == nil || string(dict["Filter"]) != "/FlateDecode" || dict["DecodeParms"] != nil {
+		return nil
+	}
+	count, _ := strconv.Atoi(string(dict["N"]))
+	first, _ := strconv.Atoi(string(dict["First"]))
+	if count <= 0 || first <= 0 {
+		return nil
+	}
+
+	start := bytes.Index(body, []byte("stream"))
+	if start < 0 {
+		return nil
+	}
+	start += len("stream")
+	for start < len(body) && (body[start] == '\r' || body[start] == '\n') {
+		start++
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(body[start:]))
+	if err != nil {
+		return nil
+	}
+	defer zr.Close()
+	content, _ := io.ReadAll(io.LimitReader(zr, MAX_PDF_OBJECT_STREAM))
+	if first > len(content) {
+		return nil
+	}
+
+	header := bytes.Fields(content[:first])
+	objects := make(map[int][]byte, count)
+	for i := 0; i+1 < len(header) && i/2 < count; i += 2 {
+		num, err := strconv.Atoi(string(header[i]))
+		if err != nil {
+			return objects
+		}
+		offset, err := strconv.Atoi(string(header[i+1]))
+		if err != nil || first+offset > len(content) {
+			return objects
+		}
+
+		end := len(content)
+		if i+3 < len(header) {
+			if next, err := strconv.Atoi(string(header[i+3])); err == nil && first+next >= first+offset && first+next <= len(content) {
+				end = first + next
+			}
+		}
+		objects[num] = content[first+offset : end]
+	}
+
+	return objects
+}
+
+func (d *pdfDocument) object(num []byte) []byte {
+	n, err := strconv.Atoi(string(num))
+	if err != nil {
+		return nil
+	}
+	return d.objects[n]
+}
+
+// resolve заменяет косвенную ссылку "N G R" содержимым объекта
+func (d *pdfDocument) resolve(value []byte) []byte {
+	for i := 0; i < 8; i++ {
+		match := pdfRefPattern.FindSubmatch(bytes.TrimSpace(value))
+		if match == nil {
+			return bytes.TrimSpace(value)
+		}
+		value = d.object(match[1])
+	}
+	return nil
+}
+
+// maxPageCount - запасной путь, если каталог не найден: корень дерева страниц содержит
+// наибольший Count среди узлов /Pages
+func (d *pdfDocument) maxPageCount() int {
+	pages := 0
+	for _, body := range d.objects {
+		dict := pdfDict(body)
+		if dict == nil || string(dict["Type"]) != "/Pages" {
+			continue
+		}
+		if count, err := strconv.Atoi(string(d.resolve(dict["Count"]))); err == nil && count > pages {
+			pages = count
+		}
+	}
+	return pages
+}
+
+// pdfDictBytes возвращает исходный текст словаря в начале data
+func pdfDictBytes(data []byte) []byte {
+	start := bytes.Index(data, []byte("<<"))
+	if start < 0 {
+		return nil
+	}
+	end := skipPDFValue(data, start)
+	if end < 0 {
+		return nil
+	}
+	return data[start:end]
+}
+
+// pdfDict разбирает словарь в начале data: ключи верхнего уровня без косой черты, значения -
+// в исходном виде, косвенные ссылки целиком ("12 0 R")
+func pdfDict(data []byte) map[string][]byte {
+	start := bytes.Index(data, []byte("<<"))
+	if start < 0 {
+		return nil
+	}
+
+	result := make(map[string][]byte)
+	pos := start + 2
+	for {
+		pos = skipPDFSpace(data, pos)
+		if pos >= len(data) || data[pos] != '/' {
+			return result
+		}
+
+		keyEnd := skipPDFValue(data, pos)
+		if keyEnd < 0 {
+			return result
+		}
+		key := string(data[pos+1 : keyEnd])
+
+		valueStart := skipPDFSpace(data, keyEnd)
+		valueEnd := skipPDFValue(data, valueStart)
+		if valueEnd < 0 {
+			return result
+		}
+		result[key] = data[valueStart:valueEnd]
+		pos = valueEnd
+	}
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return isPDFSpace(c) || bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+func skipPDFSpace(data []byte, pos int) int {
+	for pos < len(data) {
+		switch {
+		case isPDFSpace(data[pos]):
+			pos++
+		case data[pos] == '%':
+			for pos < len(data) && data[pos] != '\n' && data[pos] != '\r' {
+				pos++
+			}
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+// skipPDFValue возвращает позицию за значением, которое начинается в pos, или -1 для
+// некорректного значения
+func skipPDFValue(data []byte, pos int) int {
+	if pos >= len(data) {
+		return -1
+	}
+
+	switch data[pos] {
+	case '(':
+		depth := 0
+		for i := pos; i < len(data); i++ {
+			switch data[i] {
+			case '\\':
+				i++
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+		}
+		return -1
+	case '<':
+		if pos+1 < len(data) && data[pos+1] == '<' {
+			for i := skipPDFSpace(data, pos+2); i < len(data); i = skipPDFSpace(data, i) {
+				if bytes.HasPrefix(data[i:], []byte(">>")) {
+					return i + 2
+				}
+				next := skipPDFValue(data, i)
+				if next < 0 {
+					return -1
+				}
+				i = next
+			}
+			return -1
+		}
+		end := bytes.IndexByte(data[pos:], '>')
+		if end < 0 {
+			return -1
+		}
+		return pos + end + 1
+	case '[':
+		for i := skipPDFSpace(data, pos+1); i < len(data); i = skipPDFSpace(data, i) {
+			if data[i] == ']' {
+				return i + 1
+			}
+			next := skipPDFValue(data, i)
+			if next < 0 {
+				return -1
+			}
+			i = next
+		}
+		return -1
+	case ')', '>', ']', '{', '}':
+		return -1
+	}
+
+	end := pos + 1
+	for end < len(data) && !isPDFDelimiter(data[end]) {
+		end++
+	}
+
+	// Целое число может начинать косвенную ссылку "N G R"
+	if match := pdfRefTailPattern.Find(data[end:min(end+32, len(data))]); match != nil {
+		if _, err := strconv.Atoi(string(data[pos:end])); err == nil {
+			return end + len(match)
+		}
+	}
+
+	return end
+}
+
+// pdfString декодирует строку PDF: литеральную в скобках или шестнадцатеричную в угловых скобках.
+// Текст в UTF-16 начинается с BOM, остальной - в PDFDocEncoding, которую приближаем Latin-1
+func pdfString(value []byte) string {
+	var raw []byte
+	switch {
+	case len(value) >= 2 && value[0] == '(' && value[len(value)-1] == ')':
+		raw = pdfLiteral(value[1 : len(value)-1])
+	case len(value) >= 2 && value[0] == '<' && value[len(value)-1] == '>':
+		raw = pdfHex(value[1 : len(value)-1])
+	default:
+		return ""
+	}
+
+	switch {
+	case bytes.HasPrefix(raw, []byte{0xFE, 0xFF}), bytes.HasPrefix(raw, []byte{0xFF, 0xFE}):
+		bigEndian := raw[0] == 0xFE
+		units := make([]uint16, 0, len(raw)/2)
+		for i := 2; i+1 < len(raw); i += 2 {
+			if bigEndian {
+				units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+			} else {
+				units = append(units, uint16(raw[i+1])<<8|uint16(raw[i]))
+			}
+		}
+		return string(utf16.Decode(units))
+	case bytes.HasPrefix(raw, []byte{0xEF, 0xBB, 0xBF}):
+		return string(bytes.ToValidUTF8(raw[3:], nil))
+	}
+
+	runes := make([]rune, len(raw))
+	for i, b := range raw {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+func pdfLiteral(value []byte) []byte {
+	result := make([]byte, 0, len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			result = append(result, value[i])
+			continue
+		}
+
+		i++
+		switch c := value[i]; c {
+		case 'n':
+			result = append(result, '\n')
+		case 'r':
+			result = append(result, '\r')
+		case 't':
+			result = append(result, '\t')
+		case 'b':
+			result = append(result, '\b')
+		case 'f':
+			result = append(result, '\f')
+		case '\r':
+			// Перенос строки после обратной косой черты - продолжение строки
+			if i+1 < len(value) && value[i+1] == '\n' {
+				i++
+			}
+		case '\n':
+		default:
+			if c >= '0' && c <= '7' {
+				n := 0
+				for j := 0; j < 3 && i < len(value) && value[i] >= '0' && value[i] <= '7'; j++ {
+					n = n*8 + int(value[i]-'0')
+					i++
+				}
+				i--
+				result = append(result, byte(n))
+				continue
+			}
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func pdfHex(value []byte) []byte {
+	digits := make([]byte, 0, len(value))
+	for _, c := range value {
+		if _, err := strconv.ParseUint(string(c), 16, 8); err == nil {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	result := make([]byte, len(digits)/2)
+	for i := range result {
+		b, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		result[i] = byte(b)
+	}
+	return result
+}
+
+// pdfDate переводит дату PDF "D:YYYYMMDDHHmmSSOHH'mm'" в RFC 3339. Все части после года
+// необязательны, без часового пояса время остается местным
+func pdfDate(value string) string {
+	value = string(bytes.TrimPrefix([]byte(value), []byte("D:")))
+
+	digits := 0
+	for digits < len(value) && digits < 14 && value[digits] >= '0' && value[digits] <= '9' {
+		digits++
+	}
+	if digits < 4 || digits%2 == 1 {
+		return ""
+	}
+
+	stamp := value[:digits] + "0101000000"[digits-4:]
+	date, err := time.Parse("20060102150405", stamp)
+	if err != nil {
+		return ""
+	}
+
+	zone := value[digits:]
+	switch {
+	case zone == "" || zone[0] != 'Z' && zone[0] != '+' && zone[0] != '-':
+		return date.Format("2006-01-02T15:04:05")
+	case zone[0] == 'Z':
+		return date.Format(time.RFC3339)
+	}
+
+	offset := []byte(zone[1:])
+	offset = bytes.ReplaceAll(offset, []byte("'"), nil)
+	if len(offset) < 2 {
+		return date.Format("2006-01-02T15:04:05")
+	}
+	hours, err := strconv.Atoi(string(offset[:2]))
+	if err != nil {
+		return ""
+	}
+	minutes := 0
+	if len(offset) >= 4 {
+		minutes, _ = strconv.Atoi(string(offset[2:4]))
+	}
+	seconds := hours*3600 + minutes*60
+	if zone[0] == '-' {
+		seconds = -seconds
+	}
+
+	return time.Date(date.Year(), date.Month(), date.Day(), date.Hour(), date.Minute(), date.Second(), 0, time.FixedZone("", seconds)).Format(time.RFC3339)
+}
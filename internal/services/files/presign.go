@@ -0,0 +1,160 @@
+package fileservice
+
+import (
+	"astral/internal/domain/event"
+	"astral/internal/domain/file"
+	uploadsrepo "astral/internal/repository/uploads"
+	"context"
+	"errors"
+	"time"
+)
+
+// FINALIZE_TIMEOUT - время на проверку содержимого прямой загрузки: объект читается целиком
+const FINALIZE_TIMEOUT = time.Minute * 10
+
+// PresignUpload создает документ в состоянии pending и возвращает ссылку, по которой клиент
+// загружает содержимое напрямую в хранилище. Документ становится доступным после FinalizeUpload.
+// Заявленные размер и SHA-256 сохраняются в PostgreSQL, а не в метаданных объекта: их клиент
+// переписывает сам, когда загружает содержимое
+func (s *FilesService) PresignUpload(fileData file.File) (*file.Presigned, error) {
+	const op = "service.files.PresignUpload"
+	s.logger.Info("Usecase start", "func", op, "filename", fileData.Name, "userID", fileData.User, "size", fileData.Size)
+
+	fileData.Metadata = file.WithoutIntrinsic(fileData.Metadata)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	res, err := s.repo.PresignPut(ctx, fileData.User, fileData)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	err = s.uploads.Create(ctx, uploadsrepo.Upload{
+		DocumentID: res.ID,
+		Login:      fileData.User,
+		Size:       int64(fileData.Size),
+		Checksum:   fileData.Checksum,
+	})
+	if err != nil {
+		// Без заявленных размера и суммы загрузку не завершить, заготовка документа не нужна
+		ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+		defer cancel()
+		if err := s.repo.DeleteFile(ctx, res.ID, fileData.User); err != nil {
+			s.logger.Warn("failed to delete pending file", "func", op, "fileID", res.ID, "error", err)
+		}
+		return nil, err
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+	s.cash.DelKeyByPrefix(ctx, listKeyPrefix(fileData.User))
+
+	return res, nil
+}
+
+// FinalizeUpload проверяет загруженное по ссылке содержимое: размер и SHA-256 сверяются с заявленными
+// при выдаче ссылки. После проверки из содержимого извлекаются метаданные, документ переходит в active
+// и пишется событие document.uploaded. Ожидает завершения только документ, для которого сервер
+// сохранил заявленные значения, состояние в метаданных объекта для этого не годится.
+// При расхождении документ остается pending, клиент может загрузить содержимое заново
+func (s *FilesService) FinalizeUpload(ID, userID string) (*file.File, error) {
+	const op = "service.files.FinalizeUpload"
+	s.logger.Info("Usecase start", "func", op, "fileID", ID, "userID", userID)
+
+	fileInfo, err := s.ownFileInfo(ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	upload, err := s.uploads.Get(ctx, ID, userID)
+	if errors.Is(err, uploadsrepo.ErrUploadNotFound) {
+		return nil, ErrNotPending
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), FINALIZE_TIMEOUT)
+	defer cancel()
+
+	size, checksum, etag, err := s.repo.HashObject(ctx, userID, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if size != upload.Size {
+		s.logger.Info("size mismatch", "func", op, "fileID", ID, "userID", userID, "expected", upload.Size, "uploaded", size)
+		return nil, ErrSizeMismatch
+	}
+
+	if upload.Checksum != "" && upload.Checksum != checksum {
+		s.logger.Info("checksum mismatch", "func", op, "fileID", ID, "userID", userID)
+		return nil, ErrChecksumMismatch
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), FINALIZE_TIMEOUT)
+	defer cancel()
+	intrinsic := s.storedMetadata(ctx, ID, userID)
+
+	fileInfo.Status = file.StatusActive
+	fileInfo.Size = int(size)
+	fileInfo.Checksum = checksum
+	staged, err := s.stageEvent(event.TypeDocumentUploaded, *fileInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	res, err := s.repo.FinalizeObject(ctx, userID, ID, checksum, etag, intrinsic)
+	if err != nil {
+		s.discardEvent(staged)
+		return nil, err
+	}
+
+	// Документ уже active, оставшаяся запись только позволит проверить его повторно
+	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+	if err := s.uploads.Delete(ctx, ID); err != nil {
+		s.logger.Warn("failed to delete pending upload", "func", op, "fileID", ID, "error", err)
+	}
+
+	s.invalidate(ID, userID)
+	s.confirmEvent(staged, *res)
+
+	return res, nil
+}
+
+// PresignDownload возвращает ссылку на скачивание содержимого собственного документа напрямую
+// из хранилища. Документ без проверенного содержимого так не отдается
+func (s *FilesService) PresignDownload(ID, userID string) (*file.File, *file.Presigned, error) {
+	const op = "service.files.PresignDownload"
+	s.logger.Info("Usecase start", "func", op, "fileID", ID, "userID", userID)
+
+	fileInfo, err := s.ownFileInfo(ID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if fileInfo.Status != file.StatusActive {
+		return nil, nil, ErrNotActive
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	res, err := s.repo.PresignGet(ctx, userID, *fileInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fileInfo, res, nil
+}
@@ -0,0 +1,252 @@
+package fileservice
+
+import (
+	"astral/internal/domain/event"
+	"astral/internal/domain/file"
+	transferrepo "astral/internal/repository/transfer"
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// CopyFile копирует документ владельца userID пользователю toUserID, себе при пустом toUserID,
+// под новым ID. Содержимое копируется на стороне хранилища без скачивания, JSON данные
+// переносятся. Копия не наследует доступы, комментарии, теги и блокировку исходного документа
+func (s *FilesService) CopyFile(ID, userID, toUserID, name string) (*file.File, error) {
+	const op = "service.files.CopyFile"
+	s.logger.Info("Usecase start", "func", op, "fileID", ID, "userID", userID, "to", toUserID)
+
+	if toUserID == "" {
+		toUserID = userID
+	}
+
+	fileInfo, err := s.ownFileInfo(ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if toUserID != userID {
+		if err := s.checkUser(toUserID); err != nil {
+			return nil, err
+		}
+	}
+
+	info := *fileInfo
+	info.ID = uuid.New().String()
+	info.User = toUserID
+	info.Grant = nil
+	info.Public = false
+	if name != "" {
+		info.Name = name
+	}
+
+	staged, err := s.stageEvent(event.TypeDocumentUploaded, info)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
+	defer cancel()
+
+	res, err := s.repo.CopyFile(ctx, userID, ID, toUserID, info)
+	if err != nil {
+		s.discardEvent(staged)
+		return nil, err
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	record, err := s.data.Get(ctx, ID)
+	if err != nil {
+		// Копия уже создана и остается, событие о ней не теряется
+		s.confirmEvent(staged, *res)
+		return nil, err
+	}
+	if record != nil {
+		if err := s.saveData(*res, record.Data); err != nil {
+			s.discardEvent(staged)
+			return nil, err
+		}
+		res.Data = record.Data
+	}
+
+	s.invalidate(res.ID, toUserID)
+	s.confirmEvent(staged, *res)
+
+	return res, nil
+}
+
+// TransferFile передает документ владельца userID пользователю toUserID. ID, доступы, JSON
+// данные и комментарии сохраняются, личные теги прежнего владельца снимаются
+func (s *FilesService) TransferFile(ID, userID, toUserID string) (*file.File, error) {
+	const op = "service.files.TransferFile"
+	s.logger.Info("Usecase start", "func", op, "fileID", ID, "userID", userID, "to", toUserID)
+
+	if toUserID == userID {
+		return nil, ErrSameOwner
+	}
+
+	fileInfo, err := s.ownFileInfo(ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkUser(toUserID); err != nil {
+		return nil, err
+	}
+
+	res, err := s.transferDocument(*fileInfo, toUserID)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(ID, userID, toUserID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	record, err := s.data.Get(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil {
+		res.Data = record.Data
+		res.Revision = record.Revision
+	}
+
+	return res, nil
+}
+
+// TransferAll передает все документы fromUserID пользователю toUserID, например когда
+// сотрудник уходит. Документы передаются по одному, ошибки собираются в итог
+func (s *FilesService) TransferAll(fromUserID, toUserID string) (*file.Transfer, error) {
+	const op = "service.files.TransferAll"
+	s.logger.Info("Usecase start", "func", op, "from", fromUserID, "to", toUserID)
+
+	if toUserID == fromUserID {
+		return nil, ErrSameOwner
+	}
+
+	if err := s.checkUser(toUserID); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	// Список берется из хранилища, а не из кэша: в кэше у документов нет владельца
+	docs, err := s.repo.ListUserFiles(ctx, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &file.Transfer{
+		From:        fromUserID,
+		To:          toUserID,
+		Transferred: []string{},
+	}
+	for _, doc := range docs {
+		if _, err := s.transferDocument(doc, toUserID); err != nil {
+			result.Failed = append(result.Failed, file.TransferError{ID: doc.ID, Error: err.Error()})
+			continue
+		}
+
+		s.invalidate(doc.ID)
+		result.Transferred = append(result.Transferred, doc.ID)
+	}
+
+	if len(result.Transferred) > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+		defer cancel()
+		s.cash.DelKeyByPrefix(ctx, listKeyPrefix(fromUserID))
+		s.cash.DelKeyByPrefix(ctx, listKeyPrefix(toUserID))
+	}
+
+	s.logger.Info("documents transferred", "func", op, "from", fromUserID, "to", toUserID, "transferred", len(result.Transferred), "failed", len(result.Failed))
+
+	return result, nil
+}
+
+// transferDocument копирует объект под тем же ID к новому владельцу, переносит записи
+// PostgreSQL и удаляет прежний объект. Если перенос записей не удался, копия удаляется.
+// Повтор после ошибки безопасен: копия перезаписывается, записи уже принадлежат to
+func (s *FilesService) transferDocument(doc file.File, to string) (*file.File, error) {
+	const op = "service.files.transferDocument"
+
+	transferred, err := event.New(event.TypeDocumentTransferred, to, transferPayload(doc, to))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
+	defer cancel()
+
+	res, err := s.repo.CopyFile(ctx, doc.User, doc.ID, to, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.transfer.TransferDocument(ctx, doc.ID, doc.User, to, transferred); err != nil {
+		if delErr := s.repo.DeleteFile(ctx, doc.ID, to); delErr != nil {
+			s.logger.Error("failed to delete document copy", "func", op, "fileID", doc.ID, "to", to, "error", delErr)
+		}
+
+		if errors.Is(err, transferrepo.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.repo.DeleteFile(ctx, doc.ID, doc.User); err != nil {
+		s.logger.Error("failed to delete transferred document", "func", op, "fileID", doc.ID, "from", doc.User, "error", err)
+		return nil, err
+	}
+	s.dropVariants(doc.User, doc.ID)
+
+	return res, nil
+}
+
+// ownFileInfo возвращает документ, только если userID - его владелец. Блокировка не
+// проверяется: копия и передача не меняют содержимое
+func (s *FilesService) ownFileInfo(ID, userID string) (*file.File, error) {
+	const op = "service.files.ownFileInfo"
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	fileInfo, err := s.repo.GetFileInfo(ctx, userID, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if fileInfo.User != userID {
+		s.logger.Info("access denied", "func", op, "fileID", ID, "userID", userID)
+		return nil, ErrAccessDenied
+	}
+
+	return fileInfo, nil
+}
+
+func (s *FilesService) checkUser(userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	exists, err := s.transfer.UserExists(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func transferPayload(doc file.File, to string) event.DocumentPayload {
+	payload := documentPayload(doc)
+	payload.Owner = to
+	payload.PreviousOwner = doc.User
+
+	return payload
+}
@@ -0,0 +1,182 @@
+package fileservice
+
+import (
+	"astral/internal/domain/event"
+	"astral/internal/domain/file"
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// UpdateData заменяет JSON данные документа владельца. Данные меняются, только если
+// их ревизия равна revision, иначе возвращается docdatarepo.ErrRevisionMismatch
+func (s *FilesService) UpdateData(ID, userID string, data json.RawMessage, revision int64) (*file.File, error) {
+	const op = "service.files.UpdateData"
+	s.logger.Info("Usecase start", "func", op, "fileID", ID, "userID", userID, "revision", revision)
+
+	fileInfo, err := s.ownedFileInfo(ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	staged, err := s.stageEvent(event.TypeDocumentUpdated, *fileInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	fileInfo.Revision, err = s.data.Update(ctx, ID, data, revision)
+	if err != nil {
+		s.discardEvent(staged)
+		return nil, err
+	}
+	fileInfo.Data = data
+
+	s.invalidate(ID, userID)
+	s.confirmEvent(staged, *fileInfo)
+
+	return fileInfo, nil
+}
+
+// UpdateFileInfo меняет имя, доступы и метаданные документа владельца, не трогая содержимое и ID
+func (s *FilesService) UpdateFileInfo(ID, userID string, info file.File) (*file.File, error) {
+	const op = "service.files.UpdateFileInfo"
+	s.logger.Info("Usecase start", "func", op, "fileID", ID, "userID", userID)
+
+	fileInfo, err := s.ownedFileInfo(ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	info.ID = ID
+	info.File = fileInfo.File
+	// Извлеченные метаданные меняются только вместе с содержимым, хранилище переносит их само
+	info.Metadata = file.WithoutIntrinsic(info.Metadata)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	staged, err := s.stageEvent(event.TypeDocumentUpdated, info)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.repo.UpdateFileInfo(ctx, userID, info)
+	if err != nil {
+		s.discardEvent(staged)
+		return nil, err
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	record, err := s.data.Get(ctx, ID)
+	if err != nil {
+		// Изменение уже сохранено, событие о нем не теряется
+		s.confirmEvent(staged, *res)
+		return nil, err
+	}
+	if record != nil {
+		res.Data = record.Data
+		res.Revision = record.Revision
+	}
+
+	s.invalidate(ID, userID)
+	s.confirmEvent(staged, *res)
+
+	return res, nil
+}
+
+// ReplaceFile загружает новое содержимое документа под тем же ID. Имя, доступы, метаданные и
+// JSON данные сохраняются, извлеченные из содержимого метаданные извлекаются заново, блокировка
+// загрузившего снимается: новая версия сдана
+func (s *FilesService) ReplaceFile(ID, userID string, content io.Reader, size int, digest *file.Digest) (*file.File, error) {
+	const op = "service.files.ReplaceFile"
+	s.logger.Info("Usecase start", "func", op, "fileID", ID, "userID", userID, "size", size)
+
+	fileInfo, err := s.ownedFileInfo(ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fileInfo.Size = size
+	fileInfo.Reader = content
+	fileInfo.Digest = digest
+	if err := s.checksum(fileInfo); err != nil {
+		return nil, err
+	}
+	s.intrinsicMetadata(fileInfo)
+
+	ctx, cancel := context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
+	defer cancel()
+
+	staged, err := s.stageEvent(event.TypeDocumentUpdated, *fileInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.repo.CreateFile(ctx, userID, *fileInfo)
+	if err != nil {
+		s.discardEvent(staged)
+		return nil, err
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	record, err := s.data.Get(ctx, ID)
+	if err != nil {
+		// Изменение уже сохранено, событие о нем не теряется
+		s.confirmEvent(staged, *res)
+		return nil, err
+	}
+	if record != nil {
+		res.Data = record.Data
+		res.Revision = record.Revision
+	}
+
+	s.releaseLock(ID, userID)
+	s.invalidate(ID, userID)
+	s.dropVariants(userID, ID)
+	s.confirmEvent(staged, *res)
+
+	return res, nil
+}
+
+// ownedFileInfo возвращает документ для изменения: только владельцу и только если документ
+// не заблокирован другим пользователем
+func (s *FilesService) ownedFileInfo(ID, userID string) (*file.File, error) {
+	const op = "service.files.ownedFileInfo"
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	fileInfo, err := s.repo.GetFileInfo(ctx, userID, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if fileInfo.User != userID {
+		s.logger.Info("access denied", "func", op, "fileID", ID, "userID", userID)
+		return nil, ErrAccessDenied
+	}
+
+	if err := s.CheckLock(ID, userID); err != nil {
+		return nil, err
+	}
+
+	return fileInfo, nil
+}
+
+// invalidate сбрасывает кэш документа и списков пользователей userIDs после изменения
+func (s *FilesService) invalidate(ID string, userIDs ...string) {
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	s.cash.DelKey(ctx, generateKeyForCash("file:", ID))
+	for _, userID := range userIDs {
+		s.cash.DelKeyByPrefix(ctx, listKeyPrefix(userID))
+	}
+}
@@ -0,0 +1,16 @@
+package imagesservice
+
+import "errors"
+
+var (
+	ErrAccessDenied   = errors.New("access denied")
+	ErrNotImage       = errors.New("document is not an image")
+	ErrInvalidImage   = errors.New("image is corrupted or has unsupported format")
+	ErrImageTooLarge  = errors.New("source image has too many pixels")
+	ErrInvalidSize    = errors.New("width and height must be within the allowed limits")
+	ErrInvalidFit     = errors.New("fit must be contain, cover or fill")
+	ErrInvalidFormat  = errors.New("format must be jpeg, png or gif")
+	ErrInvalidQuality = errors.New("quality must be between 1 and 100")
+	ErrInvalidCrop    = errors.New("crop must be x,y,width,height inside the image")
+	ErrBusy           = errors.New("too many image transformations in progress, try again later")
+)
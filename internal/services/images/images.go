@@ -0,0 +1,288 @@
+package imagesservice
+
+import (
+	"astral/env"
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	"astral/internal/domain/imaging"
+	filesrepo "astral/internal/repository/files"
+	imagesrepo "astral/internal/repository/images"
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_TIMEOUT = time.Second * 5
+	CACHE_TIMEOUT   = time.Second * 30
+)
+
+// ImagesService строит изменённые, обрезанные и перекодированные варианты изображений. Готовые
+// варианты кэшируются по версии содержимого и параметрам, одновременно строится не больше
+// MaxConcurrency вариантов
+type ImagesService struct {
+	files  contracts.FilesInterface
+	images imagesrepo.ImagesRepo
+	cfg    env.Images
+	slots  chan struct{}
+	logger *slog.Logger
+}
+
+func NewImagesService(files contracts.FilesInterface, images imagesrepo.ImagesRepo, cfg env.Images, logger *slog.Logger) *ImagesService {
+	concurrency := cfg.MaxConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &ImagesService{
+		files:  files,
+		images: images,
+		cfg:    cfg,
+		slots:  make(chan struct{}, concurrency),
+		logger: logger.With("service", "ImagesService"),
+	}
+}
+
+// Transform возвращает вариант изображения owner/ID, доступного пользователю на чтение
+func (s *ImagesService) Transform(login, owner, ID string, opts imaging.Options) (*file.File, *imaging.Variant, error) {
+	const op = "services.images.Transform"
+	s.logger.Info("Usecase start", "func", op, "login", login, "owner", owner, "fileID", ID, "options", opts.Key())
+
+	doc, err := s.document(login, owner, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts, err = s.normalize(opts, *doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := variantKey(*doc, opts)
+	ctx, cancel := context.WithTimeout(context.Background(), CACHE_TIMEOUT)
+	defer cancel()
+
+	variant, err := s.images.GetVariant(ctx, key)
+	if err != nil {
+		// Недоступный кэш не мешает построить вариант заново
+		s.logger.Warn("failed to read cached variant", "func", op, "key", key, "error", err)
+	}
+	if variant != nil {
+		return doc, variant, nil
+	}
+
+	if err := s.acquire(); err != nil {
+		s.logger.Warn("transformation queue is full", "func", op, "fileID", ID)
+		return nil, nil, err
+	}
+	defer s.release()
+
+	variant, err = s.render(*doc, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cached := *variant
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), CACHE_TIMEOUT)
+		defer cancel()
+		if err := s.images.PutVariant(ctx, key, cached); err != nil {
+			s.logger.Warn("failed to cache variant", "func", op, "key", key, "error", err)
+		}
+	}()
+
+	return doc, variant, nil
+}
+
+// render читает исходное изображение и строит вариант. Размер проверяется по заголовку до
+// декодирования, чтобы не распаковывать в память изображения сверх лимитов
+func (s *ImagesService) render(doc file.File, opts imaging.Options) (*imaging.Variant, error) {
+	const op = "services.images.render"
+
+	obj, err := s.files.OpenObject(doc.ID, doc.User)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	width, height, err := decodeConfig(obj)
+	if err != nil {
+		s.logger.Info("failed to read image header", "func", op, "fileID", doc.ID, "error", err)
+		return nil, err
+	}
+	if int64(width)*int64(height) > int64(s.cfg.MaxSourcePixels) {
+		return nil, ErrImageTooLarge
+	}
+
+	plan, err := newPlan(width, height, opts)
+	if err != nil {
+		return nil, err
+	}
+	if plan.width > s.cfg.MaxWidth || plan.height > s.cfg.MaxHeight {
+		return nil, ErrInvalidSize
+	}
+
+	img, err := decode(obj)
+	if err != nil {
+		s.logger.Info("failed to decode image", "func", op, "fileID", doc.ID, "error", err)
+		return nil, err
+	}
+
+	data, err := encode(plan.apply(img, opts.Format), opts)
+	if err != nil {
+		s.logger.Error("failed to encode image", "func", op, "fileID", doc.ID, "error", err)
+		return nil, err
+	}
+
+	return &imaging.Variant{
+		Data:   data,
+		Mime:   imaging.MimeOf(opts.Format),
+		Width:  plan.width,
+		Height: plan.height,
+	}, nil
+}
+
+// acquire занимает место среди одновременных преобразований. Если место не освободилось за
+// QueueTimeout, запрос отклоняется, а не копится в очереди
+func (s *ImagesService) acquire() error {
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(s.cfg.QueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return ErrBusy
+	}
+}
+
+func (s *ImagesService) release() {
+	<-s.slots
+}
+
+// normalize проверяет параметры и подставляет значения по умолчанию, чтобы равные
+// преобразования давали один ключ кэша
+func (s *ImagesService) normalize(opts imaging.Options, doc file.File) (imaging.Options, error) {
+	if opts.Width < 0 || opts.Height < 0 || opts.Width > s.cfg.MaxWidth || opts.Height > s.cfg.MaxHeight {
+		return opts, ErrInvalidSize
+	}
+
+	switch opts.Fit {
+	case "":
+		opts.Fit = imaging.FitContain
+	case imaging.FitContain, imaging.FitCover, imaging.FitFill:
+	default:
+		return opts, ErrInvalidFit
+	}
+	// Способ вписывания важен, только когда заданы обе стороны
+	if opts.Width == 0 || opts.Height == 0 {
+		opts.Fit = imaging.FitContain
+	}
+
+	switch opts.Format = strings.ToLower(opts.Format); opts.Format {
+	case "":
+		opts.Format = sourceFormat(doc)
+	case "jpg":
+		opts.Format = imaging.FormatJPEG
+	case imaging.FormatJPEG, imaging.FormatPNG, imaging.FormatGIF:
+	default:
+		return opts, ErrInvalidFormat
+	}
+
+	if opts.Format != imaging.FormatJPEG {
+		opts.Quality = 0
+	} else if opts.Quality == 0 {
+		opts.Quality = s.cfg.Quality
+	}
+	if opts.Quality < 0 || opts.Quality > 100 {
+		return opts, ErrInvalidQuality
+	}
+
+	if crop := opts.Crop; crop != nil && (crop.X < 0 || crop.Y < 0 || crop.Width <= 0 || crop.Height <= 0) {
+		return opts, ErrInvalidCrop
+	}
+
+	return opts, nil
+}
+
+// document находит изображение владельца, доступное пользователю на чтение
+func (s *ImagesService) document(login, owner, ID string) (*file.File, error) {
+	const op = "services.images.document"
+
+	if owner == "" {
+		owner = login
+	}
+
+	docs, err := s.files.GetFilesByUser(owner, contracts.FilterData{})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range docs {
+		if docs[i].ID != ID {
+			continue
+		}
+
+		doc := docs[i]
+		// Список из кэша приходит без владельца
+		doc.User = owner
+		if doc.User != login && !doc.Public && !slices.Contains(doc.Grant, login) {
+			s.logger.Info("access denied", "func", op, "fileID", ID, "login", login)
+			return nil, ErrAccessDenied
+		}
+
+		if !doc.File || doc.IsCollection() || doc.Status != file.StatusActive || !strings.HasPrefix(documentMime(doc), "image/") {
+			return nil, ErrNotImage
+		}
+
+		return &doc, nil
+	}
+
+	return nil, filesrepo.ErrFileNotFound
+}
+
+// variantKey - owner/ID/версия содержимого/параметры. Версия меняется при замене содержимого,
+// поэтому старые варианты перестают находиться; сервис документов удаляет их по префиксу owner/ID/
+func variantKey(doc file.File, opts imaging.Options) string {
+	version := doc.Checksum
+	if version == "" {
+		var modified int64
+		if doc.CreatedAt != nil {
+			modified = doc.CreatedAt.UnixNano()
+		}
+		version = fmt.Sprintf("%d-%d", modified, doc.Size)
+	}
+
+	return path.Join(doc.User, doc.ID, version, opts.Key())
+}
+
+// sourceFormat - формат варианта по умолчанию: формат исходного изображения, если его можно
+// записать, иначе PNG
+func sourceFormat(doc file.File) string {
+	switch documentMime(doc) {
+	case "image/jpeg":
+		return imaging.FormatJPEG
+	case "image/gif":
+		return imaging.FormatGIF
+	default:
+		return imaging.FormatPNG
+	}
+}
+
+func documentMime(doc file.File) string {
+	if doc.Mime != "" && doc.Mime != "application/octet-stream" {
+		return doc.Mime
+	}
+	return mime.TypeByExtension(strings.ToLower(path.Ext(doc.Name)))
+}
@@ -0,0 +1,140 @@
+package imagesservice
+
+import (
+	"astral/internal/domain/imaging"
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/draw"
+
+	// Форматы, которые можно только прочитать: варианты из них записываются в PNG
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// plan - что сделать с исходным изображением: вырезать source и масштабировать в width x height
+type plan struct {
+	source image.Rectangle
+	width  int
+	height int
+}
+
+// newPlan рассчитывает преобразование по размеру исходного изображения, не декодируя его
+func newPlan(srcWidth, srcHeight int, opts imaging.Options) (plan, error) {
+	source := image.Rect(0, 0, srcWidth, srcHeight)
+	if crop := opts.Crop; crop != nil {
+		area := image.Rect(crop.X, crop.Y, crop.X+crop.Width, crop.Y+crop.Height)
+		if !area.In(source) {
+			return plan{}, ErrInvalidCrop
+		}
+		source = area
+	}
+
+	w, h := source.Dx(), source.Dy()
+	p := plan{source: source, width: w, height: h}
+
+	switch {
+	case opts.Width == 0 && opts.Height == 0:
+	case opts.Height == 0:
+		p.width, p.height = opts.Width, scaled(h, opts.Width, w)
+	case opts.Width == 0:
+		p.width, p.height = scaled(w, opts.Height, h), opts.Height
+	case opts.Fit == imaging.FitFill:
+		p.width, p.height = opts.Width, opts.Height
+	case opts.Fit == imaging.FitCover:
+		// Вырезаем из середины область с пропорциями рамки и растягиваем ее на всю рамку
+		p.width, p.height = opts.Width, opts.Height
+		if w*opts.Height > h*opts.Width {
+			cropWidth := scaled(h, opts.Width, opts.Height)
+			offset := (w - cropWidth) / 2
+			p.source = image.Rect(source.Min.X+offset, source.Min.Y, source.Min.X+offset+cropWidth, source.Max.Y)
+		} else {
+			cropHeight := scaled(w, opts.Height, opts.Width)
+			offset := (h - cropHeight) / 2
+			p.source = image.Rect(source.Min.X, source.Min.Y+offset, source.Max.X, source.Min.Y+offset+cropHeight)
+		}
+	default:
+		scale := math.Min(float64(opts.Width)/float64(w), float64(opts.Height)/float64(h))
+		p.width = max(1, int(math.Round(float64(w)*scale)))
+		p.height = max(1, int(math.Round(float64(h)*scale)))
+	}
+
+	return p, nil
+}
+
+// apply строит вариант. Для JPEG прозрачные области заливаются белым, иначе они станут черными
+func (p plan) apply(src image.Image, format string) image.Image {
+	bounds := src.Bounds()
+	source := p.source.Add(bounds.Min)
+	dst := image.NewNRGBA(image.Rect(0, 0, p.width, p.height))
+	if format == imaging.FormatJPEG {
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	}
+
+	op := draw.Over
+	if format != imaging.FormatJPEG {
+		op = draw.Src
+	}
+
+	if source.Dx() == p.width && source.Dy() == p.height {
+		draw.Draw(dst, dst.Bounds(), src, source.Min, op)
+		return dst
+	}
+
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, source, op, nil)
+	return dst
+}
+
+// decodeConfig читает только заголовок изображения и возвращает читатель в начало
+func decodeConfig(r io.ReadSeeker) (int, int, error) {
+	config, _, err := image.DecodeConfig(r)
+	if err != nil || config.Width <= 0 || config.Height <= 0 {
+		return 0, 0, ErrInvalidImage
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+
+	return config.Width, config.Height, nil
+}
+
+// decode читает изображение, у GIF - только первый кадр
+func decode(r io.Reader) (image.Image, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	return img, nil
+}
+
+func encode(img image.Image, opts imaging.Options) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+
+	switch opts.Format {
+	case imaging.FormatJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: opts.Quality})
+	case imaging.FormatGIF:
+		err = gif.Encode(&buf, img, nil)
+	default:
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// scaled возвращает value * num / den с округлением, не меньше 1
+func scaled(value, num, den int) int {
+	return max(1, int(math.Round(float64(value)*float64(num)/float64(den))))
+}
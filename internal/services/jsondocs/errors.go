@@ -0,0 +1,10 @@
+package jsondocsservice
+
+import "errors"
+
+var (
+	ErrDocumentNotFound = errors.New("json document not found")
+	ErrAccessDenied     = errors.New("access denied")
+	ErrInvalidPatch     = errors.New("invalid json patch")
+	ErrPatchFailed      = errors.New("json patch cannot be applied")
+)
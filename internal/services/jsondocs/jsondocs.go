@@ -0,0 +1,228 @@
+package jsondocsservice
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	docdatarepo "astral/internal/repository/docdata"
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+const (
+	DEFAULT_TIMEOUT = time.Second * 5
+)
+
+// JSONDocsService работает с JSON документами: это обычные документы без содержимого,
+// поэтому доступы, кэш и события у них те же, что у файлов. revision равный 0 означает
+// "текущая ревизия", то есть изменение без проверки конкурентных правок
+type JSONDocsService struct {
+	files  contracts.FilesInterface
+	data   docdatarepo.DataRepo
+	logger *slog.Logger
+}
+
+func NewJSONDocsService(files contracts.FilesInterface, data docdatarepo.DataRepo, logger *slog.Logger) *JSONDocsService {
+	return &JSONDocsService{
+		files:  files,
+		data:   data,
+		logger: logger,
+	}
+}
+
+func (s *JSONDocsService) Create(login string, doc file.File) (*file.File, error) {
+	const op = "services.jsondocs.Create"
+	s.logger.Info("Usecase start", "func", op, "login", login, "name", doc.Name)
+
+	return s.files.UploadFiles(file.File{
+		Name:     doc.Name,
+		File:     false,
+		Public:   doc.Public,
+		Mime:     file.JSON_MIME,
+		Grant:    doc.Grant,
+		Metadata: map[string]string{file.JSON_METADATA_KEY: "true"},
+		Data:     doc.Data,
+		Reader:   bytes.NewReader(nil),
+		User:     login,
+	})
+}
+
+// List возвращает JSON документы владельца owner, видимые пользователю: свои целиком, чужие - только публичные и выданные
+func (s *JSONDocsService) List(login, owner string, filter contracts.FilterData) ([]file.File, error) {
+	const op = "services.jsondocs.List"
+	s.logger.Info("Usecase start", "func", op, "login", login, "owner", owner)
+
+	if owner == "" {
+		owner = login
+	}
+
+	// Лимит применяем после отбора JSON документов, иначе его съедят файлы
+	limit := filter.Limit
+	filter.Limit = 0
+
+	docs, err := s.files.GetFilesByUser(owner, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []file.File{}
+	for _, doc := range docs {
+		if !doc.IsJSON() || !canRead(login, doc) {
+			continue
+		}
+
+		res = append(res, doc)
+		if limit > 0 && len(res) == limit {
+			break
+		}
+	}
+
+	return res, nil
+}
+
+func (s *JSONDocsService) Get(login, ID string) (*file.File, error) {
+	const op = "services.jsondocs.Get"
+	s.logger.Info("Usecase start", "func", op, "login", login, "docID", ID)
+
+	doc, err := s.document(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canRead(login, *doc) {
+		s.logger.Info("access denied", "func", op, "docID", ID, "login", login)
+		return nil, ErrAccessDenied
+	}
+
+	return doc, nil
+}
+
+// Replace заменяет данные документа и, если они изменились, имя и доступы
+func (s *JSONDocsService) Replace(login, ID string, doc file.File, revision int64) (*file.File, error) {
+	const op = "services.jsondocs.Replace"
+	s.logger.Info("Usecase start", "func", op, "login", login, "docID", ID, "revision", revision)
+
+	current, err := s.ownedDocument(login, ID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	// Сначала данные: их запись проверяет ревизию и отсекает конкурентную правку до смены метаданных
+	res, err := s.files.UpdateData(ID, login, doc.Data, current.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.Name == "" {
+		doc.Name = current.Name
+	}
+	if doc.Name == current.Name && doc.Public == current.Public && slices.Equal(doc.Grant, current.Grant) {
+		return res, nil
+	}
+
+	return s.files.UpdateFileInfo(ID, login, file.File{
+		Name:     doc.Name,
+		Public:   doc.Public,
+		Grant:    doc.Grant,
+		Metadata: current.Metadata,
+	})
+}
+
+// Patch применяет к данным документа JSON Patch (RFC 6902). Пути в патче отсчитываются от корня данных
+func (s *JSONDocsService) Patch(login, ID string, patch []byte, revision int64) (*file.File, error) {
+	const op = "services.jsondocs.Patch"
+	s.logger.Info("Usecase start", "func", op, "login", login, "docID", ID, "revision", revision)
+
+	operations, err := jsonpatch.DecodePatch(patch)
+	if err != nil {
+		s.logger.Info("invalid json patch", "func", op, "docID", ID, "error", err)
+		return nil, ErrInvalidPatch
+	}
+
+	current, err := s.ownedDocument(login, ID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := operations.Apply(current.Data)
+	if err != nil {
+		s.logger.Info("failed to apply json patch", "func", op, "docID", ID, "error", err)
+		return nil, ErrPatchFailed
+	}
+
+	return s.files.UpdateData(ID, login, data, current.Revision)
+}
+
+func (s *JSONDocsService) Delete(login, ID string, revision int64) (*file.File, error) {
+	const op = "services.jsondocs.Delete"
+	s.logger.Info("Usecase start", "func", op, "login", login, "docID", ID, "revision", revision)
+
+	if _, err := s.ownedDocument(login, ID, revision); err != nil {
+		return nil, err
+	}
+
+	return s.files.DeleteFile(ID, login)
+}
+
+// document находит JSON документ по ID: владелец берется из данных, объект в хранилище лежит под его логином
+func (s *JSONDocsService) document(ID string) (*file.File, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	record, err := s.data.Get(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, ErrDocumentNotFound
+	}
+
+	doc, err := s.files.GetFileByID(ID, record.Login)
+	if err != nil {
+		return nil, err
+	}
+	if closer, ok := doc.Reader.(io.Closer); ok {
+		closer.Close()
+	}
+	if !doc.IsJSON() {
+		return nil, ErrDocumentNotFound
+	}
+	doc.Reader = nil
+
+	return doc, nil
+}
+
+// ownedDocument возвращает документ для изменения владельцем. Ревизия сверяется заранее,
+// чтобы не применять патч к устаревшим данным, окончательно ее проверяет запись в базу
+func (s *JSONDocsService) ownedDocument(login, ID string, revision int64) (*file.File, error) {
+	const op = "services.jsondocs.ownedDocument"
+
+	doc, err := s.document(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.User != login {
+		s.logger.Info("access denied", "func", op, "docID", ID, "login", login)
+		if canRead(login, *doc) {
+			return nil, ErrAccessDenied
+		}
+		return nil, ErrDocumentNotFound
+	}
+
+	if revision != 0 && revision != doc.Revision {
+		s.logger.Info("document revision mismatch", "func", op, "docID", ID, "expected", revision, "actual", doc.Revision)
+		return nil, docdatarepo.ErrRevisionMismatch
+	}
+
+	return doc, nil
+}
+
+func canRead(login string, doc file.File) bool {
+	return doc.User == login || doc.Public || slices.Contains(doc.Grant, login)
+}
@@ -0,0 +1,14 @@
+package linksservice
+
+import "errors"
+
+var (
+	ErrLinksDisabled      = errors.New("signed links are disabled")
+	ErrInvalidSignature   = errors.New("invalid link signature")
+	ErrLinkExpired        = errors.New("link has expired")
+	ErrAddressMismatch    = errors.New("link is not valid for this address")
+	ErrInvalidTTL         = errors.New("invalid link lifetime")
+	ErrInvalidIP          = errors.New("invalid ip address")
+	ErrInvalidDisposition = errors.New("disposition must be inline or attachment")
+	ErrDocumentNotFound   = errors.New("document not found")
+)
@@ -0,0 +1,183 @@
+package linksservice
+
+import (
+	"astral/env"
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	"astral/internal/domain/link"
+	filesrepo "astral/internal/repository/files"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"time"
+)
+
+// LinksService выдает подписанные ссылки на скачивание документов и проверяет их. Ссылки нигде
+// не хранятся: все, что нужно для проверки, есть в самой ссылке, поэтому отозвать отдельную
+// ссылку нельзя, все ссылки отзываются сменой ключа
+type LinksService struct {
+	files      contracts.FilesInterface
+	keys       []signingKey
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+	logger     *slog.Logger
+}
+
+// NewLinksService возвращает ошибку при неверных ключах в настройках. Без LINKS_KEY ссылки выключены
+func NewLinksService(files contracts.FilesInterface, cfg env.Links, logger *slog.Logger) (*LinksService, error) {
+	const op = "services.links.New"
+
+	keys, err := signingKeys(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &LinksService{
+		files:      files,
+		keys:       keys,
+		defaultTTL: cfg.DefaultTTL,
+		maxTTL:     cfg.MaxTTL,
+		logger:     logger.With("service", "LinksService"),
+	}, nil
+}
+
+// Issue подписывает текущим ключом ссылку на собственный документ пользователя login
+func (s *LinksService) Issue(login, ID string, opts link.Options) (*file.File, *link.Link, error) {
+	const op = "services.links.Issue"
+	s.logger.Info("Usecase start", "func", op, "login", login, "fileID", ID)
+
+	if len(s.keys) == 0 {
+		return nil, nil, ErrLinksDisabled
+	}
+
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = s.defaultTTL
+	}
+	if ttl < 0 || ttl > s.maxTTL {
+		return nil, nil, ErrInvalidTTL
+	}
+
+	ip := ""
+	if opts.IP != "" {
+		parsed := net.ParseIP(opts.IP)
+		if parsed == nil {
+			return nil, nil, ErrInvalidIP
+		}
+		ip = parsed.String()
+	}
+
+	doc, err := s.document(login, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	disposition, err := contentDisposition(opts, doc.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l := &link.Link{
+		DocumentID:  doc.ID,
+		Owner:       login,
+		ExpiresAt:   time.Now().Add(ttl).Truncate(time.Second),
+		IP:          ip,
+		Disposition: disposition,
+		KeyID:       s.keys[0].id,
+	}
+	l.Signature = s.keys[0].sign(*l)
+
+	return doc, l, nil
+}
+
+// Resolve проверяет подпись, срок и адрес ссылки и возвращает документ, не открывая содержимое.
+// clientIP - адрес, с которого пришел запрос
+func (s *LinksService) Resolve(l link.Link, clientIP string) (*file.File, error) {
+	const op = "services.links.Resolve"
+
+	if len(s.keys) == 0 {
+		return nil, ErrLinksDisabled
+	}
+
+	now := time.Now()
+	if err := s.verify(l, now); err != nil {
+		s.logger.Info("invalid link signature", "func", op, "owner", l.Owner, "fileID", l.DocumentID, "keyID", l.KeyID)
+		return nil, err
+	}
+
+	if now.After(l.ExpiresAt) {
+		return nil, ErrLinkExpired
+	}
+
+	if l.IP != "" && !net.ParseIP(l.IP).Equal(net.ParseIP(clientIP)) {
+		s.logger.Info("link address mismatch", "func", op, "fileID", l.DocumentID, "ip", clientIP)
+		return nil, ErrAddressMismatch
+	}
+
+	return s.document(l.Owner, l.DocumentID)
+}
+
+// Open проверяет ссылку, как Resolve, и открывает документ для чтения
+func (s *LinksService) Open(l link.Link, clientIP string) (*file.File, file.Object, error) {
+	const op = "services.links.Open"
+	s.logger.Info("Usecase start", "func", op, "owner", l.Owner, "fileID", l.DocumentID, "keyID", l.KeyID)
+
+	doc, err := s.Resolve(l, clientIP)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	obj, err := s.files.OpenObject(doc.ID, l.Owner)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return doc, obj, nil
+}
+
+// document находит документ с содержимым владельца owner
+func (s *LinksService) document(owner, ID string) (*file.File, error) {
+	doc, err := s.files.GetFileInfo(ID, owner)
+	if err != nil {
+		if errors.Is(err, filesrepo.ErrFileNotFound) {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, err
+	}
+
+	if doc.User != owner || !doc.File || doc.IsCollection() || doc.Status != file.StatusActive {
+		return nil, ErrDocumentNotFound
+	}
+
+	return doc, nil
+}
+
+// contentDisposition собирает заголовок Content-Disposition, который отдаст ссылка. Без
+// параметров возвращает пустую строку: тогда документ отдается как вложение под своим именем
+func contentDisposition(opts link.Options, name string) (string, error) {
+	if opts.Disposition == "" && opts.Filename == "" {
+		return "", nil
+	}
+
+	disposition := opts.Disposition
+	if disposition == "" {
+		disposition = link.DispositionAttachment
+	}
+	if disposition != link.DispositionInline && disposition != link.DispositionAttachment {
+		return "", ErrInvalidDisposition
+	}
+
+	filename := opts.Filename
+	if filename == "" {
+		filename = name
+	}
+
+	value := mime.FormatMediaType(disposition, map[string]string{"filename": filename})
+	if value == "" {
+		return "", ErrInvalidDisposition
+	}
+
+	return value, nil
+}
@@ -0,0 +1,287 @@
+package linksservice
+
+import (
+	"astral/env"
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	"astral/internal/domain/link"
+	filesrepo "astral/internal/repository/files"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+const (
+	testKey         = "0123456789abcdef0123456789abcdef"
+	testPreviousKey = "fedcba9876543210fedcba9876543210"
+	testOwner       = "alice"
+	testDocumentID  = "6a1f3c52-7d0e-4b8e-9f3a-2c4d5e6f7a8b"
+)
+
+// stubFiles отдает документы по ID, остальные методы сервиса документов ссылкам не нужны
+type stubFiles struct {
+	contracts.FilesInterface
+	docs map[string]file.File
+}
+
+func (f *stubFiles) GetFileInfo(ID, userID string) (*file.File, error) {
+	doc, ok := f.docs[ID]
+	if !ok || doc.User != userID {
+		return nil, filesrepo.ErrFileNotFound
+	}
+
+	return &doc, nil
+}
+
+func newTestService(t *testing.T, cfg env.Links) *LinksService {
+	t.Helper()
+
+	if cfg.MaxTTL == 0 {
+		cfg.DefaultTTL = time.Hour
+		cfg.MaxTTL = 24 * time.Hour
+	}
+
+	files := &stubFiles{docs: map[string]file.File{
+		testDocumentID: {ID: testDocumentID, User: testOwner, Name: "report.pdf", File: true, Status: file.StatusActive},
+		"pending":      {ID: "pending", User: testOwner, Name: "draft.pdf", File: true, Status: file.StatusPending},
+		"folder":       {ID: "folder", User: testOwner, Name: "docs", File: false, Status: file.StatusActive},
+	}}
+
+	s, err := NewLinksService(files, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewLinksService: %v", err)
+	}
+
+	return s
+}
+
+func TestSigningKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     env.Links
+		keys    int
+		wantErr bool
+	}{
+		{"disabled", env.Links{}, 0, false},
+		{"current key", env.Links{Key: testKey}, 1, false},
+		{"short key", env.Links{Key: "short"}, 0, true},
+		{"previous key with until", env.Links{Key: testKey, PreviousKey: testPreviousKey, PreviousKeyUntil: "2030-01-01T00:00:00Z"}, 2, false},
+		{"previous key without until", env.Links{Key: testKey, PreviousKey: testPreviousKey}, 0, true},
+		{"invalid until", env.Links{Key: testKey, PreviousKey: testPreviousKey, PreviousKeyUntil: "tomorrow"}, 0, true},
+		{"previous key equals current", env.Links{Key: testKey, PreviousKey: testKey, PreviousKeyUntil: "2030-01-01T00:00:00Z"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := signingKeys(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(keys) != tt.keys {
+				t.Fatalf("keys = %d, want %d", len(keys), tt.keys)
+			}
+		})
+	}
+}
+
+func TestIssueAndResolve(t *testing.T) {
+	s := newTestService(t, env.Links{Key: testKey})
+
+	_, l, err := s.Issue(testOwner, testDocumentID, link.Options{})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	doc, err := s.Resolve(*l, "203.0.113.7")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if doc.ID != testDocumentID || doc.User != testOwner {
+		t.Fatalf("resolved %s/%s", doc.User, doc.ID)
+	}
+}
+
+func TestResolveTamperedLink(t *testing.T) {
+	s := newTestService(t, env.Links{Key: testKey})
+
+	_, issued, err := s.Issue(testOwner, testDocumentID, link.Options{IP: "203.0.113.7", Disposition: link.DispositionInline})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(l *link.Link)
+	}{
+		{"owner", func(l *link.Link) { l.Owner = "mallory" }},
+		{"document", func(l *link.Link) { l.DocumentID = "pending" }},
+		{"expiry", func(l *link.Link) { l.ExpiresAt = l.ExpiresAt.Add(time.Hour) }},
+		{"address removed", func(l *link.Link) { l.IP = "" }},
+		{"address changed", func(l *link.Link) { l.IP = "198.51.100.1" }},
+		{"disposition", func(l *link.Link) { l.Disposition = link.DispositionAttachment }},
+		{"signature", func(l *link.Link) { l.Signature = flipLast(l.Signature) }},
+		{"empty signature", func(l *link.Link) { l.Signature = "" }},
+		{"unknown key", func(l *link.Link) { l.KeyID = "00000000" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := *issued
+			tt.tamper(&l)
+
+			if _, err := s.Resolve(l, "203.0.113.7"); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("err = %v, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+// flipLast меняет последний символ подписи на другой
+func flipLast(signature string) string {
+	last := "A"
+	if signature[len(signature)-1] == 'A' {
+		last = "B"
+	}
+
+	return signature[:len(signature)-1] + last
+}
+
+func TestResolveExpiredLink(t *testing.T) {
+	s := newTestService(t, env.Links{Key: testKey})
+
+	l := link.Link{
+		DocumentID: testDocumentID,
+		Owner:      testOwner,
+		ExpiresAt:  time.Now().Add(-time.Second).Truncate(time.Second),
+		KeyID:      s.keys[0].id,
+	}
+	l.Signature = s.keys[0].sign(l)
+
+	if _, err := s.Resolve(l, "203.0.113.7"); !errors.Is(err, ErrLinkExpired) {
+		t.Fatalf("err = %v, want %v", err, ErrLinkExpired)
+	}
+}
+
+func TestResolveAddressBinding(t *testing.T) {
+	s := newTestService(t, env.Links{Key: testKey})
+
+	tests := []struct {
+		name     string
+		bound    string
+		clientIP string
+		err      error
+	}{
+		{"same address", "203.0.113.7", "203.0.113.7", nil},
+		{"other address", "203.0.113.7", "203.0.113.8", ErrAddressMismatch},
+		{"ipv6 in another form", "2001:db8::1", "2001:0db8:0000:0000:0000:0000:0000:0001", nil},
+		{"ipv4 mapped client", "203.0.113.7", "::ffff:203.0.113.7", nil},
+		{"unparsable client", "203.0.113.7", "unknown", ErrAddressMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, l, err := s.Issue(testOwner, testDocumentID, link.Options{IP: tt.bound})
+			if err != nil {
+				t.Fatalf("Issue: %v", err)
+			}
+
+			if _, err := s.Resolve(*l, tt.clientIP); !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestIssueOptions(t *testing.T) {
+	s := newTestService(t, env.Links{Key: testKey})
+
+	tests := []struct {
+		name        string
+		opts        link.Options
+		disposition string
+		err         error
+	}{
+		{"defaults", link.Options{}, "", nil},
+		{"inline", link.Options{Disposition: link.DispositionInline}, `inline; filename=report.pdf`, nil},
+		{"filename only", link.Options{Filename: "q3.pdf"}, `attachment; filename=q3.pdf`, nil},
+		{"unknown disposition", link.Options{Disposition: "download"}, "", ErrInvalidDisposition},
+		{"negative ttl", link.Options{TTL: -time.Minute}, "", ErrInvalidTTL},
+		{"ttl over maximum", link.Options{TTL: 25 * time.Hour}, "", ErrInvalidTTL},
+		{"invalid address", link.Options{IP: "203.0.113"}, "", ErrInvalidIP},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, l, err := s.Issue(testOwner, testDocumentID, tt.opts)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if err == nil && l.Disposition != tt.disposition {
+				t.Fatalf("disposition = %q, want %q", l.Disposition, tt.disposition)
+			}
+		})
+	}
+}
+
+func TestIssueUnavailableDocument(t *testing.T) {
+	s := newTestService(t, env.Links{Key: testKey})
+
+	for _, ID := range []string{"pending", "folder", "missing"} {
+		if _, _, err := s.Issue(testOwner, ID, link.Options{}); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("%s: err = %v, want %v", ID, err, ErrDocumentNotFound)
+		}
+	}
+
+	if _, _, err := s.Issue("mallory", testDocumentID, link.Options{}); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("other owner: err = %v, want %v", err, ErrDocumentNotFound)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	until := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	// Ссылка выдана, пока testPreviousKey был текущим ключом
+	old := newTestService(t, env.Links{Key: testPreviousKey})
+	_, l, err := old.Issue(testOwner, testDocumentID, link.Options{TTL: 2 * time.Hour})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	rotated := newTestService(t, env.Links{Key: testKey, PreviousKey: testPreviousKey, PreviousKeyUntil: until.Format(time.RFC3339)})
+	if _, err := rotated.Resolve(*l, ""); err != nil {
+		t.Fatalf("link signed with the previous key: %v", err)
+	}
+
+	// После until прежний ключ не принимается, хотя срок самой ссылки еще не истек
+	if err := rotated.verify(*l, until.Add(time.Second)); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("after until: err = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	// Ключ, убранный из настроек, больше не принимается
+	retired := newTestService(t, env.Links{Key: testKey})
+	if _, err := retired.Resolve(*l, ""); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("retired key: err = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	// Новые ссылки подписываются текущим ключом
+	_, fresh, err := rotated.Issue(testOwner, testDocumentID, link.Options{})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if fresh.KeyID == l.KeyID {
+		t.Fatal("new link is signed with the previous key")
+	}
+}
+
+func TestLinksDisabled(t *testing.T) {
+	s := newTestService(t, env.Links{})
+
+	if _, _, err := s.Issue(testOwner, testDocumentID, link.Options{}); !errors.Is(err, ErrLinksDisabled) {
+		t.Fatalf("Issue: err = %v, want %v", err, ErrLinksDisabled)
+	}
+	if _, err := s.Resolve(link.Link{DocumentID: testDocumentID, Owner: testOwner}, ""); !errors.Is(err, ErrLinksDisabled) {
+		t.Fatalf("Resolve: err = %v, want %v", err, ErrLinksDisabled)
+	}
+}
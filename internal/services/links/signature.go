@@ -0,0 +1,108 @@
+package linksservice
+
+import (
+	"astral/env"
+	"astral/internal/domain/link"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SIGNATURE_VERSION входит в подписываемую строку, чтобы смена ее формата делала старые ссылки недействительными
+	SIGNATURE_VERSION = "v1"
+	MIN_KEY_LENGTH    = 32
+)
+
+// signingKey - ключ подписи. Ссылка указывает ключ по id, поэтому при проверке не перебираются
+// все ключи. Нулевой until - ключ действует без ограничения
+type signingKey struct {
+	id     string
+	secret []byte
+	until  time.Time
+}
+
+// signingKeys собирает текущий и прежний ключи из настроек. Прежний ключ без срока не принимается:
+// он нужен только на время, пока не истекут выданные им ссылки
+func signingKeys(cfg env.Links) ([]signingKey, error) {
+	if cfg.Key == "" {
+		return nil, nil
+	}
+
+	if len(cfg.Key) < MIN_KEY_LENGTH {
+		return nil, fmt.Errorf("LINKS_KEY must be at least %d characters", MIN_KEY_LENGTH)
+	}
+	keys := []signingKey{newSigningKey(cfg.Key, time.Time{})}
+
+	if cfg.PreviousKey == "" {
+		return keys, nil
+	}
+
+	if cfg.PreviousKeyUntil == "" {
+		return nil, errors.New("LINKS_PREVIOUS_KEY_UNTIL is required with LINKS_PREVIOUS_KEY")
+	}
+	until, err := time.Parse(time.RFC3339, cfg.PreviousKeyUntil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LINKS_PREVIOUS_KEY_UNTIL: %w", err)
+	}
+
+	previous := newSigningKey(cfg.PreviousKey, until)
+	if previous.id == keys[0].id {
+		return nil, errors.New("LINKS_PREVIOUS_KEY must differ from LINKS_KEY")
+	}
+
+	return append(keys, previous), nil
+}
+
+func newSigningKey(secret string, until time.Time) signingKey {
+	sum := sha256.Sum256([]byte(secret))
+
+	return signingKey{
+		id:     hex.EncodeToString(sum[:4]),
+		secret: []byte(secret),
+		until:  until,
+	}
+}
+
+func (k signingKey) sign(l link.Link) string {
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write([]byte(strings.Join([]string{
+		SIGNATURE_VERSION,
+		k.id,
+		l.Owner,
+		l.DocumentID,
+		strconv.FormatInt(l.ExpiresAt.Unix(), 10),
+		l.IP,
+		l.Disposition,
+	}, "\n")))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify проверяет подпись ключом из ссылки. Прежний ключ после своего срока не принимается,
+// даже если срок самой ссылки еще не истек
+func (s *LinksService) verify(l link.Link, now time.Time) error {
+	for _, key := range s.keys {
+		if key.id != l.KeyID {
+			continue
+		}
+
+		if !key.until.IsZero() && now.After(key.until) {
+			return ErrInvalidSignature
+		}
+
+		if !hmac.Equal([]byte(key.sign(l)), []byte(l.Signature)) {
+			return ErrInvalidSignature
+		}
+
+		return nil
+	}
+
+	return ErrInvalidSignature
+}
@@ -0,0 +1,10 @@
+package locksservice
+
+import "errors"
+
+var (
+	ErrAccessDenied  = errors.New("access denied")
+	ErrInvalidTTL    = errors.New("invalid lock ttl")
+	ErrInvalidMode   = errors.New("invalid lock mode, expected advisory or enforced")
+	ErrInvalidReason = errors.New("lock reason is too long")
+)
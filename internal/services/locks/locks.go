@@ -0,0 +1,250 @@
+package locksservice
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	"astral/internal/domain/lock"
+	filesrepo "astral/internal/repository/files"
+	locksrepo "astral/internal/repository/locks"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	DEFAULT_TIMEOUT = time.Second * 5
+
+	MAX_REASON_LENGTH = 500
+)
+
+// LocksService выдает блокировки документов на время редактирования. Взять блокировку может
+// владелец документа или пользователь, которому выдан доступ, снять - ее держатель или владелец
+// документа: менять документ может только владелец, и чужая блокировка не должна его запирать.
+// Администратор может снять любую
+type LocksService struct {
+	repo       locksrepo.LocksRepo
+	files      contracts.FilesInterface
+	logger     *slog.Logger
+	adminToken string
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+}
+
+func NewLocksService(
+	repo locksrepo.LocksRepo,
+	files contracts.FilesInterface,
+	logger *slog.Logger,
+	adminToken string,
+	defaultTTL time.Duration,
+	maxTTL time.Duration,
+) *LocksService {
+	return &LocksService{
+		repo:       repo,
+		files:      files,
+		logger:     logger.With("service", "LocksService"),
+		adminToken: adminToken,
+		defaultTTL: defaultTTL,
+		maxTTL:     maxTTL,
+	}
+}
+
+func (s *LocksService) GetLock(login, owner, documentID string) (*lock.Lock, error) {
+	const op = "services.locks.GetLock"
+	s.logger.Info("Usecase start", "func", op, "login", login, "owner", owner, "docID", documentID)
+
+	if _, err := s.document(login, owner, documentID); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	res, err := s.repo.Get(ctx, documentID)
+	if err != nil {
+		s.logger.Error("failed to get lock", "func", op, "docID", documentID, "error", err)
+		return nil, errors.New("failed to get lock")
+	}
+	if res == nil {
+		return nil, locksrepo.ErrLockNotFound
+	}
+
+	return res, nil
+}
+
+// Lock блокирует документ или продлевает блокировку пользователя. Нулевой ttl - срок по умолчанию
+func (s *LocksService) Lock(login, owner, documentID, reason string, ttl time.Duration, mode lock.Mode) (*file.File, *lock.Lock, error) {
+	const op = "services.locks.Lock"
+	s.logger.Info("Usecase start", "func", op, "login", login, "owner", owner, "docID", documentID, "ttl", ttl, "mode", mode)
+
+	if ttl == 0 {
+		ttl = s.defaultTTL
+	}
+	if ttl < time.Second || ttl > s.maxTTL {
+		return nil, nil, ErrInvalidTTL
+	}
+
+	if mode == "" {
+		mode = lock.ModeEnforced
+	}
+	if !mode.Valid() {
+		return nil, nil, ErrInvalidMode
+	}
+
+	reason = strings.TrimSpace(reason)
+	if utf8.RuneCountInString(reason) > MAX_REASON_LENGTH {
+		return nil, nil, ErrInvalidReason
+	}
+
+	doc, err := s.document(login, owner, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	now := time.Now().UTC()
+	createdAt := now
+	current, err := s.repo.Get(ctx, documentID)
+	if err != nil {
+		s.logger.Error("failed to get lock", "func", op, "docID", documentID, "error", err)
+		return nil, nil, errors.New("failed to get lock")
+	}
+	// Продление сохраняет время, с которого документ в работе
+	if current != nil && current.Holder == login {
+		createdAt = current.CreatedAt
+	}
+
+	res, err := s.repo.Acquire(ctx, lock.Lock{
+		DocumentID: documentID,
+		Owner:      doc.User,
+		Holder:     login,
+		Reason:     reason,
+		Mode:       mode,
+		CreatedAt:  createdAt,
+		ExpiresAt:  now.Add(ttl),
+	})
+	if err != nil {
+		if errors.Is(err, locksrepo.ErrLockHeld) {
+			s.logger.Info("document is locked", "func", op, "docID", documentID, "holder", res.Holder)
+			return nil, nil, err
+		}
+
+		s.logger.Error("failed to acquire lock", "func", op, "docID", documentID, "error", err)
+		return nil, nil, errors.New("failed to acquire lock")
+	}
+
+	return doc, res, nil
+}
+
+// Unlock снимает блокировку, взятую пользователем. Владелец документа снимает и чужую блокировку
+func (s *LocksService) Unlock(login, owner, documentID string) (*file.File, *lock.Lock, error) {
+	const op = "services.locks.Unlock"
+	s.logger.Info("Usecase start", "func", op, "login", login, "owner", owner, "docID", documentID)
+
+	doc, err := s.document(login, owner, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	current, err := s.repo.Get(ctx, documentID)
+	if err != nil {
+		s.logger.Error("failed to get lock", "func", op, "docID", documentID, "error", err)
+		return nil, nil, errors.New("failed to get lock")
+	}
+	if current == nil {
+		return nil, nil, locksrepo.ErrLockNotFound
+	}
+
+	if current.Holder != login && doc.User == login {
+		res, err := s.repo.Break(ctx, documentID)
+		if err != nil {
+			if errors.Is(err, locksrepo.ErrLockNotFound) {
+				return nil, nil, err
+			}
+
+			s.logger.Error("failed to break lock", "func", op, "docID", documentID, "error", err)
+			return nil, nil, errors.New("failed to break lock")
+		}
+
+		s.logger.Info("lock broken by owner", "func", op, "docID", documentID, "holder", res.Holder)
+		return doc, res, nil
+	}
+
+	if err := s.repo.Release(ctx, documentID, login); err != nil {
+		if errors.Is(err, locksrepo.ErrLockNotFound) || errors.Is(err, locksrepo.ErrNotHolder) {
+			return nil, nil, err
+		}
+
+		s.logger.Error("failed to release lock", "func", op, "docID", documentID, "error", err)
+		return nil, nil, errors.New("failed to release lock")
+	}
+
+	return doc, current, nil
+}
+
+// BreakLock снимает чужую блокировку по токену администратора
+func (s *LocksService) BreakLock(adminToken, documentID string) (*lock.Lock, error) {
+	const op = "services.locks.BreakLock"
+	s.logger.Info("Usecase start", "func", op, "docID", documentID)
+
+	if adminToken == "" || adminToken != s.adminToken {
+		s.logger.Info("access denied", "func", op, "docID", documentID)
+		return nil, ErrAccessDenied
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	res, err := s.repo.Break(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, locksrepo.ErrLockNotFound) {
+			return nil, err
+		}
+
+		s.logger.Error("failed to break lock", "func", op, "docID", documentID, "error", err)
+		return nil, errors.New("failed to break lock")
+	}
+
+	return res, nil
+}
+
+// document находит документ владельца, доступный пользователю для совместной работы:
+// публичного доступа на чтение для блокировки недостаточно
+func (s *LocksService) document(login, owner, documentID string) (*file.File, error) {
+	const op = "services.locks.document"
+
+	if owner == "" {
+		owner = login
+	}
+
+	docs, err := s.files.GetFilesByUser(owner, contracts.FilterData{})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range docs {
+		if docs[i].ID != documentID {
+			continue
+		}
+
+		doc := docs[i]
+		// Список из кэша приходит без владельца
+		doc.User = owner
+		if doc.User != login && !slices.Contains(doc.Grant, login) {
+			s.logger.Info("access denied", "func", op, "docID", documentID, "login", login)
+			return nil, ErrAccessDenied
+		}
+
+		return &doc, nil
+	}
+
+	return nil, filesrepo.ErrFileNotFound
+}
@@ -0,0 +1,7 @@
+package notificationsservice
+
+import "errors"
+
+var (
+	ErrInvalidEventID = errors.New("invalid last event id")
+)
@@ -0,0 +1,271 @@
+package notificationsservice
+
+import (
+	"astral/internal/domain/file"
+	"astral/internal/domain/notification"
+	"astral/internal/domain/webhook"
+	notificationsrepo "astral/internal/repository/notifications"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_TIMEOUT = time.Second * 5
+	RELISTEN_DELAY  = time.Second * 2
+)
+
+// NotificationsService рассылает уведомления подключенным клиентам. Каждый экземпляр Astral слушает
+// общий канал Redis и раздает уведомления своим подписчикам, поэтому клиент получает события,
+// произошедшие на любом экземпляре
+type NotificationsService struct {
+	repo    notificationsrepo.NotificationsRepo
+	logger  *slog.Logger
+	buffer  int
+	history int64
+
+	mu   sync.Mutex
+	subs map[string]map[*subscriber]struct{}
+}
+
+// subscriber - одно подключение клиента. Канал закрывается при отписке или если клиент
+// не успевает читать: тогда он переподключится и дочитает пропущенное по Last-Event-ID
+type subscriber struct {
+	ch     chan notification.Notification
+	closed bool
+}
+
+func NewNotificationsService(repo notificationsrepo.NotificationsRepo, logger *slog.Logger, buffer int, history int64) *NotificationsService {
+	return &NotificationsService{
+		repo:    repo,
+		logger:  logger.With("service", "NotificationsService"),
+		buffer:  buffer,
+		history: history,
+		subs:    make(map[string]map[*subscriber]struct{}),
+	}
+}
+
+// Publish определяет получателей события: владелец видит изменения в своем пространстве,
+// пользователи из грантов - документы, которыми с ними поделились, упомянутые - комментарии с упоминанием
+func (s *NotificationsService) Publish(eventType webhook.EventType, actor string, doc file.File, details map[string]string) {
+	const op = "services.notifications.Publish"
+
+	if doc.User == "" {
+		s.logger.Warn("document owner is unknown, notification is skipped", "func", op, "event", eventType, "fileID", doc.ID)
+		return
+	}
+
+	doc.Reader = nil
+	recipients := []string{doc.User}
+	switch eventType {
+	case webhook.EventDocumentShared:
+		recipients = nil
+		if grant := details["grant"]; grant != "" {
+			recipients = strings.Split(grant, ",")
+		}
+	case webhook.EventCommentMentioned:
+		recipients = nil
+		if mentions := details["mentions"]; mentions != "" {
+			recipients = strings.Split(mentions, ",")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	now := time.Now().UTC()
+	for _, recipient := range recipients {
+		if recipient == "" {
+			continue
+		}
+
+		n := notification.Notification{
+			Type:      eventType,
+			Recipient: recipient,
+			Actor:     actor,
+			Owner:     doc.User,
+			Document:  doc,
+			Details:   details,
+			CreatedAt: now,
+		}
+		// Список грантов видит только владелец
+		if recipient != doc.User {
+			n.Document.Grant = nil
+			n.Details = withoutGrant(details)
+		}
+
+		if _, err := s.repo.Append(ctx, n); err != nil {
+			s.logger.Error("failed to publish notification", "func", op, "event", eventType, "recipient", recipient, "fileID", doc.ID, "error", err)
+		}
+	}
+}
+
+// Subscribe подписывает клиента на уведомления. Если передан lastEventID, сначала отдаются
+// сохраненные уведомления после него, затем живые, без повторов на стыке
+func (s *NotificationsService) Subscribe(login, lastEventID string) (<-chan notification.Notification, func(), error) {
+	const op = "services.notifications.Subscribe"
+	s.logger.Info("Usecase start", "func", op, "login", login, "lastEventID", lastEventID)
+
+	if lastEventID != "" {
+		if _, _, ok := notification.ParseID(lastEventID); !ok {
+			return nil, nil, ErrInvalidEventID
+		}
+	}
+
+	// Подписываемся до чтения истории, чтобы не потерять уведомления, пришедшие во время чтения
+	sub := &subscriber{ch: make(chan notification.Notification, s.buffer)}
+	s.mu.Lock()
+	if s.subs[login] == nil {
+		s.subs[login] = make(map[*subscriber]struct{})
+	}
+	s.subs[login][sub] = struct{}{}
+	s.mu.Unlock()
+
+	unsubscribe := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.remove(login, sub)
+	}
+
+	var backlog []notification.Notification
+	if lastEventID != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+		defer cancel()
+
+		var err error
+		backlog, err = s.repo.Since(ctx, login, lastEventID, s.history)
+		if err != nil {
+			unsubscribe()
+			s.logger.Error("failed to read notifications history", "func", op, "login", login, "error", err)
+			return nil, nil, err
+		}
+	}
+
+	out := make(chan notification.Notification)
+	done := make(chan struct{})
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			unsubscribe()
+			close(done)
+		})
+	}
+
+	go func() {
+		defer close(out)
+
+		last := lastEventID
+		for _, n := range backlog {
+			select {
+			case out <- n:
+				last = n.ID
+			case <-done:
+				return
+			}
+		}
+
+		for {
+			select {
+			case n, ok := <-sub.ch:
+				if !ok {
+					return
+				}
+				if last != "" && !notification.After(n.ID, last) {
+					continue
+				}
+
+				select {
+				case out <- n:
+					last = n.ID
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return out, cancel, nil
+}
+
+// Run слушает общий канал и раздает уведомления локальным подписчикам до отмены контекста
+func (s *NotificationsService) Run(ctx context.Context) {
+	const op = "services.notifications.Run"
+	s.logger.Info("notifications listener started", "func", op)
+
+	for {
+		err := s.repo.Listen(ctx, s.dispatch)
+		if ctx.Err() != nil {
+			s.logger.Info("notifications listener stopped", "func", op)
+			s.closeAll()
+			return
+		}
+
+		s.logger.Warn("notifications listener failed", "func", op, "retry_in", RELISTEN_DELAY, "error", err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(RELISTEN_DELAY):
+		}
+	}
+}
+
+func (s *NotificationsService) dispatch(n notification.Notification) {
+	const op = "services.notifications.dispatch"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subs[n.Recipient] {
+		select {
+		case sub.ch <- n:
+		default:
+			s.logger.Warn("subscriber is too slow, subscription is closed", "func", op, "login", n.Recipient)
+			s.remove(n.Recipient, sub)
+		}
+	}
+}
+
+func (s *NotificationsService) closeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for login, subs := range s.subs {
+		for sub := range subs {
+			s.remove(login, sub)
+		}
+	}
+}
+
+// remove вызывается под s.mu
+func (s *NotificationsService) remove(login string, sub *subscriber) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.ch)
+
+	delete(s.subs[login], sub)
+	if len(s.subs[login]) == 0 {
+		delete(s.subs, login)
+	}
+}
+
+// withoutGrant убирает из деталей список грантов, его видит только владелец
+func withoutGrant(details map[string]string) map[string]string {
+	if _, ok := details["grant"]; !ok {
+		return details
+	}
+
+	res := make(map[string]string, len(details))
+	for k, v := range details {
+		if k != "grant" {
+			res[k] = v
+		}
+	}
+
+	return res
+}
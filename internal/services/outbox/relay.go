@@ -0,0 +1,153 @@
+package outboxservice
+
+import (
+	"astral/internal/domain/event"
+	brokerrepo "astral/internal/repository/broker"
+	outboxrepo "astral/internal/repository/outbox"
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	DEFAULT_TIMEOUT = time.Second * 5
+	PUBLISH_TIMEOUT = time.Second * 10
+	BASE_BACKOFF    = time.Second
+	CLEANUP_PERIOD  = time.Hour
+)
+
+// RelayService переносит события из outbox в брокер. Событие отмечается опубликованным только
+// после подтверждения брокера, поэтому при сбое между ними оно уйдет повторно с тем же ID
+type RelayService struct {
+	repo       outboxrepo.OutboxRepo
+	broker     brokerrepo.Broker
+	logger     *slog.Logger
+	interval   time.Duration
+	batchSize  int
+	maxBackoff time.Duration
+	retention  time.Duration
+}
+
+func NewRelayService(
+	repo outboxrepo.OutboxRepo,
+	broker brokerrepo.Broker,
+	logger *slog.Logger,
+	interval time.Duration,
+	batchSize int,
+	maxBackoff time.Duration,
+	retention time.Duration,
+) *RelayService {
+	return &RelayService{
+		repo:       repo,
+		broker:     broker,
+		logger:     logger.With("service", "RelayService"),
+		interval:   interval,
+		batchSize:  batchSize,
+		maxBackoff: maxBackoff,
+		retention:  retention,
+	}
+}
+
+// Run публикует события до отмены контекста
+func (s *RelayService) Run(ctx context.Context) {
+	const op = "services.outbox.Run"
+	s.logger.Info("outbox relay started", "func", op, "interval", s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("outbox relay stopped", "func", op)
+			return
+		case <-ticker.C:
+			for s.processBatch(ctx) == s.batchSize {
+				if ctx.Err() != nil {
+					break
+				}
+			}
+
+			if time.Since(lastCleanup) >= CLEANUP_PERIOD {
+				s.cleanup(ctx)
+				lastCleanup = time.Now()
+			}
+		}
+	}
+}
+
+func (s *RelayService) processBatch(ctx context.Context) int {
+	const op = "services.outbox.processBatch"
+
+	fetchCtx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+	defer cancel()
+
+	records, err := s.repo.FetchDue(fetchCtx, s.batchSize)
+	if err != nil {
+		s.logger.Warn("failed to fetch outbox events", "func", op, "error", err)
+		return 0
+	}
+
+	// События публикуются по одному в порядке записи. Повтор после ошибки может прийти позже
+	// следующих событий, поэтому потребители не должны полагаться на строгий порядок
+	for _, record := range records {
+		s.publish(ctx, record)
+	}
+
+	return len(records)
+}
+
+func (s *RelayService) publish(ctx context.Context, record event.Record) {
+	const op = "services.outbox.publish"
+
+	publishCtx, cancel := context.WithTimeout(ctx, PUBLISH_TIMEOUT)
+	defer cancel()
+
+	err := s.broker.Publish(publishCtx, record.Event)
+
+	ctx, cancel = context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if err != nil {
+		backoff := s.backoff(record.Attempts)
+		s.logger.Warn("failed to publish event", "func", op, "eventID", record.Event.ID, "type", record.Event.Type, "attempts", record.Attempts+1, "retry_in", backoff, "error", err)
+		if err := s.repo.Fail(ctx, record.Seq, err.Error(), backoff); err != nil {
+			s.logger.Error("failed to reschedule event", "func", op, "eventID", record.Event.ID, "error", err)
+		}
+		return
+	}
+
+	if err := s.repo.MarkPublished(ctx, record.Seq); err != nil {
+		s.logger.Error("failed to mark event published", "func", op, "eventID", record.Event.ID, "error", err)
+	}
+}
+
+func (s *RelayService) cleanup(ctx context.Context) {
+	const op = "services.outbox.cleanup"
+
+	ctx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+	defer cancel()
+
+	count, err := s.repo.DeletePublished(ctx, s.retention)
+	if err != nil {
+		s.logger.Warn("failed to delete published events", "func", op, "error", err)
+		return
+	}
+
+	if count > 0 {
+		s.logger.Info("published events deleted", "func", op, "count", count)
+	}
+}
+
+func (s *RelayService) backoff(attempts int) time.Duration {
+	backoff := BASE_BACKOFF
+	for i := 0; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= s.maxBackoff {
+			return s.maxBackoff
+		}
+	}
+
+	return backoff
+}
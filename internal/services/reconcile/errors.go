@@ -0,0 +1,7 @@
+package reconcileservice
+
+import "errors"
+
+var (
+	ErrReconcileRunning = errors.New("reconciliation is already running")
+)
@@ -0,0 +1,307 @@
+package reconcileservice
+
+import (
+	"astral/internal/domain/reconcile"
+	accountrepo "astral/internal/repository/account"
+	reconcilerepo "astral/internal/repository/reconcile"
+	s3repo "astral/internal/repository/s3"
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_TIMEOUT = time.Second * 30
+	LIST_TIMEOUT    = time.Minute * 10
+)
+
+// ReconcileService сверяет метаданные в PostgreSQL, кэш в Redis и объекты в MinIO и находит
+// объекты без владельца, ссылки на удаленные документы и пользователей и устаревший кэш
+type ReconcileService struct {
+	repo     reconcilerepo.ReconcileRepo
+	logger   *slog.Logger
+	interval time.Duration
+	repair   bool
+	minAge   time.Duration
+
+	running sync.Mutex
+}
+
+// repair - исправлять ли найденное при запуске по расписанию. Объекты моложе minAge не считаются
+// брошенными: их загрузка может быть еще не закончена
+func NewReconcileService(
+	repo reconcilerepo.ReconcileRepo,
+	logger *slog.Logger,
+	interval time.Duration,
+	repair bool,
+	minAge time.Duration,
+) *ReconcileService {
+	return &ReconcileService{
+		repo:     repo,
+		logger:   logger.With("service", "ReconcileService"),
+		interval: interval,
+		repair:   repair,
+		minAge:   minAge,
+	}
+}
+
+// Run запускает сверку раз в interval до отмены контекста
+func (s *ReconcileService) Run(ctx context.Context) {
+	const op = "services.reconcile.Run"
+	s.logger.Info("reconciler started", "func", op, "interval", s.interval, "repair", s.repair)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("reconciler stopped", "func", op)
+			return
+		case <-ticker.C:
+			report, err := s.Reconcile(ctx, s.repair, false)
+			if err != nil {
+				s.logger.Error("reconciliation failed", "func", op, "error", err)
+				continue
+			}
+
+			s.logger.Info("reconciliation finished", "func", op,
+				"orphaned_objects", len(report.OrphanedObjects),
+				"dangling_references", len(report.DanglingReferences),
+				"stale_cache", len(report.StaleCache),
+				"repaired", report.Repaired)
+		}
+	}
+}
+
+// Reconcile выполняет одну сверку. С repair найденное исправляется, с dryRun - только
+// описывается в Action, без изменений. Одновременно выполняется только одна сверка
+func (s *ReconcileService) Reconcile(ctx context.Context, repair, dryRun bool) (*reconcile.Report, error) {
+	const op = "services.reconcile.Reconcile"
+	s.logger.Info("Usecase start", "func", op, "repair", repair, "dryRun", dryRun)
+
+	if !s.running.TryLock() {
+		return nil, ErrReconcileRunning
+	}
+	defer s.running.Unlock()
+
+	startedAt := time.Now()
+	report := &reconcile.Report{
+		Repair:             repair,
+		DryRun:             dryRun,
+		OrphanedObjects:    []reconcile.Finding{},
+		DanglingReferences: []reconcile.Finding{},
+		StaleCache:         []reconcile.Finding{},
+		StartedAt:          &startedAt,
+	}
+
+	readCtx, cancel := context.WithTimeout(ctx, LIST_TIMEOUT)
+	defer cancel()
+
+	// Ссылки читаются раньше пользователей и объектов: документ или пользователь, созданный во
+	// время сверки, тогда не попадет в висячие ссылки. Новые объекты отсекает minAge
+	documentRefs, err := s.repo.ListDocumentReferences(readCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	userRefs, err := s.repo.ListUserReferences(readCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.ListCacheEntries(readCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.repo.ListUsers(readCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	uploads, err := s.repo.ListMultipartUploads(readCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	exports, err := s.repo.ListExports(readCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := s.repo.ListObjects(readCtx)
+	if err != nil {
+		return nil, err
+	}
+	report.Objects = len(objects)
+
+	// Документы ищутся по ID: ссылки в таблицах и кэше не всегда знают владельца
+	documents := make(map[string]reconcile.Object, len(objects))
+	for _, obj := range objects {
+		if _, id, ok := documentKey(obj.Key); ok && obj.Document {
+			documents[id] = obj
+		}
+	}
+
+	for _, obj := range objects {
+		if time.Since(obj.LastModified) < s.minAge {
+			continue
+		}
+
+		if reason := s.orphanReason(obj, users, uploads, exports); reason != "" {
+			report.OrphanedObjects = append(report.OrphanedObjects, reconcile.Finding{
+				Kind:   reconcile.KindOrphanedObject,
+				Source: "minio",
+				Key:    obj.Key,
+				Reason: reason,
+				Action: "delete object",
+			})
+		}
+	}
+
+	// dangling идет в том же порядке, что и находки в отчете, по нему они исправляются
+	var dangling []reconcile.Reference
+	for _, ref := range documentRefs {
+		if _, ok := documents[ref.Key]; !ok {
+			dangling = append(dangling, ref)
+			report.DanglingReferences = append(report.DanglingReferences, referenceFinding(ref, "document does not exist"))
+		}
+	}
+	for _, ref := range userRefs {
+		if !users[ref.Key] {
+			dangling = append(dangling, ref)
+			report.DanglingReferences = append(report.DanglingReferences, referenceFinding(ref, "user does not exist"))
+		}
+	}
+
+	for _, entry := range entries {
+		if reason := staleReason(entry, documents, users); reason != "" {
+			report.StaleCache = append(report.StaleCache, reconcile.Finding{
+				Kind:   reconcile.KindStaleCache,
+				Source: "redis",
+				Key:    entry.Key,
+				Reason: reason,
+				Action: "delete key",
+			})
+		}
+	}
+
+	if repair && !dryRun {
+		s.repairAll(ctx, report, dangling)
+	}
+
+	finishedAt := time.Now()
+	report.FinishedAt = &finishedAt
+
+	return report, nil
+}
+
+func (s *ReconcileService) orphanReason(obj reconcile.Object, users, uploads, exports map[string]bool) string {
+	if rest, ok := strings.CutPrefix(obj.Key, s3repo.PARTS_PREFIX+"/"); ok {
+		uploadID, _, _ := strings.Cut(rest, "/")
+		if !uploads[uploadID] {
+			return "multipart upload does not exist"
+		}
+		return ""
+	}
+
+	if rest, ok := strings.CutPrefix(obj.Key, accountrepo.TAKEOUT_PREFIX+"/"); ok {
+		exportID := strings.TrimSuffix(path.Base(rest), ".zip")
+		if !exports[exportID] {
+			return "export does not exist"
+		}
+		return ""
+	}
+
+	owner, _, ok := documentKey(obj.Key)
+	switch {
+	case !ok:
+		return "key is not a document path"
+	case !obj.Document:
+		return "object has no document metadata"
+	case !users[owner]:
+		return "owner does not exist"
+	case obj.Pending:
+		return "presigned upload was not finalized"
+	}
+
+	return ""
+}
+
+func staleReason(entry reconcile.CacheEntry, documents map[string]reconcile.Object, users map[string]bool) string {
+	if entry.Login != "" && !users[entry.Login] {
+		return "user does not exist"
+	}
+
+	if entry.DocumentID == "" {
+		return ""
+	}
+
+	obj, ok := documents[entry.DocumentID]
+	switch {
+	case !ok:
+		return "document does not exist"
+	case entry.Copy && (entry.Name != obj.Name || entry.Checksum != obj.Checksum):
+		return "cached document differs from storage"
+	}
+
+	return ""
+}
+
+func referenceFinding(ref reconcile.Reference, reason string) reconcile.Finding {
+	return reconcile.Finding{
+		Kind:   reconcile.KindDanglingReference,
+		Source: ref.Table,
+		Key:    fmt.Sprintf("%s=%s", ref.Column, ref.Key),
+		Reason: fmt.Sprintf("%s (%d rows)", reason, ref.Count),
+		Action: "delete rows",
+	}
+}
+
+// repairAll исправляет найденное. Ошибка исправления одной находки записывается в нее и не
+// останавливает остальные
+func (s *ReconcileService) repairAll(ctx context.Context, report *reconcile.Report, dangling []reconcile.Reference) {
+	const op = "services.reconcile.repairAll"
+
+	apply := func(findings []reconcile.Finding, fix func(ctx context.Context, i int) error) {
+		for i := range findings {
+			fixCtx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+			err := fix(fixCtx, i)
+			cancel()
+
+			if err != nil {
+				s.logger.Warn("failed to repair", "func", op, "kind", findings[i].Kind, "key", findings[i].Key, "error", err)
+				findings[i].Error = err.Error()
+				continue
+			}
+
+			findings[i].Repaired = true
+			report.Repaired++
+		}
+	}
+
+	apply(report.OrphanedObjects, func(ctx context.Context, i int) error {
+		return s.repo.RemoveObject(ctx, report.OrphanedObjects[i].Key)
+	})
+	apply(report.DanglingReferences, func(ctx context.Context, i int) error {
+		return s.repo.DeleteReference(ctx, dangling[i])
+	})
+	apply(report.StaleCache, func(ctx context.Context, i int) error {
+		return s.repo.DeleteCacheEntry(ctx, report.StaleCache[i].Key)
+	})
+}
+
+// documentKey разбирает ключ объекта документа <владелец>/<ID>
+func documentKey(key string) (string, string, bool) {
+	owner, id, ok := strings.Cut(key, "/")
+	if !ok || owner == "" || id == "" || strings.Contains(id, "/") {
+		return "", "", false
+	}
+
+	return owner, id, true
+}
@@ -0,0 +1,251 @@
+package replicationservice
+
+import (
+	"astral/internal/domain/replication"
+	replicationrepo "astral/internal/repository/replication"
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_TIMEOUT = time.Second * 5
+	COPY_TIMEOUT    = time.Second * 120
+	BASE_BACKOFF    = time.Second * 5
+
+	// Аренда задачи перед копированием: с запасом дольше самой копии и записи результата
+	TASK_LEASE = COPY_TIMEOUT + time.Minute
+)
+
+type ReplicationService struct {
+	queue      replicationrepo.QueueRepo
+	objects    replicationrepo.ObjectsRepo
+	logger     *slog.Logger
+	interval   time.Duration
+	batchSize  int
+	maxBackoff time.Duration
+}
+
+func NewReplicationService(
+	queue replicationrepo.QueueRepo,
+	objects replicationrepo.ObjectsRepo,
+	logger *slog.Logger,
+	interval time.Duration,
+	batchSize int,
+	maxBackoff time.Duration,
+) *ReplicationService {
+	return &ReplicationService{
+		queue:      queue,
+		objects:    objects,
+		logger:     logger.With("service", "ReplicationService"),
+		interval:   interval,
+		batchSize:  batchSize,
+		maxBackoff: maxBackoff,
+	}
+}
+
+// Run обрабатывает очередь репликации до отмены контекста
+func (s *ReplicationService) Run(ctx context.Context) {
+	const op = "services.replication.Run"
+	s.logger.Info("replication worker started", "func", op, "interval", s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("replication worker stopped", "func", op)
+			return
+		case <-ticker.C:
+			for s.processBatch(ctx) == s.batchSize {
+				if ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+func (s *ReplicationService) Status() (*replication.Status, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.queue.Stats(ctx)
+}
+
+// Verify сравнивает основное хранилище с репликой. С repair недостающие и расходящиеся объекты
+// копируются в реплику, а объекты, оставшиеся только в реплике, возвращаются в основное хранилище:
+// после потери данных реплика может быть единственной копией. Удалить их из реплики можно только явно, с prune
+func (s *ReplicationService) Verify(ctx context.Context, repair, prune bool) (*replication.Report, error) {
+	const op = "services.replication.Verify"
+	s.logger.Info("Usecase start", "func", op, "repair", repair, "prune", prune)
+
+	primary, err := s.objects.ListPrimary(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	replica, err := s.objects.ListReplica(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &replication.Report{
+		Checked:          len(primary),
+		MissingOnReplica: []string{},
+		MissingOnPrimary: []string{},
+		Mismatched:       []string{},
+	}
+
+	for key, obj := range primary {
+		replicaObj, ok := replica[key]
+		if !ok {
+			report.MissingOnReplica = append(report.MissingOnReplica, key)
+			continue
+		}
+
+		if !sameObject(obj, replicaObj) {
+			report.Mismatched = append(report.Mismatched, key)
+		}
+	}
+
+	for key := range replica {
+		if _, ok := primary[key]; !ok {
+			report.MissingOnPrimary = append(report.MissingOnPrimary, key)
+		}
+	}
+
+	sort.Strings(report.MissingOnReplica)
+	sort.Strings(report.MissingOnPrimary)
+	sort.Strings(report.Mismatched)
+
+	if prune {
+		for _, key := range report.MissingOnPrimary {
+			if err := s.objects.RemoveFromReplica(ctx, key); err != nil {
+				s.logger.Warn("failed to remove stale replica object", "func", op, "key", key, "error", err)
+				continue
+			}
+			report.Pruned++
+		}
+	}
+
+	if !repair {
+		return report, nil
+	}
+
+	for _, key := range append(report.MissingOnReplica, report.Mismatched...) {
+		if err := s.objects.CopyToReplica(ctx, key); err != nil {
+			s.logger.Warn("failed to repair object", "func", op, "key", key, "error", err)
+			continue
+		}
+		report.Repaired++
+	}
+
+	if prune {
+		return report, nil
+	}
+
+	for _, key := range report.MissingOnPrimary {
+		if err := s.objects.CopyToPrimary(ctx, key); err != nil {
+			s.logger.Warn("failed to restore object to primary", "func", op, "key", key, "error", err)
+			continue
+		}
+		report.Repaired++
+	}
+
+	return report, nil
+}
+
+func (s *ReplicationService) processBatch(ctx context.Context) int {
+	const op = "services.replication.processBatch"
+
+	fetchCtx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+	defer cancel()
+
+	tasks, err := s.queue.FetchDue(fetchCtx, s.batchSize)
+	if err != nil {
+		s.logger.Warn("failed to fetch replication tasks", "func", op, "error", err)
+		return 0
+	}
+
+	for _, task := range tasks {
+		s.processTask(ctx, task)
+	}
+
+	return len(tasks)
+}
+
+func (s *ReplicationService) processTask(ctx context.Context, task replication.Task) {
+	const op = "services.replication.processTask"
+
+	// Задачи пачки выполняются по очереди, и к поздним аренда от выборки может истечь: продлеваем перед копией
+	leaseCtx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+	defer cancel()
+	if err := s.queue.Extend(leaseCtx, task.ID, TASK_LEASE); err != nil {
+		s.logger.Warn("failed to extend replication task lease, task is left for a later batch", "func", op, "taskID", task.ID, "error", err)
+		return
+	}
+
+	copyCtx, cancel := context.WithTimeout(ctx, COPY_TIMEOUT)
+	defer cancel()
+
+	var err error
+	switch task.Operation {
+	case replication.OperationPut:
+		err = s.objects.CopyToReplica(copyCtx, task.ObjectKey)
+		if errors.Is(err, replicationrepo.ErrObjectNotFound) {
+			// Объект удален раньше, чем успел реплицироваться, удаление придет отдельной задачей
+			err = nil
+		}
+	case replication.OperationDelete:
+		err = s.objects.RemoveFromReplica(copyCtx, task.ObjectKey)
+	default:
+		s.logger.Error("unknown replication operation", "func", op, "taskID", task.ID, "operation", task.Operation)
+		return
+	}
+
+	ctx, cancel = context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if err != nil {
+		backoff := s.backoff(task.Attempts)
+		s.logger.Warn("replication task failed", "func", op, "taskID", task.ID, "key", task.ObjectKey, "attempts", task.Attempts+1, "retry_in", backoff, "error", err)
+		if err := s.queue.Fail(ctx, task.ID, err.Error(), backoff); err != nil {
+			s.logger.Error("failed to reschedule replication task", "func", op, "taskID", task.ID, "error", err)
+		}
+		return
+	}
+
+	if err := s.queue.Complete(ctx, task.ID); err != nil {
+		s.logger.Error("failed to complete replication task", "func", op, "taskID", task.ID, "error", err)
+	}
+}
+
+func (s *ReplicationService) backoff(attempts int) time.Duration {
+	backoff := BASE_BACKOFF
+	for i := 0; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= s.maxBackoff {
+			return s.maxBackoff
+		}
+	}
+
+	return backoff
+}
+
+// ETag составных загрузок зависит от размера частей, поэтому сравниваем его только для обычных объектов
+func sameObject(primary, replica replication.Object) bool {
+	if primary.Size != replica.Size {
+		return false
+	}
+
+	if strings.Contains(primary.ETag, "-") || strings.Contains(replica.ETag, "-") {
+		return true
+	}
+
+	return primary.ETag == replica.ETag
+}
@@ -0,0 +1,13 @@
+package s3service
+
+import "errors"
+
+var (
+	ErrAccessDenied     = errors.New("access denied")
+	ErrNoSuchBucket     = errors.New("bucket does not exist")
+	ErrNoSuchKey        = errors.New("key does not exist")
+	ErrNoSuchUpload     = errors.New("multipart upload does not exist")
+	ErrInvalidKey       = errors.New("invalid object key")
+	ErrInvalidPart      = errors.New("one or more of the specified parts could not be found")
+	ErrInvalidPartOrder = errors.New("parts must be listed in ascending order")
+)
@@ -0,0 +1,187 @@
+package s3service
+
+import (
+	"astral/internal/domain/file"
+	"astral/internal/domain/s3"
+	s3repo "astral/internal/repository/s3"
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	MAX_PART_NUMBER = 10000
+)
+
+func (s *S3Service) CreateMultipartUpload(login, bucket, key string, fileData file.File) (*s3.MultipartUpload, error) {
+	const op = "services.s3.CreateMultipartUpload"
+	s.logger.Info("Usecase start", "func", op, "login", login, "bucket", bucket, "key", key)
+
+	if bucket != login {
+		return nil, ErrAccessDenied
+	}
+
+	_, _, collection, err := parseKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if collection {
+		return nil, ErrInvalidKey
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.multipart.CreateUpload(ctx, s3.MultipartUpload{
+		Login:       login,
+		Bucket:      bucket,
+		Key:         key,
+		ContentType: fileData.Mime,
+		Metadata:    fileData.Metadata,
+	})
+}
+
+func (s *S3Service) UploadPart(login, bucket, key, uploadID string, number int, reader io.Reader, size int64) (*s3.Part, error) {
+	const op = "services.s3.UploadPart"
+	s.logger.Info("Usecase start", "func", op, "login", login, "uploadID", uploadID, "part", number, "size", size)
+
+	if number < 1 || number > MAX_PART_NUMBER {
+		return nil, ErrInvalidPart
+	}
+
+	if _, err := s.getUpload(login, bucket, key, uploadID); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
+	defer cancel()
+
+	return s.multipart.PutPart(ctx, uploadID, number, reader, size)
+}
+
+func (s *S3Service) ListParts(login, bucket, key, uploadID string) ([]s3.Part, error) {
+	const op = "services.s3.ListParts"
+	s.logger.Info("Usecase start", "func", op, "login", login, "uploadID", uploadID)
+
+	if _, err := s.getUpload(login, bucket, key, uploadID); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.multipart.ListParts(ctx, uploadID)
+}
+
+// CompleteMultipartUpload склеивает перечисленные клиентом части в один документ и удаляет загрузку
+func (s *S3Service) CompleteMultipartUpload(login, bucket, key, uploadID string, parts []s3.Part) (*file.File, error) {
+	const op = "services.s3.CompleteMultipartUpload"
+	s.logger.Info("Usecase start", "func", op, "login", login, "uploadID", uploadID, "parts", len(parts))
+
+	upload, err := s.getUpload(login, bucket, key, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(parts) == 0 {
+		return nil, ErrInvalidPart
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
+	defer cancel()
+
+	stored, err := s.multipart.ListParts(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	storedByNumber := make(map[int]s3.Part, len(stored))
+	for _, part := range stored {
+		storedByNumber[part.Number] = part
+	}
+
+	var size int64
+	for i, part := range parts {
+		if i > 0 && part.Number <= parts[i-1].Number {
+			return nil, ErrInvalidPartOrder
+		}
+
+		storedPart, ok := storedByNumber[part.Number]
+		if !ok || strings.Trim(part.ETag, `"`) != strings.Trim(storedPart.ETag, `"`) {
+			return nil, ErrInvalidPart
+		}
+		size += storedPart.Size
+	}
+
+	readers := make([]io.Reader, 0, len(parts))
+	for _, part := range parts {
+		reader, err := s.multipart.OpenPart(ctx, uploadID, part.Number)
+		if err != nil {
+			if errors.Is(err, s3repo.ErrPartNotFound) {
+				return nil, ErrInvalidPart
+			}
+			return nil, err
+		}
+		defer reader.Close()
+
+		readers = append(readers, reader)
+	}
+
+	res, err := s.PutObject(login, bucket, key, file.File{
+		Mime:     upload.ContentType,
+		Size:     int(size),
+		Metadata: upload.Metadata,
+		Reader:   io.MultiReader(readers...),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.removeUpload(uploadID); err != nil {
+		s.logger.Warn("failed to clean up completed upload", "func", op, "uploadID", uploadID, "error", err)
+	}
+
+	return res, nil
+}
+
+func (s *S3Service) AbortMultipartUpload(login, bucket, key, uploadID string) error {
+	const op = "services.s3.AbortMultipartUpload"
+	s.logger.Info("Usecase start", "func", op, "login", login, "uploadID", uploadID)
+
+	if _, err := s.getUpload(login, bucket, key, uploadID); err != nil {
+		return err
+	}
+
+	return s.removeUpload(uploadID)
+}
+
+func (s *S3Service) getUpload(login, bucket, key, uploadID string) (*s3.MultipartUpload, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	upload, err := s.multipart.GetUpload(ctx, uploadID)
+	if err != nil {
+		if errors.Is(err, s3repo.ErrUploadNotFound) {
+			return nil, ErrNoSuchUpload
+		}
+		return nil, err
+	}
+
+	if upload.Login != login || upload.Bucket != bucket || upload.Key != key {
+		return nil, ErrNoSuchUpload
+	}
+
+	return upload, nil
+}
+
+func (s *S3Service) removeUpload(uploadID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), FILE_LOAD_TIMEOUT)
+	defer cancel()
+
+	if err := s.multipart.RemoveParts(ctx, uploadID); err != nil {
+		return err
+	}
+
+	return s.multipart.DeleteUpload(ctx, uploadID)
+}
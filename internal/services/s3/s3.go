@@ -0,0 +1,319 @@
+package s3service
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	"astral/internal/domain/s3"
+	s3repo "astral/internal/repository/s3"
+	"bytes"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	FILE_LOAD_TIMEOUT = time.Second * 60
+	DEFAULT_TIMEOUT   = time.Second * 5
+	DEFAULT_MAX_KEYS  = 1000
+)
+
+// S3Service отображает бакеты и ключи S3 на документы Astral: бакет - это логин владельца,
+// ключ - путь документа в папках. Все операции с документами идут через FilesService,
+// поэтому доступы и кэш работают так же, как в REST API
+type S3Service struct {
+	files     contracts.FilesInterface
+	multipart s3repo.MultipartRepo
+	logger    *slog.Logger
+}
+
+func NewS3Service(files contracts.FilesInterface, multipart s3repo.MultipartRepo, logger *slog.Logger) *S3Service {
+	return &S3Service{
+		files:     files,
+		multipart: multipart,
+		logger:    logger,
+	}
+}
+
+func (s *S3Service) ListBuckets(login string) ([]string, error) {
+	const op = "services.s3.ListBuckets"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	return []string{login}, nil
+}
+
+func (s *S3Service) HeadBucket(login, bucket string) error {
+	const op = "services.s3.HeadBucket"
+	s.logger.Info("Usecase start", "func", op, "login", login, "bucket", bucket)
+
+	_, err := s.visibleDocuments(login, bucket)
+	return err
+}
+
+func (s *S3Service) ListObjects(login, bucket string, query s3.ListQuery) (*s3.ListResult, error) {
+	const op = "services.s3.ListObjects"
+	s.logger.Info("Usecase start", "func", op, "login", login, "bucket", bucket, "prefix", query.Prefix)
+
+	docs, err := s.visibleDocuments(login, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]s3.Object, 0, len(docs))
+	for _, doc := range docs {
+		objects = append(objects, s3.Object{
+			Key:      objectKey(doc),
+			Document: doc,
+		})
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Key < objects[j].Key
+	})
+
+	maxKeys := query.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = DEFAULT_MAX_KEYS
+	}
+
+	res := &s3.ListResult{}
+	count := 0
+	lastPrefix := ""
+	for _, object := range objects {
+		if !strings.HasPrefix(object.Key, query.Prefix) || object.Key <= query.StartAfter {
+			continue
+		}
+
+		commonPrefix := ""
+		if query.Delimiter != "" {
+			rest := strings.TrimPrefix(object.Key, query.Prefix)
+			if idx := strings.Index(rest, query.Delimiter); idx >= 0 {
+				commonPrefix = query.Prefix + rest[:idx+len(query.Delimiter)]
+			}
+		}
+
+		// Ключи с общим префиксом идут подряд, поэтому достаточно помнить последний
+		if commonPrefix != "" && (commonPrefix == lastPrefix || commonPrefix <= query.StartAfter) {
+			continue
+		}
+
+		if count >= maxKeys {
+			res.IsTruncated = true
+			break
+		}
+		count++
+
+		if commonPrefix != "" {
+			lastPrefix = commonPrefix
+			res.CommonPrefixes = append(res.CommonPrefixes, commonPrefix)
+			res.NextMarker = commonPrefix
+			continue
+		}
+
+		res.Objects = append(res.Objects, object)
+		res.NextMarker = object.Key
+	}
+
+	if !res.IsTruncated {
+		res.NextMarker = ""
+	}
+
+	return res, nil
+}
+
+func (s *S3Service) HeadObject(login, bucket, key string) (*file.File, error) {
+	const op = "services.s3.HeadObject"
+	s.logger.Info("Usecase start", "func", op, "login", login, "bucket", bucket, "key", key)
+
+	docs, err := s.visibleDocuments(login, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := findObject(docs, key)
+	if doc == nil {
+		return nil, ErrNoSuchKey
+	}
+
+	return doc, nil
+}
+
+func (s *S3Service) GetObject(login, bucket, key string) (*file.File, error) {
+	const op = "services.s3.GetObject"
+	s.logger.Info("Usecase start", "func", op, "login", login, "bucket", bucket, "key", key)
+
+	docs, err := s.visibleDocuments(login, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := findObject(docs, key)
+	if doc == nil {
+		return nil, ErrNoSuchKey
+	}
+
+	if doc.IsCollection() {
+		doc.Reader = bytes.NewReader(nil)
+		return doc, nil
+	}
+
+	// Доступ к чужому бакету уже проверен по списку видимых документов, а объекты хранятся под логином владельца
+	fileData, err := s.files.GetFileByID(doc.ID, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	return fileData, nil
+}
+
+// PutObject загружает документ по ключу, существующий документ заменяется с сохранением доступов
+func (s *S3Service) PutObject(login, bucket, key string, fileData file.File) (*file.File, error) {
+	const op = "services.s3.PutObject"
+	s.logger.Info("Usecase start", "func", op, "login", login, "bucket", bucket, "key", key, "size", fileData.Size)
+
+	if bucket != login {
+		return nil, ErrAccessDenied
+	}
+
+	folder, name, collection, err := parseKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if collection && fileData.Size > 0 {
+		return nil, ErrInvalidKey
+	}
+
+	docs, err := s.files.GetFilesByUser(login, contracts.FilterData{})
+	if err != nil {
+		return nil, err
+	}
+	existing := findObject(docs, key)
+
+	metadata := map[string]string{}
+	if existing != nil {
+		// Перезапись удаляет старый документ, поэтому блокировку проверяем до загрузки новой версии
+		if err := s.files.CheckLock(existing.ID, login); err != nil {
+			return nil, err
+		}
+
+		fileData.Public = existing.Public
+		fileData.Grant = existing.Grant
+		fileData.Data = existing.Data
+		for k, v := range existing.Metadata {
+			metadata[k] = v
+		}
+	}
+	for k, v := range fileData.Metadata {
+		if k == file.FOLDER_METADATA_KEY || k == file.COLLECTION_METADATA_KEY {
+			continue
+		}
+		metadata[k] = v
+	}
+	metadata[file.FOLDER_METADATA_KEY] = folder
+	if collection {
+		metadata[file.COLLECTION_METADATA_KEY] = "true"
+		fileData.Reader = bytes.NewReader(nil)
+	}
+
+	fileData.Name = name
+	fileData.File = !collection
+	fileData.Metadata = metadata
+	fileData.User = login
+
+	res, err := s.files.UploadFiles(fileData)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		if _, err := s.files.DeleteFile(existing.ID, login); err != nil {
+			s.logger.Error("failed to delete replaced document", "func", op, "fileID", existing.ID, "error", err)
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// DeleteObject удаляет документ по ключу и возвращает его, для несуществующего ключа возвращается nil
+func (s *S3Service) DeleteObject(login, bucket, key string) (*file.File, error) {
+	const op = "services.s3.DeleteObject"
+	s.logger.Info("Usecase start", "func", op, "login", login, "bucket", bucket, "key", key)
+
+	if bucket != login {
+		return nil, ErrAccessDenied
+	}
+
+	if _, _, _, err := parseKey(key); err != nil {
+		return nil, err
+	}
+
+	docs, err := s.files.GetFilesByUser(login, contracts.FilterData{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Удаление несуществующего ключа в S3 не считается ошибкой
+	doc := findObject(docs, key)
+	if doc == nil {
+		return nil, nil
+	}
+
+	return s.files.DeleteFile(doc.ID, login)
+}
+
+// visibleDocuments возвращает документы бакета, доступные пользователю: свои целиком, чужие - только публичные и выданные
+func (s *S3Service) visibleDocuments(login, bucket string) ([]file.File, error) {
+	docs, err := s.files.GetFilesByUser(bucket, contracts.FilterData{})
+	if err != nil {
+		return nil, err
+	}
+
+	if bucket == login {
+		return docs, nil
+	}
+
+	var visible []file.File
+	for _, doc := range docs {
+		if doc.Public || slices.Contains(doc.Grant, login) {
+			visible = append(visible, doc)
+		}
+	}
+
+	if len(visible) == 0 {
+		return nil, ErrNoSuchBucket
+	}
+
+	return visible, nil
+}
+
+// parseKey разбирает ключ на папку и имя, ключ с завершающим слешем обозначает пустую папку
+func parseKey(key string) (string, string, bool, error) {
+	collection := strings.HasSuffix(key, "/")
+	current := strings.TrimSuffix(key, "/")
+	if current == "" || file.CleanPath(current) != current {
+		return "", "", false, ErrInvalidKey
+	}
+
+	folder, name := file.SplitPath(current)
+	return folder, name, collection, nil
+}
+
+func objectKey(doc file.File) string {
+	if doc.IsCollection() {
+		return doc.Path() + "/"
+	}
+
+	return doc.Path()
+}
+
+func findObject(docs []file.File, key string) *file.File {
+	for i := range docs {
+		if objectKey(docs[i]) == key {
+			return &docs[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,7 @@
+package scrubservice
+
+import "errors"
+
+var (
+	ErrScrubRunning = errors.New("scrub is already running")
+)
@@ -0,0 +1,193 @@
+package scrubservice
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/file"
+	"astral/internal/domain/scrub"
+	"astral/internal/domain/webhook"
+	filesrepo "astral/internal/repository/files"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_TIMEOUT = time.Second * 30
+	READ_TIMEOUT    = time.Second * 120
+)
+
+// ScrubService периодически перечитывает объекты хранилища и сверяет их с контрольными суммами,
+// записанными при загрузке. Поврежденные документы переводятся в состояние error
+type ScrubService struct {
+	repo          filesrepo.StorageRepo
+	files         contracts.FilesInterface
+	webhooks      contracts.WebhooksInterface
+	notifications contracts.NotificationsInterface
+	logger        *slog.Logger
+	interval      time.Duration
+
+	running sync.Mutex
+	mu      sync.RWMutex
+	last    *scrub.Report
+}
+
+func NewScrubService(
+	repo filesrepo.StorageRepo,
+	files contracts.FilesInterface,
+	webhooks contracts.WebhooksInterface,
+	notifications contracts.NotificationsInterface,
+	logger *slog.Logger,
+	interval time.Duration,
+) *ScrubService {
+	return &ScrubService{
+		repo:          repo,
+		files:         files,
+		webhooks:      webhooks,
+		notifications: notifications,
+		logger:        logger.With("service", "ScrubService"),
+		interval:      interval,
+	}
+}
+
+// Run запускает проверку хранилища раз в interval до отмены контекста
+func (s *ScrubService) Run(ctx context.Context) {
+	const op = "services.scrub.Run"
+	s.logger.Info("scrub worker started", "func", op, "interval", s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("scrub worker stopped", "func", op)
+			return
+		case <-ticker.C:
+			report, err := s.Scrub(ctx)
+			if err != nil {
+				s.logger.Error("scrub failed", "func", op, "error", err)
+				continue
+			}
+
+			s.logger.Info("scrub finished", "func", op, "checked", report.Checked, "skipped", report.Skipped,
+				"failed", report.Failed, "corrupted", len(report.Corrupted))
+		}
+	}
+}
+
+// Report возвращает итог последней завершенной проверки, nil - проверок еще не было
+func (s *ScrubService) Report() *scrub.Report {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.last
+}
+
+// Scrub проверяет все документы хранилища один раз. Одновременно выполняется только одна проверка
+func (s *ScrubService) Scrub(ctx context.Context) (*scrub.Report, error) {
+	const op = "services.scrub.Scrub"
+	s.logger.Info("Usecase start", "func", op)
+
+	if !s.running.TryLock() {
+		return nil, ErrScrubRunning
+	}
+	defer s.running.Unlock()
+
+	startedAt := time.Now()
+	report := &scrub.Report{
+		Corrupted: []string{},
+		StartedAt: &startedAt,
+	}
+
+	listCtx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+	defer cancel()
+
+	files, err := s.repo.ListAllFiles(listCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, doc := range files {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// Документы, загруженные до появления контрольных сумм, и служебные объекты сверить не с чем.
+		// Содержимое незавершенной прямой загрузки еще не проверено и сверяется при ее завершении
+		if doc.Checksum == "" || doc.Status == file.StatusPending {
+			report.Skipped++
+			continue
+		}
+		report.Checked++
+
+		ok, err := s.verify(ctx, doc)
+		if err != nil {
+			s.logger.Warn("failed to read object", "func", op, "owner", doc.User, "fileID", doc.ID, "error", err)
+			report.Failed++
+			continue
+		}
+		if ok {
+			continue
+		}
+
+		report.Corrupted = append(report.Corrupted, doc.User+"/"+doc.ID)
+		s.logger.Error("document is corrupted", "func", op, "owner", doc.User, "fileID", doc.ID, "checksum", doc.Checksum)
+		if doc.Status != file.StatusError {
+			s.markCorrupted(doc)
+		}
+	}
+
+	sort.Strings(report.Corrupted)
+	finishedAt := time.Now()
+	report.FinishedAt = &finishedAt
+
+	s.mu.Lock()
+	s.last = report
+	s.mu.Unlock()
+
+	return report, nil
+}
+
+// verify перечитывает документ и сравнивает SHA-256 содержимого с записанным при загрузке.
+// Ошибка означает, что объект прочитать не удалось, и ничего не говорит о его целостности
+func (s *ScrubService) verify(ctx context.Context, doc file.File) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, READ_TIMEOUT)
+	defer cancel()
+
+	reader, err := s.repo.GetFileByID(ctx, doc.User, doc.ID)
+	if err != nil {
+		if errors.Is(err, filesrepo.ErrFileNotFound) {
+			// Документ удалили во время проверки
+			return true, nil
+		}
+		return false, err
+	}
+	defer reader.Close()
+
+	sha := sha256.New()
+	if _, err := io.Copy(sha, reader); err != nil {
+		return false, err
+	}
+
+	return hex.EncodeToString(sha.Sum(nil)) == doc.Checksum, nil
+}
+
+func (s *ScrubService) markCorrupted(doc file.File) {
+	const op = "services.scrub.markCorrupted"
+
+	res, err := s.files.SetStatus(doc.ID, doc.User, file.StatusError)
+	if err != nil {
+		s.logger.Error("failed to mark document as corrupted", "func", op, "owner", doc.User, "fileID", doc.ID, "error", err)
+		return
+	}
+
+	details := map[string]string{"checksum": doc.Checksum}
+	s.webhooks.Publish(webhook.EventDocumentCorrupted, "", *res, details)
+	s.notifications.Publish(webhook.EventDocumentCorrupted, "", *res, details)
+}
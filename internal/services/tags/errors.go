@@ -0,0 +1,11 @@
+package tagsservice
+
+import "errors"
+
+var (
+	ErrInvalidName  = errors.New("invalid tag name")
+	ErrInvalidColor = errors.New("invalid tag color, expected #rrggbb")
+	ErrTagCycle     = errors.New("tag cannot be nested into itself or its descendant")
+	ErrInvalidQuery = errors.New("invalid tag query")
+	ErrUnknownTag   = errors.New("unknown tag in query")
+)
@@ -0,0 +1,176 @@
+package tagsservice
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Запрос по тегам - логическое выражение над именами тегов:
+//
+//	invoices AND (2024 OR 2025) AND NOT draft
+//
+// Операторы AND, OR и NOT пишутся в любом регистре, NOT связывает сильнее AND, AND - сильнее OR.
+// Имя с пробелами или скобками берется в двойные кавычки: "tax reports"
+
+type matcher func(tags map[int64]bool) bool
+
+type queryParser struct {
+	tokens []string
+	pos    int
+	lookup func(name string) (int64, bool)
+}
+
+// parseQuery компилирует выражение в проверку набора тегов документа. lookup находит ID тега по имени
+func parseQuery(query string, lookup func(name string) (int64, bool)) (matcher, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, ErrInvalidQuery
+	}
+
+	p := &queryParser{tokens: tokens, lookup: lookup}
+	m, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, ErrInvalidQuery
+	}
+
+	return m, nil
+}
+
+func (p *queryParser) parseOr() (matcher, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(tags map[int64]bool) bool { return l(tags) || right(tags) }
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (matcher, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(tags map[int64]bool) bool { return l(tags) && right(tags) }
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseNot() (matcher, error) {
+	if p.keyword("NOT") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(tags map[int64]bool) bool { return !operand(tags) }, nil
+	}
+
+	return p.parseOperand()
+}
+
+func (p *queryParser) parseOperand() (matcher, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, ErrInvalidQuery
+	}
+
+	token := p.tokens[p.pos]
+	p.pos++
+
+	switch token {
+	case "(":
+		m, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			return nil, ErrInvalidQuery
+		}
+		p.pos++
+		return m, nil
+	case ")":
+		return nil, ErrInvalidQuery
+	}
+
+	if isKeyword(token) {
+		return nil, ErrInvalidQuery
+	}
+
+	id, ok := p.lookup(strings.Trim(token, `"`))
+	if !ok {
+		return nil, ErrUnknownTag
+	}
+
+	return func(tags map[int64]bool) bool { return tags[id] }, nil
+}
+
+func (p *queryParser) keyword(word string) bool {
+	if p.pos < len(p.tokens) && strings.EqualFold(p.tokens[p.pos], word) {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func isKeyword(token string) bool {
+	return strings.EqualFold(token, "AND") || strings.EqualFold(token, "OR") || strings.EqualFold(token, "NOT")
+}
+
+// tokenize разбивает запрос на скобки, слова и имена в кавычках. Имена в кавычках
+// сохраняют кавычки, чтобы "and" в кавычках не принималось за оператор
+func tokenize(query string) ([]string, error) {
+	var tokens []string
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end >= len(runes) || end == i+1 {
+				return nil, ErrInvalidQuery
+			}
+			tokens = append(tokens, string(runes[i:end+1]))
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' && runes[end] != '"' {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,120 @@
+package tagsservice
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var queryTags = map[string]int64{
+	"a":           1,
+	"b":           2,
+	"c":           3,
+	"tax reports": 4,
+	"and":         5,
+	"2024":        6,
+}
+
+func lookupQueryTag(name string) (int64, bool) {
+	id, ok := queryTags[name]
+	return id, ok
+}
+
+// tagSet собирает набор тегов документа из имен через запятую
+func tagSet(names string) map[int64]bool {
+	set := map[int64]bool{}
+	for _, name := range strings.Split(names, ",") {
+		if name != "" {
+			set[queryTags[name]] = true
+		}
+	}
+
+	return set
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		// наборы тегов, на которых выражение истинно и ложно
+		match   []string
+		noMatch []string
+	}{
+		{"single tag", "a", []string{"a", "a,b"}, []string{"", "b"}},
+		{"and", "a AND b", []string{"a,b"}, []string{"a", "b", ""}},
+		{"or", "a OR b", []string{"a", "b", "a,b"}, []string{"", "c"}},
+		{"not", "NOT a", []string{"", "b"}, []string{"a", "a,b"}},
+		{"double not", "NOT NOT a", []string{"a"}, []string{"", "b"}},
+		{"and binds tighter than or", "a OR b AND c", []string{"a", "b,c", "a,b"}, []string{"b", "c", ""}},
+		{"and binds tighter than or on the left", "a AND b OR c", []string{"a,b", "c"}, []string{"a", "b", ""}},
+		{"not binds tighter than and", "NOT a AND b", []string{"b"}, []string{"a,b", "a", ""}},
+		{"not binds tighter than or", "NOT a OR b", []string{"", "b", "a,b"}, []string{"a"}},
+		{"parentheses override precedence", "(a OR b) AND c", []string{"a,c", "b,c"}, []string{"a", "b", "c", "a,b"}},
+		{"not of a group", "NOT (a OR b)", []string{"", "c"}, []string{"a", "b"}},
+		{"nested parentheses", "((a AND (b)) OR c)", []string{"a,b", "c"}, []string{"a", "b"}},
+		{"operators in any case", "a and not b Or c", []string{"a", "c", "a,b,c"}, []string{"a,b", "b", ""}},
+		{"quoted name with spaces", `"tax reports" AND 2024`, []string{"tax reports,2024"}, []string{"tax reports", "2024"}},
+		{"quoted keyword is a tag", `"and" OR a`, []string{"and", "a"}, []string{"b", ""}},
+		{"no spaces around parentheses", "(a)AND(b)", []string{"a,b"}, []string{"a", "b"}},
+		{"left-associative or chain", "a OR b OR c", []string{"a", "b", "c"}, []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := parseQuery(tt.query, lookupQueryTag)
+			if err != nil {
+				t.Fatalf("parseQuery(%q): %v", tt.query, err)
+			}
+
+			for _, names := range tt.match {
+				if !m(tagSet(names)) {
+					t.Errorf("%q does not match tags {%s}", tt.query, names)
+				}
+			}
+			for _, names := range tt.noMatch {
+				if m(tagSet(names)) {
+					t.Errorf("%q matches tags {%s}", tt.query, names)
+				}
+			}
+		})
+	}
+}
+
+func TestParseQueryRejectsMalformed(t *testing.T) {
+	tests := []struct {
+		query string
+		err   error
+	}{
+		{"", ErrInvalidQuery},
+		{"   ", ErrInvalidQuery},
+		{"()", ErrInvalidQuery},
+		{"(", ErrInvalidQuery},
+		{")", ErrInvalidQuery},
+		{"(a OR b", ErrInvalidQuery},
+		{"a OR b)", ErrInvalidQuery},
+		{"((a)", ErrInvalidQuery},
+		{"a AND", ErrInvalidQuery},
+		{"AND a", ErrInvalidQuery},
+		{"a OR OR b", ErrInvalidQuery},
+		{"NOT", ErrInvalidQuery},
+		{"a NOT b", ErrInvalidQuery},
+		{"a b", ErrInvalidQuery},
+		{`""`, ErrInvalidQuery},
+		{`"tax reports`, ErrInvalidQuery},
+		{"a AND ()", ErrInvalidQuery},
+		{"missing", ErrUnknownTag},
+		{"a OR missing", ErrUnknownTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			m, err := parseQuery(tt.query, lookupQueryTag)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("parseQuery(%q) err = %v, want %v", tt.query, err, tt.err)
+			}
+			if m != nil {
+				t.Fatalf("parseQuery(%q) returned a matcher with an error", tt.query)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+package tagsservice
+
+import (
+	"astral/internal/domain/contracts"
+	"astral/internal/domain/dto"
+	"astral/internal/domain/file"
+	"astral/internal/domain/tag"
+	filesrepo "astral/internal/repository/files"
+	tagsrepo "astral/internal/repository/tags"
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	DEFAULT_TIMEOUT = time.Second * 5
+
+	MAX_NAME_LENGTH = 100
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// TagsService ведет теги пользователя. Документы связаны с тегами по ID, поэтому переименование
+// тега сразу видно на всех документах, а слияние переносит связи одной транзакцией
+type TagsService struct {
+	repo   tagsrepo.TagsRepo
+	files  contracts.FilesInterface
+	logger *slog.Logger
+}
+
+func NewTagsService(repo tagsrepo.TagsRepo, files contracts.FilesInterface, logger *slog.Logger) *TagsService {
+	return &TagsService{
+		repo:   repo,
+		files:  files,
+		logger: logger.With("service", "TagsService"),
+	}
+}
+
+func (s *TagsService) CreateTag(login, name, color string, parentID *int64) (*tag.Tag, error) {
+	const op = "services.tags.CreateTag"
+	s.logger.Info("Usecase start", "func", op, "login", login, "name", name)
+
+	name, err := validateName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateColor(color); err != nil {
+		return nil, err
+	}
+
+	if parentID != nil {
+		if _, err := s.GetTag(login, *parentID); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.CreateTag(ctx, dto.TagData{
+		Login:    login,
+		Name:     name,
+		Color:    strings.ToLower(color),
+		ParentID: parentID,
+	})
+}
+
+func (s *TagsService) GetTags(login string) ([]tag.Tag, error) {
+	const op = "services.tags.GetTags"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.GetTags(ctx, login)
+}
+
+func (s *TagsService) GetTag(login string, id int64) (*tag.Tag, error) {
+	const op = "services.tags.GetTag"
+	s.logger.Info("Usecase start", "func", op, "login", login, "id", id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.GetTag(ctx, login, id)
+}
+
+// UpdateTag переименовывает, перекрашивает или переносит тег. Перенос в собственного потомка запрещен
+func (s *TagsService) UpdateTag(login string, id int64, update tag.Update) (*tag.Tag, error) {
+	const op = "services.tags.UpdateTag"
+	s.logger.Info("Usecase start", "func", op, "login", login, "id", id)
+
+	if update.Name != nil {
+		name, err := validateName(*update.Name)
+		if err != nil {
+			return nil, err
+		}
+		update.Name = &name
+	}
+
+	if update.Color != nil {
+		if err := validateColor(*update.Color); err != nil {
+			return nil, err
+		}
+		color := strings.ToLower(*update.Color)
+		update.Color = &color
+	}
+
+	if update.SetParent && update.ParentID != nil {
+		tags, err := s.GetTags(login)
+		if err != nil {
+			return nil, err
+		}
+
+		parents := parentsOf(tags)
+		if _, ok := parents[*update.ParentID]; !ok {
+			return nil, tagsrepo.ErrTagNotFound
+		}
+		if isDescendant(parents, *update.ParentID, id) {
+			return nil, ErrTagCycle
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.UpdateTag(ctx, login, id, update)
+}
+
+func (s *TagsService) DeleteTag(login string, id int64) (*tag.Tag, error) {
+	const op = "services.tags.DeleteTag"
+	s.logger.Info("Usecase start", "func", op, "login", login, "id", id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.DeleteTag(ctx, login, id)
+}
+
+// MergeTags сливает тег source в target: документы и дочерние теги source переходят к target, source удаляется
+func (s *TagsService) MergeTags(login string, sourceID, targetID int64) (*tag.Tag, error) {
+	const op = "services.tags.MergeTags"
+	s.logger.Info("Usecase start", "func", op, "login", login, "source", sourceID, "target", targetID)
+
+	if sourceID == targetID {
+		return nil, ErrTagCycle
+	}
+
+	tags, err := s.GetTags(login)
+	if err != nil {
+		return nil, err
+	}
+
+	parents := parentsOf(tags)
+	if _, ok := parents[sourceID]; !ok {
+		return nil, tagsrepo.ErrTagNotFound
+	}
+	if _, ok := parents[targetID]; !ok {
+		return nil, tagsrepo.ErrTagNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.MergeTags(ctx, login, sourceID, targetID, isDescendant(parents, targetID, sourceID))
+}
+
+func (s *TagsService) AttachTag(login, documentID string, tagID int64) (*file.File, *tag.Tag, error) {
+	const op = "services.tags.AttachTag"
+	s.logger.Info("Usecase start", "func", op, "login", login, "documentID", documentID, "tagID", tagID)
+
+	doc, current, err := s.documentTag(login, documentID, tagID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if err := s.repo.AttachTag(ctx, documentID, tagID); err != nil {
+		return nil, nil, err
+	}
+
+	return doc, current, nil
+}
+
+func (s *TagsService) DetachTag(login, documentID string, tagID int64) (*file.File, *tag.Tag, error) {
+	const op = "services.tags.DetachTag"
+	s.logger.Info("Usecase start", "func", op, "login", login, "documentID", documentID, "tagID", tagID)
+
+	doc, current, err := s.documentTag(login, documentID, tagID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if err := s.repo.DetachTag(ctx, documentID, tagID); err != nil {
+		return nil, nil, err
+	}
+
+	return doc, current, nil
+}
+
+func (s *TagsService) GetDocumentTags(login, documentID string) ([]tag.Tag, error) {
+	const op = "services.tags.GetDocumentTags"
+	s.logger.Info("Usecase start", "func", op, "login", login, "documentID", documentID)
+
+	if _, err := s.ownDocument(login, documentID); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.GetDocumentTags(ctx, login, documentID)
+}
+
+// FindDocuments возвращает документы пользователя, теги которых удовлетворяют запросу.
+// Документ с вложенным тегом подходит и под всех его предков
+func (s *TagsService) FindDocuments(login, query string) ([]file.File, error) {
+	const op = "services.tags.FindDocuments"
+	s.logger.Info("Usecase start", "func", op, "login", login, "query", query)
+
+	tags, err := s.GetTags(login)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]int64, len(tags))
+	for _, t := range tags {
+		ids[strings.ToLower(t.Name)] = t.ID
+	}
+
+	match, err := parseQuery(query, func(name string) (int64, bool) {
+		id, ok := ids[strings.ToLower(name)]
+		return id, ok
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	tagged, err := s.repo.GetTaggedDocuments(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := s.files.GetFilesByUser(login, contracts.FilterData{})
+	if err != nil {
+		return nil, err
+	}
+
+	parents := parentsOf(tags)
+	res := []file.File{}
+	for _, doc := range docs {
+		if match(withAncestors(parents, tagged[doc.ID])) {
+			res = append(res, doc)
+		}
+	}
+
+	return res, nil
+}
+
+func (s *TagsService) documentTag(login, documentID string, tagID int64) (*file.File, *tag.Tag, error) {
+	doc, err := s.ownDocument(login, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current, err := s.GetTag(login, tagID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return doc, current, nil
+}
+
+// ownDocument ищет документ среди документов пользователя: теги - личные, чужие документы ими не отмечаются
+func (s *TagsService) ownDocument(login, documentID string) (*file.File, error) {
+	docs, err := s.files.GetFilesByUser(login, contracts.FilterData{})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range docs {
+		if docs[i].ID == documentID {
+			return &docs[i], nil
+		}
+	}
+
+	return nil, filesrepo.ErrFileNotFound
+}
+
+func validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MAX_NAME_LENGTH || strings.Contains(name, `"`) {
+		return "", ErrInvalidName
+	}
+
+	return name, nil
+}
+
+func validateColor(color string) error {
+	if color != "" && !colorPattern.MatchString(color) {
+		return ErrInvalidColor
+	}
+
+	return nil
+}
+
+// parentsOf возвращает родителя каждого тега, 0 - корневой тег
+func parentsOf(tags []tag.Tag) map[int64]int64 {
+	parents := make(map[int64]int64, len(tags))
+	for _, t := range tags {
+		parents[t.ID] = 0
+		if t.ParentID != nil {
+			parents[t.ID] = *t.ParentID
+		}
+	}
+
+	return parents
+}
+
+// isDescendant проверяет, что id совпадает с ancestor или вложен в него
+func isDescendant(parents map[int64]int64, id, ancestor int64) bool {
+	for steps := 0; id != 0 && steps <= len(parents); steps++ {
+		if id == ancestor {
+			return true
+		}
+		id = parents[id]
+	}
+
+	return false
+}
+
+func withAncestors(parents map[int64]int64, tagIDs []int64) map[int64]bool {
+	res := make(map[int64]bool, len(tagIDs))
+	for _, id := range tagIDs {
+		for steps := 0; id != 0 && !res[id] && steps <= len(parents); steps++ {
+			res[id] = true
+			id = parents[id]
+		}
+	}
+
+	return res
+}
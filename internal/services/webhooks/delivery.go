@@ -0,0 +1,171 @@
+package webhooksservice
+
+import (
+	"astral/internal/domain/webhook"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	BASE_BACKOFF = time.Second * 10
+
+	// Из ответа получателя в журнал сохраняется только начало тела
+	MAX_RESPONSE_BODY = 1024
+
+	USER_AGENT       = "Astral-Webhooks/1.0"
+	HEADER_EVENT     = "X-Astral-Event"
+	HEADER_DELIVERY  = "X-Astral-Delivery"
+	HEADER_TIMESTAMP = "X-Astral-Timestamp"
+	HEADER_SIGNATURE = "X-Astral-Signature"
+)
+
+// Run отправляет доставки из очереди до отмены контекста
+func (s *WebhooksService) Run(ctx context.Context) {
+	const op = "services.webhooks.Run"
+	s.logger.Info("webhooks worker started", "func", op, "interval", s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("webhooks worker stopped", "func", op)
+			return
+		case <-ticker.C:
+			for s.processBatch(ctx) == s.batchSize {
+				if ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+func (s *WebhooksService) processBatch(ctx context.Context) int {
+	const op = "services.webhooks.processBatch"
+
+	fetchCtx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+	defer cancel()
+
+	tasks, err := s.repo.FetchDue(fetchCtx, s.batchSize)
+	if err != nil {
+		s.logger.Warn("failed to fetch webhook deliveries", "func", op, "error", err)
+		return 0
+	}
+
+	// Медленный получатель не должен задерживать доставки остальным, поэтому пачка отправляется параллельно
+	var wg sync.WaitGroup
+	for _, task := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.processTask(ctx, task)
+		}()
+	}
+	wg.Wait()
+
+	return len(tasks)
+}
+
+func (s *WebhooksService) processTask(ctx context.Context, task webhook.Task) {
+	const op = "services.webhooks.processTask"
+
+	attempt := s.deliver(ctx, task)
+
+	ctx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if attempt.Error == "" {
+		if err := s.repo.Complete(ctx, attempt); err != nil {
+			s.logger.Error("failed to complete webhook delivery", "func", op, "deliveryID", task.Delivery.ID, "error", err)
+		}
+		return
+	}
+
+	attempts := task.Delivery.Attempts + 1
+	final := attempts >= s.maxAttempts
+	backoff := s.backoff(task.Delivery.Attempts)
+	s.logger.Warn("webhook delivery failed", "func", op, "deliveryID", task.Delivery.ID, "url", task.URL, "attempts", attempts, "final", final, "retry_in", backoff, "error", attempt.Error)
+
+	if err := s.repo.Fail(ctx, attempt, backoff, final); err != nil {
+		s.logger.Error("failed to reschedule webhook delivery", "func", op, "deliveryID", task.Delivery.ID, "error", err)
+	}
+}
+
+// deliver отправляет событие получателю, успешной считается доставка с ответом 2xx
+func (s *WebhooksService) deliver(ctx context.Context, task webhook.Task) webhook.Attempt {
+	attempt := webhook.Attempt{DeliveryID: task.Delivery.ID}
+
+	secret, err := s.decryptSecret(task.EncryptedSecret)
+	if err != nil {
+		attempt.Error = "failed to decrypt webhook secret"
+		return attempt
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, task.URL, bytes.NewReader(task.Delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", USER_AGENT)
+	req.Header.Set(HEADER_EVENT, string(task.Delivery.Event))
+	req.Header.Set(HEADER_DELIVERY, strconv.FormatInt(task.Delivery.ID, 10))
+	req.Header.Set(HEADER_TIMESTAMP, timestamp)
+	req.Header.Set(HEADER_SIGNATURE, "sha256="+Sign(secret, timestamp, task.Delivery.Payload))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	attempt.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, MAX_RESPONSE_BODY))
+	attempt.ResponseCode = &resp.StatusCode
+	// PostgreSQL не хранит нулевой байт в TEXT
+	attempt.ResponseBody = strings.ReplaceAll(string(bytes.ToValidUTF8(body, nil)), "\x00", "")
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return attempt
+}
+
+// Sign считает подпись доставки: HMAC-SHA256 секрета от "<timestamp>.<тело запроса>" в hex
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *WebhooksService) backoff(attempts int) time.Duration {
+	backoff := BASE_BACKOFF
+	for i := 0; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= s.maxBackoff {
+			return s.maxBackoff
+		}
+	}
+
+	return backoff
+}
@@ -0,0 +1,8 @@
+package webhooksservice
+
+import "errors"
+
+var (
+	ErrInvalidURL    = errors.New("webhook url must be an absolute http or https url")
+	ErrInvalidEvents = errors.New("unknown or empty webhook events")
+)
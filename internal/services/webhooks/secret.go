@@ -0,0 +1,61 @@
+package webhooksservice
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+// Секрет нужен для подписи каждой доставки, поэтому хранится зашифрованным, а не хэшем
+
+func (s *WebhooksService) secretCipher() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(s.secretKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func (s *WebhooksService) encryptSecret(secret string) (string, error) {
+	gcm, err := s.secretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *WebhooksService) decryptSecret(encrypted string) (string, error) {
+	gcm, err := s.secretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("encrypted secret is too short")
+	}
+
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	secret, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(secret), nil
+}
@@ -0,0 +1,237 @@
+package webhooksservice
+
+import (
+	"astral/internal/domain/dto"
+	"astral/internal/domain/file"
+	"astral/internal/domain/webhook"
+	webhooksrepo "astral/internal/repository/webhooks"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DEFAULT_TIMEOUT = time.Second * 5
+
+	SECRET_BYTES = 32
+
+	DEFAULT_DELIVERIES_LIMIT = 50
+	MAX_DELIVERIES_LIMIT     = 500
+)
+
+type WebhooksService struct {
+	repo        webhooksrepo.WebhooksRepo
+	logger      *slog.Logger
+	secretKey   string
+	client      *http.Client
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	maxBackoff  time.Duration
+}
+
+func NewWebhooksService(
+	repo webhooksrepo.WebhooksRepo,
+	logger *slog.Logger,
+	secretKey string,
+	interval time.Duration,
+	batchSize int,
+	maxAttempts int,
+	maxBackoff time.Duration,
+	timeout time.Duration,
+) *WebhooksService {
+	return &WebhooksService{
+		repo:      repo,
+		logger:    logger.With("service", "WebhooksService"),
+		secretKey: secretKey,
+		client: &http.Client{
+			Timeout: timeout,
+			// Редирект считаем ответом получателя: подпись привязана к исходному адресу
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		maxBackoff:  maxBackoff,
+	}
+}
+
+// CreateWebhook регистрирует получателя событий. Если секрет не передан, он генерируется
+// и, как и переданный, возвращается только в ответе на создание
+func (s *WebhooksService) CreateWebhook(login, rawURL string, events []webhook.EventType, secret string) (*webhook.Webhook, error) {
+	const op = "services.webhooks.CreateWebhook"
+	s.logger.Info("Usecase start", "func", op, "login", login, "url", rawURL)
+
+	// Адреса в локальной сети разрешены намеренно, чтобы получателем мог быть тестовый сервер
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, ErrInvalidURL
+	}
+
+	if len(events) == 0 {
+		return nil, ErrInvalidEvents
+	}
+
+	var eventNames []string
+	for _, event := range events {
+		if !webhook.IsEventType(string(event)) {
+			return nil, ErrInvalidEvents
+		}
+		if !slices.Contains(eventNames, string(event)) {
+			eventNames = append(eventNames, string(event))
+		}
+	}
+
+	if secret == "" {
+		secretBytes := make([]byte, SECRET_BYTES)
+		if _, err := rand.Read(secretBytes); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = base64.RawURLEncoding.EncodeToString(secretBytes)
+	}
+
+	encrypted, err := s.encryptSecret(secret)
+	if err != nil {
+		s.logger.Error("failed to encrypt webhook secret", "func", op, "login", login, "error", err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	res, err := s.repo.CreateWebhook(ctx, dto.WebhookData{
+		Login:           login,
+		URL:             target.String(),
+		Events:          eventNames,
+		EncryptedSecret: encrypted,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res.Secret = secret
+	return res, nil
+}
+
+func (s *WebhooksService) GetWebhooks(login string) ([]webhook.Webhook, error) {
+	const op = "services.webhooks.GetWebhooks"
+	s.logger.Info("Usecase start", "func", op, "login", login)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.GetWebhooks(ctx, login)
+}
+
+func (s *WebhooksService) GetWebhook(login, id string) (*webhook.Webhook, error) {
+	const op = "services.webhooks.GetWebhook"
+	s.logger.Info("Usecase start", "func", op, "login", login, "id", id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.GetWebhook(ctx, login, id)
+}
+
+func (s *WebhooksService) DeleteWebhook(login, id string) (*webhook.Webhook, error) {
+	const op = "services.webhooks.DeleteWebhook"
+	s.logger.Info("Usecase start", "func", op, "login", login, "id", id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	return s.repo.DeleteWebhook(ctx, login, id)
+}
+
+func (s *WebhooksService) GetDeliveries(login, webhookID string, beforeID int64, limit int) ([]webhook.Delivery, error) {
+	const op = "services.webhooks.GetDeliveries"
+	s.logger.Info("Usecase start", "func", op, "login", login, "webhookID", webhookID)
+
+	if limit <= 0 {
+		limit = DEFAULT_DELIVERIES_LIMIT
+	}
+	limit = min(limit, MAX_DELIVERIES_LIMIT)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	// Доставки доступны только владельцу webhook
+	if _, err := s.repo.GetWebhook(ctx, login, webhookID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetDeliveries(ctx, webhookID, beforeID, limit)
+}
+
+func (s *WebhooksService) GetDelivery(login, webhookID string, deliveryID int64) (*webhook.Delivery, error) {
+	const op = "services.webhooks.GetDelivery"
+	s.logger.Info("Usecase start", "func", op, "login", login, "webhookID", webhookID, "deliveryID", deliveryID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if _, err := s.repo.GetWebhook(ctx, login, webhookID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetDelivery(ctx, webhookID, deliveryID)
+}
+
+// Redeliver ставит доставку в очередь повторно, отправка будет с тем же телом и ID события
+func (s *WebhooksService) Redeliver(login, webhookID string, deliveryID int64) (*webhook.Delivery, error) {
+	const op = "services.webhooks.Redeliver"
+	s.logger.Info("Usecase start", "func", op, "login", login, "webhookID", webhookID, "deliveryID", deliveryID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	if _, err := s.repo.GetWebhook(ctx, login, webhookID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Redeliver(ctx, webhookID, deliveryID)
+}
+
+// Publish ставит событие документа в очередь доставки подписчикам владельца.
+// Как и аудит, ошибка очереди не должна ломать действие пользователя, поэтому только логируется
+func (s *WebhooksService) Publish(eventType webhook.EventType, actor string, doc file.File, details map[string]string) {
+	const op = "services.webhooks.Publish"
+
+	if doc.User == "" {
+		s.logger.Warn("document owner is unknown, event is skipped", "func", op, "event", eventType, "fileID", doc.ID)
+		return
+	}
+
+	payload := webhook.Payload{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Actor:     actor,
+		Owner:     doc.User,
+		Document:  doc,
+		Details:   details,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_TIMEOUT)
+	defer cancel()
+
+	count, err := s.repo.Enqueue(ctx, doc.User, payload)
+	if err != nil {
+		s.logger.Error("failed to enqueue webhook event", "func", op, "event", eventType, "owner", doc.User, "fileID", doc.ID, "error", err)
+		return
+	}
+
+	if count > 0 {
+		s.logger.Info("webhook event enqueued", "func", op, "event", eventType, "owner", doc.User, "deliveries", count)
+	}
+}
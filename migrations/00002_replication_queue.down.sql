@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_replication_queue_next_attempt;
+
+DROP TABLE IF EXISTS replication_queue;
+
+DROP TYPE IF EXISTS replication_operation;
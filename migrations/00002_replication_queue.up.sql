@@ -0,0 +1,17 @@
+CREATE TYPE replication_operation AS ENUM (
+    'put',
+    'delete'
+);
+
+CREATE TABLE IF NOT EXISTS replication_queue (
+    id BIGSERIAL PRIMARY KEY,
+    operation replication_operation NOT NULL,
+    object_key VARCHAR(512) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_replication_queue_next_attempt
+ON replication_queue(next_attempt_at);
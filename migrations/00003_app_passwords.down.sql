@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_app_passwords_user;
+
+DROP TABLE IF EXISTS app_passwords;
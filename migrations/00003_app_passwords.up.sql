@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS app_passwords (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_login VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_login) REFERENCES users(login) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_app_passwords_user
+ON app_passwords(user_login);
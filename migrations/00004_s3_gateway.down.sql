@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS s3_multipart_uploads;
+
+DROP INDEX IF EXISTS idx_access_keys_user;
+
+DROP TABLE IF EXISTS access_keys;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS access_keys (
+    access_key_id VARCHAR(32) PRIMARY KEY,
+    user_login VARCHAR(255) NOT NULL,
+    secret_key TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_login) REFERENCES users(login) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_access_keys_user
+ON access_keys(user_login);
+
+CREATE TABLE IF NOT EXISTS s3_multipart_uploads (
+    upload_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_login VARCHAR(255) NOT NULL,
+    bucket VARCHAR(255) NOT NULL,
+    object_key VARCHAR(1024) NOT NULL,
+    content_type VARCHAR(255),
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_login) REFERENCES users(login) ON DELETE CASCADE
+);
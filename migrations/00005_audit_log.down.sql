@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS audit_events_no_truncate ON audit_events;
+
+DROP TRIGGER IF EXISTS audit_events_no_update ON audit_events;
+
+DROP FUNCTION IF EXISTS audit_events_append_only();
+
+DROP INDEX IF EXISTS idx_audit_events_target;
+
+DROP INDEX IF EXISTS idx_audit_events_owner;
+
+DROP INDEX IF EXISTS idx_audit_events_actor;
+
+DROP TABLE IF EXISTS audit_events;
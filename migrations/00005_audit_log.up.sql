@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    action VARCHAR(32) NOT NULL,
+    actor VARCHAR(255),
+    owner VARCHAR(255),
+    target_type VARCHAR(32),
+    target_id VARCHAR(255),
+    ip VARCHAR(64),
+    user_agent TEXT,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor
+ON audit_events(actor, id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_owner
+ON audit_events(owner, id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_target
+ON audit_events(target_id);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update
+BEFORE UPDATE OR DELETE ON audit_events
+FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+BEFORE TRUNCATE ON audit_events
+FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
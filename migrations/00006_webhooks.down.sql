@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TYPE IF EXISTS webhook_delivery_status;
+DROP TABLE IF EXISTS webhooks;
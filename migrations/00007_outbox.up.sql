@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+    seq BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE,
+    event_type VARCHAR(64) NOT NULL,
+    event_key VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    published_at TIMESTAMP,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending
+ON outbox_events(next_attempt_at) WHERE published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published
+ON outbox_events(published_at) WHERE published_at IS NOT NULL;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS document_data (
+    document_id VARCHAR(255) PRIMARY KEY,
+    user_login VARCHAR(255) NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_login) REFERENCES users(login) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_data_user
+ON document_data(user_login);
+
+CREATE INDEX IF NOT EXISTS idx_document_data_data
+ON document_data USING GIN (data jsonb_path_ops);
@@ -0,0 +1,2 @@
+ALTER TABLE document_data
+DROP COLUMN IF EXISTS revision;
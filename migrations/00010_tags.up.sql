@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id BIGSERIAL PRIMARY KEY,
+    user_login VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    color VARCHAR(7),
+    parent_id BIGINT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_login) REFERENCES users(login) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES tags(id) ON DELETE SET NULL,
+    UNIQUE (user_login, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tags_parent
+ON tags(parent_id);
+
+CREATE TABLE IF NOT EXISTS document_tags (
+    document_id VARCHAR(255) NOT NULL,
+    tag_id BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (document_id, tag_id),
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_tags_tag
+ON document_tags(tag_id);
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS comments (
+    id BIGSERIAL PRIMARY KEY,
+    document_id VARCHAR(255) NOT NULL,
+    document_owner VARCHAR(255) NOT NULL,
+    parent_id BIGINT,
+    author VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    mentions TEXT[] NOT NULL DEFAULT '{}',
+    anchor_page INT,
+    anchor_start BIGINT,
+    anchor_end BIGINT,
+    resolved BOOLEAN NOT NULL DEFAULT FALSE,
+    resolved_by VARCHAR(255),
+    resolved_at TIMESTAMP,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    FOREIGN KEY (author) REFERENCES users(login) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES comments(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_document
+ON comments(document_id, id);
+
+CREATE INDEX IF NOT EXISTS idx_comments_parent
+ON comments(parent_id);
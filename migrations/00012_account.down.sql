@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_takeout_exports_status;
+
+DROP INDEX IF EXISTS idx_takeout_exports_user;
+
+DROP TABLE IF EXISTS takeout_exports;
+
+DROP INDEX IF EXISTS idx_users_delete_after;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS delete_after,
+DROP COLUMN IF EXISTS deletion_requested_at;
@@ -0,0 +1,25 @@
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS deletion_requested_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS delete_after TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_delete_after
+ON users(delete_after) WHERE delete_after IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS takeout_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_login VARCHAR(255) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    size BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    lease_until TIMESTAMP,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    FOREIGN KEY (user_login) REFERENCES users(login) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_takeout_exports_user
+ON takeout_exports(user_login, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_takeout_exports_status
+ON takeout_exports(status);
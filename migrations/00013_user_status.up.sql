@@ -0,0 +1,3 @@
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS disabled_reason TEXT;
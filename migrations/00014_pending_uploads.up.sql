@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS pending_uploads (
+    document_id VARCHAR(255) PRIMARY KEY,
+    user_login VARCHAR(255) NOT NULL,
+    expected_size BIGINT NOT NULL,
+    expected_sha256 VARCHAR(64),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_login) REFERENCES users(login) ON DELETE CASCADE
+);
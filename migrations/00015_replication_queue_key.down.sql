@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_replication_queue_object_key;
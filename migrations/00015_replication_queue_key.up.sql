@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_replication_queue_object_key
+ON replication_queue(object_key, id);